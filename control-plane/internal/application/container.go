@@ -35,7 +35,7 @@ func CreateServiceContainer(cfg *config.Config, agentfieldHome string) *framewor
 
 	// Create services
 	packageService := services.NewPackageService(registryStorage, fileSystem, agentfieldHome)
-	agentService := services.NewAgentService(processManager, portManager, registryStorage, nil, agentfieldHome) // nil agentClient for now
+	agentService := services.NewAgentService(processManager, portManager, registryStorage, nil, agentfieldHome, nil) // nil agentClient and node store for now
 	devService := services.NewDevService(processManager, portManager, fileSystem)
 
 	// Create DID services if enabled
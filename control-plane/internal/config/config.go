@@ -27,6 +27,12 @@ type UIConfig struct {
 	SourcePath string `yaml:"source_path" mapstructure:"source_path"` // Path to UI source for building
 	DistPath   string `yaml:"dist_path" mapstructure:"dist_path"`     // Path to built UI assets for serving
 	DevPort    int    `yaml:"dev_port" mapstructure:"dev_port"`       // Port for UI dev server
+
+	// SensitiveMemoryKeyPattern is a regular expression; memory keys matching
+	// it have their values redacted by the session memory inspector. Empty
+	// uses a default covering passwords, secrets, tokens and API keys; an
+	// invalid pattern fails server startup.
+	SensitiveMemoryKeyPattern string `yaml:"sensitive_memory_key_pattern" mapstructure:"sensitive_memory_key_pattern"`
 }
 
 // AgentFieldConfig holds the core AgentField server configuration.
@@ -34,6 +40,7 @@ type AgentFieldConfig struct {
 	Port             int                    `yaml:"port"`
 	ExecutionCleanup ExecutionCleanupConfig `yaml:"execution_cleanup" mapstructure:"execution_cleanup"`
 	ExecutionQueue   ExecutionQueueConfig   `yaml:"execution_queue" mapstructure:"execution_queue"`
+	NodeEvents       NodeEventsConfig       `yaml:"node_events" mapstructure:"node_events"`
 }
 
 // ExecutionCleanupConfig holds configuration for execution cleanup and garbage collection
@@ -55,6 +62,12 @@ type ExecutionQueueConfig struct {
 	WebhookMaxRetryBackoff time.Duration `yaml:"webhook_max_retry_backoff" mapstructure:"webhook_max_retry_backoff"`
 }
 
+// NodeEventsConfig selects the sinks that receive node lifecycle events.
+type NodeEventsConfig struct {
+	// LogSink writes every lifecycle event to the control plane log.
+	LogSink bool `yaml:"log_sink" mapstructure:"log_sink"`
+}
+
 // FeatureConfig holds configuration for enabling/disabling features.
 type FeatureConfig struct {
 	DID DIDConfig `yaml:"did" mapstructure:"did"`
@@ -1,7 +1,25 @@
 package interfaces
 
 import (
+	"context"
+	"errors"
+
 	"github.com/Agent-Field/agentfield/control-plane/internal/core/domain"
+	"github.com/Agent-Field/agentfield/control-plane/pkg/types"
+)
+
+var (
+	// ErrAgentAlreadyRegistered is returned when registering a node ID that already exists.
+	ErrAgentAlreadyRegistered = errors.New("agent node already registered")
+	// ErrAgentNotRegistered is returned when deregistering a node ID that does not exist.
+	ErrAgentNotRegistered = errors.New("agent node not registered")
+	// ErrInvalidAgentNode is returned when a node is missing required fields.
+	ErrInvalidAgentNode = errors.New("invalid agent node")
+	// ErrNodeStoreUnavailable is returned when node registration is attempted
+	// on a service that was built without an AgentNodeStore.
+	ErrNodeStoreUnavailable = errors.New("agent node store not configured")
+	// ErrEmptySearchQuery is returned when searching nodes with a blank query.
+	ErrEmptySearchQuery = errors.New("search query is empty")
 )
 
 // PackageService defines the contract for package management operations.
@@ -43,6 +61,32 @@ type AgentService interface {
 	// ListRunningAgents returns a list of all currently running agents.
 	// Returns an error if the agent information cannot be retrieved.
 	ListRunningAgents() ([]domain.RunningAgent, error)
+
+	// RegisterAgent records a node in the control plane registry without
+	// waiting for it to self-register.
+	// Returns ErrInvalidAgentNode if required fields are missing and
+	// ErrAgentAlreadyRegistered if the node ID is taken.
+	RegisterAgent(ctx context.Context, node *types.AgentNode) error
+
+	// DeregisterAgent removes a node from the control plane registry.
+	// Returns ErrAgentNotRegistered if the node ID is unknown.
+	DeregisterAgent(ctx context.Context, id string) error
+
+	// GetAgentStatusesByLabel counts registered nodes tagged key=value by
+	// health and lifecycle status. No matches yield an empty aggregate.
+	GetAgentStatusesByLabel(ctx context.Context, key, value string) (*types.NodeStatusAggregate, error)
+
+	// GetAgentManifest returns the manifest a registered node advertises, or
+	// nil if it has none.
+	// Returns ErrAgentNotRegistered if the node ID is unknown.
+	GetAgentManifest(ctx context.Context, id string) (*types.AgentManifest, error)
+
+	// SearchAgents returns registered nodes whose ID or deployment tag values
+	// contain query, ignoring case. Nodes matching at the start of a field
+	// rank ahead of those matching only inside one; ties are ordered by ID.
+	// A limit of zero or less returns every match.
+	// Returns ErrEmptySearchQuery if query is blank.
+	SearchAgents(ctx context.Context, query string, limit int) ([]*types.AgentNode, error)
 }
 
 // DevService defines the contract for development mode operations.
@@ -1,7 +1,13 @@
 // agentfield/internal/core/interfaces/storage.go
 package interfaces
 
-import "github.com/Agent-Field/agentfield/control-plane/internal/core/domain"
+import (
+	"context"
+	"errors"
+
+	"github.com/Agent-Field/agentfield/control-plane/internal/core/domain"
+	"github.com/Agent-Field/agentfield/control-plane/pkg/types"
+)
 
 type FileSystemAdapter interface {
 	ReadFile(path string) ([]byte, error)
@@ -18,6 +24,23 @@ type RegistryStorage interface {
 	SavePackage(name string, pkg *domain.InstalledPackage) error
 }
 
+var (
+	// ErrAgentNodeExists is returned by AgentNodeStore.CreateAgent when the ID is taken.
+	ErrAgentNodeExists = errors.New("agent node already exists")
+	// ErrAgentNodeNotFound is returned by AgentNodeStore.DeleteAgent when the ID is unknown.
+	ErrAgentNodeNotFound = errors.New("agent node not found")
+)
+
+// AgentNodeStore is the subset of the control plane storage used to manage
+// registered agent nodes.
+type AgentNodeStore interface {
+	// CreateAgent inserts a node without overwriting an existing one.
+	CreateAgent(ctx context.Context, agent *types.AgentNode) error
+	GetAgent(ctx context.Context, id string) (*types.AgentNode, error)
+	DeleteAgent(ctx context.Context, id string) error
+	ListAgents(ctx context.Context, filters types.AgentFilters) ([]*types.AgentNode, error)
+}
+
 type ConfigStorage interface {
 	LoadAgentFieldConfig(path string) (*domain.AgentFieldConfig, error)
 	SaveAgentFieldConfig(path string, config *domain.AgentFieldConfig) error
@@ -11,6 +11,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"strings"
 	"syscall"
 	"time"
@@ -18,6 +19,7 @@ import (
 	"github.com/Agent-Field/agentfield/control-plane/internal/core/domain"
 	"github.com/Agent-Field/agentfield/control-plane/internal/core/interfaces"
 	"github.com/Agent-Field/agentfield/control-plane/internal/packages"
+	"github.com/Agent-Field/agentfield/control-plane/pkg/types"
 	"gopkg.in/yaml.v3"
 )
 
@@ -28,15 +30,19 @@ type DefaultAgentService struct {
 	registryStorage interfaces.RegistryStorage
 	agentClient     interfaces.AgentClient
 	agentfieldHome  string
+	nodeStore       interfaces.AgentNodeStore
 }
 
-// NewAgentService creates a new agent service instance
+// NewAgentService creates a new agent service instance.
+// nodeStore may be nil, in which case RegisterAgent and DeregisterAgent
+// return interfaces.ErrNodeStoreUnavailable.
 func NewAgentService(
 	processManager interfaces.ProcessManager,
 	portManager interfaces.PortManager,
 	registryStorage interfaces.RegistryStorage,
 	agentClient interfaces.AgentClient,
 	agentfieldHome string,
+	nodeStore interfaces.AgentNodeStore,
 ) interfaces.AgentService {
 	return &DefaultAgentService{
 		processManager:  processManager,
@@ -44,6 +50,7 @@ func NewAgentService(
 		registryStorage: registryStorage,
 		agentClient:     agentClient,
 		agentfieldHome:  agentfieldHome,
+		nodeStore:       nodeStore,
 	}
 }
 
@@ -370,6 +377,169 @@ func (as *DefaultAgentService) reconcileProcessState(pkg *packages.InstalledPack
 	return true, false
 }
 
+// RegisterAgent records a node in the control plane registry on behalf of an admin.
+func (as *DefaultAgentService) RegisterAgent(ctx context.Context, node *types.AgentNode) error {
+	if as.nodeStore == nil {
+		return interfaces.ErrNodeStoreUnavailable
+	}
+	if node == nil {
+		return fmt.Errorf("%w: node is required", interfaces.ErrInvalidAgentNode)
+	}
+	node.ID = strings.TrimSpace(node.ID)
+	if node.ID == "" {
+		return fmt.Errorf("%w: id is required", interfaces.ErrInvalidAgentNode)
+	}
+	if strings.TrimSpace(node.BaseURL) == "" && (node.InvocationURL == nil || strings.TrimSpace(*node.InvocationURL) == "") {
+		return fmt.Errorf("%w: base_url or invocation_url is required", interfaces.ErrInvalidAgentNode)
+	}
+
+	now := time.Now().UTC()
+	if node.RegisteredAt.IsZero() {
+		node.RegisteredAt = now
+	}
+	if node.LastHeartbeat.IsZero() {
+		node.LastHeartbeat = now
+	}
+	if node.HealthStatus == "" {
+		node.HealthStatus = types.HealthStatusUnknown
+	}
+	if node.LifecycleStatus == "" {
+		node.LifecycleStatus = types.AgentStatusStarting
+	}
+
+	// Insert-only, so a concurrent registration of the same ID cannot be
+	// overwritten between a lookup and the write.
+	if err := as.nodeStore.CreateAgent(ctx, node); err != nil {
+		if errors.Is(err, interfaces.ErrAgentNodeExists) {
+			return fmt.Errorf("%w: %s", interfaces.ErrAgentAlreadyRegistered, node.ID)
+		}
+		return fmt.Errorf("failed to register agent node %s: %w", node.ID, err)
+	}
+	return nil
+}
+
+// DeregisterAgent removes a node from the control plane registry.
+func (as *DefaultAgentService) DeregisterAgent(ctx context.Context, id string) error {
+	if as.nodeStore == nil {
+		return interfaces.ErrNodeStoreUnavailable
+	}
+	id = strings.TrimSpace(id)
+	if err := as.nodeStore.DeleteAgent(ctx, id); err != nil {
+		if errors.Is(err, interfaces.ErrAgentNodeNotFound) {
+			return fmt.Errorf("%w: %s", interfaces.ErrAgentNotRegistered, id)
+		}
+		return fmt.Errorf("failed to deregister agent node %s: %w", id, err)
+	}
+	return nil
+}
+
+// GetAgentStatusesByLabel aggregates the status of nodes whose deployment tags
+// include key=value.
+func (as *DefaultAgentService) GetAgentStatusesByLabel(ctx context.Context, key, value string) (*types.NodeStatusAggregate, error) {
+	if as.nodeStore == nil {
+		return nil, interfaces.ErrNodeStoreUnavailable
+	}
+	nodes, err := as.nodeStore.ListAgents(ctx, types.AgentFilters{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list agent nodes: %w", err)
+	}
+
+	aggregate := &types.NodeStatusAggregate{
+		LabelKey:   key,
+		LabelValue: value,
+		Health:     make(map[types.HealthStatus]int),
+		Lifecycle:  make(map[types.AgentLifecycleStatus]int),
+	}
+	for _, node := range nodes {
+		if node == nil || node.Metadata.Deployment == nil {
+			continue
+		}
+		if tag, ok := node.Metadata.Deployment.Tags[key]; !ok || tag != value {
+			continue
+		}
+		aggregate.Total++
+		aggregate.Health[node.HealthStatus]++
+		aggregate.Lifecycle[node.LifecycleStatus]++
+	}
+	return aggregate, nil
+}
+
+// GetAgentManifest returns the manifest advertised by a registered node.
+func (as *DefaultAgentService) GetAgentManifest(ctx context.Context, id string) (*types.AgentManifest, error) {
+	if as.nodeStore == nil {
+		return nil, interfaces.ErrNodeStoreUnavailable
+	}
+	id = strings.TrimSpace(id)
+	node, err := as.nodeStore.GetAgent(ctx, id)
+	if err != nil || node == nil {
+		return nil, fmt.Errorf("%w: %s", interfaces.ErrAgentNotRegistered, id)
+	}
+	return node.Metadata.Manifest, nil
+}
+
+// SearchAgents matches query against each node's ID and deployment tag values.
+func (as *DefaultAgentService) SearchAgents(ctx context.Context, query string, limit int) ([]*types.AgentNode, error) {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return nil, interfaces.ErrEmptySearchQuery
+	}
+	if as.nodeStore == nil {
+		return nil, interfaces.ErrNodeStoreUnavailable
+	}
+	nodes, err := as.nodeStore.ListAgents(ctx, types.AgentFilters{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list agent nodes: %w", err)
+	}
+
+	type match struct {
+		node   *types.AgentNode
+		prefix bool
+	}
+	var matches []match
+	for _, node := range nodes {
+		if node == nil {
+			continue
+		}
+		fields := []string{node.ID}
+		if node.Metadata.Deployment != nil {
+			for _, value := range node.Metadata.Deployment.Tags {
+				fields = append(fields, value)
+			}
+		}
+
+		found, prefix := false, false
+		for _, field := range fields {
+			field = strings.ToLower(field)
+			if strings.HasPrefix(field, query) {
+				found, prefix = true, true
+				break
+			}
+			if strings.Contains(field, query) {
+				found = true
+			}
+		}
+		if found {
+			matches = append(matches, match{node: node, prefix: prefix})
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].prefix != matches[j].prefix {
+			return matches[i].prefix
+		}
+		return matches[i].node.ID < matches[j].node.ID
+	})
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	results := make([]*types.AgentNode, len(matches))
+	for i, m := range matches {
+		results[i] = m.node
+	}
+	return results, nil
+}
+
 // ListRunningAgents returns a list of all running agents
 func (as *DefaultAgentService) ListRunningAgents() ([]domain.RunningAgent, error) {
 	registry, err := as.loadRegistryDirect()
@@ -224,6 +224,7 @@ func TestNewAgentService(t *testing.T) {
 		registryStorage,
 		agentClient,
 		agentfieldHome,
+		nil,
 	)
 
 	assert.NotNil(t, service)
@@ -271,6 +272,7 @@ func TestRunAgent_Success(t *testing.T) {
 		registryStorage,
 		agentClient,
 		agentfieldHome,
+		nil,
 	).(*DefaultAgentService)
 
 	// Mock port manager to return a free port
@@ -323,6 +325,7 @@ func TestRunAgent_AgentNotInstalled(t *testing.T) {
 		registryStorage,
 		agentClient,
 		agentfieldHome,
+		nil,
 	).(*DefaultAgentService)
 
 	options := domain.RunOptions{Port: 0}
@@ -379,6 +382,7 @@ func TestRunAgent_AlreadyRunning(t *testing.T) {
 		registryStorage,
 		agentClient,
 		agentfieldHome,
+		nil,
 	).(*DefaultAgentService)
 
 	options := domain.RunOptions{Port: 0}
@@ -466,6 +470,7 @@ func TestStopAgent_Success(t *testing.T) {
 		registryStorage,
 		agentClient,
 		agentfieldHome,
+		nil,
 	).(*DefaultAgentService)
 
 	err := service.StopAgent("test-agent")
@@ -496,6 +501,7 @@ func TestStopAgent_NotInstalled(t *testing.T) {
 		registryStorage,
 		agentClient,
 		agentfieldHome,
+		nil,
 	).(*DefaultAgentService)
 
 	err := service.StopAgent("nonexistent-agent")
@@ -549,6 +555,7 @@ func TestGetAgentStatus_Success(t *testing.T) {
 		registryStorage,
 		agentClient,
 		agentfieldHome,
+		nil,
 	).(*DefaultAgentService)
 
 	status, err := service.GetAgentStatus("test-agent")
@@ -576,6 +583,7 @@ func TestGetAgentStatus_NotInstalled(t *testing.T) {
 		registryStorage,
 		agentClient,
 		agentfieldHome,
+		nil,
 	).(*DefaultAgentService)
 
 	_, err := service.GetAgentStatus("nonexistent-agent")
@@ -620,6 +628,7 @@ func TestReconcileProcessState_ProcessNotRunning(t *testing.T) {
 		registryStorage,
 		agentClient,
 		agentfieldHome,
+		nil,
 	).(*DefaultAgentService)
 
 	actuallyRunning, wasReconciled := service.reconcileProcessState(pkg, "test-agent")
@@ -669,6 +678,7 @@ func TestReconcileProcessState_ProcessRunning(t *testing.T) {
 		registryStorage,
 		agentClient,
 		tmpDir,
+		nil,
 	).(*DefaultAgentService)
 
 	actuallyRunning, wasReconciled := service.reconcileProcessState(pkg, "test-agent")
@@ -704,6 +714,7 @@ func TestReconcileProcessState_NoPID(t *testing.T) {
 		registryStorage,
 		agentClient,
 		tmpDir,
+		nil,
 	).(*DefaultAgentService)
 
 	actuallyRunning, wasReconciled := service.reconcileProcessState(pkg, "test-agent")
@@ -763,6 +774,7 @@ func TestListRunningAgents(t *testing.T) {
 		registryStorage,
 		agentClient,
 		agentfieldHome,
+		nil,
 	).(*DefaultAgentService)
 
 	runningAgents, err := service.ListRunningAgents()
@@ -793,6 +805,7 @@ func TestFindAgentInRegistry_ExactMatch(t *testing.T) {
 		registryStorage,
 		agentClient,
 		tmpDir,
+		nil,
 	).(*DefaultAgentService)
 
 	pkg, name, exists := service.findAgentInRegistry(registry, "test-agent")
@@ -823,6 +836,7 @@ func TestFindAgentInRegistry_NormalizedMatch(t *testing.T) {
 		registryStorage,
 		agentClient,
 		tmpDir,
+		nil,
 	).(*DefaultAgentService)
 
 	pkg, name, exists := service.findAgentInRegistry(registry, "deepresearchagent")
@@ -848,6 +862,7 @@ func TestFindAgentInRegistry_NotFound(t *testing.T) {
 		registryStorage,
 		agentClient,
 		tmpDir,
+		nil,
 	).(*DefaultAgentService)
 
 	_, _, exists := service.findAgentInRegistry(registry, "nonexistent")
@@ -879,6 +894,7 @@ func TestBuildProcessConfig(t *testing.T) {
 		registryStorage,
 		agentClient,
 		tmpDir,
+		nil,
 	).(*DefaultAgentService)
 
 	config := service.buildProcessConfig(agentNode, 8001)
@@ -0,0 +1,303 @@
+package events
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Agent-Field/agentfield/control-plane/internal/logger"
+)
+
+// EventSink receives node lifecycle events (registered, online, offline and
+// removed) for delivery to a system outside the control plane. Delivery is
+// at-least-once: a queued event is retried until Send returns nil, so sinks
+// should tolerate duplicates. When a sink's buffer is full, publishers wait
+// up to EnqueueTimeout for room, and removing a sink or stopping the
+// dispatcher first drains its buffer for up to DrainTimeout. Only events
+// that miss one of those deadlines are dropped, and they are counted.
+type EventSink interface {
+	Send(ctx context.Context, event NodeEvent) error
+}
+
+// EventSinkFunc adapts a function to the EventSink interface.
+type EventSinkFunc func(ctx context.Context, event NodeEvent) error
+
+// Send calls f(ctx, event).
+func (f EventSinkFunc) Send(ctx context.Context, event NodeEvent) error {
+	return f(ctx, event)
+}
+
+// NodeSinkConfig controls buffering and retries for node event sinks.
+type NodeSinkConfig struct {
+	// BufferSize bounds the number of undelivered events held per sink.
+	BufferSize int
+	// EnqueueTimeout bounds how long Dispatch waits for room in full
+	// buffers. Events that still do not fit are dropped and counted.
+	EnqueueTimeout time.Duration
+	// DrainTimeout bounds how long RemoveSink and Stop keep delivering
+	// queued events. Events still undelivered are dropped and counted.
+	DrainTimeout    time.Duration
+	SendTimeout     time.Duration
+	RetryBackoff    time.Duration
+	MaxRetryBackoff time.Duration
+}
+
+// NodeSinkDispatcher fans node lifecycle events out to registered sinks.
+// Each sink has its own bounded buffer and worker, so a slow sink delays
+// publishers only once its buffer fills and never delays other sinks, and
+// events reach each sink in the order they were published.
+type NodeSinkDispatcher struct {
+	cfg NodeSinkConfig
+
+	mu    sync.RWMutex
+	sinks map[string]*sinkWorker
+
+	dropped atomic.Uint64
+}
+
+type sinkWorker struct {
+	name  string
+	sink  EventSink
+	queue chan NodeEvent
+	// stopCh is closed to make the worker drain its queue and exit; ctx is
+	// cancelled once the drain deadline passes, abandoning retries.
+	stopCh chan struct{}
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewNodeSinkDispatcher creates a dispatcher with no registered sinks.
+func NewNodeSinkDispatcher(cfg NodeSinkConfig) *NodeSinkDispatcher {
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = 1000
+	}
+	if cfg.EnqueueTimeout <= 0 {
+		cfg.EnqueueTimeout = 5 * time.Second
+	}
+	if cfg.DrainTimeout <= 0 {
+		cfg.DrainTimeout = 10 * time.Second
+	}
+	if cfg.SendTimeout <= 0 {
+		cfg.SendTimeout = 10 * time.Second
+	}
+	if cfg.RetryBackoff <= 0 {
+		cfg.RetryBackoff = 500 * time.Millisecond
+	}
+	if cfg.MaxRetryBackoff <= 0 {
+		cfg.MaxRetryBackoff = 30 * time.Second
+	}
+	return &NodeSinkDispatcher{
+		cfg:   cfg,
+		sinks: make(map[string]*sinkWorker),
+	}
+}
+
+// AddSink registers sink under name and starts delivering events to it.
+// Registering a name again replaces the previous sink, which is drained as
+// by RemoveSink.
+func (d *NodeSinkDispatcher) AddSink(name string, sink EventSink) {
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &sinkWorker{
+		name:   name,
+		sink:   sink,
+		queue:  make(chan NodeEvent, d.cfg.BufferSize),
+		stopCh: make(chan struct{}),
+		ctx:    ctx,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+
+	d.mu.Lock()
+	old := d.sinks[name]
+	d.sinks[name] = w
+	d.mu.Unlock()
+
+	if old != nil {
+		old.stop(d.cfg.DrainTimeout)
+	}
+	go d.run(w)
+}
+
+// RemoveSink stops delivery to the named sink once its queued events are
+// delivered, waiting up to DrainTimeout for them.
+func (d *NodeSinkDispatcher) RemoveSink(name string) {
+	d.mu.Lock()
+	w := d.sinks[name]
+	delete(d.sinks, name)
+	d.mu.Unlock()
+
+	if w != nil {
+		w.stop(d.cfg.DrainTimeout)
+	}
+}
+
+// Dispatch queues a lifecycle event for every registered sink. Other event
+// types are ignored. When a sink's buffer is full, Dispatch waits for room
+// until EnqueueTimeout has passed since the call, then drops the event for
+// that sink.
+func (d *NodeSinkDispatcher) Dispatch(event NodeEvent) {
+	if !isLifecycleEvent(event.Type) {
+		return
+	}
+
+	d.mu.RLock()
+	workers := make([]*sinkWorker, 0, len(d.sinks))
+	for _, w := range d.sinks {
+		workers = append(workers, w)
+	}
+	d.mu.RUnlock()
+
+	var timeout <-chan time.Time
+	for _, w := range workers {
+		select {
+		case w.queue <- event:
+			continue
+		default:
+		}
+
+		if timeout == nil {
+			timer := time.NewTimer(d.cfg.EnqueueTimeout)
+			defer timer.Stop()
+			timeout = timer.C
+		}
+		select {
+		case w.queue <- event:
+		case <-w.stopCh:
+			// The sink was removed while we waited.
+		case <-timeout:
+			d.dropped.Add(1)
+			logger.Logger.Warn().Msgf("[NodeSinkDispatcher] Buffer full for sink %s, dropping %s event for node %s", w.name, event.Type, event.NodeID)
+		}
+	}
+}
+
+// Dropped returns the number of events discarded because a sink's buffer
+// stayed full past EnqueueTimeout or was not drained within DrainTimeout.
+func (d *NodeSinkDispatcher) Dropped() uint64 {
+	return d.dropped.Load()
+}
+
+// Stop stops delivery to all sinks once their queued events are delivered,
+// waiting up to DrainTimeout for them. Sinks added afterwards are delivered
+// to as usual.
+func (d *NodeSinkDispatcher) Stop() {
+	d.mu.Lock()
+	sinks := d.sinks
+	d.sinks = make(map[string]*sinkWorker)
+	d.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, w := range sinks {
+		wg.Add(1)
+		go func(w *sinkWorker) {
+			defer wg.Done()
+			w.stop(d.cfg.DrainTimeout)
+		}(w)
+	}
+	wg.Wait()
+}
+
+func (d *NodeSinkDispatcher) run(w *sinkWorker) {
+	defer close(w.done)
+	for {
+		select {
+		case event := <-w.queue:
+			if !d.deliver(w, event) {
+				d.discard(w, 1)
+				return
+			}
+		case <-w.stopCh:
+			d.drain(w)
+			return
+		}
+	}
+}
+
+// drain delivers the events still queued for a stopping worker. Whatever is
+// left when the drain deadline passes is discarded.
+func (d *NodeSinkDispatcher) drain(w *sinkWorker) {
+	for {
+		select {
+		case event := <-w.queue:
+			if !d.deliver(w, event) {
+				d.discard(w, 1)
+				return
+			}
+		default:
+			return
+		}
+	}
+}
+
+// discard counts pending, plus whatever is still queued, as dropped.
+func (d *NodeSinkDispatcher) discard(w *sinkWorker, pending int) {
+	dropped := pending + len(w.queue)
+	d.dropped.Add(uint64(dropped))
+	logger.Logger.Warn().Msgf("[NodeSinkDispatcher] Sink %s stopped with %d undelivered events", w.name, dropped)
+}
+
+// deliver retries event until the sink accepts it. It returns false if the
+// worker's drain deadline passed first.
+func (d *NodeSinkDispatcher) deliver(w *sinkWorker, event NodeEvent) bool {
+	backoff := d.cfg.RetryBackoff
+	for attempt := 1; ; attempt++ {
+		ctx, cancel := context.WithTimeout(w.ctx, d.cfg.SendTimeout)
+		err := w.sink.Send(ctx, event)
+		cancel()
+		if err == nil {
+			return true
+		}
+
+		logger.Logger.Warn().Err(err).Msgf("[NodeSinkDispatcher] Sink %s failed to accept %s event for node %s (attempt %d)", w.name, event.Type, event.NodeID, attempt)
+
+		select {
+		case <-w.ctx.Done():
+			return false
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > d.cfg.MaxRetryBackoff {
+			backoff = d.cfg.MaxRetryBackoff
+		}
+	}
+}
+
+// stop asks the worker to drain its queue and waits for it to exit,
+// abandoning delivery once timeout has passed.
+func (w *sinkWorker) stop(timeout time.Duration) {
+	close(w.stopCh)
+	deadline := time.AfterFunc(timeout, w.cancel)
+	<-w.done
+	deadline.Stop()
+	w.cancel()
+}
+
+func isLifecycleEvent(t NodeEventType) bool {
+	switch t {
+	case NodeRegistered, NodeOnline, NodeOffline, NodeRemoved:
+		return true
+	}
+	return false
+}
+
+// LoggingEventSink is a reference EventSink that writes each lifecycle event
+// to the control plane log.
+type LoggingEventSink struct{}
+
+// Send logs event at info level.
+func (LoggingEventSink) Send(_ context.Context, event NodeEvent) error {
+	logger.Logger.Info().
+		Str("event", string(event.Type)).
+		Str("node_id", event.NodeID).
+		Str("status", event.Status).
+		Time("timestamp", event.Timestamp).
+		Msg("node lifecycle event")
+	return nil
+}
+
+// GlobalNodeSinks receives the lifecycle events published through
+// GlobalNodeEventBus. Register sinks with GlobalNodeSinks.AddSink; the server
+// adds the sinks enabled in its configuration and stops them on shutdown.
+var GlobalNodeSinks = NewNodeSinkDispatcher(NodeSinkConfig{})
@@ -0,0 +1,203 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type capturingSink struct {
+	mu       sync.Mutex
+	events   []NodeEvent
+	failures int
+}
+
+func (s *capturingSink) Send(_ context.Context, event NodeEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.failures > 0 {
+		s.failures--
+		return errors.New("sink unavailable")
+	}
+	s.events = append(s.events, event)
+	return nil
+}
+
+func (s *capturingSink) types() []NodeEventType {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]NodeEventType, 0, len(s.events))
+	for _, e := range s.events {
+		out = append(out, e.Type)
+	}
+	return out
+}
+
+func TestNodeSinks_LifecycleEventsInOrder(t *testing.T) {
+	sink := &capturingSink{}
+	GlobalNodeSinks.AddSink("capture", sink)
+	t.Cleanup(func() { GlobalNodeSinks.RemoveSink("capture") })
+
+	PublishNodeRegistered("node-sink-1", nil)
+	PublishNodeStatusUpdated("node-sink-1", "starting", nil)
+	PublishNodeOnline("node-sink-1", nil)
+	PublishNodeOffline("node-sink-1", nil)
+
+	want := []NodeEventType{NodeRegistered, NodeOnline, NodeOffline}
+	require.Eventually(t, func() bool { return len(sink.types()) == len(want) }, time.Second, 5*time.Millisecond)
+	require.Equal(t, want, sink.types())
+}
+
+func TestNodeSinkDispatcher_RetriesUntilDelivered(t *testing.T) {
+	d := NewNodeSinkDispatcher(NodeSinkConfig{RetryBackoff: time.Millisecond, MaxRetryBackoff: 2 * time.Millisecond})
+	defer d.Stop()
+
+	sink := &capturingSink{failures: 3}
+	d.AddSink("flaky", sink)
+
+	d.Dispatch(NodeEvent{Type: NodeRegistered, NodeID: "n1"})
+	d.Dispatch(NodeEvent{Type: NodeOnline, NodeID: "n1"})
+
+	want := []NodeEventType{NodeRegistered, NodeOnline}
+	require.Eventually(t, func() bool { return len(sink.types()) == len(want) }, time.Second, 5*time.Millisecond)
+	require.Equal(t, want, sink.types())
+}
+
+// blockingSink accepts events only after release is closed, signalling
+// started on its first call.
+func blockingSink(release <-chan struct{}, started chan<- struct{}, sink *capturingSink) EventSink {
+	return EventSinkFunc(func(ctx context.Context, event NodeEvent) error {
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		<-release
+		return sink.Send(ctx, event)
+	})
+}
+
+func TestNodeSinkDispatcher_DropsWhenBufferStaysFull(t *testing.T) {
+	d := NewNodeSinkDispatcher(NodeSinkConfig{BufferSize: 2, EnqueueTimeout: 20 * time.Millisecond})
+	defer d.Stop()
+
+	release := make(chan struct{})
+	started := make(chan struct{}, 1)
+	d.AddSink("blocked", blockingSink(release, started, &capturingSink{}))
+
+	d.Dispatch(NodeEvent{Type: NodeOnline, NodeID: "n1"})
+	<-started
+
+	for i := 0; i < 4; i++ {
+		d.Dispatch(NodeEvent{Type: NodeOffline, NodeID: "n1"})
+	}
+	close(release)
+
+	require.Equal(t, uint64(2), d.Dropped())
+}
+
+func TestNodeSinkDispatcher_WaitsForBufferRoom(t *testing.T) {
+	d := NewNodeSinkDispatcher(NodeSinkConfig{BufferSize: 1, EnqueueTimeout: 5 * time.Second})
+	defer d.Stop()
+
+	release := make(chan struct{})
+	started := make(chan struct{}, 1)
+	sink := &capturingSink{}
+	d.AddSink("slow", blockingSink(release, started, sink))
+
+	d.Dispatch(NodeEvent{Type: NodeRegistered, NodeID: "n1"})
+	<-started
+	d.Dispatch(NodeEvent{Type: NodeOnline, NodeID: "n1"})
+
+	dispatched := make(chan struct{})
+	go func() {
+		d.Dispatch(NodeEvent{Type: NodeOffline, NodeID: "n1"})
+		close(dispatched)
+	}()
+	select {
+	case <-dispatched:
+		t.Fatal("Dispatch returned while the buffer was full")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(release)
+	<-dispatched
+
+	want := []NodeEventType{NodeRegistered, NodeOnline, NodeOffline}
+	require.Eventually(t, func() bool { return len(sink.types()) == len(want) }, time.Second, 5*time.Millisecond)
+	require.Equal(t, want, sink.types())
+	require.Zero(t, d.Dropped())
+}
+
+func TestNodeSinkDispatcher_DrainsOnStop(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		stop func(d *NodeSinkDispatcher)
+	}{
+		{"stop", (*NodeSinkDispatcher).Stop},
+		{"remove sink", func(d *NodeSinkDispatcher) { d.RemoveSink("slow") }},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			d := NewNodeSinkDispatcher(NodeSinkConfig{DrainTimeout: 5 * time.Second})
+
+			release := make(chan struct{})
+			started := make(chan struct{}, 1)
+			sink := &capturingSink{}
+			d.AddSink("slow", blockingSink(release, started, sink))
+
+			d.Dispatch(NodeEvent{Type: NodeRegistered, NodeID: "n1"})
+			<-started
+			d.Dispatch(NodeEvent{Type: NodeOnline, NodeID: "n1"})
+			d.Dispatch(NodeEvent{Type: NodeRemoved, NodeID: "n1"})
+
+			time.AfterFunc(20*time.Millisecond, func() { close(release) })
+			tc.stop(d)
+
+			require.Equal(t, []NodeEventType{NodeRegistered, NodeOnline, NodeRemoved}, sink.types())
+			require.Zero(t, d.Dropped())
+		})
+	}
+}
+
+func TestNodeSinkDispatcher_DrainDeadline(t *testing.T) {
+	d := NewNodeSinkDispatcher(NodeSinkConfig{
+		DrainTimeout:    20 * time.Millisecond,
+		RetryBackoff:    time.Millisecond,
+		MaxRetryBackoff: time.Millisecond,
+	})
+
+	d.AddSink("down", EventSinkFunc(func(context.Context, NodeEvent) error {
+		return errors.New("sink unavailable")
+	}))
+	for i := 0; i < 3; i++ {
+		d.Dispatch(NodeEvent{Type: NodeOffline, NodeID: "n1"})
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		d.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("Stop did not return after the drain deadline")
+	}
+	require.Equal(t, uint64(3), d.Dropped())
+}
+
+func TestNodeSinkDispatcher_AddSinkAfterStop(t *testing.T) {
+	d := NewNodeSinkDispatcher(NodeSinkConfig{})
+	d.AddSink("first", &capturingSink{})
+	d.Stop()
+
+	sink := &capturingSink{}
+	d.AddSink("second", sink)
+	defer d.Stop()
+
+	d.Dispatch(NodeEvent{Type: NodeRegistered, NodeID: "n1"})
+	require.Eventually(t, func() bool { return len(sink.types()) == 1 }, time.Second, 5*time.Millisecond)
+}
@@ -22,6 +22,7 @@ const (
 	NodeMCPHealthChanged NodeEventType = "mcp_health_changed"
 	NodesRefresh         NodeEventType = "nodes_refresh"
 	NodeHeartbeat        NodeEventType = "node_heartbeat"
+	NodePresenceExpired  NodeEventType = "node_presence_expired"
 
 	// New unified status events
 	NodeUnifiedStatusChanged NodeEventType = "node_unified_status_changed"
@@ -145,6 +146,7 @@ func PublishNodeOnline(nodeID string, data interface{}) {
 	}
 
 	GlobalNodeEventBus.Publish(event)
+	GlobalNodeSinks.Dispatch(event)
 }
 
 // PublishNodeOffline publishes a node offline event
@@ -157,6 +159,22 @@ func PublishNodeOffline(nodeID string, data interface{}) {
 		Data:      data,
 	}
 
+	GlobalNodeEventBus.Publish(event)
+	GlobalNodeSinks.Dispatch(event)
+}
+
+// PublishNodePresenceExpired publishes a presence lease expiry or eviction
+// event. It is published after the node's status has been updated and the
+// presence expire callbacks have run.
+func PublishNodePresenceExpired(nodeID, reason string) {
+	event := NodeEvent{
+		Type:      NodePresenceExpired,
+		NodeID:    nodeID,
+		Status:    "offline",
+		Timestamp: time.Now(),
+		Reason:    reason,
+	}
+
 	GlobalNodeEventBus.Publish(event)
 }
 
@@ -171,6 +189,7 @@ func PublishNodeRegistered(nodeID string, data interface{}) {
 	}
 
 	GlobalNodeEventBus.Publish(event)
+	GlobalNodeSinks.Dispatch(event)
 }
 
 // PublishNodeStatusUpdated publishes a node status change event
@@ -226,6 +245,7 @@ func PublishNodeRemoved(nodeID string, data interface{}) {
 	logger.Logger.Debug().Msgf("🔍 NODE_EVENT_DEBUG: Publishing NodeRemoved event - NodeID: %s", nodeID)
 
 	GlobalNodeEventBus.Publish(event)
+	GlobalNodeSinks.Dispatch(event)
 }
 
 // PublishNodesRefresh publishes a general refresh event
@@ -117,6 +117,8 @@ type executionController struct {
 	webhooks   services.WebhookDispatcher
 	eventBus   *events.ExecutionEventBus
 	timeout    time.Duration
+	// statusManager, when set, rejects executions targeting cordoned nodes.
+	statusManager *services.StatusManager
 }
 
 type asyncExecutionJob struct {
@@ -151,15 +153,22 @@ const (
 	maxWebhookSecretLength = 4096
 )
 
-// ExecuteHandler handles synchronous execution requests.
-func ExecuteHandler(store ExecutionStore, payloads services.PayloadStore, webhooks services.WebhookDispatcher, timeout time.Duration) gin.HandlerFunc {
+// errNodeCordoned is returned when an execution targets a cordoned node.
+var errNodeCordoned = errors.New("node is cordoned")
+
+// ExecuteHandler handles synchronous execution requests. Nodes cordoned in
+// statusManager are refused new executions; statusManager may be nil.
+func ExecuteHandler(store ExecutionStore, payloads services.PayloadStore, webhooks services.WebhookDispatcher, timeout time.Duration, statusManager *services.StatusManager) gin.HandlerFunc {
 	controller := newExecutionController(store, payloads, webhooks, timeout)
+	controller.statusManager = statusManager
 	return controller.handleSync
 }
 
-// ExecuteAsyncHandler handles asynchronous execution requests.
-func ExecuteAsyncHandler(store ExecutionStore, payloads services.PayloadStore, webhooks services.WebhookDispatcher, timeout time.Duration) gin.HandlerFunc {
+// ExecuteAsyncHandler handles asynchronous execution requests, refusing
+// cordoned nodes like ExecuteHandler.
+func ExecuteAsyncHandler(store ExecutionStore, payloads services.PayloadStore, webhooks services.WebhookDispatcher, timeout time.Duration, statusManager *services.StatusManager) gin.HandlerFunc {
 	controller := newExecutionController(store, payloads, webhooks, timeout)
+	controller.statusManager = statusManager
 	return controller.handleAsync
 }
 
@@ -777,6 +786,9 @@ func (c *executionController) prepareExecution(ctx context.Context, ginCtx *gin.
 	if agent == nil {
 		return nil, fmt.Errorf("agent '%s' not found", target.NodeID)
 	}
+	if c.statusManager != nil && c.statusManager.IsCordoned(agent.ID) {
+		return nil, fmt.Errorf("%w: agent '%s' is not accepting new executions", errNodeCordoned, agent.ID)
+	}
 	if agent.DeploymentType == "" && agent.Metadata.Custom != nil {
 		if v, ok := agent.Metadata.Custom["serverless"]; ok && fmt.Sprint(v) == "true" {
 			agent.DeploymentType = "serverless"
@@ -1455,6 +1467,10 @@ func writeExecutionError(ctx *gin.Context, err error) {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "unknown error"})
 		return
 	}
+	if errors.Is(err, errNodeCordoned) {
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
 	ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 }
 
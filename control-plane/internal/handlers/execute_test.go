@@ -149,6 +149,12 @@ func (m *MockStorageProvider) GetLockStatus(ctx context.Context, key string) (*t
 func (m *MockStorageProvider) RegisterAgent(ctx context.Context, agent *types.AgentNode) error {
 	return nil
 }
+func (m *MockStorageProvider) CreateAgent(ctx context.Context, agent *types.AgentNode) error {
+	return nil
+}
+func (m *MockStorageProvider) DeleteAgent(ctx context.Context, id string) error {
+	return nil
+}
 func (m *MockStorageProvider) ListAgents(ctx context.Context, filters types.AgentFilters) ([]*types.AgentNode, error) {
 	return nil, nil
 }
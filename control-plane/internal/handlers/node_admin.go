@@ -0,0 +1,247 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Agent-Field/agentfield/control-plane/internal/core/interfaces"
+	"github.com/Agent-Field/agentfield/control-plane/internal/events"
+	"github.com/Agent-Field/agentfield/control-plane/internal/logger"
+	"github.com/Agent-Field/agentfield/control-plane/internal/services"
+	"github.com/Agent-Field/agentfield/control-plane/internal/storage"
+	"github.com/Agent-Field/agentfield/control-plane/pkg/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminRegisterNodeHandler registers a node on behalf of an operator rather than
+// waiting for the node to self-register. Unlike RegisterNodeHandler it performs
+// no callback discovery and rejects IDs that are already registered.
+func AdminRegisterNodeHandler(agentService interfaces.AgentService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var node types.AgentNode
+		if err := c.ShouldBindJSON(&node); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON format: " + err.Error()})
+			return
+		}
+
+		if err := agentService.RegisterAgent(c.Request.Context(), &node); err != nil {
+			switch {
+			case errors.Is(err, interfaces.ErrInvalidAgentNode):
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			case errors.Is(err, interfaces.ErrAgentAlreadyRegistered):
+				c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			default:
+				logger.Logger.Error().Err(err).Str("node_id", node.ID).Msg("❌ Admin node registration failed")
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to register node"})
+			}
+			return
+		}
+
+		events.PublishNodeRegistered(node.ID, nil)
+		logger.Logger.Info().Str("node_id", node.ID).Msg("✅ Node registered via admin API")
+		c.JSON(http.StatusCreated, node)
+	}
+}
+
+// DeregisterNodeHandler removes a node from the registry and drops its presence
+// lease and cached status so it is not resurrected by the sweeper.
+func DeregisterNodeHandler(agentService interfaces.AgentService, statusManager *services.StatusManager, presenceManager *services.PresenceManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		nodeID := c.Param("node_id")
+		if nodeID == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "node_id is required"})
+			return
+		}
+
+		if err := agentService.DeregisterAgent(c.Request.Context(), nodeID); err != nil {
+			if errors.Is(err, interfaces.ErrAgentNotRegistered) {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			logger.Logger.Error().Err(err).Str("node_id", nodeID).Msg("❌ Node deregistration failed")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to deregister node"})
+			return
+		}
+
+		if presenceManager != nil {
+			presenceManager.Forget(nodeID)
+		}
+		if statusManager != nil {
+			statusManager.ForgetAgentStatus(nodeID)
+			statusManager.SetCordoned(nodeID, false)
+		}
+		events.PublishNodeRemoved(nodeID, nil)
+
+		logger.Logger.Info().Str("node_id", nodeID).Msg("🗑️ Node deregistered via admin API")
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// NodeStatusByLabelHandler aggregates node statuses for nodes tagged with the
+// key and value query parameters, so alert rules need not fetch the fleet.
+// GET /api/v1/nodes/status/by-label?key=team&value=payments
+func NodeStatusByLabelHandler(agentService interfaces.AgentService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.Query("key")
+		value, hasValue := c.GetQuery("value")
+		if key == "" || !hasValue {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "key and value query parameters are required"})
+			return
+		}
+
+		aggregate, err := agentService.GetAgentStatusesByLabel(c.Request.Context(), key, value)
+		if err != nil {
+			logger.Logger.Error().Err(err).Str("label_key", key).Msg("❌ Failed to aggregate node statuses by label")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to aggregate node statuses"})
+			return
+		}
+		c.JSON(http.StatusOK, aggregate)
+	}
+}
+
+// NodeSearchHandler finds nodes by a partial ID or deployment tag value for
+// the dashboard search bar, best matches first.
+// GET /api/v1/nodes/search?q=pay&limit=20
+func NodeSearchHandler(agentService interfaces.AgentService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		query := strings.TrimSpace(c.Query("q"))
+		if query == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "q query parameter is required"})
+			return
+		}
+
+		limit := 20
+		if raw := c.Query("limit"); raw != "" {
+			parsed, err := strconv.Atoi(raw)
+			if err != nil || parsed < 1 || parsed > 100 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 100"})
+				return
+			}
+			limit = parsed
+		}
+
+		nodes, err := agentService.SearchAgents(c.Request.Context(), query, limit)
+		if err != nil {
+			logger.Logger.Error().Err(err).Str("query", query).Msg("❌ Failed to search nodes")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to search nodes"})
+			return
+		}
+		if nodes == nil {
+			nodes = []*types.AgentNode{}
+		}
+		c.JSON(http.StatusOK, gin.H{"nodes": nodes, "count": len(nodes)})
+	}
+}
+
+// NodeManifestHandler returns the capabilities and handlers a node advertises,
+// with 204 No Content when the node has not published a manifest.
+// GET /api/v1/nodes/:node_id/manifest
+func NodeManifestHandler(agentService interfaces.AgentService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		nodeID := c.Param("node_id")
+		if nodeID == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "node_id is required"})
+			return
+		}
+
+		manifest, err := agentService.GetAgentManifest(c.Request.Context(), nodeID)
+		if err != nil {
+			if errors.Is(err, interfaces.ErrAgentNotRegistered) {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			logger.Logger.Error().Err(err).Str("node_id", nodeID).Msg("❌ Failed to load node manifest")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load node manifest"})
+			return
+		}
+		if manifest == nil {
+			c.Status(http.StatusNoContent)
+			return
+		}
+		c.JSON(http.StatusOK, manifest)
+	}
+}
+
+// CordonNodeHandler cordons a node so the execution handlers refuse new work
+// for it, and drains its presence lease if it holds one. The node stays
+// registered and present. Registered nodes without a lease, for example after
+// a hard eviction, can be cordoned too.
+func CordonNodeHandler(storageProvider storage.StorageProvider, statusManager *services.StatusManager, presenceManager *services.PresenceManager) gin.HandlerFunc {
+	return setNodeCordonHandler(storageProvider, statusManager, presenceManager, true)
+}
+
+// UncordonNodeHandler reverses CordonNodeHandler.
+func UncordonNodeHandler(storageProvider storage.StorageProvider, statusManager *services.StatusManager, presenceManager *services.PresenceManager) gin.HandlerFunc {
+	return setNodeCordonHandler(storageProvider, statusManager, presenceManager, false)
+}
+
+func setNodeCordonHandler(storageProvider storage.StorageProvider, statusManager *services.StatusManager, presenceManager *services.PresenceManager, cordon bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		nodeID := c.Param("node_id")
+		if nodeID == "" {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "node_id is required",
+				"code":  "MISSING_NODE_ID",
+			})
+			return
+		}
+
+		if statusManager == nil || presenceManager == nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"error": "Status or presence manager not available",
+				"code":  "SERVICE_UNAVAILABLE",
+			})
+			return
+		}
+
+		var known bool
+		if cordon {
+			known = presenceManager.Drain(nodeID)
+		} else {
+			known = presenceManager.Undrain(nodeID)
+		}
+		if !known && storageProvider != nil {
+			// No lease; the node may still be registered.
+			node, err := storageProvider.GetAgent(c.Request.Context(), nodeID)
+			if err != nil && !strings.Contains(err.Error(), "not found") {
+				logger.Logger.Error().Err(err).Str("node_id", nodeID).Msg("❌ Failed to look up node for cordon")
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error": "Failed to look up node",
+					"code":  "NODE_LOOKUP_FAILED",
+				})
+				return
+			}
+			known = err == nil && node != nil
+		}
+		if !known {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Node not found",
+				"code":  "NODE_NOT_FOUND",
+			})
+			return
+		}
+		statusManager.SetCordoned(nodeID, cordon)
+
+		leaseState, leaseKnown := presenceManager.LeaseState(nodeID)
+		status, err := statusManager.GetAgentStatusSnapshot(c.Request.Context(), nodeID, nil)
+		if err != nil {
+			// The cordon is recorded regardless; report it without a status.
+			logger.Logger.Warn().Err(err).Str("node_id", nodeID).Msg("⚠️ Failed to load status for cordoned node")
+			status = nil
+		}
+		reason, message := explainNodeStatus(status, statusManager.IsFlapping(nodeID), cordon, leaseState, leaseKnown)
+
+		logger.Logger.Info().Str("node_id", nodeID).Bool("cordoned", cordon).Msg("🚧 Node cordon updated")
+		c.JSON(http.StatusOK, gin.H{
+			"success":  true,
+			"node_id":  nodeID,
+			"cordoned": cordon,
+			"status":   status,
+			"reason":   reason,
+			"message":  message,
+		})
+	}
+}
@@ -0,0 +1,435 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	coreservices "github.com/Agent-Field/agentfield/control-plane/internal/core/services"
+	"github.com/Agent-Field/agentfield/control-plane/internal/services"
+	"github.com/Agent-Field/agentfield/control-plane/pkg/types"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminNodeRegistration(t *testing.T) {
+	provider, ctx := setupTestStorage(t)
+
+	agentService := coreservices.NewAgentService(nil, nil, nil, nil, t.TempDir(), provider)
+	statusManager := services.NewStatusManager(provider, services.StatusManagerConfig{}, nil, nil)
+	presenceManager := services.NewPresenceManager(nil, services.PresenceManagerConfig{HeartbeatTTL: time.Minute})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/admin/nodes", AdminRegisterNodeHandler(agentService))
+	router.DELETE("/nodes/:node_id", DeregisterNodeHandler(agentService, statusManager, presenceManager))
+
+	register := func(body map[string]any) *httptest.ResponseRecorder {
+		payload, err := json.Marshal(body)
+		require.NoError(t, err)
+		req := httptest.NewRequest(http.MethodPost, "/admin/nodes", bytes.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+	node := map[string]any{
+		"id":       "node-admin",
+		"team_id":  "team",
+		"base_url": "http://localhost:8001",
+		"version":  "1.0.0",
+	}
+
+	t.Run("register", func(t *testing.T) {
+		resp := register(node)
+		require.Equal(t, http.StatusCreated, resp.Code, resp.Body.String())
+
+		stored, err := provider.GetAgent(ctx, "node-admin")
+		require.NoError(t, err)
+		assert.Equal(t, "http://localhost:8001", stored.BaseURL)
+		assert.Equal(t, types.AgentStatusStarting, stored.LifecycleStatus)
+	})
+
+	t.Run("duplicate is rejected", func(t *testing.T) {
+		resp := register(node)
+		assert.Equal(t, http.StatusConflict, resp.Code)
+	})
+
+	t.Run("missing fields are rejected", func(t *testing.T) {
+		resp := register(map[string]any{"id": "node-no-url"})
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+
+	t.Run("deregister", func(t *testing.T) {
+		presenceManager.Touch("node-admin", time.Now())
+		require.True(t, presenceManager.HasLease("node-admin"))
+		statusManager.SetCordoned("node-admin", true)
+
+		req := httptest.NewRequest(http.MethodDelete, "/nodes/node-admin", nil)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		require.Equal(t, http.StatusNoContent, resp.Code)
+
+		_, err := provider.GetAgent(ctx, "node-admin")
+		assert.Error(t, err)
+		_, known := presenceManager.LeaseState("node-admin")
+		assert.False(t, known, "presence lease should be forgotten")
+		assert.False(t, statusManager.IsCordoned("node-admin"), "deregistration should lift the cordon")
+
+		resp = httptest.NewRecorder()
+		router.ServeHTTP(resp, httptest.NewRequest(http.MethodDelete, "/nodes/node-admin", nil))
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+	})
+
+	t.Run("storage failures are not reported as not found", func(t *testing.T) {
+		require.Equal(t, http.StatusCreated, register(node).Code)
+		require.NoError(t, provider.Close(ctx))
+
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, httptest.NewRequest(http.MethodDelete, "/nodes/node-admin", nil))
+		assert.Equal(t, http.StatusInternalServerError, resp.Code)
+
+		assert.Equal(t, http.StatusInternalServerError, register(node).Code)
+	})
+}
+
+func TestCordonNodeHandlers(t *testing.T) {
+	provider, ctx := setupTestStorage(t)
+
+	statusManager := services.NewStatusManager(provider, services.StatusManagerConfig{}, nil, nil)
+	presenceManager := services.NewPresenceManager(statusManager, services.PresenceManagerConfig{
+		HeartbeatTTL: time.Minute,
+		HardEvictTTL: time.Hour,
+	})
+
+	now := time.Now().UTC()
+	require.NoError(t, provider.RegisterAgent(ctx, &types.AgentNode{
+		ID:              "node-cordon",
+		TeamID:          "team",
+		BaseURL:         "http://localhost:8001",
+		Version:         "1.0.0",
+		HealthStatus:    types.HealthStatusActive,
+		LifecycleStatus: types.AgentStatusReady,
+		LastHeartbeat:   now,
+		RegisteredAt:    now,
+	}))
+	presenceManager.Touch("node-cordon", now)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/nodes/:node_id/cordon", CordonNodeHandler(provider, statusManager, presenceManager))
+	router.POST("/nodes/:node_id/uncordon", UncordonNodeHandler(provider, statusManager, presenceManager))
+	router.POST("/execute/:target", ExecuteHandler(provider, services.NewFilePayloadStore(t.TempDir()), nil, 90*time.Second, statusManager))
+
+	post := func(path string) (int, map[string]any) {
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, httptest.NewRequest(http.MethodPost, path, nil))
+		var body map[string]any
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &body))
+		return resp.Code, body
+	}
+
+	t.Run("cordon", func(t *testing.T) {
+		code, body := post("/nodes/node-cordon/cordon")
+		require.Equal(t, http.StatusOK, code, body)
+		assert.Equal(t, true, body["cordoned"])
+		assert.Equal(t, string(types.StatusReasonCordoned), body["reason"])
+		assert.NotNil(t, body["status"])
+
+		assert.True(t, presenceManager.IsDraining("node-cordon"))
+		assert.True(t, statusManager.IsCordoned("node-cordon"))
+		state, _ := presenceManager.LeaseState("node-cordon")
+		assert.Equal(t, services.LeaseActive, state, "cordoning must not expire the lease")
+	})
+
+	t.Run("uncordon", func(t *testing.T) {
+		code, body := post("/nodes/node-cordon/uncordon")
+		require.Equal(t, http.StatusOK, code, body)
+		assert.Equal(t, false, body["cordoned"])
+		assert.NotEqual(t, string(types.StatusReasonCordoned), body["reason"])
+
+		assert.False(t, presenceManager.IsDraining("node-cordon"))
+		assert.False(t, statusManager.IsCordoned("node-cordon"))
+	})
+
+	t.Run("cordoned node stops receiving work", func(t *testing.T) {
+		var calls atomic.Int32
+		agentServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"ok":true}`))
+		}))
+		defer agentServer.Close()
+
+		require.NoError(t, provider.RegisterAgent(ctx, &types.AgentNode{
+			ID:              "node-work",
+			TeamID:          "team",
+			BaseURL:         agentServer.URL,
+			Version:         "1.0.0",
+			Reasoners:       []types.ReasonerDefinition{{ID: "reasoner-a"}},
+			HealthStatus:    types.HealthStatusActive,
+			LifecycleStatus: types.AgentStatusReady,
+			LastHeartbeat:   now,
+			RegisteredAt:    now,
+		}))
+		presenceManager.Touch("node-work", now)
+
+		execute := func() int {
+			resp := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/execute/node-work.reasoner-a", strings.NewReader(`{"input":{"foo":"bar"}}`))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(resp, req)
+			return resp.Code
+		}
+
+		code, body := post("/nodes/node-work/cordon")
+		require.Equal(t, http.StatusOK, code, body)
+		assert.Equal(t, http.StatusServiceUnavailable, execute())
+		assert.Zero(t, calls.Load(), "a cordoned node must not be called")
+
+		code, body = post("/nodes/node-work/uncordon")
+		require.Equal(t, http.StatusOK, code, body)
+		assert.Equal(t, http.StatusOK, execute())
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("registered node without a lease", func(t *testing.T) {
+		require.NoError(t, provider.RegisterAgent(ctx, &types.AgentNode{
+			ID:              "node-evicted",
+			TeamID:          "team",
+			BaseURL:         "http://localhost:8002",
+			Version:         "1.0.0",
+			HealthStatus:    types.HealthStatusInactive,
+			LifecycleStatus: types.AgentStatusOffline,
+			LastHeartbeat:   now,
+			RegisteredAt:    now,
+		}))
+		_, leaseKnown := presenceManager.LeaseState("node-evicted")
+		require.False(t, leaseKnown)
+
+		code, body := post("/nodes/node-evicted/cordon")
+		require.Equal(t, http.StatusOK, code, body)
+		assert.True(t, statusManager.IsCordoned("node-evicted"))
+
+		code, body = post("/nodes/node-evicted/uncordon")
+		require.Equal(t, http.StatusOK, code, body)
+		assert.False(t, statusManager.IsCordoned("node-evicted"))
+	})
+
+	t.Run("unknown node", func(t *testing.T) {
+		code, body := post("/nodes/node-missing/cordon")
+		assert.Equal(t, http.StatusNotFound, code)
+		assert.Equal(t, "NODE_NOT_FOUND", body["code"])
+		assert.False(t, statusManager.IsCordoned("node-missing"))
+
+		code, _ = post("/nodes/node-missing/uncordon")
+		assert.Equal(t, http.StatusNotFound, code)
+	})
+}
+
+func TestNodeStatusByLabelHandler(t *testing.T) {
+	provider, ctx := setupTestStorage(t)
+	agentService := coreservices.NewAgentService(nil, nil, nil, nil, t.TempDir(), provider)
+
+	now := time.Now().UTC()
+	register := func(id string, tags map[string]string, health types.HealthStatus, lifecycle types.AgentLifecycleStatus) {
+		require.NoError(t, provider.RegisterAgent(ctx, &types.AgentNode{
+			ID:              id,
+			TeamID:          "team",
+			BaseURL:         "http://localhost:8001",
+			Version:         "1.0.0",
+			HealthStatus:    health,
+			LifecycleStatus: lifecycle,
+			LastHeartbeat:   now,
+			RegisteredAt:    now,
+			Metadata:        types.AgentMetadata{Deployment: &types.DeploymentMetadata{Tags: tags}},
+		}))
+	}
+	payments := map[string]string{"team": "payments"}
+	register("pay-1", payments, types.HealthStatusActive, types.AgentStatusReady)
+	register("pay-2", payments, types.HealthStatusActive, types.AgentStatusReady)
+	register("pay-3", payments, types.HealthStatusInactive, types.AgentStatusOffline)
+	register("search-1", map[string]string{"team": "search"}, types.HealthStatusActive, types.AgentStatusReady)
+	register("untagged", nil, types.HealthStatusActive, types.AgentStatusReady)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/nodes/:node_id/status", func(c *gin.Context) { c.Status(http.StatusTeapot) })
+	router.GET("/nodes/status/by-label", NodeStatusByLabelHandler(agentService))
+
+	get := func(query string) (*httptest.ResponseRecorder, types.NodeStatusAggregate) {
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/nodes/status/by-label"+query, nil))
+		var aggregate types.NodeStatusAggregate
+		if resp.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &aggregate))
+		}
+		return resp, aggregate
+	}
+
+	t.Run("mixed statuses", func(t *testing.T) {
+		resp, aggregate := get("?key=team&value=payments")
+		require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+		assert.Equal(t, 3, aggregate.Total)
+		assert.Equal(t, map[types.HealthStatus]int{
+			types.HealthStatusActive:   2,
+			types.HealthStatusInactive: 1,
+		}, aggregate.Health)
+		assert.Equal(t, map[types.AgentLifecycleStatus]int{
+			types.AgentStatusReady:   2,
+			types.AgentStatusOffline: 1,
+		}, aggregate.Lifecycle)
+	})
+
+	t.Run("no matches", func(t *testing.T) {
+		resp, aggregate := get("?key=team&value=billing")
+		require.Equal(t, http.StatusOK, resp.Code)
+		assert.Equal(t, 0, aggregate.Total)
+		assert.Empty(t, aggregate.Health)
+	})
+
+	t.Run("missing parameters", func(t *testing.T) {
+		resp, _ := get("?key=team")
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+}
+
+func TestNodeSearchHandler(t *testing.T) {
+	provider, ctx := setupTestStorage(t)
+	agentService := coreservices.NewAgentService(nil, nil, nil, nil, t.TempDir(), provider)
+
+	now := time.Now().UTC()
+	register := func(id string, tags map[string]string) {
+		require.NoError(t, provider.RegisterAgent(ctx, &types.AgentNode{
+			ID:              id,
+			TeamID:          "team",
+			BaseURL:         "http://localhost:8001",
+			Version:         "1.0.0",
+			HealthStatus:    types.HealthStatusActive,
+			LifecycleStatus: types.AgentStatusReady,
+			LastHeartbeat:   now,
+			RegisteredAt:    now,
+			Metadata:        types.AgentMetadata{Deployment: &types.DeploymentMetadata{Tags: tags}},
+		}))
+	}
+	register("billing-pay", nil)
+	register("pay-worker", nil)
+	register("search-1", map[string]string{"team": "Payments"})
+	register("repayer", nil)
+	register("unrelated", map[string]string{"team": "search"})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/nodes/:node_id", func(c *gin.Context) { c.Status(http.StatusTeapot) })
+	router.GET("/nodes/search", NodeSearchHandler(agentService))
+
+	search := func(query string) (*httptest.ResponseRecorder, []string) {
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/nodes/search"+query, nil))
+		var body struct {
+			Nodes []types.AgentNode `json:"nodes"`
+		}
+		var ids []string
+		if resp.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &body))
+			for _, node := range body.Nodes {
+				ids = append(ids, node.ID)
+			}
+		}
+		return resp, ids
+	}
+
+	t.Run("prefix matches rank first", func(t *testing.T) {
+		resp, ids := search("?q=PAY")
+		require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+		// pay-worker and search-1 (tag "Payments") match at the start of a
+		// field; billing-pay and repayer only inside one.
+		assert.Equal(t, []string{"pay-worker", "search-1", "billing-pay", "repayer"}, ids)
+	})
+
+	t.Run("limit", func(t *testing.T) {
+		resp, ids := search("?q=pay&limit=1")
+		require.Equal(t, http.StatusOK, resp.Code)
+		assert.Equal(t, []string{"pay-worker"}, ids)
+	})
+
+	t.Run("no matches", func(t *testing.T) {
+		resp, ids := search("?q=zzz")
+		require.Equal(t, http.StatusOK, resp.Code)
+		assert.Empty(t, ids)
+		assert.Contains(t, resp.Body.String(), `"nodes":[]`)
+	})
+
+	t.Run("empty query", func(t *testing.T) {
+		resp, _ := search("?q=%20")
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		resp, _ = search("")
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+
+	t.Run("invalid limit", func(t *testing.T) {
+		resp, _ := search("?q=pay&limit=0")
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+}
+
+func TestNodeManifestHandler(t *testing.T) {
+	provider, ctx := setupTestStorage(t)
+	agentService := coreservices.NewAgentService(nil, nil, nil, nil, t.TempDir(), provider)
+
+	now := time.Now().UTC()
+	register := func(id string, manifest *types.AgentManifest) {
+		require.NoError(t, provider.RegisterAgent(ctx, &types.AgentNode{
+			ID:              id,
+			TeamID:          "team",
+			BaseURL:         "http://localhost:8001",
+			Version:         "1.0.0",
+			HealthStatus:    types.HealthStatusActive,
+			LifecycleStatus: types.AgentStatusReady,
+			LastHeartbeat:   now,
+			RegisteredAt:    now,
+			Metadata:        types.AgentMetadata{Manifest: manifest},
+		}))
+	}
+	register("node-capable", &types.AgentManifest{
+		Capabilities: []string{"summarize", "translate"},
+		Handlers:     []string{"summarize_text"},
+	})
+	register("node-plain", nil)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/nodes/:node_id/manifest", NodeManifestHandler(agentService))
+
+	get := func(nodeID string) *httptest.ResponseRecorder {
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/nodes/"+nodeID+"/manifest", nil))
+		return resp
+	}
+
+	t.Run("node with capabilities", func(t *testing.T) {
+		resp := get("node-capable")
+		require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+		var manifest types.AgentManifest
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &manifest))
+		assert.Equal(t, []string{"summarize", "translate"}, manifest.Capabilities)
+		assert.Equal(t, []string{"summarize_text"}, manifest.Handlers)
+	})
+
+	t.Run("node without manifest", func(t *testing.T) {
+		resp := get("node-plain")
+		assert.Equal(t, http.StatusNoContent, resp.Code)
+		assert.Empty(t, resp.Body.String())
+	})
+
+	t.Run("unknown node", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, get("node-missing").Code)
+	})
+}
@@ -11,7 +11,9 @@ import (
 	"strings"
 	"sync"
 	"time"
+	"unicode"
 
+	"github.com/Agent-Field/agentfield/control-plane/internal/events"
 	"github.com/Agent-Field/agentfield/control-plane/internal/logger"
 	"github.com/Agent-Field/agentfield/control-plane/internal/services" // Import services package
 	"github.com/Agent-Field/agentfield/control-plane/internal/storage"
@@ -32,6 +34,25 @@ func (rc *readCloser) Close() error {
 
 var validate = validator.New()
 
+// maxNodeIDLength bounds node IDs accepted from request paths.
+const maxNodeIDLength = 256
+
+// validateNodeID rejects node IDs that could not have been registered.
+func validateNodeID(nodeID string) error {
+	if strings.TrimSpace(nodeID) == "" {
+		return fmt.Errorf("node_id is required")
+	}
+	if len(nodeID) > maxNodeIDLength {
+		return fmt.Errorf("node_id exceeds %d characters", maxNodeIDLength)
+	}
+	for _, r := range nodeID {
+		if unicode.IsSpace(r) || unicode.IsControl(r) {
+			return fmt.Errorf("node_id contains whitespace or control characters")
+		}
+	}
+	return nil
+}
+
 // validateCallbackURL validates that a callback URL is properly formatted and reachable
 func validateCallbackURL(baseURL string) error {
 	if baseURL == "" {
@@ -512,6 +533,7 @@ func RegisterNodeHandler(storageProvider storage.StorageProvider, uiService *ser
 			return
 		}
 		InvalidateDiscoveryCache()
+		events.PublishNodeRegistered(newNode.ID, nil)
 
 		logger.Logger.Debug().Msgf("✅ Successfully registered node: %s", newNode.ID)
 
@@ -562,6 +584,7 @@ func RegisterNodeHandler(storageProvider storage.StorageProvider, uiService *ser
 
 		if presenceManager != nil {
 			presenceManager.Touch(newNode.ID, time.Now().UTC())
+			presenceManager.SetLabels(newNode.ID, services.NodeLabels(&newNode))
 		}
 
 		responsePayload := gin.H{
@@ -609,6 +632,20 @@ func ListNodesHandler(storageProvider storage.StorageProvider) gin.HandlerFunc {
 			filters.HealthStatus = nil // Remove health status filter to show all nodes
 		}
 
+		// Check for since parameter to return only nodes changed after the timestamp
+		if sinceParam := c.Query("since"); sinceParam != "" {
+			since, err := time.Parse(time.RFC3339Nano, sinceParam)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "since must be an RFC3339 timestamp"})
+				return
+			}
+			filters.UpdatedSince = &since
+			// Delta consumers need to see nodes that left the active state too
+			if c.Query("health_status") == "" {
+				filters.HealthStatus = nil
+			}
+		}
+
 		// Get filtered nodes from storage
 		nodes, err := storageProvider.ListAgents(ctx, filters)
 		if err != nil {
@@ -647,15 +684,28 @@ func GetNodeHandler(storageProvider storage.StorageProvider) gin.HandlerFunc {
 // HeartbeatHandler handles heartbeat requests from agent nodes
 // Supports both simple heartbeats and enhanced heartbeats with status updates
 // Now integrates with the unified status management system
+//
+// The response carries the presence lease expiry and a next_heartbeat_at hint
+// when the node holds a lease. With PresenceManagerConfig.StrictRegistration,
+// heartbeats from unregistered nodes are rejected with 404.
 func HeartbeatHandler(storageProvider storage.StorageProvider, uiService *services.UIService, healthMonitor *services.HealthMonitor, statusManager *services.StatusManager, presenceManager *services.PresenceManager) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		ctx := c.Request.Context()
 		nodeID := c.Param("node_id")
-		if nodeID == "" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "node_id is required"})
+		if err := validateNodeID(nodeID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
 
+		// Nodes holding a live lease were registered; only look up the rest.
+		if presenceManager != nil && presenceManager.StrictRegistration() && !presenceManager.HasLease(nodeID) {
+			if node, err := storageProvider.GetAgent(ctx, nodeID); err != nil || node == nil {
+				logger.Logger.Warn().Str("node_id", nodeID).Msg("⚠️ Rejected heartbeat from unregistered node")
+				c.JSON(http.StatusNotFound, gin.H{"error": "node not registered"})
+				return
+			}
+		}
+
 		// We'll verify node exists conditionally during heartbeat caching
 		var existingNode *types.AgentNode
 
@@ -667,8 +717,9 @@ func HeartbeatHandler(storageProvider storage.StorageProvider, uiService *servic
 				Status    string `json:"status"`
 				ToolCount int    `json:"tool_count"`
 			} `json:"mcp_servers,omitempty"`
-			Timestamp   string `json:"timestamp,omitempty"`
-			HealthScore *int   `json:"health_score,omitempty"` // New: allow agents to report health score
+			Timestamp   string            `json:"timestamp,omitempty"`
+			HealthScore *int              `json:"health_score,omitempty"` // New: allow agents to report health score
+			Labels      map[string]string `json:"labels,omitempty"`       // Replaces the presence lease labels
 		}
 
 		// Read the request body if present
@@ -683,8 +734,10 @@ func HeartbeatHandler(storageProvider storage.StorageProvider, uiService *servic
 
 		// Check if database update is needed using caching
 		now := time.Now().UTC()
-		if presenceManager != nil && presenceManager.HasLease(nodeID) {
-			presenceManager.Touch(nodeID, now)
+		if presenceManager != nil {
+			if state, ok := presenceManager.LeaseState(nodeID); ok && state != services.LeaseEvicted {
+				presenceManager.Touch(nodeID, now)
+			}
 		}
 		needsDBUpdate, cached := heartbeatCache.shouldUpdateDatabase(nodeID, now, enhancedHeartbeat.Status, enhancedHeartbeat.MCPServers)
 
@@ -837,12 +890,27 @@ func HeartbeatHandler(storageProvider storage.StorageProvider, uiService *servic
 
 		logger.Logger.Debug().Msgf("💓 Heartbeat received from node: %s at %s", nodeID, now.Format(time.RFC3339))
 
-		// Return immediate acknowledgment
-		c.JSON(http.StatusOK, gin.H{
+		response := gin.H{
 			"success":   true,
 			"message":   "heartbeat received",
 			"timestamp": now.Format(time.RFC3339),
-		})
+		}
+		if presenceManager != nil {
+			if enhancedHeartbeat.Labels != nil {
+				presenceManager.SetLabels(nodeID, enhancedHeartbeat.Labels)
+			}
+			if expiresAt, ok := presenceManager.LeaseExpiry(nodeID); ok {
+				nextHeartbeat := now.Add(presenceManager.HeartbeatInterval())
+				if nextHeartbeat.After(expiresAt) {
+					nextHeartbeat = now
+				}
+				response["lease_expires_at"] = expiresAt.UTC().Format(time.RFC3339)
+				response["next_heartbeat_at"] = nextHeartbeat.Format(time.RFC3339)
+			}
+		}
+
+		// Return immediate acknowledgment
+		c.JSON(http.StatusOK, response)
 	}
 }
 
@@ -948,8 +1016,10 @@ func UpdateLifecycleStatusHandler(storageProvider storage.StorageProvider, uiSer
 	}
 }
 
-// GetNodeStatusHandler handles getting the unified status for a specific node
-func GetNodeStatusHandler(statusManager *services.StatusManager) gin.HandlerFunc {
+// GetNodeStatusHandler handles getting the unified status for a specific node.
+// The response carries a machine-readable reason and a human-readable message
+// derived from the node's status and presence lease.
+func GetNodeStatusHandler(statusManager *services.StatusManager, presenceManager *services.PresenceManager) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		ctx := c.Request.Context()
 		nodeID := c.Param("node_id")
@@ -969,25 +1039,80 @@ func GetNodeStatusHandler(statusManager *services.StatusManager) gin.HandlerFunc
 			return
 		}
 
+		var leaseState services.LeaseState
+		var leaseKnown bool
+		if presenceManager != nil {
+			leaseState, leaseKnown = presenceManager.LeaseState(nodeID)
+		}
+
 		status, err := statusManager.GetAgentStatus(ctx, nodeID)
 		if err != nil {
 			logger.Logger.Error().Err(err).Str("node_id", nodeID).Msg("❌ Failed to get node status")
+			reason, message := explainNodeStatus(nil, false, false, leaseState, leaseKnown)
 			c.JSON(http.StatusNotFound, gin.H{
 				"error":   "Node not found or status unavailable",
 				"code":    "NODE_NOT_FOUND",
 				"details": err.Error(),
+				"reason":  reason,
+				"message": message,
 			})
 			return
 		}
 
+		reason, message := explainNodeStatus(status, statusManager.IsFlapping(nodeID), statusManager.IsCordoned(nodeID), leaseState, leaseKnown)
 		c.JSON(http.StatusOK, gin.H{
 			"success": true,
 			"node_id": nodeID,
 			"status":  status,
+			"reason":  reason,
+			"message": message,
 		})
 	}
 }
 
+// explainNodeStatus maps a node's status and presence lease to a reason code
+// and message. Draining takes precedence, then cordoning, then flapping, then
+// presence, then the status state.
+func explainNodeStatus(status *types.AgentStatus, flapping, cordoned bool, leaseState services.LeaseState, leaseKnown bool) (types.StatusReason, string) {
+	if status != nil && (status.State == types.AgentStateStopping ||
+		(status.StateTransition != nil && status.StateTransition.To == types.AgentStateStopping)) {
+		return types.StatusReasonDraining, "Node is shutting down"
+	}
+
+	if cordoned {
+		return types.StatusReasonCordoned, "Node is cordoned and not accepting new work"
+	}
+
+	if flapping {
+		return types.StatusReasonFlapping, "Node is repeatedly going online and offline"
+	}
+
+	if leaseKnown {
+		switch leaseState {
+		case services.LeaseEvicted:
+			return types.StatusReasonHardEvicted, "Node was evicted after missing heartbeats for an extended period"
+		case services.LeaseExpired:
+			return types.StatusReasonHeartbeatExpired, "Node has not sent a heartbeat within the presence TTL"
+		}
+	}
+
+	if status == nil {
+		if leaseKnown {
+			return types.StatusReasonUnreachable, "Node status is unavailable"
+		}
+		return types.StatusReasonNeverSeen, "Node has never been seen by this control plane"
+	}
+
+	switch status.State {
+	case types.AgentStateActive:
+		return types.StatusReasonHealthy, "Node is active and responding"
+	case types.AgentStateStarting:
+		return types.StatusReasonStarting, "Node is starting up"
+	default:
+		return types.StatusReasonUnreachable, "Node is not responding to health checks"
+	}
+}
+
 // RefreshNodeStatusHandler handles manual refresh of a node's status
 func RefreshNodeStatusHandler(statusManager *services.StatusManager) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -1121,6 +1246,27 @@ func BulkNodeStatusHandler(statusManager *services.StatusManager, storageProvide
 	}
 }
 
+// AllNodeStatusHandler returns the latest known status of every node in one
+// response, for exports and reports that would otherwise query node by node.
+// GET /api/v1/nodes/status/all
+func AllNodeStatusHandler(statusManager *services.StatusManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if statusManager == nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"error": "Status manager not available",
+				"code":  "SERVICE_UNAVAILABLE",
+			})
+			return
+		}
+
+		statuses := statusManager.AllStatuses()
+		c.JSON(http.StatusOK, gin.H{
+			"statuses": statuses,
+			"total":    len(statuses),
+		})
+	}
+}
+
 // RegisterServerlessAgentHandler handles the registration of a serverless agent node
 // by discovering its capabilities via the /discover endpoint
 func RegisterServerlessAgentHandler(storageProvider storage.StorageProvider, uiService *services.UIService, didService *services.DIDService, presenceManager *services.PresenceManager) gin.HandlerFunc {
@@ -1297,6 +1443,7 @@ func RegisterServerlessAgentHandler(storageProvider storage.StorageProvider, uiS
 			return
 		}
 		InvalidateDiscoveryCache()
+		events.PublishNodeRegistered(newNode.ID, nil)
 
 		logger.Logger.Info().Msgf("✅ Successfully registered serverless agent: %s", newNode.ID)
 
@@ -1320,6 +1467,7 @@ func RegisterServerlessAgentHandler(storageProvider storage.StorageProvider, uiS
 		// Touch presence manager
 		if presenceManager != nil {
 			presenceManager.Touch(newNode.ID, time.Now().UTC())
+			presenceManager.SetLabels(newNode.ID, services.NodeLabels(&newNode))
 		}
 
 		c.JSON(http.StatusCreated, gin.H{
@@ -1,12 +1,17 @@
 package handlers
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/Agent-Field/agentfield/control-plane/internal/services"
 	"github.com/Agent-Field/agentfield/control-plane/pkg/types"
+	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -357,3 +362,168 @@ func TestGatherCallbackCandidates_WhitespaceInCandidates(t *testing.T) {
 		assert.Equal(t, len(c), len(c), "Should not have leading/trailing whitespace")
 	}
 }
+
+func TestListNodesHandler_Since(t *testing.T) {
+	provider, ctx := setupTestStorage(t)
+
+	for _, id := range []string{"node-unchanged", "node-updated"} {
+		require.NoError(t, provider.RegisterAgent(ctx, &types.AgentNode{
+			ID:              id,
+			TeamID:          "team",
+			BaseURL:         "http://localhost:8001",
+			Version:         "1.0.0",
+			HealthStatus:    types.HealthStatusActive,
+			LifecycleStatus: types.AgentStatusReady,
+			LastHeartbeat:   time.Now().UTC(),
+			RegisteredAt:    time.Now().UTC(),
+		}))
+	}
+
+	time.Sleep(5 * time.Millisecond)
+	since := time.Now().UTC()
+	time.Sleep(5 * time.Millisecond)
+	require.NoError(t, provider.UpdateAgentLifecycleStatus(ctx, "node-updated", types.AgentStatusDegraded))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/nodes", ListNodesHandler(provider))
+
+	req := httptest.NewRequest(http.MethodGet, "/nodes?since="+since.Format(time.RFC3339Nano), nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	require.Equal(t, http.StatusOK, resp.Code)
+
+	var body struct {
+		Nodes []*types.AgentNode `json:"nodes"`
+		Count int                `json:"count"`
+	}
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &body))
+	require.Equal(t, 1, body.Count)
+	require.Equal(t, "node-updated", body.Nodes[0].ID)
+	assert.True(t, body.Nodes[0].UpdatedAt.After(since))
+}
+
+func TestListNodesHandler_InvalidSince(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/nodes", ListNodesHandler(nil))
+
+	req := httptest.NewRequest(http.MethodGet, "/nodes?since=yesterday", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	require.Equal(t, http.StatusBadRequest, resp.Code)
+}
+
+func TestGetNodeStatusHandler_Reasons(t *testing.T) {
+	provider, ctx := setupTestStorage(t)
+
+	statusManager := services.NewStatusManager(provider, services.StatusManagerConfig{}, nil, nil)
+	presenceManager := services.NewPresenceManager(statusManager, services.PresenceManagerConfig{
+		HeartbeatTTL: 5 * time.Second,
+		HardEvictTTL: time.Minute,
+	})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/nodes/:node_id/status", GetNodeStatusHandler(statusManager, presenceManager))
+
+	getStatus := func(nodeID string) (int, map[string]any) {
+		req := httptest.NewRequest(http.MethodGet, "/nodes/"+nodeID+"/status", nil)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		var body map[string]any
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &body))
+		return resp.Code, body
+	}
+
+	t.Run("never seen", func(t *testing.T) {
+		code, body := getStatus("node-unknown")
+		assert.Equal(t, http.StatusNotFound, code)
+		assert.Equal(t, string(types.StatusReasonNeverSeen), body["reason"])
+		assert.NotEmpty(t, body["message"])
+	})
+
+	t.Run("heartbeat expired", func(t *testing.T) {
+		lastSeen := time.Now().UTC().Add(-30 * time.Second)
+		require.NoError(t, provider.RegisterAgent(ctx, &types.AgentNode{
+			ID:              "node-expired",
+			TeamID:          "team",
+			BaseURL:         "http://localhost:8001",
+			Version:         "1.0.0",
+			HealthStatus:    types.HealthStatusInactive,
+			LifecycleStatus: types.AgentStatusOffline,
+			LastHeartbeat:   lastSeen,
+			RegisteredAt:    lastSeen,
+		}))
+		presenceManager.Touch("node-expired", lastSeen)
+
+		code, body := getStatus("node-expired")
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, string(types.StatusReasonHeartbeatExpired), body["reason"])
+		assert.NotEmpty(t, body["message"])
+		assert.NotNil(t, body["status"], "top-level status is kept for compatibility")
+	})
+}
+
+func TestHeartbeatHandler_LeaseHint(t *testing.T) {
+	provider, ctx := setupTestStorage(t)
+
+	now := time.Now().UTC()
+	require.NoError(t, provider.RegisterAgent(ctx, &types.AgentNode{
+		ID:              "node-heartbeat",
+		TeamID:          "team",
+		BaseURL:         "http://localhost:8001",
+		Version:         "1.0.0",
+		HealthStatus:    types.HealthStatusActive,
+		LifecycleStatus: types.AgentStatusReady,
+		LastHeartbeat:   now,
+		RegisteredAt:    now,
+	}))
+
+	presenceManager := services.NewPresenceManager(nil, services.PresenceManagerConfig{
+		HeartbeatTTL:       30 * time.Second,
+		StrictRegistration: true,
+	})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/nodes/:node_id/heartbeat", HeartbeatHandler(provider, nil, nil, nil, presenceManager))
+
+	heartbeat := func(nodeID, body string) (int, map[string]any) {
+		req := httptest.NewRequest(http.MethodPost, "/nodes/"+nodeID+"/heartbeat", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		var payload map[string]any
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &payload))
+		return resp.Code, payload
+	}
+
+	t.Run("returns lease expiry and next heartbeat", func(t *testing.T) {
+		code, body := heartbeat("node-heartbeat", `{"labels":{"pool":"gpu"}}`)
+		require.Equal(t, http.StatusOK, code, body)
+		assert.True(t, presenceManager.HasLease("node-heartbeat"))
+		assert.Equal(t, map[string]int{"gpu": 1}, presenceManager.PresentCountByLabel("pool"))
+
+		expiresAt, err := time.Parse(time.RFC3339, body["lease_expires_at"].(string))
+		require.NoError(t, err)
+		nextHeartbeat, err := time.Parse(time.RFC3339, body["next_heartbeat_at"].(string))
+		require.NoError(t, err)
+
+		assert.WithinDuration(t, now.Add(30*time.Second), expiresAt, 5*time.Second)
+		assert.WithinDuration(t, now.Add(10*time.Second), nextHeartbeat, 5*time.Second)
+		assert.True(t, nextHeartbeat.Before(expiresAt), "the hint must leave room before the lease expires")
+	})
+
+	t.Run("strict mode rejects unregistered nodes", func(t *testing.T) {
+		code, _ := heartbeat("node-unregistered", "")
+		assert.Equal(t, http.StatusNotFound, code)
+		_, known := presenceManager.LeaseState("node-unregistered")
+		assert.False(t, known)
+	})
+
+	t.Run("invalid node id", func(t *testing.T) {
+		code, _ := heartbeat("node%20with%20spaces", "")
+		assert.Equal(t, http.StatusBadRequest, code)
+	})
+}
@@ -11,6 +11,7 @@ import (
 	"time" // Added for time.Now()
 
 	"github.com/Agent-Field/agentfield/control-plane/internal/logger"
+	"github.com/Agent-Field/agentfield/control-plane/internal/services"
 	"github.com/Agent-Field/agentfield/control-plane/internal/storage"
 	"github.com/Agent-Field/agentfield/control-plane/internal/utils" // Added for ID generation
 	"github.com/Agent-Field/agentfield/control-plane/pkg/types"      // Added for new types
@@ -41,8 +42,9 @@ type ExecuteReasonerResponse struct {
 	Timestamp string      `json:"timestamp"`
 }
 
-// ExecuteReasonerHandler handles execution of reasoners with full tracking
-func ExecuteReasonerHandler(storageProvider storage.StorageProvider) gin.HandlerFunc {
+// ExecuteReasonerHandler handles execution of reasoners with full tracking.
+// Nodes cordoned in statusManager are refused; statusManager may be nil.
+func ExecuteReasonerHandler(storageProvider storage.StorageProvider, statusManager *services.StatusManager) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		ctx := c.Request.Context()
 		startTime := time.Now()
@@ -111,6 +113,12 @@ func ExecuteReasonerHandler(storageProvider storage.StorageProvider) gin.Handler
 			})
 			return
 		}
+		if statusManager != nil && statusManager.IsCordoned(nodeID) {
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"error": fmt.Sprintf("node '%s' is cordoned", nodeID),
+			})
+			return
+		}
 
 		// Check if reasoner exists on the node
 		reasonerExists := false
@@ -390,8 +398,9 @@ func ExecuteReasonerHandler(storageProvider storage.StorageProvider) gin.Handler
 	}
 }
 
-// ExecuteSkillHandler handles execution of skills via AgentField server
-func ExecuteSkillHandler(storageProvider storage.StorageProvider) gin.HandlerFunc {
+// ExecuteSkillHandler handles execution of skills via AgentField server.
+// Nodes cordoned in statusManager are refused; statusManager may be nil.
+func ExecuteSkillHandler(storageProvider storage.StorageProvider, statusManager *services.StatusManager) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		ctx := c.Request.Context()
 		startTime := time.Now()
@@ -460,6 +469,12 @@ func ExecuteSkillHandler(storageProvider storage.StorageProvider) gin.HandlerFun
 			})
 			return
 		}
+		if statusManager != nil && statusManager.IsCordoned(nodeID) {
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"error": fmt.Sprintf("node '%s' is cordoned", nodeID),
+			})
+			return
+		}
 
 		// Check if skill exists on the node
 		skillExists := false
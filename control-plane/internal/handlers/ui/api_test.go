@@ -3,16 +3,19 @@ package ui
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/Agent-Field/agentfield/control-plane/internal/core/domain"
 	"github.com/Agent-Field/agentfield/control-plane/internal/core/interfaces"
 	"github.com/Agent-Field/agentfield/control-plane/internal/services"
 	"github.com/Agent-Field/agentfield/control-plane/internal/storage"
 	"github.com/Agent-Field/agentfield/control-plane/pkg/types"
+	"github.com/Agent-Field/agentfield/sdk/go/agent"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -113,6 +116,217 @@ func TestGetNodeDetailsHandler_Structure(t *testing.T) {
 	assert.True(t, resp.Code == http.StatusNotFound || resp.Code == http.StatusInternalServerError)
 }
 
+// TestGetNodeDetailsHandler_StatusHistory verifies recent transitions are embedded newest-first
+func TestGetNodeDetailsHandler_StatusHistory(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctx := context.Background()
+	tempDir := t.TempDir()
+	cfg := storage.StorageConfig{
+		Mode: "local",
+		Local: storage.LocalStorageConfig{
+			DatabasePath: tempDir + "/test.db",
+			KVStorePath:  tempDir + "/test.bolt",
+		},
+	}
+
+	realStorage := storage.NewLocalStorage(storage.LocalStorageConfig{})
+	err := realStorage.Initialize(ctx, cfg)
+	if err != nil && strings.Contains(strings.ToLower(err.Error()), "fts5") {
+		t.Skip("sqlite3 compiled without FTS5")
+	}
+	require.NoError(t, err)
+	defer realStorage.Close(ctx)
+
+	require.NoError(t, realStorage.RegisterAgent(ctx, &types.AgentNode{
+		ID:              "node-history",
+		TeamID:          "team",
+		BaseURL:         "http://localhost:8001",
+		Version:         "1.0.0",
+		HealthStatus:    types.HealthStatusActive,
+		LifecycleStatus: types.AgentStatusReady,
+		LastHeartbeat:   time.Now().UTC(),
+		RegisteredAt:    time.Now().UTC(),
+	}))
+
+	mockAgentClient := &MockAgentClientForUI{}
+	mockAgentService := &MockAgentServiceForUI{}
+	statusManager := services.NewStatusManager(realStorage, services.StatusManagerConfig{}, nil, mockAgentClient)
+	uiService := services.NewUIService(realStorage, mockAgentClient, mockAgentService, statusManager)
+
+	handler := NewNodesHandler(uiService)
+	router := gin.New()
+	router.GET("/api/ui/v1/nodes/:nodeId/details", handler.GetNodeDetailsHandler)
+
+	type detailsBody struct {
+		StatusHistory []services.StatusHistoryEntry `json:"status_history"`
+	}
+	fetch := func(query string) (int, string) {
+		req := httptest.NewRequest(http.MethodGet, "/api/ui/v1/nodes/node-history/details"+query, nil)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp.Code, resp.Body.String()
+	}
+
+	// Empty history is an empty array, not null
+	code, raw := fetch("")
+	require.Equal(t, http.StatusOK, code)
+	assert.Contains(t, raw, `"status_history":[]`)
+
+	for _, state := range []types.AgentState{types.AgentStateInactive, types.AgentStateActive, types.AgentStateInactive} {
+		s := state
+		require.NoError(t, statusManager.UpdateAgentStatus(ctx, "node-history", &types.AgentStatusUpdate{
+			State:  &s,
+			Source: types.StatusSourceManual,
+		}))
+	}
+
+	code, raw = fetch("")
+	require.Equal(t, http.StatusOK, code)
+	var body detailsBody
+	require.NoError(t, json.Unmarshal([]byte(raw), &body))
+	require.Len(t, body.StatusHistory, 3)
+	assert.Equal(t, types.AgentStateInactive, body.StatusHistory[0].To)
+	assert.Equal(t, types.AgentStateActive, body.StatusHistory[1].To)
+	assert.Equal(t, types.AgentStateInactive, body.StatusHistory[2].To)
+	assert.False(t, body.StatusHistory[0].At.Before(body.StatusHistory[1].At))
+
+	code, raw = fetch("?history=1")
+	require.Equal(t, http.StatusOK, code)
+	body = detailsBody{}
+	require.NoError(t, json.Unmarshal([]byte(raw), &body))
+	require.Len(t, body.StatusHistory, 1)
+	assert.Equal(t, types.AgentStateInactive, body.StatusHistory[0].To)
+}
+
+// TestGetNodesTimelineHandler verifies transitions from several nodes are merged in time order
+func TestGetNodesTimelineHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctx := context.Background()
+	tempDir := t.TempDir()
+	cfg := storage.StorageConfig{
+		Mode: "local",
+		Local: storage.LocalStorageConfig{
+			DatabasePath: tempDir + "/test.db",
+			KVStorePath:  tempDir + "/test.bolt",
+		},
+	}
+
+	realStorage := storage.NewLocalStorage(storage.LocalStorageConfig{})
+	err := realStorage.Initialize(ctx, cfg)
+	if err != nil && strings.Contains(strings.ToLower(err.Error()), "fts5") {
+		t.Skip("sqlite3 compiled without FTS5")
+	}
+	require.NoError(t, err)
+	defer realStorage.Close(ctx)
+
+	for _, nodeID := range []string{"node-a", "node-b"} {
+		require.NoError(t, realStorage.RegisterAgent(ctx, &types.AgentNode{
+			ID:              nodeID,
+			TeamID:          "team",
+			BaseURL:         "http://localhost:8001",
+			Version:         "1.0.0",
+			HealthStatus:    types.HealthStatusActive,
+			LifecycleStatus: types.AgentStatusReady,
+			LastHeartbeat:   time.Now().UTC(),
+			RegisteredAt:    time.Now().UTC(),
+		}))
+	}
+
+	mockAgentClient := &MockAgentClientForUI{}
+	mockAgentService := &MockAgentServiceForUI{}
+	statusManager := services.NewStatusManager(realStorage, services.StatusManagerConfig{}, nil, mockAgentClient)
+	uiService := services.NewUIService(realStorage, mockAgentClient, mockAgentService, statusManager)
+
+	handler := NewNodesHandler(uiService)
+	router := gin.New()
+	router.GET("/api/ui/v1/nodes/timeline", handler.GetNodesTimelineHandler)
+
+	type timelineBody struct {
+		Transitions []services.StatusTimelineEntry `json:"transitions"`
+		Total       int                            `json:"total"`
+		HasMore     bool                           `json:"has_more"`
+	}
+	fetch := func(query string) (int, timelineBody) {
+		req := httptest.NewRequest(http.MethodGet, "/api/ui/v1/nodes/timeline"+query, nil)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		var body timelineBody
+		if resp.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &body))
+		}
+		return resp.Code, body
+	}
+
+	// Alternate between the nodes so their histories interleave in time.
+	start := time.Now()
+	steps := []struct {
+		node  string
+		state types.AgentState
+	}{
+		{"node-a", types.AgentStateInactive},
+		{"node-b", types.AgentStateInactive},
+		{"node-a", types.AgentStateActive},
+		{"node-b", types.AgentStateActive},
+		{"node-a", types.AgentStateInactive},
+	}
+	for _, step := range steps {
+		s := step.state
+		require.NoError(t, statusManager.UpdateAgentStatus(ctx, step.node, &types.AgentStatusUpdate{
+			State:  &s,
+			Source: types.StatusSourceManual,
+			Reason: "test",
+		}))
+	}
+
+	code, body := fetch("")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, len(steps), body.Total)
+	require.Len(t, body.Transitions, len(steps))
+	for i, step := range steps {
+		assert.Equal(t, step.node, body.Transitions[i].NodeID, "transition %d", i)
+		assert.Equal(t, step.state, body.Transitions[i].To, "transition %d", i)
+		if i > 0 {
+			assert.False(t, body.Transitions[i].At.Before(body.Transitions[i-1].At), "transition %d is out of order", i)
+		}
+	}
+	assert.False(t, body.HasMore)
+
+	t.Run("pagination", func(t *testing.T) {
+		code, page := fetch("?limit=2&offset=2")
+		require.Equal(t, http.StatusOK, code)
+		require.Len(t, page.Transitions, 2)
+		assert.Equal(t, body.Transitions[2:4], page.Transitions)
+		assert.True(t, page.HasMore)
+
+		code, page = fetch("?limit=2&offset=4")
+		require.Equal(t, http.StatusOK, code)
+		require.Len(t, page.Transitions, 1)
+		assert.False(t, page.HasMore)
+	})
+
+	t.Run("window", func(t *testing.T) {
+		code, window := fetch("?to=" + start.Add(-time.Hour).UTC().Format(time.RFC3339))
+		require.Equal(t, http.StatusOK, code)
+		assert.Empty(t, window.Transitions)
+
+		code, window = fetch("?from=" + start.Add(-time.Hour).UTC().Format(time.RFC3339))
+		require.Equal(t, http.StatusOK, code)
+		assert.Len(t, window.Transitions, len(steps))
+	})
+
+	t.Run("invalid parameters", func(t *testing.T) {
+		code, _ := fetch("?from=yesterday")
+		assert.Equal(t, http.StatusBadRequest, code)
+		code, _ = fetch("?offset=-1")
+		assert.Equal(t, http.StatusBadRequest, code)
+		now := time.Now().UTC().Format(time.RFC3339)
+		code, _ = fetch("?from=" + now + "&to=" + now)
+		assert.Equal(t, http.StatusBadRequest, code)
+	})
+}
+
 // TestGetNodeStatusHandler_Structure tests node status handler
 func TestGetNodeStatusHandler_Structure(t *testing.T) {
 	gin.SetMode(gin.TestMode)
@@ -492,6 +706,26 @@ func (m *MockAgentServiceForUI) ListRunningAgents() ([]domain.RunningAgent, erro
 	return []domain.RunningAgent{}, nil
 }
 
+func (m *MockAgentServiceForUI) RegisterAgent(ctx context.Context, node *types.AgentNode) error {
+	return nil
+}
+
+func (m *MockAgentServiceForUI) DeregisterAgent(ctx context.Context, id string) error {
+	return nil
+}
+
+func (m *MockAgentServiceForUI) GetAgentStatusesByLabel(ctx context.Context, key, value string) (*types.NodeStatusAggregate, error) {
+	return &types.NodeStatusAggregate{LabelKey: key, LabelValue: value}, nil
+}
+
+func (m *MockAgentServiceForUI) GetAgentManifest(ctx context.Context, id string) (*types.AgentManifest, error) {
+	return nil, nil
+}
+
+func (m *MockAgentServiceForUI) SearchAgents(ctx context.Context, query string, limit int) ([]*types.AgentNode, error) {
+	return nil, nil
+}
+
 // MockAgentService is a mock for interfaces.AgentService (used by dashboard)
 type MockAgentService struct {
 	mock.Mock
@@ -512,3 +746,88 @@ func (m *MockAgentService) GetAgent(ctx context.Context, id string) (*types.Agen
 	}
 	return args.Get(0).(*types.AgentNode), args.Error(1)
 }
+
+// failingPingBackend is a memory backend whose Ping always fails.
+type failingPingBackend struct {
+	agent.MemoryBackend
+}
+
+func (failingPingBackend) Ping(context.Context) error {
+	return errors.New("connection refused")
+}
+
+// TestGetEnhancedDashboardSummaryHandler_HealthRollup tests the health section
+// with a mix of healthy and unhealthy dependencies
+func TestGetEnhancedDashboardSummaryHandler_HealthRollup(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctx := context.Background()
+	tempDir := t.TempDir()
+	cfg := storage.StorageConfig{
+		Mode: "local",
+		Local: storage.LocalStorageConfig{
+			DatabasePath: tempDir + "/test.db",
+			KVStorePath:  tempDir + "/test.bolt",
+		},
+	}
+
+	realStorage := storage.NewLocalStorage(storage.LocalStorageConfig{})
+	err := realStorage.Initialize(ctx, cfg)
+	if err != nil && strings.Contains(strings.ToLower(err.Error()), "fts5") {
+		t.Skip("sqlite3 compiled without FTS5")
+	}
+	require.NoError(t, err)
+
+	// The status manager is never started, so reconciliation is unhealthy.
+	statusManager := services.NewStatusManager(realStorage, services.StatusManagerConfig{}, nil, &MockAgentClientForUI{})
+	presenceManager := services.NewPresenceManager(nil, services.PresenceManagerConfig{HeartbeatTTL: time.Minute})
+	presenceManager.Touch("node-draining", time.Now())
+	require.True(t, presenceManager.Drain("node-draining"))
+
+	handler := NewDashboardHandler(realStorage, &MockAgentServiceForUI{})
+	handler.SetHealthSources(statusManager, presenceManager)
+	router := gin.New()
+	router.GET("/api/ui/v1/dashboard/enhanced", handler.GetEnhancedDashboardSummaryHandler)
+
+	getHealth := func() HealthRollup {
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/api/ui/v1/dashboard/enhanced", nil))
+		require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+
+		var result EnhancedDashboardResponse
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &result))
+		require.NotNil(t, result.Health)
+		return *result.Health
+	}
+
+	health := getHealth()
+	assert.False(t, health.OK)
+	assert.True(t, health.Memory.OK, health.Memory.Detail)
+	assert.True(t, health.Presence.OK, health.Presence.Detail)
+	require.NotNil(t, health.Presence.Stats)
+	assert.Equal(t, 1, health.Presence.Stats.Active)
+	assert.False(t, health.Reconcile.OK)
+	assert.Equal(t, "status manager not running", health.Reconcile.Detail)
+	assert.True(t, health.Nodes.OK)
+	assert.Equal(t, 1, health.Nodes.Draining)
+	assert.Equal(t, 0, health.Nodes.Flapping)
+
+	// A memory backend that can be pinged is probed instead of storage.
+	handler.SetMemoryBackend(failingPingBackend{MemoryBackend: agent.NewInMemoryBackend()})
+	health = getHealth()
+	assert.False(t, health.Memory.OK)
+	assert.Equal(t, "memory backend unreachable: connection refused", health.Memory.Detail)
+
+	// A backend without Ping falls back to the storage health check.
+	handler.SetMemoryBackend(agent.NewInMemoryBackend())
+	health = getHealth()
+	assert.True(t, health.Memory.OK, health.Memory.Detail)
+
+	// The cached dashboard is still served once storage goes away; only the
+	// memory check reports the failure.
+	require.NoError(t, realStorage.Close(ctx))
+	health = getHealth()
+	assert.False(t, health.Memory.OK)
+	assert.Contains(t, health.Memory.Detail, "storage unreachable")
+	assert.True(t, health.Presence.OK)
+}
@@ -234,6 +234,11 @@ func (m *MockStorageProvider) RegisterAgent(ctx context.Context, agent *types.Ag
 	return args.Error(0)
 }
 
+func (m *MockStorageProvider) CreateAgent(ctx context.Context, agent *types.AgentNode) error {
+	args := m.Called(ctx, agent)
+	return args.Error(0)
+}
+
 func (m *MockStorageProvider) GetAgent(ctx context.Context, id string) (*types.AgentNode, error) {
 	args := m.Called(ctx, id)
 	if args.Get(0) == nil {
@@ -242,6 +247,11 @@ func (m *MockStorageProvider) GetAgent(ctx context.Context, id string) (*types.A
 	return args.Get(0).(*types.AgentNode), args.Error(1)
 }
 
+func (m *MockStorageProvider) DeleteAgent(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
 func (m *MockStorageProvider) ListAgents(ctx context.Context, filters types.AgentFilters) ([]*types.AgentNode, error) {
 	args := m.Called(ctx, filters)
 	if args.Get(0) == nil {
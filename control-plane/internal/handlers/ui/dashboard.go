@@ -10,8 +10,10 @@ import (
 
 	"github.com/Agent-Field/agentfield/control-plane/internal/core/interfaces"
 	"github.com/Agent-Field/agentfield/control-plane/internal/logger"
+	"github.com/Agent-Field/agentfield/control-plane/internal/services"
 	"github.com/Agent-Field/agentfield/control-plane/internal/storage"
 	"github.com/Agent-Field/agentfield/control-plane/pkg/types"
+	"github.com/Agent-Field/agentfield/sdk/go/agent"
 
 	"github.com/gin-gonic/gin"
 )
@@ -23,6 +25,11 @@ type DashboardHandler struct {
 	agentService  interfaces.AgentService
 	cache         *DashboardCache
 	enhancedCache *EnhancedDashboardCache
+
+	// Optional sources for the enhanced dashboard health rollup.
+	statusManager   *services.StatusManager
+	presenceManager *services.PresenceManager
+	memoryBackend   agent.MemoryBackend
 }
 
 // NewDashboardHandler creates a new DashboardHandler.
@@ -176,6 +183,7 @@ type EnhancedDashboardResponse struct {
 	Comparison       *ComparisonData     `json:"comparison,omitempty"`
 	Hotspots         HotspotSummary      `json:"hotspots"`
 	ActivityPatterns ActivityPatterns    `json:"activity_patterns"`
+	Health           *HealthRollup       `json:"health,omitempty"`
 }
 
 type EnhancedOverview struct {
@@ -548,7 +556,10 @@ func (h *DashboardHandler) GetEnhancedDashboardSummaryHandler(c *gin.Context) {
 	cacheKey := generateCacheKey(startTime, endTime, enableComparison)
 	if cached, found := h.enhancedCache.Get(cacheKey, preset); found {
 		logger.Logger.Debug().Str("key", cacheKey).Msg("Returning cached enhanced dashboard summary")
-		c.JSON(http.StatusOK, cached)
+		// Health is always fresh; copy so the cached entry is not mutated.
+		response := *cached
+		response.Health = h.buildHealthRollup(ctx, now)
+		c.JSON(http.StatusOK, &response)
 		return
 	}
 
@@ -634,7 +645,10 @@ func (h *DashboardHandler) GetEnhancedDashboardSummaryHandler(c *gin.Context) {
 	}
 
 	h.enhancedCache.Set(cacheKey, response)
-	c.JSON(http.StatusOK, response)
+
+	withHealth := *response
+	withHealth.Health = h.buildHealthRollup(ctx, now)
+	c.JSON(http.StatusOK, &withHealth)
 }
 
 // buildEnhancedOverviewForRange builds overview metrics for a specific time range
@@ -0,0 +1,167 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Agent-Field/agentfield/control-plane/internal/services"
+	"github.com/Agent-Field/agentfield/sdk/go/agent"
+)
+
+// healthCheckTimeout bounds the memory reachability probe in the rollup.
+const healthCheckTimeout = 2 * time.Second
+
+// staleIntervals is how many missed intervals mark a background loop as stalled.
+const staleIntervals = 3
+
+// HealthRollup summarizes control plane health for the enhanced dashboard.
+// OK is true only when every sub-check is OK; a failing check is reported in
+// its own section and never fails the dashboard request.
+type HealthRollup struct {
+	OK        bool            `json:"ok"`
+	Memory    HealthCheck     `json:"memory"`
+	Presence  PresenceHealth  `json:"presence"`
+	Reconcile ReconcileHealth `json:"reconcile"`
+	Nodes     NodeHealth      `json:"nodes"`
+}
+
+// HealthCheck is the outcome of a single health sub-check.
+type HealthCheck struct {
+	OK     bool   `json:"ok"`
+	Detail string `json:"detail"`
+}
+
+// PresenceHealth reports the presence sweeper and lease counts.
+type PresenceHealth struct {
+	HealthCheck
+	Stats *services.PresenceStats `json:"stats,omitempty"`
+}
+
+// ReconcileHealth reports how long ago status reconciliation last completed.
+type ReconcileHealth struct {
+	HealthCheck
+	LastReconcileAt *time.Time `json:"last_reconcile_at,omitempty"`
+	AgeSeconds      float64    `json:"age_seconds"`
+}
+
+// NodeHealth counts nodes needing operator attention.
+type NodeHealth struct {
+	HealthCheck
+	Flapping int `json:"flapping"`
+	Draining int `json:"draining"`
+}
+
+// SetHealthSources supplies the managers used for the enhanced dashboard's
+// health rollup. Without them the matching sections report not OK.
+func (h *DashboardHandler) SetHealthSources(statusManager *services.StatusManager, presenceManager *services.PresenceManager) {
+	h.statusManager = statusManager
+	h.presenceManager = presenceManager
+}
+
+// SetMemoryBackend supplies the agent memory backend probed by the health
+// rollup. Backends implementing agent.Pinger are pinged directly; otherwise
+// the rollup falls back to the storage health check.
+func (h *DashboardHandler) SetMemoryBackend(backend agent.MemoryBackend) {
+	h.memoryBackend = backend
+}
+
+// buildHealthRollup runs every health sub-check. It is computed per request
+// rather than cached with the rest of the dashboard.
+func (h *DashboardHandler) buildHealthRollup(ctx context.Context, now time.Time) *HealthRollup {
+	rollup := &HealthRollup{
+		Memory:    h.memoryHealth(ctx),
+		Presence:  h.presenceHealth(now),
+		Reconcile: h.reconcileHealth(now),
+		Nodes:     h.nodeHealth(),
+	}
+	rollup.OK = rollup.Memory.OK && rollup.Presence.OK && rollup.Reconcile.OK && rollup.Nodes.OK
+	return rollup
+}
+
+// memoryHealth probes the agent memory backend when it can be pinged, and the
+// storage provider otherwise.
+func (h *DashboardHandler) memoryHealth(ctx context.Context) HealthCheck {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+	if pinger, ok := h.memoryBackend.(agent.Pinger); ok {
+		if err := pinger.Ping(ctx); err != nil {
+			return HealthCheck{Detail: "memory backend unreachable: " + err.Error()}
+		}
+		return HealthCheck{OK: true, Detail: "reachable"}
+	}
+	if h.storage == nil {
+		return HealthCheck{Detail: "storage not configured"}
+	}
+	if err := h.storage.HealthCheck(ctx); err != nil {
+		return HealthCheck{Detail: "storage unreachable: " + err.Error()}
+	}
+	return HealthCheck{OK: true, Detail: "reachable"}
+}
+
+func (h *DashboardHandler) presenceHealth(now time.Time) PresenceHealth {
+	if h.presenceManager == nil {
+		return PresenceHealth{HealthCheck: HealthCheck{Detail: "presence manager not configured"}}
+	}
+	stats := h.presenceManager.Stats()
+	health := PresenceHealth{
+		HealthCheck: HealthCheck{
+			OK:     true,
+			Detail: fmt.Sprintf("%d active, %d expired, %d evicted leases", stats.Active, stats.Expired, stats.Evicted),
+		},
+		Stats: &stats,
+	}
+	if stats.Sweeps > 0 && now.Sub(stats.LastSweep) > staleIntervals*h.presenceManager.SweepInterval() {
+		health.OK = false
+		health.Detail = fmt.Sprintf("sweeper stalled; last sweep %s ago", now.Sub(stats.LastSweep).Round(time.Second))
+	}
+	return health
+}
+
+func (h *DashboardHandler) reconcileHealth(now time.Time) ReconcileHealth {
+	if h.statusManager == nil {
+		return ReconcileHealth{HealthCheck: HealthCheck{Detail: "status manager not configured"}}
+	}
+	info := h.statusManager.ReconcileInfo()
+	if info.StartedAt.IsZero() {
+		return ReconcileHealth{HealthCheck: HealthCheck{Detail: "status manager not running"}}
+	}
+
+	// Before the first pass, measure from startup so a stuck loop still ages.
+	since := info.StartedAt
+	health := ReconcileHealth{}
+	if !info.LastReconcile.IsZero() {
+		since = info.LastReconcile
+		lastReconcile := info.LastReconcile
+		health.LastReconcileAt = &lastReconcile
+	}
+	age := now.Sub(since)
+	health.AgeSeconds = age.Seconds()
+
+	switch {
+	case age > staleIntervals*info.Interval:
+		health.Detail = fmt.Sprintf("no reconciliation for %s", age.Round(time.Second))
+	case info.LastReconcile.IsZero():
+		health.OK = true
+		health.Detail = "awaiting first reconciliation"
+	default:
+		health.OK = true
+		health.Detail = fmt.Sprintf("last reconciled %s ago", age.Round(time.Second))
+	}
+	return health
+}
+
+// nodeHealth is not OK while any node is flapping. Draining nodes are an
+// operator decision and only counted.
+func (h *DashboardHandler) nodeHealth() NodeHealth {
+	if h.statusManager == nil {
+		return NodeHealth{HealthCheck: HealthCheck{Detail: "status manager not configured"}}
+	}
+	health := NodeHealth{Flapping: len(h.statusManager.FlappingNodes())}
+	if h.presenceManager != nil {
+		health.Draining = h.presenceManager.Stats().Draining
+	}
+	health.OK = health.Flapping == 0
+	health.Detail = fmt.Sprintf("%d flapping, %d draining", health.Flapping, health.Draining)
+	return health
+}
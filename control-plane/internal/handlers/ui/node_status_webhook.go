@@ -0,0 +1,86 @@
+package ui
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Agent-Field/agentfield/control-plane/internal/services"
+	"github.com/Agent-Field/agentfield/control-plane/pkg/types"
+	"github.com/gin-gonic/gin"
+)
+
+// NodeStatusWebhookHandler provides handlers for node status webhook management.
+type NodeStatusWebhookHandler struct {
+	notifier *services.NodeStatusNotifier
+}
+
+// NewNodeStatusWebhookHandler creates a new NodeStatusWebhookHandler.
+func NewNodeStatusWebhookHandler(notifier *services.NodeStatusNotifier) *NodeStatusWebhookHandler {
+	return &NodeStatusWebhookHandler{notifier: notifier}
+}
+
+// NodeStatusWebhookRequest registers a node status webhook.
+type NodeStatusWebhookRequest struct {
+	URL     string             `json:"url" binding:"required"`
+	Secret  string             `json:"secret,omitempty"`
+	Headers map[string]string  `json:"headers,omitempty"`
+	States  []types.AgentState `json:"states,omitempty"`
+}
+
+// NodeStatusWebhookResponse describes a registered webhook without its secret.
+type NodeStatusWebhookResponse struct {
+	services.NodeStatusWebhook
+	HasSecret bool `json:"has_secret"`
+}
+
+func toNodeStatusWebhookResponse(webhook services.NodeStatusWebhook) NodeStatusWebhookResponse {
+	return NodeStatusWebhookResponse{NodeStatusWebhook: webhook, HasSecret: webhook.Secret != ""}
+}
+
+// ListWebhooksHandler lists registered node status webhooks.
+// GET /api/v1/settings/node-status-webhooks
+func (h *NodeStatusWebhookHandler) ListWebhooksHandler(c *gin.Context) {
+	webhooks := h.notifier.Webhooks()
+	response := make([]NodeStatusWebhookResponse, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		response = append(response, toNodeStatusWebhookResponse(webhook))
+	}
+	c.JSON(http.StatusOK, gin.H{"webhooks": response})
+}
+
+// CreateWebhookHandler registers a node status webhook.
+// POST /api/v1/settings/node-status-webhooks
+func (h *NodeStatusWebhookHandler) CreateWebhookHandler(c *gin.Context) {
+	var req NodeStatusWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request body: " + err.Error()})
+		return
+	}
+
+	webhook, err := h.notifier.Register(services.NodeStatusWebhook{
+		URL:     req.URL,
+		Secret:  req.Secret,
+		Headers: req.Headers,
+		States:  req.States,
+	})
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidNodeStatusWebhook) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid url: must be http or https"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to register node status webhook"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, toNodeStatusWebhookResponse(webhook))
+}
+
+// DeleteWebhookHandler removes a node status webhook.
+// DELETE /api/v1/settings/node-status-webhooks/:webhook_id
+func (h *NodeStatusWebhookHandler) DeleteWebhookHandler(c *gin.Context) {
+	if !h.notifier.Unregister(c.Param("webhook_id")) {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "node status webhook not found"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/Agent-Field/agentfield/control-plane/internal/events"
@@ -37,7 +38,14 @@ func (h *NodesHandler) GetNodesSummaryHandler(c *gin.Context) {
 	})
 }
 
+const (
+	defaultNodeStatusHistory = 20
+	maxNodeStatusHistory     = 100
+)
+
 // GetNodeDetailsHandler handles requests for detailed information about a specific node.
+// The optional "history" query parameter controls how many recent status transitions
+// are embedded (default 20, max 100).
 func (h *NodesHandler) GetNodeDetailsHandler(c *gin.Context) {
 	nodeID := c.Param("nodeId")
 	if nodeID == "" {
@@ -52,9 +60,66 @@ func (h *NodesHandler) GetNodeDetailsHandler(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "node not found or failed to retrieve details"})
 		return
 	}
+	historyLimit := parseBoundedIntOrDefault(c.Query("history"), defaultNodeStatusHistory, 1, maxNodeStatusHistory)
+	details.StatusHistory = h.service.GetNodeStatusHistory(nodeID, historyLimit)
 	c.JSON(http.StatusOK, details)
 }
 
+const (
+	defaultFleetTimelineLimit = 100
+	maxFleetTimelineLimit     = 1000
+)
+
+// GetNodesTimelineHandler returns the status transitions of every node in a
+// time window, merged oldest first, for incident review.
+// GET /api/ui/v1/nodes/timeline?from=2024-01-01T00:00:00Z&to=2024-01-02T00:00:00Z&limit=100&offset=0
+// from and to are RFC3339 and optional; limit defaults to 100 and is capped at 1000.
+func (h *NodesHandler) GetNodesTimelineHandler(c *gin.Context) {
+	from, err := parseTimePtrValue(c.Query("from"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from must be an RFC3339 timestamp"})
+		return
+	}
+	to, err := parseTimePtrValue(c.Query("to"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "to must be an RFC3339 timestamp"})
+		return
+	}
+	var fromAt, toAt time.Time
+	if from != nil {
+		fromAt = *from
+	}
+	if to != nil {
+		toAt = *to
+	}
+	if from != nil && to != nil && !fromAt.Before(toAt) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from must be before to"})
+		return
+	}
+	limit := parseBoundedIntOrDefault(c.Query("limit"), defaultFleetTimelineLimit, 1, maxFleetTimelineLimit)
+	offset := 0
+	if raw := c.Query("offset"); raw != "" {
+		offset, err = strconv.Atoi(raw)
+		if err != nil || offset < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "offset must be a non-negative integer"})
+			return
+		}
+	}
+
+	timeline := h.service.GetFleetStatusTimeline(fromAt, toAt)
+	total := len(timeline)
+	start := min(offset, total)
+	end := min(start+limit, total)
+
+	c.JSON(http.StatusOK, gin.H{
+		"transitions": timeline[start:end],
+		"total":       total,
+		"limit":       limit,
+		"offset":      offset,
+		"has_more":    end < total,
+	})
+}
+
 // StreamNodeEventsHandler handles SSE connections for real-time node events.
 func (h *NodesHandler) StreamNodeEventsHandler(c *gin.Context) {
 	// Set headers for SSE
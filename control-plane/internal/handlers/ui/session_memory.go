@@ -0,0 +1,92 @@
+package ui
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+
+	"github.com/Agent-Field/agentfield/control-plane/internal/logger"
+	"github.com/Agent-Field/agentfield/sdk/go/agent"
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultSensitiveMemoryKeyPattern matches memory keys whose values the
+// session memory inspector redacts when no pattern is configured.
+const DefaultSensitiveMemoryKeyPattern = `(?i)(password|passwd|secret|token|api[_-]?key|credential|private[_-]?key)`
+
+// SessionMemoryHandler lets operators inspect what a session has stored in
+// memory when debugging user issues.
+type SessionMemoryHandler struct {
+	backend   agent.MemoryBackend
+	sensitive *regexp.Regexp
+}
+
+// NewSessionMemoryHandler creates a SessionMemoryHandler reading from backend.
+// Values of keys matching sensitiveKeyPattern are redacted; an empty pattern
+// uses DefaultSensitiveMemoryKeyPattern.
+func NewSessionMemoryHandler(backend agent.MemoryBackend, sensitiveKeyPattern string) (*SessionMemoryHandler, error) {
+	if sensitiveKeyPattern == "" {
+		sensitiveKeyPattern = DefaultSensitiveMemoryKeyPattern
+	}
+	sensitive, err := regexp.Compile(sensitiveKeyPattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sensitive memory key pattern: %w", err)
+	}
+	return &SessionMemoryHandler{backend: backend, sensitive: sensitive}, nil
+}
+
+// SessionMemoryEntry is a single key in a session's memory.
+type SessionMemoryEntry struct {
+	Key      string `json:"key"`
+	Value    any    `json:"value,omitempty"`
+	Redacted bool   `json:"redacted,omitempty"`
+}
+
+// SessionMemoryResponse lists a session's memory, sorted by key.
+type SessionMemoryResponse struct {
+	SessionID string               `json:"session_id"`
+	Entries   []SessionMemoryEntry `json:"entries"`
+}
+
+// GetSessionMemoryHandler lists the keys and values in a session's memory.
+// GET /api/ui/v1/sessions/:sessionId/memory
+func (h *SessionMemoryHandler) GetSessionMemoryHandler(c *gin.Context) {
+	sessionID := c.Param("sessionId")
+	if sessionID == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "sessionId is required"})
+		return
+	}
+
+	// Enumerating a scope is an optional backend capability.
+	lister, ok := h.backend.(agent.EntriesBackend)
+	if !ok {
+		c.JSON(http.StatusNotImplemented, ErrorResponse{Error: "memory backend cannot enumerate session contents"})
+		return
+	}
+
+	entries, err := lister.Entries(agent.ScopeSession, sessionID)
+	if err != nil {
+		logger.Logger.Error().Err(err).Str("session_id", sessionID).Msg("failed to list session memory")
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to list session memory"})
+		return
+	}
+
+	response := SessionMemoryResponse{
+		SessionID: sessionID,
+		Entries:   make([]SessionMemoryEntry, 0, len(entries)),
+	}
+	for key, value := range entries {
+		entry := SessionMemoryEntry{Key: key, Value: value}
+		if h.sensitive.MatchString(key) {
+			entry.Value = nil
+			entry.Redacted = true
+		}
+		response.Entries = append(response.Entries, entry)
+	}
+	sort.Slice(response.Entries, func(i, j int) bool {
+		return response.Entries[i].Key < response.Entries[j].Key
+	})
+
+	c.JSON(http.StatusOK, response)
+}
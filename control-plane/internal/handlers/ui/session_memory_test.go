@@ -0,0 +1,70 @@
+package ui
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Agent-Field/agentfield/sdk/go/agent"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetSessionMemoryHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	backend := agent.NewInMemoryBackend()
+	require.NoError(t, backend.Set(agent.ScopeSession, "session-1", "cart", map[string]any{"items": 2}))
+	require.NoError(t, backend.Set(agent.ScopeSession, "session-1", "api_key", "sk-live-123"))
+	require.NoError(t, backend.Set(agent.ScopeSession, "session-1", "user_password", "hunter2"))
+	require.NoError(t, backend.Set(agent.ScopeSession, "session-2", "other", "hidden"))
+
+	serve := func(backend agent.MemoryBackend, pattern string) *httptest.ResponseRecorder {
+		handler, err := NewSessionMemoryHandler(backend, pattern)
+		require.NoError(t, err)
+		router := gin.New()
+		router.GET("/sessions/:sessionId/memory", handler.GetSessionMemoryHandler)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/sessions/session-1/memory", nil))
+		return resp
+	}
+
+	t.Run("sensitive keys are redacted", func(t *testing.T) {
+		resp := serve(backend, "")
+		require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+		assert.NotContains(t, resp.Body.String(), "sk-live-123")
+		assert.NotContains(t, resp.Body.String(), "hunter2")
+
+		var body SessionMemoryResponse
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &body))
+		assert.Equal(t, "session-1", body.SessionID)
+		assert.Equal(t, []SessionMemoryEntry{
+			{Key: "api_key", Redacted: true},
+			{Key: "cart", Value: map[string]any{"items": float64(2)}},
+			{Key: "user_password", Redacted: true},
+		}, body.Entries)
+	})
+
+	t.Run("custom pattern", func(t *testing.T) {
+		resp := serve(backend, `^cart$`)
+		require.Equal(t, http.StatusOK, resp.Code)
+
+		var body SessionMemoryResponse
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &body))
+		require.Len(t, body.Entries, 3)
+		assert.Equal(t, "sk-live-123", body.Entries[0].Value)
+		assert.True(t, body.Entries[1].Redacted)
+	})
+
+	t.Run("backend cannot enumerate", func(t *testing.T) {
+		resp := serve(struct{ agent.MemoryBackend }{backend}, "")
+		assert.Equal(t, http.StatusNotImplemented, resp.Code)
+	})
+
+	t.Run("invalid pattern", func(t *testing.T) {
+		_, err := NewSessionMemoryHandler(backend, "(")
+		assert.Error(t, err)
+	})
+}
@@ -34,6 +34,7 @@ import (
 
 	"github.com/gin-contrib/cors" // CORS middleware
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
@@ -50,6 +51,7 @@ type AgentFieldServer struct {
 	executionsUIService   *services.ExecutionsUIService // Add ExecutionsUIService
 	healthMonitor         *services.HealthMonitor
 	presenceManager       *services.PresenceManager
+	metricsRegistry       *prometheus.Registry    // server-owned collectors, served on /metrics beside the default registry
 	statusManager         *services.StatusManager // Add StatusManager for unified status management
 	agentService          interfaces.AgentService // Add AgentService for lifecycle management
 	agentClient           interfaces.AgentClient  // Add AgentClient for MCP communication
@@ -71,6 +73,19 @@ type AgentFieldServer struct {
 	adminGRPCPort            int
 	webhookDispatcher        services.WebhookDispatcher
 	observabilityForwarder   services.ObservabilityForwarder
+	nodeStatusNotifier       *services.NodeStatusNotifier
+	sessionMemoryHandler     *ui.SessionMemoryHandler
+}
+
+// newMetricsRegistry returns a registry for collectors tied to one server's
+// components. Keeping them off the default registry lets several servers be
+// constructed in one process without AlreadyRegisteredError.
+func newMetricsRegistry(presenceManager *services.PresenceManager) (*prometheus.Registry, error) {
+	registry := prometheus.NewRegistry()
+	if err := registry.Register(presenceManager.LeaseAgeCollector()); err != nil {
+		return nil, fmt.Errorf("failed to register presence metrics: %w", err)
+	}
+	return registry, nil
 }
 
 // NewAgentFieldServer creates a new instance of the AgentFieldServer.
@@ -112,7 +127,7 @@ func NewAgentFieldServer(cfg *config.Config) (*AgentFieldServer, error) {
 	portManager := process.NewPortManager()
 
 	// Create AgentService
-	agentService := coreservices.NewAgentService(processManager, portManager, registryStorage, agentClient, agentfieldHome)
+	agentService := coreservices.NewAgentService(processManager, portManager, registryStorage, agentClient, agentfieldHome, storageProvider)
 
 	// Initialize StatusManager for unified status management
 	statusManagerConfig := services.StatusManagerConfig{
@@ -130,13 +145,27 @@ func NewAgentFieldServer(cfg *config.Config) (*AgentFieldServer, error) {
 	// Update UIService with StatusManager reference
 	uiService = services.NewUIService(storageProvider, agentClient, agentService, statusManager)
 
+	// Node status webhooks are notified of state changes, including those found by reconciliation
+	nodeStatusNotifier := services.NewNodeStatusNotifier(services.NodeStatusNotifierConfig{})
+	statusManager.AddEventHandler(nodeStatusNotifier)
+
 	// Presence manager tracks node leases so stale nodes age out quickly
 	presenceConfig := services.PresenceManagerConfig{
-		HeartbeatTTL:  5 * time.Minute,
-		SweepInterval: 30 * time.Second,
-		HardEvictTTL:  30 * time.Minute,
+		HeartbeatTTL:   5 * time.Minute,
+		SweepInterval:  30 * time.Second,
+		HardEvictTTL:   30 * time.Minute,
+		EvictionLogger: &logger.Logger, // audit trail of disappearing nodes
+	}
+	if err := presenceConfig.Validate(); err != nil {
+		return nil, err
 	}
 	presenceManager := services.NewPresenceManager(statusManager, presenceConfig)
+	metricsRegistry, err := newMetricsRegistry(presenceManager)
+	if err != nil {
+		return nil, err
+	}
+	// Reconciliation only consults presence if ReconcilePriority says so
+	statusManager.SetReconcilePresence(presenceManager)
 
 	executionsUIService := services.NewExecutionsUIService(storageProvider) // Initialize ExecutionsUIService
 
@@ -254,6 +283,18 @@ func NewAgentFieldServer(cfg *config.Config) (*AgentFieldServer, error) {
 	// Initialize execution cleanup service
 	cleanupService := handlers.NewExecutionCleanupService(storageProvider, cfg.AgentField.ExecutionCleanup)
 
+	// Session memory inspector; an invalid redaction pattern must not
+	// silently weaken redaction, so it fails startup
+	sessionMemoryHandler, err := ui.NewSessionMemoryHandler(storage.NewStorageProviderBackend(storageProvider), cfg.UI.SensitiveMemoryKeyPattern)
+	if err != nil {
+		return nil, err
+	}
+
+	// Sinks for node lifecycle events; stopped again in Stop
+	if cfg.AgentField.NodeEvents.LogSink {
+		events.GlobalNodeSinks.AddSink("logging", events.LoggingEventSink{})
+	}
+
 	adminPort := cfg.AgentField.Port + 100
 	if envPort := os.Getenv("AGENTFIELD_ADMIN_GRPC_PORT"); envPort != "" {
 		if parsedPort, parseErr := strconv.Atoi(envPort); parseErr == nil {
@@ -271,6 +312,7 @@ func NewAgentFieldServer(cfg *config.Config) (*AgentFieldServer, error) {
 		executionsUIService:   executionsUIService,
 		healthMonitor:         healthMonitor,
 		presenceManager:       presenceManager,
+		metricsRegistry:       metricsRegistry,
 		statusManager:         statusManager,
 		agentService:          agentService,
 		agentClient:           agentClient,
@@ -284,6 +326,8 @@ func NewAgentFieldServer(cfg *config.Config) (*AgentFieldServer, error) {
 		payloadStore:          payloadStore,
 		webhookDispatcher:        webhookDispatcher,
 		observabilityForwarder:   observabilityForwarder,
+		nodeStatusNotifier:       nodeStatusNotifier,
+		sessionMemoryHandler:     sessionMemoryHandler,
 		registryWatcherCancel:    nil,
 		adminGRPCPort:            adminPort,
 	}, nil
@@ -456,6 +500,13 @@ func (s *AgentFieldServer) Stop() error {
 		}
 	}
 
+	if s.nodeStatusNotifier != nil {
+		s.nodeStatusNotifier.Stop()
+	}
+
+	// Stop delivering node lifecycle events to external sinks
+	events.GlobalNodeSinks.Stop()
+
 	// TODO: Implement graceful shutdown for HTTP, WebSocket, gRPC
 	return nil
 }
@@ -661,8 +712,14 @@ func (s *AgentFieldServer) setupRoutes() {
 		logger.Logger.Info().Msg("🔐 API key authentication enabled")
 	}
 
-	// Expose Prometheus metrics
-	s.Router.GET("/metrics", gin.WrapH(promhttp.Handler()))
+	// Expose Prometheus metrics: package-level collectors from the default
+	// registry plus the ones owned by this server
+	gatherers := prometheus.Gatherers{prometheus.DefaultGatherer}
+	if s.metricsRegistry != nil {
+		gatherers = append(gatherers, s.metricsRegistry)
+	}
+	metricsHandler := promhttp.InstrumentMetricHandler(prometheus.DefaultRegisterer, promhttp.HandlerFor(gatherers, promhttp.HandlerOpts{}))
+	s.Router.GET("/metrics", gin.WrapH(metricsHandler))
 
 	// Public health check endpoint for load balancers and container orchestration (e.g., Railway, K8s)
 	s.Router.GET("/health", s.healthCheckHandler)
@@ -771,6 +828,7 @@ func (s *AgentFieldServer) setupRoutes() {
 				uiNodesHandler := ui.NewNodesHandler(s.uiService)
 				nodes.GET("/summary", uiNodesHandler.GetNodesSummaryHandler)
 				nodes.GET("/events", uiNodesHandler.StreamNodeEventsHandler)
+				nodes.GET("/timeline", uiNodesHandler.GetNodesTimelineHandler)
 
 				// Unified status endpoints
 				nodes.GET("/:nodeId/status", uiNodesHandler.GetNodeStatusHandler)
@@ -829,6 +887,11 @@ func (s *AgentFieldServer) setupRoutes() {
 			}
 
 			// Workflows management group
+			// Session memory inspector for support debugging; sensitive keys are redacted
+			if s.sessionMemoryHandler != nil {
+				uiAPI.GET("/sessions/:sessionId/memory", s.sessionMemoryHandler.GetSessionMemoryHandler)
+			}
+
 			workflows := uiAPI.Group("/workflows")
 			{
 				workflows.GET("/:workflowId/dag", handlers.GetWorkflowDAGHandler(s.storage))
@@ -866,6 +929,8 @@ func (s *AgentFieldServer) setupRoutes() {
 			dashboard := uiAPI.Group("/dashboard")
 			{
 				dashboardHandler := ui.NewDashboardHandler(s.storage, s.agentService)
+				dashboardHandler.SetHealthSources(s.statusManager, s.presenceManager)
+				dashboardHandler.SetMemoryBackend(storage.NewStorageProviderBackend(s.storage))
 				dashboard.GET("/summary", dashboardHandler.GetDashboardSummaryHandler)
 				dashboard.GET("/enhanced", dashboardHandler.GetEnhancedDashboardSummaryHandler)
 			}
@@ -922,15 +987,27 @@ func (s *AgentFieldServer) setupRoutes() {
 		agentAPI.POST("/nodes/:node_id/heartbeat", handlers.HeartbeatHandler(s.storage, s.uiService, s.healthMonitor, s.statusManager, s.presenceManager))
 		agentAPI.DELETE("/nodes/:node_id/monitoring", s.unregisterAgentFromMonitoring)
 
+		// Operator-driven node registration. POST /nodes is already taken by
+		// self-registration, which overwrites an existing node, so the
+		// insert-only admin variant gets its own path.
+		agentAPI.POST("/admin/nodes", handlers.AdminRegisterNodeHandler(s.agentService))
+		agentAPI.DELETE("/nodes/:node_id", handlers.DeregisterNodeHandler(s.agentService, s.statusManager, s.presenceManager))
+
 		// New unified status API endpoints
-		agentAPI.GET("/nodes/:node_id/status", handlers.GetNodeStatusHandler(s.statusManager))
+		agentAPI.GET("/nodes/:node_id/status", handlers.GetNodeStatusHandler(s.statusManager, s.presenceManager))
+		agentAPI.GET("/nodes/:node_id/manifest", handlers.NodeManifestHandler(s.agentService))
 		agentAPI.POST("/nodes/:node_id/status/refresh", handlers.RefreshNodeStatusHandler(s.statusManager))
 		agentAPI.POST("/nodes/status/bulk", handlers.BulkNodeStatusHandler(s.statusManager, s.storage))
+		agentAPI.GET("/nodes/status/all", handlers.AllNodeStatusHandler(s.statusManager))
+		agentAPI.GET("/nodes/status/by-label", handlers.NodeStatusByLabelHandler(s.agentService))
+		agentAPI.GET("/nodes/search", handlers.NodeSearchHandler(s.agentService))
 		agentAPI.POST("/nodes/status/refresh", handlers.RefreshAllNodeStatusHandler(s.statusManager, s.storage))
 
 		// Enhanced lifecycle management endpoints
 		agentAPI.POST("/nodes/:node_id/start", handlers.StartNodeHandler(s.statusManager, s.storage))
 		agentAPI.POST("/nodes/:node_id/stop", handlers.StopNodeHandler(s.statusManager, s.storage))
+		agentAPI.POST("/nodes/:node_id/cordon", handlers.CordonNodeHandler(s.storage, s.statusManager, s.presenceManager))
+		agentAPI.POST("/nodes/:node_id/uncordon", handlers.UncordonNodeHandler(s.storage, s.statusManager, s.presenceManager))
 		agentAPI.POST("/nodes/:node_id/lifecycle/status", handlers.UpdateLifecycleStatusHandler(s.storage, s.uiService, s.statusManager))
 		agentAPI.PATCH("/nodes/:node_id/status", handlers.NodeStatusLeaseHandler(s.storage, s.statusManager, s.presenceManager, handlers.DefaultLeaseTTL))
 		agentAPI.POST("/nodes/:node_id/actions/ack", handlers.NodeActionAckHandler(s.storage, s.presenceManager, handlers.DefaultLeaseTTL))
@@ -940,14 +1017,14 @@ func (s *AgentFieldServer) setupRoutes() {
 		// TODO: Add other node routes (DeleteNode)
 
 		// Reasoner execution endpoints (legacy)
-		agentAPI.POST("/reasoners/:reasoner_id", handlers.ExecuteReasonerHandler(s.storage))
+		agentAPI.POST("/reasoners/:reasoner_id", handlers.ExecuteReasonerHandler(s.storage, s.statusManager))
 
 		// Skill execution endpoints (legacy)
-		agentAPI.POST("/skills/:skill_id", handlers.ExecuteSkillHandler(s.storage))
+		agentAPI.POST("/skills/:skill_id", handlers.ExecuteSkillHandler(s.storage, s.statusManager))
 
 		// Unified execution endpoints (path-based)
-		agentAPI.POST("/execute/:target", handlers.ExecuteHandler(s.storage, s.payloadStore, s.webhookDispatcher, s.config.AgentField.ExecutionQueue.AgentCallTimeout))
-		agentAPI.POST("/execute/async/:target", handlers.ExecuteAsyncHandler(s.storage, s.payloadStore, s.webhookDispatcher, s.config.AgentField.ExecutionQueue.AgentCallTimeout))
+		agentAPI.POST("/execute/:target", handlers.ExecuteHandler(s.storage, s.payloadStore, s.webhookDispatcher, s.config.AgentField.ExecutionQueue.AgentCallTimeout, s.statusManager))
+		agentAPI.POST("/execute/async/:target", handlers.ExecuteAsyncHandler(s.storage, s.payloadStore, s.webhookDispatcher, s.config.AgentField.ExecutionQueue.AgentCallTimeout, s.statusManager))
 		agentAPI.GET("/executions/:execution_id", handlers.GetExecutionStatusHandler(s.storage))
 		agentAPI.POST("/executions/batch-status", handlers.BatchExecutionStatusHandler(s.storage))
 		agentAPI.POST("/executions/:execution_id/status", handlers.UpdateExecutionStatusHandler(s.storage, s.payloadStore, s.webhookDispatcher, s.config.AgentField.ExecutionQueue.AgentCallTimeout))
@@ -1061,6 +1138,11 @@ func (s *AgentFieldServer) setupRoutes() {
 			settings.POST("/observability-webhook/redrive", obsHandler.RedriveHandler)
 			settings.GET("/observability-webhook/dlq", obsHandler.GetDeadLetterQueueHandler)
 			settings.DELETE("/observability-webhook/dlq", obsHandler.ClearDeadLetterQueueHandler)
+
+			nodeWebhookHandler := ui.NewNodeStatusWebhookHandler(s.nodeStatusNotifier)
+			settings.GET("/node-status-webhooks", nodeWebhookHandler.ListWebhooksHandler)
+			settings.POST("/node-status-webhooks", nodeWebhookHandler.CreateWebhookHandler)
+			settings.DELETE("/node-status-webhooks/:webhook_id", nodeWebhookHandler.DeleteWebhookHandler)
 		}
 	}
 
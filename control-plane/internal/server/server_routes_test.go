@@ -187,6 +187,8 @@ func (s *stubStorage) GetLockStatus(ctx context.Context, key string) (*types.Dis
 
 // Agent registry
 func (s *stubStorage) RegisterAgent(ctx context.Context, agent *types.AgentNode) error { return nil }
+func (s *stubStorage) CreateAgent(ctx context.Context, agent *types.AgentNode) error   { return nil }
+func (s *stubStorage) DeleteAgent(ctx context.Context, id string) error                { return nil }
 func (s *stubStorage) ListAgents(ctx context.Context, filters types.AgentFilters) ([]*types.AgentNode, error) {
 	return nil, nil
 }
@@ -425,6 +427,42 @@ func TestSetupRoutesRegistersMetricsAndUI(t *testing.T) {
 	})
 }
 
+func TestMetricsRegistryIsPerServer(t *testing.T) {
+	t.Parallel()
+
+	gin.SetMode(gin.TestMode)
+
+	// Each server gets its own registry, so building a second one in the
+	// same process must not fail with AlreadyRegisteredError.
+	var servers []*AgentFieldServer
+	for i := 0; i < 2; i++ {
+		pm := services.NewPresenceManager(nil, services.PresenceManagerConfig{HeartbeatTTL: time.Minute})
+		pm.Touch("node-1", time.Now())
+		registry, err := newMetricsRegistry(pm)
+		require.NoError(t, err)
+
+		srv := &AgentFieldServer{
+			Router:            gin.New(),
+			storage:           newStubStorage(),
+			payloadStore:      &stubPayloadStore{},
+			webhookDispatcher: &stubWebhookDispatcher{},
+			metricsRegistry:   registry,
+			config:            &config.Config{API: config.APIConfig{}},
+		}
+		srv.setupRoutes()
+		servers = append(servers, srv)
+	}
+
+	for _, srv := range servers {
+		req, _ := http.NewRequest(http.MethodGet, "/metrics", nil)
+		w := httptest.NewRecorder()
+		srv.Router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Contains(t, w.Body.String(), "agentfield_presence_lease_age_seconds_count 1")
+		require.Contains(t, w.Body.String(), "go_goroutines", "default registry metrics are still served")
+	}
+}
+
 func TestSetupRoutesRegistersHealthEndpoint(t *testing.T) {
 	t.Parallel()
 
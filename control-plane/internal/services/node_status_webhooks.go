@@ -0,0 +1,254 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Agent-Field/agentfield/control-plane/internal/logger"
+	"github.com/Agent-Field/agentfield/control-plane/pkg/types"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidNodeStatusWebhook is returned by Register for webhooks without a
+// usable http(s) URL.
+var ErrInvalidNodeStatusWebhook = errors.New("node status webhook requires an http or https URL")
+
+// NodeStatusWebhook is a registration for node state change notifications.
+type NodeStatusWebhook struct {
+	ID      string            `json:"id"`
+	URL     string            `json:"url"`
+	Secret  string            `json:"-"`
+	Headers map[string]string `json:"headers,omitempty"`
+	// States filters on the state a node moves to; empty matches every
+	// transition. Use []types.AgentState{types.AgentStateInactive} to be told
+	// only when nodes go offline.
+	States    []types.AgentState `json:"states,omitempty"`
+	CreatedAt time.Time          `json:"created_at"`
+}
+
+func (w *NodeStatusWebhook) matches(to types.AgentState) bool {
+	if len(w.States) == 0 {
+		return true
+	}
+	for _, state := range w.States {
+		if state == to {
+			return true
+		}
+	}
+	return false
+}
+
+// NodeStatusNotifierConfig controls delivery of node status webhooks.
+type NodeStatusNotifierConfig struct {
+	Timeout         time.Duration
+	MaxAttempts     int
+	RetryBackoff    time.Duration
+	MaxRetryBackoff time.Duration
+}
+
+// NodeStatusNotifier POSTs a types.NodeStatusChange to every matching webhook
+// when a node changes state. Register it with StatusManager.AddEventHandler.
+// Deliveries are signed with X-AgentField-Signature when the webhook has a
+// secret, and failed deliveries are retried with exponential backoff.
+// Registrations live in memory and do not survive a restart.
+type NodeStatusNotifier struct {
+	cfg    NodeStatusNotifierConfig
+	client *http.Client
+
+	mu       sync.RWMutex
+	webhooks map[string]*NodeStatusWebhook
+
+	// stopCh is closed under mu, so a delivery started while holding mu
+	// either precedes Stop's wg.Wait or sees the notifier stopped.
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewNodeStatusNotifier creates a notifier with no registered webhooks.
+func NewNodeStatusNotifier(cfg NodeStatusNotifierConfig) *NodeStatusNotifier {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 5
+	}
+	if cfg.RetryBackoff <= 0 {
+		cfg.RetryBackoff = 5 * time.Second
+	}
+	if cfg.MaxRetryBackoff <= 0 {
+		cfg.MaxRetryBackoff = 5 * time.Minute
+	}
+	return &NodeStatusNotifier{
+		cfg:      cfg,
+		client:   &http.Client{Timeout: cfg.Timeout},
+		webhooks: make(map[string]*NodeStatusWebhook),
+		stopCh:   make(chan struct{}),
+	}
+}
+
+// Register adds webhook and returns it with its assigned ID.
+func (n *NodeStatusNotifier) Register(webhook NodeStatusWebhook) (NodeStatusWebhook, error) {
+	parsed, err := url.Parse(strings.TrimSpace(webhook.URL))
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return NodeStatusWebhook{}, ErrInvalidNodeStatusWebhook
+	}
+	webhook.URL = parsed.String()
+	if webhook.ID == "" {
+		webhook.ID = uuid.NewString()
+	}
+	webhook.CreatedAt = time.Now().UTC()
+
+	n.mu.Lock()
+	n.webhooks[webhook.ID] = &webhook
+	n.mu.Unlock()
+	return webhook, nil
+}
+
+// Unregister removes the webhook with id and reports whether it existed.
+func (n *NodeStatusNotifier) Unregister(id string) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if _, ok := n.webhooks[id]; !ok {
+		return false
+	}
+	delete(n.webhooks, id)
+	return true
+}
+
+// Webhooks returns the registered webhooks, oldest first.
+func (n *NodeStatusNotifier) Webhooks() []NodeStatusWebhook {
+	n.mu.RLock()
+	webhooks := make([]NodeStatusWebhook, 0, len(n.webhooks))
+	for _, webhook := range n.webhooks {
+		webhooks = append(webhooks, *webhook)
+	}
+	n.mu.RUnlock()
+
+	sort.Slice(webhooks, func(i, j int) bool {
+		return webhooks[i].CreatedAt.Before(webhooks[j].CreatedAt)
+	})
+	return webhooks
+}
+
+// OnStatusChanged implements StatusEventHandler. Only changes of State are
+// delivered; health score and lifecycle updates are not. Changes arriving
+// after Stop are dropped.
+func (n *NodeStatusNotifier) OnStatusChanged(nodeID string, oldStatus, newStatus *types.AgentStatus) {
+	if oldStatus == nil || newStatus == nil || oldStatus.State == newStatus.State {
+		return
+	}
+
+	change := types.NodeStatusChange{
+		Event:     types.WebhookEventNodeStatusChanged,
+		NodeID:    nodeID,
+		From:      oldStatus.State,
+		To:        newStatus.State,
+		Source:    newStatus.Source,
+		Timestamp: newStatus.LastUpdated.UTC().Format(time.RFC3339),
+	}
+	body, err := json.Marshal(change)
+	if err != nil {
+		logger.Logger.Error().Err(err).Str("node_id", nodeID).Msg("failed to encode node status webhook payload")
+		return
+	}
+
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	select {
+	case <-n.stopCh:
+		return
+	default:
+	}
+	for _, webhook := range n.webhooks {
+		if !webhook.matches(newStatus.State) {
+			continue
+		}
+		n.wg.Add(1)
+		go func(webhook NodeStatusWebhook) {
+			defer n.wg.Done()
+			n.deliver(webhook, nodeID, body)
+		}(*webhook)
+	}
+}
+
+// Stop abandons pending retries, rejects new deliveries and waits for
+// in-flight deliveries to finish.
+func (n *NodeStatusNotifier) Stop() {
+	n.stopOnce.Do(func() {
+		n.mu.Lock()
+		close(n.stopCh)
+		n.mu.Unlock()
+	})
+	n.wg.Wait()
+}
+
+func (n *NodeStatusNotifier) deliver(webhook NodeStatusWebhook, nodeID string, body []byte) {
+	for attempt := 1; ; attempt++ {
+		err := n.post(webhook, body)
+		if err == nil {
+			return
+		}
+		if attempt >= n.cfg.MaxAttempts {
+			logger.Logger.Warn().Err(err).Str("webhook_id", webhook.ID).Str("node_id", nodeID).Int("attempts", attempt).Msg("node status webhook delivery failed")
+			return
+		}
+
+		timer := time.NewTimer(n.computeBackoff(attempt))
+		select {
+		case <-timer.C:
+		case <-n.stopCh:
+			timer.Stop()
+			return
+		}
+	}
+}
+
+func (n *NodeStatusNotifier) post(webhook NodeStatusWebhook, body []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), n.cfg.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range webhook.Headers {
+		trimmedKey := strings.TrimSpace(key)
+		if trimmedKey == "" {
+			continue
+		}
+		req.Header.Set(trimmedKey, value)
+	}
+	if webhook.Secret != "" {
+		req.Header.Set("X-AgentField-Signature", generateWebhookSignature(webhook.Secret, body))
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("non-2xx response: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (n *NodeStatusNotifier) computeBackoff(attempt int) time.Duration {
+	backoff := n.cfg.RetryBackoff * time.Duration(1<<uint(attempt-1))
+	if backoff > n.cfg.MaxRetryBackoff {
+		backoff = n.cfg.MaxRetryBackoff
+	}
+	return backoff
+}
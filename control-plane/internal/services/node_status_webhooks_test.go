@@ -0,0 +1,174 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Agent-Field/agentfield/control-plane/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type webhookRequest struct {
+	body      []byte
+	signature string
+}
+
+// webhookRecorder serves node status webhooks, answering with statuses in
+// order (then 200) and recording every request.
+func webhookRecorder(t *testing.T, statuses ...int) (*httptest.Server, func() []webhookRequest) {
+	t.Helper()
+	var mu sync.Mutex
+	var requests []webhookRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		requests = append(requests, webhookRequest{body: body, signature: r.Header.Get("X-AgentField-Signature")})
+		status := http.StatusOK
+		if len(requests) <= len(statuses) {
+			status = statuses[len(requests)-1]
+		}
+		mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return server, func() []webhookRequest {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]webhookRequest(nil), requests...)
+	}
+}
+
+func TestNodeStatusNotifier_OfflineTransition(t *testing.T) {
+	provider, ctx := setupStatusManagerStorage(t)
+	registerTestAgent(t, provider, ctx, "node-webhook")
+
+	server, requests := webhookRecorder(t)
+	notifier := NewNodeStatusNotifier(NodeStatusNotifierConfig{})
+	t.Cleanup(notifier.Stop)
+	_, err := notifier.Register(NodeStatusWebhook{
+		URL:    server.URL,
+		Secret: "s3cret",
+		States: []types.AgentState{types.AgentStateInactive},
+	})
+	require.NoError(t, err)
+
+	sm := NewStatusManager(provider, StatusManagerConfig{}, nil, nil)
+	sm.AddEventHandler(notifier)
+	sm.cacheMutex.Lock()
+	sm.statusCache["node-webhook"] = &cachedAgentStatus{
+		Status: &types.AgentStatus{
+			State:       types.AgentStateActive,
+			HealthScore: 100,
+			LastSeen:    time.Now(),
+			LastUpdated: time.Now(),
+			Source:      types.StatusSourceHeartbeat,
+		},
+		Timestamp: time.Now(),
+	}
+	sm.cacheMutex.Unlock()
+
+	require.NoError(t, sm.UpdateAgentStatus(ctx, "node-webhook", &types.AgentStatusUpdate{
+		State:  ptrAgentState(types.AgentStateInactive),
+		Source: types.StatusSourceReconcile,
+		Reason: "heartbeat stale",
+	}))
+
+	require.Eventually(t, func() bool { return len(requests()) == 1 }, 2*time.Second, 10*time.Millisecond)
+	got := requests()[0]
+
+	var change types.NodeStatusChange
+	require.NoError(t, json.Unmarshal(got.body, &change))
+	assert.Equal(t, types.WebhookEventNodeStatusChanged, change.Event)
+	assert.Equal(t, "node-webhook", change.NodeID)
+	assert.Equal(t, types.AgentStateActive, change.From)
+	assert.Equal(t, types.AgentStateInactive, change.To)
+	assert.Equal(t, types.StatusSourceReconcile, change.Source)
+	assert.NotEmpty(t, change.Timestamp)
+
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write(got.body)
+	assert.Equal(t, "sha256="+hex.EncodeToString(mac.Sum(nil)), got.signature)
+}
+
+func TestNodeStatusNotifier_Delivery(t *testing.T) {
+	active := &types.AgentStatus{State: types.AgentStateActive, LastUpdated: time.Now()}
+	inactive := &types.AgentStatus{State: types.AgentStateInactive, LastUpdated: time.Now()}
+
+	t.Run("filter skips other states", func(t *testing.T) {
+		server, requests := webhookRecorder(t)
+		notifier := NewNodeStatusNotifier(NodeStatusNotifierConfig{})
+		_, err := notifier.Register(NodeStatusWebhook{URL: server.URL, States: []types.AgentState{types.AgentStateInactive}})
+		require.NoError(t, err)
+
+		notifier.OnStatusChanged("node-1", inactive, active)
+		notifier.OnStatusChanged("node-1", active, active)
+		notifier.Stop()
+		assert.Empty(t, requests())
+	})
+
+	t.Run("failed delivery is retried", func(t *testing.T) {
+		server, requests := webhookRecorder(t, http.StatusInternalServerError, http.StatusBadGateway)
+		notifier := NewNodeStatusNotifier(NodeStatusNotifierConfig{RetryBackoff: 5 * time.Millisecond})
+		_, err := notifier.Register(NodeStatusWebhook{URL: server.URL})
+		require.NoError(t, err)
+
+		notifier.OnStatusChanged("node-1", active, inactive)
+		require.Eventually(t, func() bool { return len(requests()) == 3 }, 2*time.Second, 5*time.Millisecond)
+		notifier.Stop()
+
+		all := requests()
+		assert.Equal(t, all[0].body, all[2].body)
+		assert.Empty(t, all[0].signature, "unsigned without a secret")
+	})
+
+	t.Run("changes after Stop are dropped", func(t *testing.T) {
+		server, requests := webhookRecorder(t)
+		notifier := NewNodeStatusNotifier(NodeStatusNotifierConfig{})
+		_, err := notifier.Register(NodeStatusWebhook{URL: server.URL})
+		require.NoError(t, err)
+
+		// Status changes racing with Stop must neither panic nor deliver
+		// once Stop has returned.
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 50; j++ {
+					notifier.OnStatusChanged("node-1", active, inactive)
+				}
+			}()
+		}
+		notifier.Stop()
+		delivered := len(requests())
+		wg.Wait()
+
+		notifier.OnStatusChanged("node-1", active, inactive)
+		notifier.Stop()
+		assert.Equal(t, delivered, len(requests()))
+	})
+
+	t.Run("registration", func(t *testing.T) {
+		notifier := NewNodeStatusNotifier(NodeStatusNotifierConfig{})
+		_, err := notifier.Register(NodeStatusWebhook{URL: "ftp://example.com"})
+		assert.ErrorIs(t, err, ErrInvalidNodeStatusWebhook)
+
+		webhook, err := notifier.Register(NodeStatusWebhook{URL: "https://example.com/hook"})
+		require.NoError(t, err)
+		assert.NotEmpty(t, webhook.ID)
+		assert.Len(t, notifier.Webhooks(), 1)
+		assert.True(t, notifier.Unregister(webhook.ID))
+		assert.False(t, notifier.Unregister(webhook.ID))
+		assert.Empty(t, notifier.Webhooks())
+	})
+}
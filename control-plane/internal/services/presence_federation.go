@@ -0,0 +1,121 @@
+package services
+
+import (
+	"sort"
+	"time"
+)
+
+// LeaseSnapshot is a portable copy of a presence lease, exchanged between
+// control planes in different regions.
+type LeaseSnapshot struct {
+	NodeID   string            `json:"node_id"`
+	Region   string            `json:"region,omitempty"`
+	LastSeen time.Time         `json:"last_seen"`
+	TTL      time.Duration     `json:"ttl"`
+	Labels   map[string]string `json:"labels,omitempty"`
+}
+
+// expired reports whether the snapshot's lease has lapsed at now.
+func (s LeaseSnapshot) expired(now time.Time) bool {
+	return !now.Before(s.LastSeen.Add(s.TTL))
+}
+
+// PresentNode is a node currently holding a lease, local or imported.
+type PresentNode struct {
+	NodeID   string            `json:"node_id"`
+	Region   string            `json:"region,omitempty"`
+	Remote   bool              `json:"remote"`
+	LastSeen time.Time         `json:"last_seen"`
+	Labels   map[string]string `json:"labels,omitempty"`
+}
+
+// ExportLeases snapshots the local leases that have not been marked offline,
+// tagged with the configured Region. Imported leases are never re-exported.
+func (pm *PresenceManager) ExportLeases() []LeaseSnapshot {
+	pm.rlock()
+	defer pm.mu.RUnlock()
+
+	snapshots := make([]LeaseSnapshot, 0, len(pm.leases))
+	for nodeID, lease := range pm.leases {
+		if lease.MarkedOffline {
+			continue
+		}
+		snapshots = append(snapshots, LeaseSnapshot{
+			NodeID:   nodeID,
+			Region:   pm.config.Region,
+			LastSeen: lease.LastSeen,
+			TTL:      lease.expiresAt(pm.config.HeartbeatTTL).Sub(lease.LastSeen),
+			Labels:   copyLabels(lease.Labels),
+		})
+	}
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].NodeID < snapshots[j].NodeID })
+	return snapshots
+}
+
+// ImportRemoteLeases replaces the read-only view of region's leases with
+// leases. Imported leases live apart from local ones: sweeps never expire or
+// evict them, and they are not visible through LeaseState or HasLease. They
+// simply stop being reported by PresentNodes once their TTL lapses. Importing
+// an empty slice clears the region.
+func (pm *PresenceManager) ImportRemoteLeases(region string, leases []LeaseSnapshot) {
+	imported := make(map[string]LeaseSnapshot, len(leases))
+	for _, lease := range leases {
+		lease.Region = region
+		lease.Labels = copyLabels(lease.Labels)
+		imported[lease.NodeID] = lease
+	}
+
+	pm.lock()
+	defer pm.mu.Unlock()
+	if len(imported) == 0 {
+		delete(pm.remote, region)
+		return
+	}
+	pm.remote[region] = imported
+}
+
+// PresentNodes lists nodes present under the PresencePredicate, sorted by region and node
+// ID. With includeRemote, unexpired imported leases are included and flagged
+// Remote.
+func (pm *PresenceManager) PresentNodes(includeRemote bool) []PresentNode {
+	pm.rlock()
+	defer pm.mu.RUnlock()
+
+	now := pm.clock.Now()
+	var nodes []PresentNode
+	for nodeID, lease := range pm.leases {
+		if !pm.presentLocked(nodeID, now) {
+			continue
+		}
+		nodes = append(nodes, PresentNode{
+			NodeID:   nodeID,
+			Region:   pm.config.Region,
+			LastSeen: lease.LastSeen,
+			Labels:   copyLabels(lease.Labels),
+		})
+	}
+	if includeRemote {
+		for region, leases := range pm.remote {
+			for _, lease := range leases {
+				if lease.expired(now) {
+					continue
+				}
+				nodes = append(nodes, PresentNode{
+					NodeID:   lease.NodeID,
+					Region:   region,
+					Remote:   true,
+					LastSeen: lease.LastSeen,
+					Labels:   copyLabels(lease.Labels),
+				})
+			}
+		}
+	}
+
+	sort.Slice(nodes, func(i, j int) bool {
+		if nodes[i].Region != nodes[j].Region {
+			return nodes[i].Region < nodes[j].Region
+		}
+		return nodes[i].NodeID < nodes[j].NodeID
+	})
+	return nodes
+}
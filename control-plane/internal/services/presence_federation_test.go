@@ -0,0 +1,81 @@
+package services
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/Agent-Field/agentfield/control-plane/internal/services/presencetest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPresenceManager_LeaseFederation(t *testing.T) {
+	start := time.Now()
+
+	us := NewPresenceManagerWithClock(nil, PresenceManagerConfig{HeartbeatTTL: 30 * time.Second, Region: "us"}, presencetest.NewManualClock(start))
+	us.Touch("node-a", start)
+	us.Touch("node-b", start)
+	us.SetLabels("node-a", map[string]string{"pool": "gpu"})
+
+	clock := presencetest.NewManualClock(start)
+	eu := NewPresenceManagerWithClock(nil, PresenceManagerConfig{HeartbeatTTL: 30 * time.Second, HardEvictTTL: time.Minute, Region: "eu"}, clock)
+	eu.Touch("node-local", start)
+
+	t.Run("export round-trips", func(t *testing.T) {
+		exported := us.ExportLeases()
+		require.Len(t, exported, 2)
+		assert.Equal(t, "node-a", exported[0].NodeID)
+		assert.Equal(t, "us", exported[0].Region)
+		assert.Equal(t, 30*time.Second, exported[0].TTL)
+		assert.Equal(t, map[string]string{"pool": "gpu"}, exported[0].Labels)
+
+		// Snapshots travel between regions as JSON.
+		data, err := json.Marshal(exported)
+		require.NoError(t, err)
+		var received []LeaseSnapshot
+		require.NoError(t, json.Unmarshal(data, &received))
+		eu.ImportRemoteLeases("us", received)
+
+		assert.Len(t, eu.ExportLeases(), 1, "imported leases are not re-exported")
+	})
+
+	t.Run("imported leases are flagged remote", func(t *testing.T) {
+		local := eu.PresentNodes(false)
+		require.Len(t, local, 1)
+		assert.Equal(t, "node-local", local[0].NodeID)
+		assert.False(t, local[0].Remote)
+
+		all := eu.PresentNodes(true)
+		require.Len(t, all, 3)
+		assert.Equal(t, PresentNode{NodeID: "node-local", Region: "eu", LastSeen: start}, all[0])
+		for _, node := range all[1:] {
+			assert.True(t, node.Remote)
+			assert.Equal(t, "us", node.Region)
+		}
+		assert.Equal(t, map[string]string{"pool": "gpu"}, all[1].Labels)
+
+		_, known := eu.LeaseState("node-a")
+		assert.False(t, known, "imported leases stay out of the local namespace")
+	})
+
+	t.Run("local sweeps do not evict imported leases", func(t *testing.T) {
+		clock.Advance(20 * time.Second)
+		eu.Touch("node-local", clock.Now())
+		eu.checkExpirations()
+		assert.Len(t, eu.PresentNodes(true), 3)
+
+		// Once the remote TTL lapses they drop out of the view, but only
+		// a fresh import replaces them.
+		clock.Advance(11 * time.Second)
+		eu.checkExpirations()
+		assert.Len(t, eu.PresentNodes(true), 1)
+
+		eu.ImportRemoteLeases("us", nil)
+		eu.mu.RLock()
+		_, present := eu.remote["us"]
+		eu.mu.RUnlock()
+		assert.False(t, present)
+	})
+}
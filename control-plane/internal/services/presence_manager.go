@@ -2,24 +2,222 @@ package services
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"runtime"
 	"sync"
 	"time"
 
+	"github.com/Agent-Field/agentfield/control-plane/internal/events"
 	"github.com/Agent-Field/agentfield/control-plane/internal/logger"
 	"github.com/Agent-Field/agentfield/control-plane/internal/storage"
 	"github.com/Agent-Field/agentfield/control-plane/pkg/types"
+
+	"github.com/rs/zerolog"
 )
 
 type PresenceManagerConfig struct {
 	HeartbeatTTL  time.Duration
 	SweepInterval time.Duration
 	HardEvictTTL  time.Duration
+
+	// MinTouchInterval coalesces touches that arrive within this window of the
+	// last recorded touch. Coalesced touches still advance the lease expiry but
+	// skip status side effects. Zero records every touch.
+	MinTouchInterval time.Duration
+
+	// SyncStatus opts into driving the StatusManager from lease changes:
+	// acquiring a lease marks the node active and hard eviction drops its
+	// cached status. Lease expiry always marks the node inactive.
+	SyncStatus bool
+
+	// QuorumThreshold is the number of distinct reporters that must have
+	// touched a node within HeartbeatTTL for its lease to count as active.
+	// Values below two disable the quorum check.
+	QuorumThreshold int
+
+	// SweepBatchSize bounds how many leases a sweep examines per lock
+	// acquisition. Between batches the lock is released and the goroutine
+	// yields so Touch and HasLease are not stalled behind a full pass over a
+	// large lease map. Zero sweeps every lease under a single lock.
+	SweepBatchSize int
+
+	// SweepWorkers splits each sweep batch across this many goroutines that
+	// find the due leases in parallel; expiring and evicting them still
+	// happens one at a time under the lock, so each node is evicted once.
+	// Batches smaller than minSweepShard leases per worker are swept
+	// serially. Values below two sweep on a single goroutine.
+	SweepWorkers int
+
+	// StrictRegistration makes the heartbeat endpoint reject nodes that are
+	// not registered instead of silently accepting their heartbeats.
+	StrictRegistration bool
+
+	// Region names this control plane in federated lease exports.
+	Region string
+
+	// LeaderWarmup is how long sweeps neither expire nor evict leases after
+	// BecomeLeader, giving nodes time to re-heartbeat to a new leader before
+	// the leases it imported from storage are judged. Zero judges them on the
+	// first sweep.
+	LeaderWarmup time.Duration
+
+	// ReadmissionCooldown makes Touch ignore a hard-evicted node for this long
+	// after its eviction, so a flapping node cannot immediately reappear.
+	// Zero readmits evicted nodes on their next touch.
+	ReadmissionCooldown time.Duration
+
+	// NewLeaseLimit caps how many leases for nodes without one each reporter
+	// may create per NewLeaseWindow, so a faulty reporter inventing node IDs
+	// cannot grow the lease table without bound. Touches through Touch share
+	// one anonymous reporter's allowance. Renewals of existing leases are
+	// never limited. Zero disables the limit.
+	NewLeaseLimit int
+	// NewLeaseWindow is the period NewLeaseLimit applies to. Zero defaults
+	// to one minute.
+	NewLeaseWindow time.Duration
+
+	// TouchHistorySize keeps the last N touch timestamps per lease for
+	// RecentTouches. Zero keeps none.
+	TouchHistorySize int
+
+	// ExpectedNodes is the fleet size HealthyRatio divides by. Zero uses the
+	// number of distinct nodes that have held a lease since startup.
+	ExpectedNodes int
+
+	// EvictionLogger receives a warn-level entry for every eviction with the
+	// node's lease age and labels. Nil logs nothing.
+	EvictionLogger *zerolog.Logger
+
+	// NodeCallbacksReplaceGlobal makes a node's SetNodeExpireCallback callback
+	// run instead of the global expire callbacks rather than after them.
+	NodeCallbacksReplaceGlobal bool
+
+	// Instrument records lock wait times and sweep durations for Metrics.
+	// When false the lock paths pay only a nil check.
+	Instrument bool
+
+	// LeaseAgeBuckets are the upper bounds, in seconds, of the lease age
+	// histogram exported by LeaseAgeCollector. Nil uses
+	// DefaultLeaseAgeBuckets.
+	LeaseAgeBuckets []float64
 }
 
 type presenceLease struct {
+	LastSeen      time.Time
+	LastRecorded  time.Time
+	LastExpired   time.Time
+	MarkedOffline bool
+	Reporters     map[string]time.Time // reporterID -> last touch
+	ExtendedUntil time.Time            // set by ExtendLease; never past LastSeen+HardEvictTTL
+	Labels        map[string]string    // grouping labels, e.g. deployment tags
+	Draining      bool                 // set by Drain; survives touches and expiry
+	touches       []time.Time          // ring of recent touches when TouchHistorySize > 0
+	touchNext     int                  // next write position in touches once full
+	index         int                  // position in PresenceManager.order
+}
+
+// LeaseState describes where a node sits in the two-stage presence timeout.
+type LeaseState string
+
+const (
+	// LeaseActive means the node has been seen within HeartbeatTTL.
+	LeaseActive LeaseState = "active"
+	// LeaseExpired means the node is past HeartbeatTTL but not yet hard-evicted,
+	// or that too few reporters are fresh to meet QuorumThreshold.
+	LeaseExpired LeaseState = "expired"
+	// LeaseEvicted means the node is past HardEvictTTL (or was forcibly evicted)
+	// and its lease has been dropped. Evictions are remembered for HardEvictTTL.
+	LeaseEvicted LeaseState = "evicted"
+)
+
+// EvictionReason explains why a node left the present set.
+type EvictionReason string
+
+const (
+	// EvictionReasonExpiredHeartbeat means the node stopped heartbeating for HeartbeatTTL.
+	EvictionReasonExpiredHeartbeat EvictionReason = "expired_heartbeat"
+	// EvictionReasonHardEvicted means the lease was dropped, either after
+	// HardEvictTTL or by a forced eviction.
+	EvictionReasonHardEvicted EvictionReason = "hard_evicted"
+)
+
+// StatusReason maps r to the reason code the StatusManager records and the
+// node status endpoint reports.
+func (r EvictionReason) StatusReason() types.StatusReason {
+	if r == EvictionReasonHardEvicted {
+		return types.StatusReasonHardEvicted
+	}
+	return types.StatusReasonHeartbeatExpired
+}
+
+// LeaseInfo is a read-only snapshot of a node's presence lease.
+type LeaseInfo struct {
 	LastSeen      time.Time
 	LastExpired   time.Time
 	MarkedOffline bool
+	Labels        map[string]string
+	Draining      bool
+}
+
+// expiresAt returns when the lease passes HeartbeatTTL, honoring any extension.
+func (l *presenceLease) expiresAt(ttl time.Duration) time.Time {
+	expiry := l.LastSeen.Add(ttl)
+	if l.ExtendedUntil.After(expiry) {
+		return l.ExtendedUntil
+	}
+	return expiry
+}
+
+// freshReporters counts the reporters that touched the lease within ttl of now.
+func (l *presenceLease) freshReporters(now time.Time, ttl time.Duration) int {
+	fresh := 0
+	for _, at := range l.Reporters {
+		if now.Sub(at) < ttl {
+			fresh++
+		}
+	}
+	return fresh
+}
+
+func (l *presenceLease) info() LeaseInfo {
+	return LeaseInfo{
+		LastSeen:      l.LastSeen,
+		LastExpired:   l.LastExpired,
+		MarkedOffline: l.MarkedOffline,
+		Labels:        copyLabels(l.Labels),
+		Draining:      l.Draining,
+	}
+}
+
+func copyLabels(labels map[string]string) map[string]string {
+	if len(labels) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(labels))
+	for k, v := range labels {
+		out[k] = v
+	}
+	return out
+}
+
+// NodeLabels returns the grouping labels presence tracks for node, taken from
+// its deployment tags.
+func NodeLabels(node *types.AgentNode) map[string]string {
+	if node == nil || node.Metadata.Deployment == nil {
+		return nil
+	}
+	return node.Metadata.Deployment.Tags
+}
+
+// PresenceStats is a point-in-time summary of the presence manager's leases.
+type PresenceStats struct {
+	Active    int       `json:"active"`
+	Expired   int       `json:"expired"`
+	Evicted   int       `json:"evicted"`
+	Draining  int       `json:"draining"`
+	Sweeps    uint64    `json:"sweeps"`
+	LastSweep time.Time `json:"last_sweep"`
 }
 
 type PresenceManager struct {
@@ -27,33 +225,177 @@ type PresenceManager struct {
 	config        PresenceManagerConfig
 
 	leases   map[string]*presenceLease
+	order    []string                            // lease node IDs; lets sweeps resume between batches
+	evicted  map[string]time.Time                // nodeID -> eviction time
+	remote   map[string]map[string]LeaseSnapshot // region -> nodeID -> imported lease; never swept
+	waiters  map[string][]chan struct{}          // nodeID -> WaitForLease callers, closed once active
+	seen     map[string]struct{}                 // every node that has held a lease; Forget removes
+	offline  int                                 // leases with MarkedOffline set
 	mu       sync.RWMutex
 	stopCh   chan struct{}
 	stopOnce sync.Once
 
-	expireCallback func(string)
+	expireCallback           func(string)
+	expireCallbackWithReason func(string, EvictionReason)
+	presence                 PresencePredicate // nil means ActiveOnly
+	nodeExpireCallbacks      map[string]func(EvictionReason)
+	thresholds               *thresholdAlert // nil unless SetThresholdAlert
+
+	sweeps    uint64
+	lastSweep time.Time
+	statsStop chan struct{}
+
+	warmupUntil time.Time // sweeps leave leases alone before this; set by BecomeLeader
+
+	newLeases map[string]*leaseRateWindow // reporterID -> current NewLeaseLimit window
+
+	instruments *presenceInstruments // nil unless config.Instrument
+
+	clock Clock
 }
 
-func NewPresenceManager(statusManager *StatusManager, config PresenceManagerConfig) *PresenceManager {
-	if config.HeartbeatTTL == 0 {
-		config.HeartbeatTTL = 15 * time.Second
+// Clock supplies time to a PresenceManager: lease ages, expiry checks and the
+// sweep and stats schedules all read it. Tests inject a controllable one, such
+// as presencetest.ManualClock, through NewPresenceManagerWithClock.
+type Clock interface {
+	Now() time.Time
+	// After returns a channel that receives once d has elapsed on this clock.
+	After(d time.Duration) <-chan time.Time
+}
+
+// realClock is the wall clock.
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// PresencePredicate decides whether a node in the given lease state counts as
+// present for HasLease, HasLeases, PresentNodes and PresentCountByLabel. info
+// is the zero LeaseInfo for evicted nodes, which no longer hold a lease.
+type PresencePredicate func(state LeaseState, info LeaseInfo) bool
+
+// ActiveOnly is the default PresencePredicate: only LeaseActive nodes are present.
+func ActiveOnly(state LeaseState, _ LeaseInfo) bool {
+	return state == LeaseActive
+}
+
+// ErrInvalidPresenceConfig is wrapped by every PresenceManagerConfig.Validate error.
+var ErrInvalidPresenceConfig = errors.New("invalid presence manager config")
+
+// withDefaults fills the zero durations NewPresenceManager defaults.
+func (c PresenceManagerConfig) withDefaults() PresenceManagerConfig {
+	if c.HeartbeatTTL == 0 {
+		c.HeartbeatTTL = 15 * time.Second
+	}
+	if c.SweepInterval == 0 {
+		c.SweepInterval = c.HeartbeatTTL / 3
+		if c.SweepInterval < time.Second {
+			c.SweepInterval = time.Second
+		}
+	}
+	if c.HardEvictTTL == 0 {
+		c.HardEvictTTL = 5 * time.Minute
+	}
+	if c.NewLeaseWindow == 0 {
+		c.NewLeaseWindow = time.Minute
+	}
+	if c.LeaseAgeBuckets == nil {
+		c.LeaseAgeBuckets = DefaultLeaseAgeBuckets
+	}
+	return c
+}
+
+// Validate rejects configs NewPresenceManager would accept but that cannot
+// behave sensibly. Zero durations are checked as the defaults they become.
+// The invariants are:
+//   - no duration or count is negative;
+//   - HardEvictTTL is at least HeartbeatTTL, since a lease must expire before
+//     it can be evicted;
+//   - an explicit SweepInterval is at most HeartbeatTTL, or expiry could be
+//     noticed more than a full TTL late;
+//   - LeaseAgeBuckets are strictly increasing.
+func (c PresenceManagerConfig) Validate() error {
+	durations := []struct {
+		name  string
+		value time.Duration
+	}{
+		{"HeartbeatTTL", c.HeartbeatTTL},
+		{"SweepInterval", c.SweepInterval},
+		{"HardEvictTTL", c.HardEvictTTL},
+		{"MinTouchInterval", c.MinTouchInterval},
+		{"ReadmissionCooldown", c.ReadmissionCooldown},
+		{"LeaderWarmup", c.LeaderWarmup},
+		{"NewLeaseWindow", c.NewLeaseWindow},
+	}
+	for _, d := range durations {
+		if d.value < 0 {
+			return fmt.Errorf("%w: %s must not be negative, got %s", ErrInvalidPresenceConfig, d.name, d.value)
+		}
+	}
+	counts := []struct {
+		name  string
+		value int
+	}{
+		{"QuorumThreshold", c.QuorumThreshold},
+		{"SweepBatchSize", c.SweepBatchSize},
+		{"SweepWorkers", c.SweepWorkers},
+		{"TouchHistorySize", c.TouchHistorySize},
+		{"ExpectedNodes", c.ExpectedNodes},
+		{"NewLeaseLimit", c.NewLeaseLimit},
+	}
+	for _, n := range counts {
+		if n.value < 0 {
+			return fmt.Errorf("%w: %s must not be negative, got %d", ErrInvalidPresenceConfig, n.name, n.value)
+		}
+	}
+
+	effective := c.withDefaults()
+	if effective.HardEvictTTL < effective.HeartbeatTTL {
+		return fmt.Errorf("%w: HardEvictTTL (%s) is shorter than HeartbeatTTL (%s)", ErrInvalidPresenceConfig, effective.HardEvictTTL, effective.HeartbeatTTL)
 	}
-	if config.SweepInterval == 0 {
-		config.SweepInterval = config.HeartbeatTTL / 3
-		if config.SweepInterval < time.Second {
-			config.SweepInterval = time.Second
+	if c.SweepInterval > 0 && c.SweepInterval > effective.HeartbeatTTL {
+		return fmt.Errorf("%w: SweepInterval (%s) is longer than HeartbeatTTL (%s)", ErrInvalidPresenceConfig, c.SweepInterval, effective.HeartbeatTTL)
+	}
+	for i := 1; i < len(c.LeaseAgeBuckets); i++ {
+		if c.LeaseAgeBuckets[i] <= c.LeaseAgeBuckets[i-1] {
+			return fmt.Errorf("%w: LeaseAgeBuckets must be strictly increasing, got %v", ErrInvalidPresenceConfig, c.LeaseAgeBuckets)
 		}
 	}
-	if config.HardEvictTTL == 0 {
-		config.HardEvictTTL = 5 * time.Minute
+	return nil
+}
+
+// NewPresenceManager creates a manager for config, defaulting zero durations.
+// It does not validate config; call PresenceManagerConfig.Validate first to
+// catch inconsistent settings.
+func NewPresenceManager(statusManager *StatusManager, config PresenceManagerConfig) *PresenceManager {
+	return NewPresenceManagerWithClock(statusManager, config, nil)
+}
+
+// NewPresenceManagerWithClock is NewPresenceManager with an injected Clock,
+// so tests can advance time instead of sleeping. A nil clock uses the wall clock.
+func NewPresenceManagerWithClock(statusManager *StatusManager, config PresenceManagerConfig, clock Clock) *PresenceManager {
+	config = config.withDefaults()
+	if clock == nil {
+		clock = realClock{}
 	}
 
-	return &PresenceManager{
-		statusManager: statusManager,
-		config:        config,
-		leases:        make(map[string]*presenceLease),
-		stopCh:        make(chan struct{}),
+	pm := &PresenceManager{
+		statusManager:       statusManager,
+		config:              config,
+		leases:              make(map[string]*presenceLease),
+		evicted:             make(map[string]time.Time),
+		remote:              make(map[string]map[string]LeaseSnapshot),
+		waiters:             make(map[string][]chan struct{}),
+		seen:                make(map[string]struct{}),
+		newLeases:           make(map[string]*leaseRateWindow),
+		nodeExpireCallbacks: make(map[string]func(EvictionReason)),
+		stopCh:              make(chan struct{}),
+		clock:               clock,
+	}
+	if config.Instrument {
+		pm.instruments = &presenceInstruments{}
 	}
+	return pm
 }
 
 func (pm *PresenceManager) Start() {
@@ -66,37 +408,598 @@ func (pm *PresenceManager) Stop() {
 	})
 }
 
-func (pm *PresenceManager) Touch(nodeID string, seenAt time.Time) {
-	pm.mu.Lock()
+// Touch renews the lease for nodeID and reports whether the touch was recorded.
+// Touches within MinTouchInterval of the last recorded touch are coalesced:
+// the lease stays fresh but Touch returns false so callers can skip persisting.
+// Touches for a node still inside its ReadmissionCooldown are dropped and also
+// return false; use CooldownUntil to tell the two apart.
+func (pm *PresenceManager) Touch(nodeID string, seenAt time.Time) bool {
+	return pm.TouchFrom(nodeID, "", seenAt)
+}
+
+// TouchFrom is Touch on behalf of a specific heartbeat reporter. When
+// QuorumThreshold is set, a lease only counts as active once that many
+// distinct reporters have touched it within HeartbeatTTL. Touches refused by
+// NewLeaseLimit return false; use TryTouchFrom to tell them apart.
+func (pm *PresenceManager) TouchFrom(nodeID, reporterID string, seenAt time.Time) bool {
+	recorded, _ := pm.TryTouchFrom(nodeID, reporterID, seenAt)
+	return recorded
+}
+
+// ErrNewLeaseRateLimited is returned by TryTouchFrom when creating a lease
+// would exceed the reporter's NewLeaseLimit.
+var ErrNewLeaseRateLimited = errors.New("presence: new lease rate limit exceeded")
+
+// TryTouchFrom is TouchFrom that reports why a touch for a node without a
+// lease was refused: ErrNewLeaseRateLimited when reporterID has used up its
+// NewLeaseLimit for the current window.
+func (pm *PresenceManager) TryTouchFrom(nodeID, reporterID string, seenAt time.Time) (bool, error) {
+	pm.lock()
+	if _, cooling := pm.cooldownUntilLocked(nodeID); cooling {
+		pm.mu.Unlock()
+		return false, nil
+	}
 	lease, exists := pm.leases[nodeID]
+	if !exists && !pm.allowNewLeaseLocked(reporterID, pm.clock.Now()) {
+		pm.mu.Unlock()
+		return false, ErrNewLeaseRateLimited
+	}
+	acquired := !exists || lease.MarkedOffline
 	if !exists {
 		lease = &presenceLease{}
-		pm.leases[nodeID] = lease
+		pm.putLeaseLocked(nodeID, lease)
 	}
 	lease.LastSeen = seenAt
-	lease.MarkedOffline = false
+	if size := pm.config.TouchHistorySize; size > 0 {
+		if len(lease.touches) < size {
+			lease.touches = append(lease.touches, seenAt)
+		} else {
+			lease.touches[lease.touchNext] = seenAt
+			lease.touchNext = (lease.touchNext + 1) % size
+		}
+	}
+	if pm.config.QuorumThreshold > 1 {
+		if lease.Reporters == nil {
+			lease.Reporters = make(map[string]time.Time)
+		}
+		lease.Reporters[reporterID] = seenAt
+		for id, at := range lease.Reporters {
+			if seenAt.Sub(at) >= pm.config.HeartbeatTTL {
+				delete(lease.Reporters, id)
+			}
+		}
+	}
+	coalesced := !acquired && pm.config.MinTouchInterval > 0 &&
+		seenAt.Sub(lease.LastRecorded) < pm.config.MinTouchInterval
+	if !coalesced {
+		lease.LastRecorded = seenAt
+	}
+	pm.setOfflineLocked(lease, false)
+	delete(pm.evicted, nodeID)
+	pm.wakeWaitersLocked(nodeID)
 	pm.mu.Unlock()
+
+	if acquired && pm.config.SyncStatus {
+		pm.markActive(nodeID)
+	}
+	return !coalesced, nil
+}
+
+// leaseRateWindow counts the leases a reporter has created in one
+// NewLeaseWindow.
+type leaseRateWindow struct {
+	start time.Time
+	count int
+}
+
+// allowNewLeaseLocked charges a lease creation to reporterID's window and
+// reports whether NewLeaseLimit allows it.
+func (pm *PresenceManager) allowNewLeaseLocked(reporterID string, now time.Time) bool {
+	if pm.config.NewLeaseLimit <= 0 {
+		return true
+	}
+	window := pm.newLeases[reporterID]
+	if window == nil || now.Sub(window.start) >= pm.config.NewLeaseWindow {
+		window = &leaseRateWindow{start: now}
+		pm.newLeases[reporterID] = window
+	}
+	if window.count >= pm.config.NewLeaseLimit {
+		return false
+	}
+	window.count++
+	return true
+}
+
+// RecentTouches returns up to TouchHistorySize of nodeID's most recent touch
+// times, oldest first, for inspecting heartbeat cadence. It returns nil when
+// history is disabled or the node holds no lease.
+func (pm *PresenceManager) RecentTouches(nodeID string) []time.Time {
+	pm.rlock()
+	defer pm.mu.RUnlock()
+
+	lease, exists := pm.leases[nodeID]
+	if !exists || len(lease.touches) == 0 {
+		return nil
+	}
+	touches := make([]time.Time, 0, len(lease.touches))
+	touches = append(touches, lease.touches[lease.touchNext:]...)
+	return append(touches, lease.touches[:lease.touchNext]...)
+}
+
+// CooldownUntil reports when nodeID may be readmitted after a hard eviction.
+// ok is false when the node is not being held back by ReadmissionCooldown.
+func (pm *PresenceManager) CooldownUntil(nodeID string) (until time.Time, ok bool) {
+	pm.rlock()
+	defer pm.mu.RUnlock()
+	return pm.cooldownUntilLocked(nodeID)
+}
+
+func (pm *PresenceManager) cooldownUntilLocked(nodeID string) (time.Time, bool) {
+	if pm.config.ReadmissionCooldown <= 0 {
+		return time.Time{}, false
+	}
+	evictedAt, evicted := pm.evicted[nodeID]
+	if !evicted {
+		return time.Time{}, false
+	}
+	until := evictedAt.Add(pm.config.ReadmissionCooldown)
+	if !pm.clock.Now().Before(until) {
+		return time.Time{}, false
+	}
+	return until, true
+}
+
+// ExtendLease pushes the expiry of nodeID's current lease out by the given
+// duration without recording a touch, so a node busy with a known-long
+// operation is not expired mid-task. The extension never reaches past
+// HardEvictTTL from the last touch. It returns false if the node has no
+// live lease.
+func (pm *PresenceManager) ExtendLease(nodeID string, by time.Duration) bool {
+	pm.lock()
+	defer pm.mu.Unlock()
+
+	lease, exists := pm.leases[nodeID]
+	if !exists || lease.MarkedOffline {
+		return false
+	}
+	now := pm.clock.Now()
+	expiry := lease.expiresAt(pm.config.HeartbeatTTL)
+	if !now.Before(expiry) {
+		return false
+	}
+	extended := expiry.Add(by)
+	if pm.config.HardEvictTTL > 0 {
+		if ceiling := lease.LastSeen.Add(pm.config.HardEvictTTL); extended.After(ceiling) {
+			extended = ceiling
+		}
+	}
+	lease.ExtendedUntil = extended
+	return true
+}
+
+// Drain marks nodeID's lease as draining and reports whether the node holds a
+// lease. A draining node keeps its lease and presence; it is only flagged so
+// that new work can be steered elsewhere. The flag is dropped on eviction.
+func (pm *PresenceManager) Drain(nodeID string) bool {
+	return pm.setDraining(nodeID, true)
+}
+
+// Undrain clears the draining flag set by Drain and reports whether the node
+// holds a lease.
+func (pm *PresenceManager) Undrain(nodeID string) bool {
+	return pm.setDraining(nodeID, false)
+}
+
+// IsDraining reports whether nodeID holds a lease that has been drained.
+func (pm *PresenceManager) IsDraining(nodeID string) bool {
+	pm.rlock()
+	defer pm.mu.RUnlock()
+
+	lease, exists := pm.leases[nodeID]
+	return exists && lease.Draining
+}
+
+func (pm *PresenceManager) setDraining(nodeID string, draining bool) bool {
+	pm.lock()
+	defer pm.mu.Unlock()
+
+	lease, exists := pm.leases[nodeID]
+	if !exists {
+		return false
+	}
+	lease.Draining = draining
+	return true
+}
+
+// SetLabels replaces the grouping labels on nodeID's lease and reports whether
+// the node holds a lease. Labels survive expiry but are dropped on eviction.
+func (pm *PresenceManager) SetLabels(nodeID string, labels map[string]string) bool {
+	pm.lock()
+	defer pm.mu.Unlock()
+
+	lease, exists := pm.leases[nodeID]
+	if !exists {
+		return false
+	}
+	lease.Labels = copyLabels(labels)
+	return true
+}
+
+// PresentCountByLabel buckets present nodes by the value of their
+// label key. Nodes without the label are not counted.
+func (pm *PresenceManager) PresentCountByLabel(key string) map[string]int {
+	pm.rlock()
+	defer pm.mu.RUnlock()
+
+	now := pm.clock.Now()
+	counts := make(map[string]int)
+	for nodeID, lease := range pm.leases {
+		value, ok := lease.Labels[key]
+		if !ok {
+			continue
+		}
+		if pm.presentLocked(nodeID, now) {
+			counts[value]++
+		}
+	}
+	return counts
 }
 
 func (pm *PresenceManager) Forget(nodeID string) {
-	pm.mu.Lock()
-	delete(pm.leases, nodeID)
+	pm.lock()
+	pm.removeLeaseLocked(nodeID)
+	delete(pm.evicted, nodeID)
+	delete(pm.seen, nodeID)
 	pm.mu.Unlock()
 }
 
+// ForgetMatching evicts every lease for which pred returns true and reports how
+// many were evicted. Leases that were still online are marked inactive and fire
+// the expire callback; leases already offline are treated as hard evictions.
+// Either way the StatusManager is left as a sweep would leave it, so with
+// SyncStatus the node's cached status is dropped.
+// pred is called with the manager's lock held and must not call back into it.
+func (pm *PresenceManager) ForgetMatching(pred func(nodeID string, info LeaseInfo) bool) int {
+	var expired []leaseEviction
+	var evicted []leaseEviction
+
+	pm.lock()
+	now := pm.clock.Now()
+	for nodeID, lease := range pm.leases {
+		if !pred(nodeID, lease.info()) {
+			continue
+		}
+		pm.removeLeaseLocked(nodeID)
+		pm.evicted[nodeID] = now
+		eviction := newLeaseEviction(nodeID, lease, now)
+		if lease.MarkedOffline {
+			evicted = append(evicted, eviction)
+		} else {
+			expired = append(expired, eviction)
+		}
+	}
+	pm.mu.Unlock()
+
+	for _, eviction := range expired {
+		pm.logEviction(eviction, EvictionReasonHardEvicted)
+		// The inactive transition records why, so there is no separate
+		// eviction entry as in handleEvicted.
+		pm.updateStatusInactive(eviction.nodeID, EvictionReasonHardEvicted)
+		pm.forgetSyncedStatus(eviction.nodeID)
+		pm.notifyExpired(eviction.nodeID, EvictionReasonHardEvicted, true)
+	}
+	pm.handleEvicted(evicted)
+
+	return len(expired) + len(evicted)
+}
+
+// HasLease reports whether nodeID counts as present under the manager's
+// PresencePredicate. By default that means its LeaseState is LeaseActive, so
+// nodes that are expired or evicted return false. Unknown nodes never count.
+// Lease state is computed from timestamps on every call, so a lease reads as
+// expired the moment it passes HeartbeatTTL, without waiting for a sweep.
 func (pm *PresenceManager) HasLease(nodeID string) bool {
-	pm.mu.RLock()
+	pm.rlock()
+	defer pm.mu.RUnlock()
+	return pm.presentLocked(nodeID, pm.clock.Now())
+}
+
+// HasLeases reports HasLease for each of nodeIDs under a single read lock, so
+// a group of nodes is checked against one consistent view of the leases.
+func (pm *PresenceManager) HasLeases(nodeIDs []string) map[string]bool {
+	present := make(map[string]bool, len(nodeIDs))
+	pm.rlock()
 	defer pm.mu.RUnlock()
-	_, exists := pm.leases[nodeID]
-	return exists
+	now := pm.clock.Now()
+	for _, nodeID := range nodeIDs {
+		present[nodeID] = pm.presentLocked(nodeID, now)
+	}
+	return present
 }
 
+// presentLocked applies the PresencePredicate to nodeID. Callers hold pm.mu.
+// With the default predicate it reads only the lease timestamps and does not
+// allocate, since HasLease sits on the scheduling hot path; LeaseInfo, which
+// copies labels, is built only for a custom predicate.
+func (pm *PresenceManager) presentLocked(nodeID string, now time.Time) bool {
+	state, ok := pm.leaseStateLocked(nodeID, now)
+	if !ok {
+		return false
+	}
+	if pm.presence == nil {
+		return state == LeaseActive
+	}
+	var info LeaseInfo
+	if lease, exists := pm.leases[nodeID]; exists {
+		info = lease.info()
+	}
+	return pm.presence(state, info)
+}
+
+// WaitForLease blocks until nodeID holds an active lease, returning nil as soon
+// as it does or ctx's error if ctx ends first. It does not poll: the touch that
+// activates the lease wakes the waiter directly.
+func (pm *PresenceManager) WaitForLease(ctx context.Context, nodeID string) error {
+	pm.lock()
+	if state, ok := pm.leaseStateLocked(nodeID, pm.clock.Now()); ok && state == LeaseActive {
+		pm.mu.Unlock()
+		return nil
+	}
+	ch := make(chan struct{})
+	pm.waiters[nodeID] = append(pm.waiters[nodeID], ch)
+	pm.mu.Unlock()
+
+	select {
+	case <-ch:
+		return nil
+	case <-ctx.Done():
+		pm.lock()
+		pm.removeWaiterLocked(nodeID, ch)
+		pm.mu.Unlock()
+		// The lease may have become active while the lock was contended.
+		select {
+		case <-ch:
+			return nil
+		default:
+			return ctx.Err()
+		}
+	}
+}
+
+// wakeWaitersLocked releases WaitForLease callers once nodeID's lease is
+// active. Under quorum a touch may leave the lease inactive, in which case
+// waiters keep waiting for the next reporter.
+func (pm *PresenceManager) wakeWaitersLocked(nodeID string) {
+	waiters, ok := pm.waiters[nodeID]
+	if !ok {
+		return
+	}
+	if state, _ := pm.leaseStateLocked(nodeID, pm.clock.Now()); state != LeaseActive {
+		return
+	}
+	for _, ch := range waiters {
+		close(ch)
+	}
+	delete(pm.waiters, nodeID)
+}
+
+func (pm *PresenceManager) removeWaiterLocked(nodeID string, ch chan struct{}) {
+	waiters := pm.waiters[nodeID]
+	for i, w := range waiters {
+		if w == ch {
+			waiters = append(waiters[:i], waiters[i+1:]...)
+			break
+		}
+	}
+	if len(waiters) == 0 {
+		delete(pm.waiters, nodeID)
+	} else {
+		pm.waiters[nodeID] = waiters
+	}
+}
+
+// LeaseExpiry returns when nodeID's lease passes HeartbeatTTL, honoring any
+// ExtendLease extension. ok is false when the node holds no lease.
+func (pm *PresenceManager) LeaseExpiry(nodeID string) (expiresAt time.Time, ok bool) {
+	pm.rlock()
+	defer pm.mu.RUnlock()
+
+	lease, exists := pm.leases[nodeID]
+	if !exists {
+		return time.Time{}, false
+	}
+	return lease.expiresAt(pm.config.HeartbeatTTL), true
+}
+
+// SweepInterval is how often the expiry sweeper runs once started.
+func (pm *PresenceManager) SweepInterval() time.Duration {
+	return pm.config.SweepInterval
+}
+
+// HeartbeatInterval is how often nodes are advised to heartbeat: a third of
+// HeartbeatTTL, so two consecutive heartbeats can be lost before expiry.
+func (pm *PresenceManager) HeartbeatInterval() time.Duration {
+	return pm.config.HeartbeatTTL / 3
+}
+
+// StrictRegistration reports whether heartbeats from unregistered nodes
+// should be rejected.
+func (pm *PresenceManager) StrictRegistration() bool {
+	return pm.config.StrictRegistration
+}
+
+// LeaseState reports where nodeID sits in the two-stage presence timeout.
+// ok is false when the node has no lease and no recent eviction on record.
+func (pm *PresenceManager) LeaseState(nodeID string) (state LeaseState, ok bool) {
+	pm.rlock()
+	defer pm.mu.RUnlock()
+	return pm.leaseStateLocked(nodeID, pm.clock.Now())
+}
+
+// PresenceObservation implements ReconcilePresence: active reports whether
+// nodeID's lease is LeaseActive and lastSeen is its last touch, zero once the
+// lease has been evicted.
+func (pm *PresenceManager) PresenceObservation(nodeID string) (active bool, lastSeen time.Time, ok bool) {
+	pm.rlock()
+	defer pm.mu.RUnlock()
+
+	state, ok := pm.leaseStateLocked(nodeID, pm.clock.Now())
+	if !ok {
+		return false, time.Time{}, false
+	}
+	if lease, exists := pm.leases[nodeID]; exists {
+		lastSeen = lease.LastSeen
+	}
+	return state == LeaseActive, lastSeen, true
+}
+
+func (pm *PresenceManager) leaseStateLocked(nodeID string, now time.Time) (LeaseState, bool) {
+	if lease, exists := pm.leases[nodeID]; exists {
+		age := now.Sub(lease.LastSeen)
+		switch {
+		case pm.config.HardEvictTTL > 0 && age >= pm.config.HardEvictTTL:
+			return LeaseEvicted, true
+		case lease.MarkedOffline || !now.Before(lease.expiresAt(pm.config.HeartbeatTTL)):
+			return LeaseExpired, true
+		case pm.config.QuorumThreshold > 1 && lease.freshReporters(now, pm.config.HeartbeatTTL) < pm.config.QuorumThreshold:
+			return LeaseExpired, true
+		default:
+			return LeaseActive, true
+		}
+	}
+	if _, evicted := pm.evicted[nodeID]; evicted {
+		return LeaseEvicted, true
+	}
+	return "", false
+}
+
+// SetExpireCallback registers fn to run when a node is marked inactive after
+// losing its lease. Use SetExpireCallbackWithReason to also observe hard evictions.
+// fn runs after the StatusManager has marked the node inactive and before the
+// SetExpireCallbackWithReason callback.
 func (pm *PresenceManager) SetExpireCallback(fn func(string)) {
-	pm.mu.Lock()
+	pm.lock()
 	pm.expireCallback = fn
 	pm.mu.Unlock()
 }
 
+// SetNodeExpireCallback registers fn to run whenever nodeID expires or is
+// evicted, e.g. to page on a critical node. It runs after the global expire
+// callbacks, or instead of them with NodeCallbacksReplaceGlobal. The
+// registration survives Forget so it applies if the node returns; a nil fn
+// removes it.
+func (pm *PresenceManager) SetNodeExpireCallback(nodeID string, fn func(reason EvictionReason)) {
+	pm.lock()
+	if fn == nil {
+		delete(pm.nodeExpireCallbacks, nodeID)
+	} else {
+		pm.nodeExpireCallbacks[nodeID] = fn
+	}
+	pm.mu.Unlock()
+}
+
+// SetPresencePredicate replaces the rule deciding which lease states count as
+// present. A nil fn restores ActiveOnly. fn is called with the manager's lock
+// held and must not call back into it. WaitForLease is unaffected and always
+// waits for an active lease.
+func (pm *PresenceManager) SetPresencePredicate(fn PresencePredicate) {
+	pm.lock()
+	pm.presence = fn
+	pm.mu.Unlock()
+}
+
+// SetExpireCallbackWithReason registers fn to run whenever a node expires or is
+// evicted, along with the threshold that was crossed. fn runs after the status
+// update and the SetExpireCallback callback, and before the
+// node_presence_expired event is published.
+func (pm *PresenceManager) SetExpireCallbackWithReason(fn func(string, EvictionReason)) {
+	pm.lock()
+	pm.expireCallbackWithReason = fn
+	pm.mu.Unlock()
+}
+
+// Stats returns a snapshot of lease counts by state along with sweep progress.
+func (pm *PresenceManager) Stats() PresenceStats {
+	pm.rlock()
+	defer pm.mu.RUnlock()
+
+	now := pm.clock.Now()
+	stats := PresenceStats{
+		Evicted:   len(pm.evicted),
+		Sweeps:    pm.sweeps,
+		LastSweep: pm.lastSweep,
+	}
+	for nodeID, lease := range pm.leases {
+		if lease.Draining {
+			stats.Draining++
+		}
+		state, _ := pm.leaseStateLocked(nodeID, now)
+		switch state {
+		case LeaseActive:
+			stats.Active++
+		case LeaseExpired:
+			stats.Expired++
+		case LeaseEvicted:
+			stats.Evicted++
+		}
+	}
+	return stats
+}
+
+// HealthyRatio returns the fraction of the fleet that is present: leases not
+// yet marked offline divided by ExpectedNodes, or by the number of distinct
+// nodes seen since startup when ExpectedNodes is zero. It is capped at 1 and
+// reads maintained counters, so it is cheap enough to call per request for
+// load shedding. Expiry is only noticed by sweeps, so the ratio can lag by up
+// to SweepInterval. With nothing seen or expected it reports 1.
+func (pm *PresenceManager) HealthyRatio() float64 {
+	pm.rlock()
+	present := len(pm.leases) - pm.offline
+	total := pm.config.ExpectedNodes
+	if total == 0 {
+		total = len(pm.seen)
+	}
+	pm.mu.RUnlock()
+
+	if total == 0 || present >= total {
+		return 1
+	}
+	return float64(present) / float64(total)
+}
+
+// SetStatsCallback invokes fn with the current Stats every interval until the
+// manager is stopped. Calling it again replaces the previous callback; a nil fn
+// or non-positive interval just stops it.
+func (pm *PresenceManager) SetStatsCallback(fn func(PresenceStats), interval time.Duration) {
+	pm.lock()
+	if pm.statsStop != nil {
+		close(pm.statsStop)
+		pm.statsStop = nil
+	}
+	if fn == nil || interval <= 0 {
+		pm.mu.Unlock()
+		return
+	}
+	stop := make(chan struct{})
+	pm.statsStop = stop
+	pm.mu.Unlock()
+
+	go pm.statsLoop(fn, interval, stop)
+}
+
+func (pm *PresenceManager) statsLoop(fn func(PresenceStats), interval time.Duration, stop chan struct{}) {
+	for {
+		select {
+		case <-pm.clock.After(interval):
+			fn(pm.Stats())
+		case <-stop:
+			return
+		case <-pm.stopCh:
+			return
+		}
+	}
+}
+
 // RecoverFromDatabase loads previously registered nodes from the database
 // and initializes presence leases based on their LastHeartbeat timestamps.
 // This should be called on startup to recover state after a control plane restart.
@@ -113,7 +1016,7 @@ func (pm *PresenceManager) RecoverFromDatabase(ctx context.Context, storageProvi
 
 	logger.Logger.Info().Int("count", len(nodes)).Msg("📍 Recovering presence leases from database")
 
-	pm.mu.Lock()
+	pm.lock()
 	defer pm.mu.Unlock()
 
 	for _, node := range nodes {
@@ -122,23 +1025,43 @@ func (pm *PresenceManager) RecoverFromDatabase(ctx context.Context, storageProvi
 		}
 
 		// Initialize lease based on LastHeartbeat from database
-		pm.leases[node.ID] = &presenceLease{
+		pm.putLeaseLocked(node.ID, &presenceLease{
 			LastSeen:      node.LastHeartbeat,
-			MarkedOffline: time.Since(node.LastHeartbeat) > pm.config.HeartbeatTTL,
-		}
+			MarkedOffline: pm.clock.Now().Sub(node.LastHeartbeat) > pm.config.HeartbeatTTL,
+			Labels:        copyLabels(NodeLabels(node)),
+		})
+		pm.wakeWaitersLocked(node.ID)
 	}
 
 	logger.Logger.Info().Msg("📍 Presence lease recovery complete")
 	return nil
 }
 
-func (pm *PresenceManager) loop() {
-	ticker := time.NewTicker(pm.config.SweepInterval)
-	defer ticker.Stop()
+// BecomeLeader prepares a manager on a control plane that has just taken
+// over sweeping: it imports the last-known leases with RecoverFromDatabase
+// and then holds off expiry and eviction for LeaderWarmup, so nodes that
+// were heartbeating to the previous leader are not reported offline before
+// they have had a chance to reach this one. Call it on election, before
+// Start.
+func (pm *PresenceManager) BecomeLeader(ctx context.Context, storageProvider storage.StorageProvider) error {
+	if err := pm.RecoverFromDatabase(ctx, storageProvider); err != nil {
+		return err
+	}
+
+	pm.lock()
+	pm.warmupUntil = pm.clock.Now().Add(pm.config.LeaderWarmup)
+	pm.mu.Unlock()
 
+	if pm.config.LeaderWarmup > 0 {
+		logger.Logger.Info().Dur("warmup", pm.config.LeaderWarmup).Msg("📍 Suppressing presence evictions during leader warmup")
+	}
+	return nil
+}
+
+func (pm *PresenceManager) loop() {
 	for {
 		select {
-		case <-ticker.C:
+		case <-pm.clock.After(pm.config.SweepInterval):
 			pm.checkExpirations()
 		case <-pm.stopCh:
 			return
@@ -146,30 +1069,285 @@ func (pm *PresenceManager) loop() {
 	}
 }
 
+// checkExpirations walks the leases from the end of pm.order towards the
+// front, SweepBatchSize at a time. Each batch re-reads the clock, so a lease
+// that expires while earlier batches run is still caught when its batch comes
+// up. Removals swap the last ID into the freed slot, and everything past the
+// cursor has already been visited, so no lease is skipped; leases acquired
+// mid-sweep are appended behind the cursor and picked up by the next sweep.
 func (pm *PresenceManager) checkExpirations() {
-	now := time.Now()
-	var expired []string
+	if pm.instruments != nil {
+		began := time.Now()
+		defer func() { pm.instruments.recordSweep(time.Since(began)) }()
+	}
 
-	pm.mu.Lock()
-	for nodeID, lease := range pm.leases {
-		if now.Sub(lease.LastSeen) >= pm.config.HeartbeatTTL {
-			if !lease.MarkedOffline {
-				lease.MarkedOffline = true
-				lease.LastExpired = now
-				expired = append(expired, nodeID)
-			} else if pm.config.HardEvictTTL > 0 && now.Sub(lease.LastSeen) >= pm.config.HardEvictTTL {
-				delete(pm.leases, nodeID)
+	batchSize := pm.config.SweepBatchSize
+	if batchSize <= 0 {
+		batchSize = int(^uint(0) >> 1)
+	}
+
+	cursor := -1
+	started := false
+	for {
+		now := pm.clock.Now()
+		var expired []string
+		var evicted []leaseEviction
+		var alerts []ThresholdAlert
+		var alertFn func(ThresholdAlert)
+
+		pm.lock()
+		if !started {
+			cursor = len(pm.order) - 1
+			started = true
+		} else if cursor >= len(pm.order) {
+			cursor = len(pm.order) - 1
+		}
+		stop := cursor - batchSize
+		if lo := max(stop+1, 0); pm.config.SweepWorkers > 1 && cursor-lo+1 >= pm.config.SweepWorkers*minSweepShard {
+			for _, nodeID := range pm.dueLeasesLocked(lo, cursor, now) {
+				expired, evicted = pm.sweepLeaseLocked(nodeID, pm.leases[nodeID], now, expired, evicted)
 			}
+			cursor = lo - 1
+		} else {
+			for ; cursor >= 0 && cursor > stop; cursor-- {
+				nodeID := pm.order[cursor]
+				expired, evicted = pm.sweepLeaseLocked(nodeID, pm.leases[nodeID], now, expired, evicted)
+			}
+		}
+		done := cursor < 0
+		if done {
+			pm.finishSweepLocked(now)
+			alerts, alertFn = pm.checkThresholdsLocked(now)
+		}
+		pm.mu.Unlock()
+
+		for _, nodeID := range expired {
+			pm.markInactive(nodeID, EvictionReasonExpiredHeartbeat)
+		}
+		pm.handleEvicted(evicted)
+		for _, alert := range alerts {
+			alertFn(alert)
+		}
+
+		if done {
+			return
 		}
+		runtime.Gosched()
 	}
-	pm.mu.Unlock()
+}
+
+// minSweepShard is the fewest leases a SweepWorkers goroutine is given;
+// below that the goroutine overhead outweighs the parallel scan.
+const minSweepShard = 4096
+
+// dueLeasesLocked returns the IDs in pm.order[lo:hi+1] whose leases have
+// passed their expiry at now, scanning SweepWorkers partitions in parallel.
+// IDs are returned from hi down to lo, the order a serial sweep visits them.
+// The workers only read; the caller holds the write lock throughout.
+func (pm *PresenceManager) dueLeasesLocked(lo, hi int, now time.Time) []string {
+	workers := pm.config.SweepWorkers
+	shard := (hi - lo + workers) / workers
+	due := make([][]string, workers)
 
-	for _, nodeID := range expired {
-		pm.markInactive(nodeID)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		top := hi - w*shard
+		bottom := max(top-shard+1, lo)
+		if top < bottom {
+			break
+		}
+		wg.Add(1)
+		go func(w, top, bottom int) {
+			defer wg.Done()
+			for i := top; i >= bottom; i-- {
+				nodeID := pm.order[i]
+				if !now.Before(pm.leases[nodeID].expiresAt(pm.config.HeartbeatTTL)) {
+					due[w] = append(due[w], nodeID)
+				}
+			}
+		}(w, top, bottom)
+	}
+	wg.Wait()
+
+	var out []string
+	for _, ids := range due {
+		out = append(out, ids...)
+	}
+	return out
+}
+
+// putLeaseLocked stores lease for nodeID, reusing the node's slot in pm.order
+// when it already holds a lease.
+func (pm *PresenceManager) putLeaseLocked(nodeID string, lease *presenceLease) {
+	if existing, exists := pm.leases[nodeID]; exists {
+		lease.index = existing.index
+		if existing.MarkedOffline {
+			pm.offline--
+		}
+	} else {
+		lease.index = len(pm.order)
+		pm.order = append(pm.order, nodeID)
+	}
+	if lease.MarkedOffline {
+		pm.offline++
+	}
+	pm.leases[nodeID] = lease
+	pm.seen[nodeID] = struct{}{}
+}
+
+// setOfflineLocked sets lease.MarkedOffline, keeping pm.offline in step.
+func (pm *PresenceManager) setOfflineLocked(lease *presenceLease, offline bool) {
+	if lease.MarkedOffline == offline {
+		return
+	}
+	lease.MarkedOffline = offline
+	if offline {
+		pm.offline++
+	} else {
+		pm.offline--
+	}
+}
+
+// removeLeaseLocked drops nodeID's lease, moving the last ID in pm.order into
+// the freed slot.
+func (pm *PresenceManager) removeLeaseLocked(nodeID string) {
+	lease, exists := pm.leases[nodeID]
+	if !exists {
+		return
+	}
+	last := len(pm.order) - 1
+	moved := pm.order[last]
+	pm.order[lease.index] = moved
+	pm.leases[moved].index = lease.index
+	pm.order[last] = ""
+	pm.order = pm.order[:last]
+	delete(pm.leases, nodeID)
+	if lease.MarkedOffline {
+		pm.offline--
 	}
 }
 
-func (pm *PresenceManager) markInactive(nodeID string) {
+// sweepLeaseLocked marks lease offline once it passes HeartbeatTTL and evicts
+// it once it passes HardEvictTTL, appending it to the matching slice.
+func (pm *PresenceManager) sweepLeaseLocked(nodeID string, lease *presenceLease, now time.Time, expired []string, evicted []leaseEviction) ([]string, []leaseEviction) {
+	if now.Before(pm.warmupUntil) || now.Before(lease.expiresAt(pm.config.HeartbeatTTL)) {
+		return expired, evicted
+	}
+	if !lease.MarkedOffline {
+		pm.setOfflineLocked(lease, true)
+		lease.LastExpired = now
+		expired = append(expired, nodeID)
+	} else if pm.config.HardEvictTTL > 0 && now.Sub(lease.LastSeen) >= pm.config.HardEvictTTL {
+		pm.removeLeaseLocked(nodeID)
+		pm.evicted[nodeID] = now
+		evicted = append(evicted, newLeaseEviction(nodeID, lease, now))
+	}
+	return expired, evicted
+}
+
+// finishSweepLocked prunes old eviction records and finished NewLeaseLimit
+// windows, and updates sweep stats.
+// Records are kept for at least ReadmissionCooldown so the cooldown holds.
+func (pm *PresenceManager) finishSweepLocked(now time.Time) {
+	retain := pm.config.HardEvictTTL
+	if pm.config.ReadmissionCooldown > retain {
+		retain = pm.config.ReadmissionCooldown
+	}
+	for nodeID, evictedAt := range pm.evicted {
+		if now.Sub(evictedAt) >= retain {
+			delete(pm.evicted, nodeID)
+		}
+	}
+	for reporterID, window := range pm.newLeases {
+		if now.Sub(window.start) >= pm.config.NewLeaseWindow {
+			delete(pm.newLeases, reporterID)
+		}
+	}
+	pm.sweeps++
+	pm.lastSweep = now
+}
+
+// leaseEviction captures a dropped lease so it can be logged after the lock
+// is released.
+type leaseEviction struct {
+	nodeID   string
+	info     LeaseInfo
+	leaseAge time.Duration // time since the node was last seen
+}
+
+func newLeaseEviction(nodeID string, lease *presenceLease, now time.Time) leaseEviction {
+	return leaseEviction{nodeID: nodeID, info: lease.info(), leaseAge: now.Sub(lease.LastSeen)}
+}
+
+// logEviction reports eviction to the configured EvictionLogger, if any.
+func (pm *PresenceManager) logEviction(eviction leaseEviction, reason EvictionReason) {
+	if pm.config.EvictionLogger == nil {
+		return
+	}
+	labels := zerolog.Dict()
+	for key, value := range eviction.info.Labels {
+		labels.Str(key, value)
+	}
+	pm.config.EvictionLogger.Warn().
+		Str("node_id", eviction.nodeID).
+		Str("reason", string(reason)).
+		Dur("lease_age", eviction.leaseAge).
+		Time("last_seen", eviction.info.LastSeen).
+		Time("last_expired", eviction.info.LastExpired).
+		Bool("draining", eviction.info.Draining).
+		Dict("labels", labels).
+		Msg("presence lease evicted")
+}
+
+func (pm *PresenceManager) handleEvicted(evictions []leaseEviction) {
+	for _, eviction := range evictions {
+		pm.logEviction(eviction, EvictionReasonHardEvicted)
+		if pm.statusManager != nil {
+			// The node is already inactive, so no transition records why.
+			pm.statusManager.RecordPresenceEviction(eviction.nodeID, EvictionReasonHardEvicted.StatusReason())
+		}
+		pm.forgetSyncedStatus(eviction.nodeID)
+		pm.notifyExpired(eviction.nodeID, EvictionReasonHardEvicted, false)
+	}
+}
+
+// forgetSyncedStatus drops the StatusManager's cached status for a
+// hard-evicted node when SyncStatus is set.
+func (pm *PresenceManager) forgetSyncedStatus(nodeID string) {
+	if pm.statusManager == nil || !pm.config.SyncStatus {
+		return
+	}
+	pm.statusManager.ForgetAgentStatus(nodeID)
+}
+
+func (pm *PresenceManager) markActive(nodeID string) {
+	if pm.statusManager == nil {
+		return
+	}
+
+	active := types.AgentStateActive
+	update := &types.AgentStatusUpdate{
+		State:  &active,
+		Source: types.StatusSourcePresence,
+		Reason: "presence lease acquired",
+	}
+
+	if err := pm.statusManager.UpdateAgentStatus(context.Background(), nodeID, update); err != nil {
+		logger.Logger.Error().Err(err).Str("node_id", nodeID).Msg("❌ Failed to mark node active from presence manager")
+		return
+	}
+
+	logger.Logger.Debug().Str("node_id", nodeID).Msg("📈 Presence lease acquired; node marked active")
+}
+
+func (pm *PresenceManager) markInactive(nodeID string, reason EvictionReason) {
+	pm.updateStatusInactive(nodeID, reason)
+	pm.notifyExpired(nodeID, reason, true)
+}
+
+// updateStatusInactive marks nodeID inactive, recording reason's status code
+// as the transition reason.
+func (pm *PresenceManager) updateStatusInactive(nodeID string, reason EvictionReason) {
 	if pm.statusManager == nil {
 		return
 	}
@@ -181,7 +1359,7 @@ func (pm *PresenceManager) markInactive(nodeID string) {
 		State:       &inactive,
 		HealthScore: &zero,
 		Source:      types.StatusSourcePresence,
-		Reason:      "presence lease expired",
+		Reason:      string(reason.StatusReason()),
 	}
 
 	if err := pm.statusManager.UpdateAgentStatus(ctx, nodeID, update); err != nil {
@@ -189,14 +1367,45 @@ func (pm *PresenceManager) markInactive(nodeID string) {
 		return
 	}
 
-	logger.Logger.Debug().Str("node_id", nodeID).Msg("📉 Presence lease expired; node marked inactive")
+	logger.Logger.Debug().Str("node_id", nodeID).Str("reason", string(reason)).Msg("📉 Presence lease expired; node marked inactive")
+}
 
+// notifyExpired runs the steps that follow a node's status update on expiry
+// or eviction. Expiry handling always proceeds in this order:
+//
+//  1. The StatusManager is updated, by the caller, before notifyExpired runs.
+//  2. The SetExpireCallback callback runs (when includePlain is set), then
+//     the SetExpireCallbackWithReason callback, then the node's
+//     SetNodeExpireCallback callback. NodeCallbacksReplaceGlobal skips the
+//     global callbacks for nodes that have their own.
+//  3. A node_presence_expired event is published.
+//
+// Steps 2 and 3 run on one goroutine per node so slow callbacks do not stall
+// the sweeper. A failed status update is logged and does not skip them, so
+// callbacks may observe a stale status only when that update failed.
+func (pm *PresenceManager) notifyExpired(nodeID string, reason EvictionReason, includePlain bool) {
 	var callback func(string)
-	pm.mu.RLock()
-	callback = pm.expireCallback
+	var reasonCallback func(string, EvictionReason)
+	pm.rlock()
+	nodeCallback := pm.nodeExpireCallbacks[nodeID]
+	if nodeCallback == nil || !pm.config.NodeCallbacksReplaceGlobal {
+		if includePlain {
+			callback = pm.expireCallback
+		}
+		reasonCallback = pm.expireCallbackWithReason
+	}
 	pm.mu.RUnlock()
 
-	if callback != nil {
-		go callback(nodeID)
-	}
+	go func() {
+		if callback != nil {
+			callback(nodeID)
+		}
+		if reasonCallback != nil {
+			reasonCallback(nodeID, reason)
+		}
+		if nodeCallback != nil {
+			nodeCallback(reason)
+		}
+		events.PublishNodePresenceExpired(nodeID, string(reason))
+	}()
 }
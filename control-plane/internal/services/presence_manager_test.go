@@ -1,20 +1,34 @@
 package services
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/Agent-Field/agentfield/control-plane/internal/events"
+	"github.com/Agent-Field/agentfield/control-plane/internal/services/presencetest"
 	"github.com/Agent-Field/agentfield/control-plane/internal/storage"
 	"github.com/Agent-Field/agentfield/control-plane/pkg/types"
 
+	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
 func setupPresenceManagerTest(t *testing.T) (*PresenceManager, storage.StorageProvider) {
 	t.Helper()
+	return setupPresenceManagerTestWithClock(t, nil)
+}
+
+// setupPresenceManagerTestWithClock is setupPresenceManagerTest with an
+// injected clock; a nil clock uses the wall clock.
+func setupPresenceManagerTestWithClock(t *testing.T, clock Clock) (*PresenceManager, storage.StorageProvider) {
+	t.Helper()
 
 	provider, ctx := setupTestStorage(t)
 
@@ -30,7 +44,7 @@ func setupPresenceManagerTest(t *testing.T) (*PresenceManager, storage.StoragePr
 		HardEvictTTL:  10 * time.Second,
 	}
 
-	presenceManager := NewPresenceManager(statusManager, config)
+	presenceManager := NewPresenceManagerWithClock(statusManager, config, clock)
 
 	t.Cleanup(func() {
 		presenceManager.Stop()
@@ -132,34 +146,35 @@ func TestPresenceManager_HasLease(t *testing.T) {
 }
 
 func TestPresenceManager_SetExpireCallback(t *testing.T) {
-	pm, _ := setupPresenceManagerTest(t)
-
-	var callbackInvoked bool
-	var callbackNodeID string
-
-	callback := func(nodeID string) {
-		callbackInvoked = true
-		callbackNodeID = nodeID
-	}
+	clock := presencetest.NewManualClock(time.Now())
+	pm := NewPresenceManagerWithClock(nil, PresenceManagerConfig{
+		HeartbeatTTL:  5 * time.Second,
+		SweepInterval: time.Second,
+		HardEvictTTL:  10 * time.Second,
+	}, clock)
+	t.Cleanup(pm.Stop)
 
-	pm.SetExpireCallback(callback)
+	expired := make(chan string, 1)
+	pm.SetExpireCallback(func(nodeID string) {
+		expired <- nodeID
+	})
 	require.NotNil(t, pm.expireCallback)
 
-	// Start the presence manager to trigger expiration
-	pm.Start()
-
-	// Touch a node
 	nodeID := "node-callback-1"
-	pm.Touch(nodeID, time.Now().Add(-10*time.Second)) // Touch in the past
-
-	// Wait for expiration
-	time.Sleep(2 * time.Second)
+	pm.Touch(nodeID, clock.Now())
+	pm.Start()
 
-	pm.Stop()
+	// Park the sweeper on the clock, then move past HeartbeatTTL in one step.
+	clock.BlockUntilWaiters(1)
+	clock.Advance(6 * time.Second)
 
-	// Callback should have been invoked
-	require.True(t, callbackInvoked)
-	require.Equal(t, nodeID, callbackNodeID)
+	select {
+	case got := <-expired:
+		require.Equal(t, nodeID, got)
+	case <-time.After(2 * time.Second):
+		t.Fatal("expire callback not invoked")
+	}
+	require.False(t, pm.HasLease(nodeID))
 }
 
 func TestPresenceManager_ExpirationDetection(t *testing.T) {
@@ -383,3 +398,1150 @@ func TestPresenceManager_RecoverFromDatabase_SkipsNilNodes(t *testing.T) {
 	// Verify the valid agent has a lease
 	assert.True(t, pm.HasLease("valid-agent"))
 }
+
+func TestPresenceManager_BecomeLeaderSuppressesEvictionsDuringWarmup(t *testing.T) {
+	provider, ctx := setupTestStorage(t)
+	clock := presencetest.NewManualClock(time.Now())
+	pm := NewPresenceManagerWithClock(nil, PresenceManagerConfig{
+		HeartbeatTTL:  5 * time.Second,
+		SweepInterval: time.Second,
+		HardEvictTTL:  10 * time.Second,
+		LeaderWarmup:  30 * time.Second,
+	}, clock)
+	t.Cleanup(pm.Stop)
+
+	// The previous leader last heard from both nodes a minute ago, long past
+	// HardEvictTTL by the time this control plane takes over.
+	for _, nodeID := range []string{"node-returning", "node-gone"} {
+		require.NoError(t, provider.RegisterAgent(ctx, &types.AgentNode{
+			ID:            nodeID,
+			BaseURL:       "http://localhost:8001",
+			LastHeartbeat: clock.Now().Add(-time.Minute),
+		}))
+	}
+	require.NoError(t, pm.BecomeLeader(ctx, provider))
+
+	leaseCount := func() int {
+		pm.mu.RLock()
+		defer pm.mu.RUnlock()
+		return len(pm.leases)
+	}
+
+	pm.checkExpirations()
+	require.Equal(t, 2, leaseCount(), "no lease may be evicted during warmup")
+
+	clock.Advance(28 * time.Second)
+	pm.Touch("node-returning", clock.Now())
+	pm.checkExpirations()
+	require.Equal(t, 2, leaseCount())
+
+	// Once warmup is over the node that never came back is evicted.
+	clock.Advance(3 * time.Second)
+	pm.checkExpirations()
+	require.Equal(t, 1, leaseCount())
+	state, _ := pm.LeaseState("node-gone")
+	assert.Equal(t, LeaseEvicted, state)
+	assert.True(t, pm.HasLease("node-returning"))
+}
+
+func TestPresenceManager_SyncStatus_TouchMarksNodeActive(t *testing.T) {
+	provider, ctx := setupTestStorage(t)
+	statusManager := NewStatusManager(provider, StatusManagerConfig{ReconcileInterval: 30 * time.Second}, nil, nil)
+
+	pm := NewPresenceManager(statusManager, PresenceManagerConfig{
+		HeartbeatTTL:  5 * time.Second,
+		SweepInterval: 1 * time.Second,
+		HardEvictTTL:  10 * time.Second,
+		SyncStatus:    true,
+	})
+	t.Cleanup(pm.Stop)
+
+	nodeID := "node-sync-status"
+	require.NoError(t, provider.RegisterAgent(ctx, &types.AgentNode{
+		ID:              nodeID,
+		BaseURL:         "http://localhost:8001",
+		HealthStatus:    types.HealthStatusInactive,
+		LifecycleStatus: types.AgentStatusOffline,
+		LastHeartbeat:   time.Now().Add(-time.Hour),
+	}))
+
+	before, err := statusManager.GetAgentStatusSnapshot(ctx, nodeID, nil)
+	require.NoError(t, err)
+	require.Equal(t, types.AgentStateInactive, before.State)
+
+	pm.Touch(nodeID, time.Now())
+
+	after, err := statusManager.GetAgentStatusSnapshot(ctx, nodeID, nil)
+	require.NoError(t, err)
+	assert.Equal(t, types.AgentStateActive, after.State)
+	assert.Equal(t, types.StatusSourcePresence, after.Source)
+}
+
+func TestPresenceManager_SyncStatus_HardEvictKeepsCordon(t *testing.T) {
+	provider, _ := setupTestStorage(t)
+	statusManager := NewStatusManager(provider, StatusManagerConfig{ReconcileInterval: 30 * time.Second}, nil, nil)
+
+	clock := presencetest.NewManualClock(time.Now())
+	pm := NewPresenceManagerWithClock(statusManager, PresenceManagerConfig{
+		HeartbeatTTL: 5 * time.Second,
+		HardEvictTTL: 10 * time.Second,
+		SyncStatus:   true,
+	}, clock)
+	t.Cleanup(pm.Stop)
+
+	nodeID := "node-cordoned-evicted"
+	pm.Touch(nodeID, clock.Now())
+	statusManager.SetCordoned(nodeID, true)
+
+	clock.Advance(6 * time.Second)
+	pm.checkExpirations()
+	clock.Advance(5 * time.Second)
+	pm.checkExpirations()
+
+	state, _ := pm.LeaseState(nodeID)
+	require.Equal(t, LeaseEvicted, state)
+	assert.True(t, statusManager.IsCordoned(nodeID), "hard eviction must not lift an operator cordon")
+}
+
+func TestPresenceManager_SyncStatus_DisabledByDefault(t *testing.T) {
+	pm, provider := setupPresenceManagerTest(t)
+	ctx := context.Background()
+
+	nodeID := "node-no-sync"
+	require.NoError(t, provider.RegisterAgent(ctx, &types.AgentNode{
+		ID:              nodeID,
+		BaseURL:         "http://localhost:8001",
+		HealthStatus:    types.HealthStatusInactive,
+		LifecycleStatus: types.AgentStatusOffline,
+		LastHeartbeat:   time.Now().Add(-time.Hour),
+	}))
+
+	pm.Touch(nodeID, time.Now())
+
+	status, err := pm.statusManager.GetAgentStatusSnapshot(ctx, nodeID, nil)
+	require.NoError(t, err)
+	assert.Equal(t, types.AgentStateInactive, status.State)
+}
+
+func TestPresenceManager_ForgetMatching(t *testing.T) {
+	pm, _ := setupPresenceManagerTest(t)
+
+	now := time.Now()
+	pm.Touch("region-a-1", now)
+	pm.Touch("region-a-2", now)
+	pm.Touch("region-b-1", now)
+
+	evicted := pm.ForgetMatching(func(nodeID string, info LeaseInfo) bool {
+		return strings.HasPrefix(nodeID, "region-a-")
+	})
+
+	require.Equal(t, 2, evicted)
+	assert.False(t, pm.HasLease("region-a-1"))
+	assert.False(t, pm.HasLease("region-a-2"))
+	assert.True(t, pm.HasLease("region-b-1"))
+}
+
+func TestPresenceManager_MinTouchInterval_CoalescesRapidTouches(t *testing.T) {
+	pm, _ := setupPresenceManagerTest(t)
+	pm.config.MinTouchInterval = time.Second
+
+	nodeID := "node-chatty"
+	start := time.Now()
+
+	recorded := 0
+	for i := 0; i < 100; i++ {
+		if pm.Touch(nodeID, start.Add(time.Duration(i)*time.Millisecond)) {
+			recorded++
+		}
+	}
+	assert.Equal(t, 1, recorded, "rapid touches should be coalesced into a single recorded update")
+
+	// Coalesced touches must still advance the lease expiry.
+	pm.mu.RLock()
+	lastSeen := pm.leases[nodeID].LastSeen
+	pm.mu.RUnlock()
+	assert.Equal(t, start.Add(99*time.Millisecond), lastSeen)
+
+	// A touch outside the window is recorded again.
+	assert.True(t, pm.Touch(nodeID, start.Add(2*time.Second)))
+}
+
+func TestPresenceManager_LeaseState(t *testing.T) {
+	clock := presencetest.NewManualClock(time.Now())
+	pm, _ := setupPresenceManagerTestWithClock(t, clock)
+
+	nodeID := "node-lease-state"
+	_, ok := pm.LeaseState(nodeID)
+	require.False(t, ok, "unknown node should have no lease state")
+
+	pm.Touch(nodeID, clock.Now())
+	state, ok := pm.LeaseState(nodeID)
+	require.True(t, ok)
+	assert.Equal(t, LeaseActive, state)
+	assert.True(t, pm.HasLease(nodeID))
+
+	// Past HeartbeatTTL (5s) but before HardEvictTTL (10s).
+	clock.Advance(6 * time.Second)
+	pm.checkExpirations()
+	state, ok = pm.LeaseState(nodeID)
+	require.True(t, ok)
+	assert.Equal(t, LeaseExpired, state)
+	assert.False(t, pm.HasLease(nodeID))
+
+	// Past HardEvictTTL: the sweeper drops the lease but remembers the eviction.
+	clock.Advance(5 * time.Second)
+	pm.checkExpirations()
+	state, ok = pm.LeaseState(nodeID)
+	require.True(t, ok)
+	assert.Equal(t, LeaseEvicted, state)
+	assert.False(t, pm.HasLease(nodeID))
+
+	// A fresh touch re-acquires the lease.
+	pm.Touch(nodeID, clock.Now())
+	state, _ = pm.LeaseState(nodeID)
+	assert.Equal(t, LeaseActive, state)
+}
+
+func TestPresenceManager_ExpireCallbackWithReason(t *testing.T) {
+	clock := presencetest.NewManualClock(time.Now())
+	pm, provider := setupPresenceManagerTestWithClock(t, clock)
+	ctx := context.Background()
+
+	reasons := make(chan EvictionReason, 4)
+	pm.SetExpireCallbackWithReason(func(nodeID string, reason EvictionReason) {
+		reasons <- reason
+	})
+
+	nodeID := "node-eviction-reason"
+	require.NoError(t, provider.RegisterAgent(ctx, &types.AgentNode{
+		ID:            nodeID,
+		BaseURL:       "http://localhost:8001",
+		HealthStatus:  types.HealthStatusActive,
+		LastHeartbeat: clock.Now(),
+	}))
+	pm.Touch(nodeID, clock.Now())
+
+	waitReason := func() EvictionReason {
+		t.Helper()
+		select {
+		case reason := <-reasons:
+			return reason
+		case <-time.After(2 * time.Second):
+			t.Fatal("expire callback not invoked")
+			return ""
+		}
+	}
+
+	// Past HeartbeatTTL (5s): soft expiry.
+	clock.Advance(6 * time.Second)
+	pm.checkExpirations()
+	assert.Equal(t, EvictionReasonExpiredHeartbeat, waitReason())
+
+	// Past HardEvictTTL (10s): hard eviction.
+	clock.Advance(5 * time.Second)
+	pm.checkExpirations()
+	assert.Equal(t, EvictionReasonHardEvicted, waitReason())
+}
+
+func TestPresenceManager_NodeExpireCallback(t *testing.T) {
+	run := func(t *testing.T, replaceGlobal bool) (global, critical chan string) {
+		clock := presencetest.NewManualClock(time.Now())
+		pm := NewPresenceManagerWithClock(nil, PresenceManagerConfig{
+			HeartbeatTTL:               5 * time.Second,
+			HardEvictTTL:               time.Minute,
+			NodeCallbacksReplaceGlobal: replaceGlobal,
+		}, clock)
+
+		global = make(chan string, 4)
+		critical = make(chan string, 4)
+		pm.SetExpireCallbackWithReason(func(nodeID string, _ EvictionReason) {
+			global <- nodeID
+		})
+		pm.SetNodeExpireCallback("node-critical", func(reason EvictionReason) {
+			critical <- string(reason)
+		})
+
+		pm.Touch("node-critical", clock.Now())
+		pm.Touch("node-other", clock.Now())
+		clock.Advance(6 * time.Second)
+		pm.checkExpirations()
+		return global, critical
+	}
+	receive := func(t *testing.T, ch chan string) string {
+		t.Helper()
+		select {
+		case v := <-ch:
+			return v
+		case <-time.After(2 * time.Second):
+			t.Fatal("callback not invoked")
+			return ""
+		}
+	}
+
+	t.Run("in addition to the global callback", func(t *testing.T) {
+		global, critical := run(t, false)
+		assert.Equal(t, string(EvictionReasonExpiredHeartbeat), receive(t, critical))
+		assert.ElementsMatch(t, []string{"node-critical", "node-other"}, []string{receive(t, global), receive(t, global)})
+		select {
+		case v := <-critical:
+			t.Fatalf("node callback fired for another node: %s", v)
+		case <-time.After(50 * time.Millisecond):
+		}
+	})
+
+	t.Run("instead of the global callback", func(t *testing.T) {
+		global, critical := run(t, true)
+		assert.Equal(t, string(EvictionReasonExpiredHeartbeat), receive(t, critical))
+		assert.Equal(t, "node-other", receive(t, global))
+		select {
+		case v := <-global:
+			t.Fatalf("global callback fired for %s", v)
+		case <-time.After(50 * time.Millisecond):
+		}
+	})
+}
+
+func TestPresenceManager_EvictionReasonInStatusHistory(t *testing.T) {
+	for _, syncStatus := range []bool{false, true} {
+		t.Run(fmt.Sprintf("sync_status=%t", syncStatus), func(t *testing.T) {
+			clock := presencetest.NewManualClock(time.Now())
+			pm, provider := setupPresenceManagerTestWithClock(t, clock)
+			pm.config.SyncStatus = syncStatus
+			ctx := context.Background()
+
+			register := func(nodeID string) {
+				t.Helper()
+				require.NoError(t, provider.RegisterAgent(ctx, &types.AgentNode{
+					ID:              nodeID,
+					BaseURL:         "http://localhost:8001",
+					HealthStatus:    types.HealthStatusActive,
+					LifecycleStatus: types.AgentStatusReady,
+					LastHeartbeat:   clock.Now(),
+				}))
+				pm.Touch(nodeID, clock.Now())
+			}
+			reasons := func(nodeID string) []string {
+				var out []string
+				for _, entry := range pm.statusManager.StatusHistory(nodeID, 0) {
+					out = append(out, entry.Reason)
+				}
+				return out
+			}
+
+			register("node-swept")
+			register("node-forced")
+
+			// Past HeartbeatTTL (5s): node-swept expires.
+			clock.Advance(6 * time.Second)
+			pm.ForgetMatching(func(nodeID string, _ LeaseInfo) bool { return nodeID == "node-forced" })
+			pm.checkExpirations()
+			// Past HardEvictTTL (10s): node-swept is hard-evicted while already inactive.
+			clock.Advance(5 * time.Second)
+			pm.checkExpirations()
+
+			assert.Equal(t, []string{
+				string(types.StatusReasonHardEvicted),
+				string(types.StatusReasonHeartbeatExpired),
+			}, reasons("node-swept"), "newest first")
+			assert.Equal(t, []string{string(types.StatusReasonHardEvicted)}, reasons("node-forced"))
+
+			if syncStatus {
+				// A force-evict leaves the StatusManager as a sweep does.
+				statuses := pm.statusManager.AllStatuses()
+				assert.NotContains(t, statuses, "node-swept")
+				assert.NotContains(t, statuses, "node-forced")
+			}
+		})
+	}
+}
+
+func TestPresenceManager_ExpiryOrdering(t *testing.T) {
+	clock := presencetest.NewManualClock(time.Now())
+	pm, provider := setupPresenceManagerTestWithClock(t, clock)
+	ctx := context.Background()
+
+	nodeID := "node-expiry-order"
+	require.NoError(t, provider.RegisterAgent(ctx, &types.AgentNode{
+		ID:            nodeID,
+		BaseURL:       "http://localhost:8001",
+		HealthStatus:  types.HealthStatusActive,
+		LastHeartbeat: clock.Now(),
+	}))
+	active := types.AgentStateActive
+	require.NoError(t, pm.statusManager.UpdateAgentStatus(ctx, nodeID, &types.AgentStatusUpdate{
+		State:  &active,
+		Source: types.StatusSourceManual,
+	}))
+	pm.Touch(nodeID, clock.Now())
+
+	subscriberID := "presence-expiry-order"
+	eventsCh := events.GlobalNodeEventBus.Subscribe(subscriberID)
+	t.Cleanup(func() { events.GlobalNodeEventBus.Unsubscribe(subscriberID) })
+
+	var mu sync.Mutex
+	var steps []string
+	record := func(step string) {
+		mu.Lock()
+		steps = append(steps, step)
+		mu.Unlock()
+	}
+	statesSeen := make(chan types.AgentState, 2)
+	observe := func() {
+		status, err := pm.statusManager.GetAgentStatusSnapshot(ctx, nodeID, nil)
+		if err == nil {
+			statesSeen <- status.State
+		}
+	}
+	pm.SetExpireCallback(func(string) {
+		observe()
+		record("callback")
+	})
+	pm.SetExpireCallbackWithReason(func(string, EvictionReason) {
+		observe()
+		record("callback_with_reason")
+	})
+
+	clock.Advance(6 * time.Second)
+	pm.checkExpirations()
+
+	deadline := time.After(2 * time.Second)
+	for {
+		select {
+		case event := <-eventsCh:
+			if event.Type != events.NodePresenceExpired || event.NodeID != nodeID {
+				continue
+			}
+			record("event")
+			mu.Lock()
+			assert.Equal(t, []string{"callback", "callback_with_reason", "event"}, steps)
+			mu.Unlock()
+			require.Len(t, statesSeen, 2)
+			assert.Equal(t, types.AgentStateInactive, <-statesSeen, "status is updated before callbacks")
+			assert.Equal(t, types.AgentStateInactive, <-statesSeen)
+			return
+		case <-deadline:
+			t.Fatal("presence expiry event not published")
+		}
+	}
+}
+
+func TestPresenceManager_QuorumThreshold(t *testing.T) {
+	clock := presencetest.NewManualClock(time.Now())
+	pm, _ := setupPresenceManagerTestWithClock(t, clock)
+	pm.config.QuorumThreshold = 2
+
+	nodeID := "node-quorum"
+	pm.TouchFrom(nodeID, "reporter-a", clock.Now())
+	assert.False(t, pm.HasLease(nodeID), "a single reporter should not meet a quorum of two")
+
+	// Repeated touches from the same reporter still count once.
+	pm.TouchFrom(nodeID, "reporter-a", clock.Now().Add(time.Second))
+	assert.False(t, pm.HasLease(nodeID))
+
+	pm.TouchFrom(nodeID, "reporter-b", clock.Now().Add(time.Second))
+	assert.True(t, pm.HasLease(nodeID), "two distinct reporters should meet the quorum")
+
+	// reporter-a goes stale while reporter-b keeps touching.
+	clock.Advance(6500 * time.Millisecond)
+	pm.TouchFrom(nodeID, "reporter-b", clock.Now())
+	assert.False(t, pm.HasLease(nodeID), "quorum should be lost once a reporter goes stale")
+}
+
+func TestPresenceManager_StatsCallback(t *testing.T) {
+	clock := presencetest.NewManualClock(time.Now())
+	pm, _ := setupPresenceManagerTestWithClock(t, clock)
+
+	pm.Touch("node-stats", clock.Now())
+
+	statsCh := make(chan PresenceStats, 16)
+	pm.SetStatsCallback(func(stats PresenceStats) {
+		select {
+		case statsCh <- stats:
+		default:
+		}
+	}, 10*time.Millisecond)
+
+	var collected []PresenceStats
+	for i := 0; i < 3; i++ {
+		// The stats loop must be parked on the clock for the advance to reach it.
+		clock.BlockUntilWaiters(1)
+		clock.Advance(2 * time.Second)
+		pm.checkExpirations()
+		select {
+		case stats := <-statsCh:
+			collected = append(collected, stats)
+		case <-time.After(time.Second):
+			t.Fatal("stats callback did not fire")
+		}
+	}
+
+	for i := 1; i < len(collected); i++ {
+		assert.GreaterOrEqual(t, collected[i].Sweeps, collected[i-1].Sweeps, "sweep counts should not decrease")
+	}
+	last := collected[len(collected)-1]
+	assert.Equal(t, 1, last.Active+last.Expired+last.Evicted)
+	assert.Equal(t, uint64(3), pm.Stats().Sweeps)
+
+	// Stopping the manager stops the ticker.
+	pm.Stop()
+	time.Sleep(30 * time.Millisecond)
+	for len(statsCh) > 0 {
+		<-statsCh
+	}
+	clock.Advance(time.Minute)
+	time.Sleep(30 * time.Millisecond)
+	assert.Empty(t, statsCh, "callback should not fire after Stop")
+}
+
+func TestPresenceManager_ExtendLease(t *testing.T) {
+	clock := presencetest.NewManualClock(time.Now())
+	pm, _ := setupPresenceManagerTestWithClock(t, clock)
+
+	assert.False(t, pm.ExtendLease("node-unknown", time.Minute), "no lease to extend")
+
+	nodeID := "node-extend"
+	pm.Touch(nodeID, clock.Now())
+
+	// Near expiry (HeartbeatTTL is 5s), extend by 3s.
+	clock.Advance(4 * time.Second)
+	require.True(t, pm.ExtendLease(nodeID, 3*time.Second))
+
+	// Past the original TTL but within the extension.
+	clock.Advance(2 * time.Second)
+	pm.checkExpirations()
+	assert.True(t, pm.HasLease(nodeID), "extended lease should outlive the original TTL")
+	require.NotNil(t, pm.leases[nodeID])
+	assert.False(t, pm.leases[nodeID].MarkedOffline)
+
+	// The extension does not count as a touch.
+	clock.Advance(2 * time.Second)
+	pm.checkExpirations()
+	assert.False(t, pm.HasLease(nodeID), "lease expires once the extension runs out")
+	assert.False(t, pm.ExtendLease(nodeID, time.Minute), "expired leases cannot be extended")
+}
+
+func TestPresenceManager_ExtendLease_HardEvictCeiling(t *testing.T) {
+	clock := presencetest.NewManualClock(time.Now())
+	pm, _ := setupPresenceManagerTestWithClock(t, clock)
+
+	nodeID := "node-extend-ceiling"
+	pm.Touch(nodeID, clock.Now())
+	require.True(t, pm.ExtendLease(nodeID, time.Hour))
+
+	// HardEvictTTL (10s) caps the extension.
+	clock.Advance(9 * time.Second)
+	pm.checkExpirations()
+	assert.True(t, pm.HasLease(nodeID))
+
+	clock.Advance(time.Second)
+	pm.checkExpirations()
+	state, _ := pm.LeaseState(nodeID)
+	assert.NotEqual(t, LeaseActive, state)
+}
+
+func TestPresenceManager_PresentCountByLabel(t *testing.T) {
+	clock := presencetest.NewManualClock(time.Now())
+	pm, _ := setupPresenceManagerTestWithClock(t, clock)
+
+	pools := map[string]string{
+		"gpu-1": "gpu",
+		"gpu-2": "gpu",
+		"cpu-1": "cpu",
+		"cpu-2": "cpu",
+		"cpu-3": "cpu",
+	}
+	for nodeID, pool := range pools {
+		pm.Touch(nodeID, clock.Now())
+		require.True(t, pm.SetLabels(nodeID, map[string]string{"pool": pool}))
+	}
+	pm.Touch("unlabeled", clock.Now())
+	assert.False(t, pm.SetLabels("unknown", map[string]string{"pool": "gpu"}), "no lease to label")
+
+	assert.Equal(t, map[string]int{"gpu": 2, "cpu": 3}, pm.PresentCountByLabel("pool"))
+	assert.Empty(t, pm.PresentCountByLabel("zone"))
+
+	// Only active leases are counted: let cpu-3 lapse while the rest keep touching.
+	clock.Advance(6 * time.Second)
+	for nodeID := range pools {
+		if nodeID != "cpu-3" {
+			pm.Touch(nodeID, clock.Now())
+		}
+	}
+	pm.checkExpirations()
+	assert.Equal(t, map[string]int{"gpu": 2, "cpu": 2}, pm.PresentCountByLabel("pool"))
+}
+
+// steppingClock returns each of times from successive Now calls, then keeps
+// returning the last one, so a single sweep can watch time move.
+type steppingClock struct {
+	mu    sync.Mutex
+	times []time.Time
+}
+
+func (c *steppingClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.times[0]
+	if len(c.times) > 1 {
+		c.times = c.times[1:]
+	}
+	return now
+}
+
+func (c *steppingClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+func TestPresenceManager_SweepBatchSize(t *testing.T) {
+	start := time.Now()
+	clock := &steppingClock{times: []time.Time{start}}
+	pm := NewPresenceManagerWithClock(nil, PresenceManagerConfig{
+		HeartbeatTTL:   5 * time.Second,
+		HardEvictTTL:   time.Minute,
+		SweepBatchSize: 2,
+	}, clock)
+
+	for i := 0; i < 5; i++ {
+		pm.Touch(fmt.Sprintf("node-%d", i), start)
+	}
+
+	// The first batch sees the leases still fresh; the clock then moves past
+	// HeartbeatTTL, so the remaining batches must catch the expiry.
+	clock.mu.Lock()
+	clock.times = []time.Time{start.Add(4 * time.Second), start.Add(6 * time.Second)}
+	clock.mu.Unlock()
+
+	countExpired := func() int {
+		pm.mu.RLock()
+		defer pm.mu.RUnlock()
+		expired := 0
+		for _, lease := range pm.leases {
+			if lease.MarkedOffline {
+				expired++
+			}
+		}
+		return expired
+	}
+
+	pm.checkExpirations()
+	assert.Equal(t, 3, countExpired(), "leases in later batches expire mid-sweep")
+	assert.Equal(t, uint64(1), pm.Stats().Sweeps)
+
+	pm.checkExpirations()
+	assert.Equal(t, 5, countExpired(), "leases skipped by the first batch expire on the next sweep")
+}
+
+func TestPresenceManager_SweepWorkersEvictOnce(t *testing.T) {
+	const nodes = 4 * 4 * minSweepShard
+
+	for _, batchSize := range []int{0, nodes / 3} {
+		t.Run(fmt.Sprintf("batch=%d", batchSize), func(t *testing.T) {
+			start := time.Now()
+			clock := presencetest.NewManualClock(start)
+			pm := NewPresenceManagerWithClock(nil, PresenceManagerConfig{
+				HeartbeatTTL:   5 * time.Second,
+				HardEvictTTL:   time.Minute,
+				SweepBatchSize: batchSize,
+				SweepWorkers:   4,
+			}, clock)
+
+			var mu sync.Mutex
+			notified := map[EvictionReason]map[string]int{}
+			pm.SetExpireCallbackWithReason(func(nodeID string, reason EvictionReason) {
+				mu.Lock()
+				defer mu.Unlock()
+				if notified[reason] == nil {
+					notified[reason] = map[string]int{}
+				}
+				notified[reason][nodeID]++
+			})
+
+			// Even-numbered nodes keep heartbeating; odd ones go silent.
+			for i := 0; i < nodes; i++ {
+				pm.Touch(fmt.Sprintf("node-%d", i), start)
+			}
+			touchEven := func() {
+				for i := 0; i < nodes; i += 2 {
+					pm.Touch(fmt.Sprintf("node-%d", i), clock.Now())
+				}
+			}
+
+			clock.Advance(10 * time.Second)
+			touchEven()
+			pm.checkExpirations()
+			pm.checkExpirations()
+
+			clock.Advance(2*time.Minute - 10*time.Second)
+			touchEven()
+			pm.checkExpirations()
+			pm.checkExpirations()
+
+			// Callbacks run on their own goroutines.
+			reasons := []EvictionReason{EvictionReasonExpiredHeartbeat, EvictionReasonHardEvicted}
+			require.Eventually(t, func() bool {
+				mu.Lock()
+				defer mu.Unlock()
+				for _, reason := range reasons {
+					if len(notified[reason]) < nodes/2 {
+						return false
+					}
+				}
+				return true
+			}, 5*time.Second, 10*time.Millisecond)
+
+			mu.Lock()
+			defer mu.Unlock()
+			for _, reason := range reasons {
+				require.Len(t, notified[reason], nodes/2, "reason %s", reason)
+				for nodeID, count := range notified[reason] {
+					require.Equal(t, 1, count, "%s notified %d times for %s", nodeID, count, reason)
+				}
+			}
+			assert.Equal(t, nodes/2, pm.Stats().Active)
+		})
+	}
+}
+
+func TestPresenceManager_RecentTouches(t *testing.T) {
+	pm := NewPresenceManager(nil, PresenceManagerConfig{HeartbeatTTL: time.Minute, TouchHistorySize: 3})
+	start := time.Now()
+	at := func(i int) time.Time { return start.Add(time.Duration(i) * time.Second) }
+
+	assert.Nil(t, pm.RecentTouches("node-1"))
+	pm.Touch("node-1", at(0))
+	pm.Touch("node-1", at(1))
+	assert.Equal(t, []time.Time{at(0), at(1)}, pm.RecentTouches("node-1"))
+
+	for i := 2; i < 7; i++ {
+		pm.Touch("node-1", at(i))
+	}
+	assert.Equal(t, []time.Time{at(4), at(5), at(6)}, pm.RecentTouches("node-1"))
+
+	disabled := NewPresenceManager(nil, PresenceManagerConfig{HeartbeatTTL: time.Minute})
+	disabled.Touch("node-1", start)
+	assert.Nil(t, disabled.RecentTouches("node-1"))
+}
+
+func TestPresenceManager_HasLeases(t *testing.T) {
+	pm := NewPresenceManager(nil, PresenceManagerConfig{HeartbeatTTL: 5 * time.Second})
+	now := time.Now()
+	pm.Touch("node-a", now)
+	pm.Touch("node-b", now)
+	pm.Touch("node-stale", now.Add(-time.Minute))
+
+	assert.Equal(t, map[string]bool{
+		"node-a":       true,
+		"node-b":       true,
+		"node-stale":   false,
+		"node-unknown": false,
+	}, pm.HasLeases([]string{"node-a", "node-b", "node-stale", "node-unknown"}))
+	assert.Empty(t, pm.HasLeases(nil))
+}
+
+func TestPresenceManager_PresencePredicate(t *testing.T) {
+	pm := NewPresenceManager(nil, PresenceManagerConfig{HeartbeatTTL: 5 * time.Second, HardEvictTTL: time.Hour})
+	now := time.Now()
+	pm.Touch("node-active", now)
+	pm.Touch("node-draining", now.Add(-time.Minute))
+	pm.Touch("node-expired", now.Add(-time.Minute))
+	require.True(t, pm.SetLabels("node-draining", map[string]string{"zone": "a"}))
+	require.True(t, pm.Drain("node-draining"))
+
+	assert.False(t, pm.HasLease("node-draining"))
+	assert.Empty(t, pm.PresentCountByLabel("zone"))
+
+	pm.SetPresencePredicate(func(state LeaseState, info LeaseInfo) bool {
+		return state == LeaseActive || info.Draining
+	})
+	assert.True(t, pm.HasLease("node-draining"))
+	assert.Equal(t, map[string]bool{
+		"node-active":   true,
+		"node-draining": true,
+		"node-expired":  false,
+		"node-unknown":  false,
+	}, pm.HasLeases([]string{"node-active", "node-draining", "node-expired", "node-unknown"}))
+	assert.Equal(t, map[string]int{"a": 1}, pm.PresentCountByLabel("zone"))
+	assert.Len(t, pm.PresentNodes(false), 2)
+
+	pm.SetPresencePredicate(nil)
+	assert.False(t, pm.HasLease("node-draining"))
+	assert.True(t, pm.HasLease("node-active"))
+}
+
+func TestPresenceManager_HasLeaseExpiresBeforeSweep(t *testing.T) {
+	clock := presencetest.NewManualClock(time.Now())
+	pm := NewPresenceManagerWithClock(nil, PresenceManagerConfig{
+		HeartbeatTTL:  5 * time.Second,
+		SweepInterval: time.Hour,
+		HardEvictTTL:  10 * time.Second,
+	}, clock)
+
+	pm.Touch("node-a", clock.Now())
+	clock.Advance(5*time.Second - time.Millisecond)
+	require.True(t, pm.HasLease("node-a"))
+
+	clock.Advance(time.Millisecond)
+	assert.False(t, pm.HasLease("node-a"), "past HeartbeatTTL with no sweep run")
+	state, ok := pm.LeaseState("node-a")
+	assert.True(t, ok)
+	assert.Equal(t, LeaseExpired, state)
+
+	clock.Advance(5 * time.Second)
+	state, _ = pm.LeaseState("node-a")
+	assert.Equal(t, LeaseEvicted, state, "past HardEvictTTL with no sweep run")
+	assert.Zero(t, pm.Stats().Sweeps)
+}
+
+func TestPresenceManager_HasLeaseDoesNotAllocate(t *testing.T) {
+	pm := NewPresenceManager(nil, PresenceManagerConfig{HeartbeatTTL: time.Hour})
+	pm.Touch("node-a", time.Now())
+	require.True(t, pm.SetLabels("node-a", map[string]string{"zone": "a", "team": "payments"}))
+
+	allocs := testing.AllocsPerRun(100, func() {
+		if !pm.HasLease("node-a") || pm.HasLease("node-unknown") {
+			t.Fatal("unexpected lease state")
+		}
+		if state, ok := pm.LeaseState("node-a"); !ok || state != LeaseActive {
+			t.Fatal("unexpected lease state")
+		}
+	})
+	assert.Zero(t, allocs)
+}
+
+func BenchmarkPresenceManager_HasLease(b *testing.B) {
+	pm := NewPresenceManager(nil, PresenceManagerConfig{HeartbeatTTL: time.Hour})
+	pm.Touch("node-a", time.Now())
+	pm.SetLabels("node-a", map[string]string{"zone": "a", "team": "payments"})
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		pm.HasLease("node-a")
+	}
+}
+
+func TestPresenceManager_NewLeaseLimit(t *testing.T) {
+	clock := presencetest.NewManualClock(time.Now())
+	pm := NewPresenceManagerWithClock(nil, PresenceManagerConfig{
+		HeartbeatTTL:   time.Minute,
+		NewLeaseLimit:  2,
+		NewLeaseWindow: 10 * time.Second,
+	}, clock)
+
+	now := clock.Now()
+	for i := 0; i < 2; i++ {
+		recorded, err := pm.TryTouchFrom(fmt.Sprintf("node-%d", i), "reporter-a", now)
+		require.NoError(t, err)
+		require.True(t, recorded)
+	}
+
+	// reporter-a has used its allowance: new nodes are refused...
+	recorded, err := pm.TryTouchFrom("node-fake", "reporter-a", now)
+	require.ErrorIs(t, err, ErrNewLeaseRateLimited)
+	assert.False(t, recorded)
+	assert.False(t, pm.TouchFrom("node-fake", "reporter-a", now))
+	assert.False(t, pm.HasLease("node-fake"))
+
+	// ...but renewals pass freely, however many there are.
+	for i := 0; i < 10; i++ {
+		recorded, err := pm.TryTouchFrom("node-0", "reporter-a", now)
+		require.NoError(t, err)
+		assert.True(t, recorded)
+	}
+
+	// Other reporters have their own allowance.
+	recorded, err = pm.TryTouchFrom("node-b", "reporter-b", now)
+	require.NoError(t, err)
+	assert.True(t, recorded)
+
+	// The allowance resets once the window has passed.
+	clock.Advance(10 * time.Second)
+	recorded, err = pm.TryTouchFrom("node-fake", "reporter-a", clock.Now())
+	require.NoError(t, err)
+	assert.True(t, recorded)
+	assert.True(t, pm.HasLease("node-fake"))
+}
+
+func TestPresenceManager_ReadmissionCooldown(t *testing.T) {
+	evict := func(pm *PresenceManager, clock *presencetest.ManualClock, nodeID string) time.Time {
+		pm.Touch(nodeID, clock.Now())
+		clock.Advance(6 * time.Second)
+		pm.checkExpirations()
+		clock.Advance(5 * time.Second)
+		pm.checkExpirations()
+		state, _ := pm.LeaseState(nodeID)
+		require.Equal(t, LeaseEvicted, state)
+		return clock.Now()
+	}
+
+	t.Run("touch during cooldown is rejected", func(t *testing.T) {
+		clock := presencetest.NewManualClock(time.Now())
+		pm := NewPresenceManagerWithClock(nil, PresenceManagerConfig{
+			HeartbeatTTL:        5 * time.Second,
+			HardEvictTTL:        10 * time.Second,
+			ReadmissionCooldown: 30 * time.Second,
+		}, clock)
+		evictedAt := evict(pm, clock, "node-flap")
+
+		clock.Advance(time.Second)
+		assert.False(t, pm.Touch("node-flap", clock.Now()))
+		assert.False(t, pm.HasLease("node-flap"))
+		until, cooling := pm.CooldownUntil("node-flap")
+		assert.True(t, cooling)
+		assert.Equal(t, evictedAt.Add(30*time.Second), until)
+
+		// The eviction record outlives HardEvictTTL while the cooldown runs.
+		clock.Advance(19 * time.Second)
+		pm.checkExpirations()
+		_, cooling = pm.CooldownUntil("node-flap")
+		assert.True(t, cooling)
+
+		clock.Advance(10 * time.Second)
+		_, cooling = pm.CooldownUntil("node-flap")
+		assert.False(t, cooling)
+		assert.True(t, pm.Touch("node-flap", clock.Now()))
+		assert.True(t, pm.HasLease("node-flap"))
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		clock := presencetest.NewManualClock(time.Now())
+		pm := NewPresenceManagerWithClock(nil, PresenceManagerConfig{
+			HeartbeatTTL: 5 * time.Second,
+			HardEvictTTL: 10 * time.Second,
+		}, clock)
+		evictedAt := evict(pm, clock, "node-back")
+
+		assert.True(t, pm.Touch("node-back", evictedAt))
+		assert.True(t, pm.HasLease("node-back"))
+	})
+}
+
+func TestPresenceManager_WaitForLease(t *testing.T) {
+	pm := NewPresenceManager(nil, PresenceManagerConfig{HeartbeatTTL: 5 * time.Second})
+
+	t.Run("already present", func(t *testing.T) {
+		pm.Touch("node-present", time.Now())
+		assert.NoError(t, pm.WaitForLease(context.Background(), "node-present"))
+	})
+
+	t.Run("delayed touch unblocks waiter", func(t *testing.T) {
+		done := make(chan error, 1)
+		go func() {
+			done <- pm.WaitForLease(context.Background(), "node-late")
+		}()
+
+		select {
+		case err := <-done:
+			t.Fatalf("WaitForLease returned before the node touched: %v", err)
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		pm.Touch("node-late", time.Now())
+		select {
+		case err := <-done:
+			assert.NoError(t, err)
+		case <-time.After(time.Second):
+			t.Fatal("WaitForLease did not return after Touch")
+		}
+	})
+
+	t.Run("cancelled context returns promptly", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error, 1)
+		go func() {
+			done <- pm.WaitForLease(ctx, "node-never")
+		}()
+
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+		select {
+		case err := <-done:
+			assert.ErrorIs(t, err, context.Canceled)
+		case <-time.After(time.Second):
+			t.Fatal("WaitForLease did not honor cancellation")
+		}
+
+		pm.mu.RLock()
+		defer pm.mu.RUnlock()
+		assert.Empty(t, pm.waiters, "cancelled waiter should be removed")
+	})
+}
+
+func BenchmarkPresenceManager_TouchDuringSweep(b *testing.B) {
+	const leases = 200_000
+
+	for _, batchSize := range []int{0, 1000} {
+		name := "unbatched"
+		if batchSize > 0 {
+			name = fmt.Sprintf("batch=%d", batchSize)
+		}
+		b.Run(name, func(b *testing.B) {
+			pm := NewPresenceManager(nil, PresenceManagerConfig{
+				HeartbeatTTL:   time.Hour,
+				SweepBatchSize: batchSize,
+			})
+			now := time.Now()
+			for i := 0; i < leases; i++ {
+				pm.Touch(fmt.Sprintf("node-%d", i), now)
+			}
+
+			stop := make(chan struct{})
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				for {
+					select {
+					case <-stop:
+						return
+					default:
+						pm.checkExpirations()
+					}
+				}
+			}()
+
+			var worst time.Duration
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				began := time.Now()
+				pm.Touch(fmt.Sprintf("node-%d", i%leases), now)
+				if elapsed := time.Since(began); elapsed > worst {
+					worst = elapsed
+				}
+			}
+			b.StopTimer()
+			close(stop)
+			<-done
+
+			b.ReportMetric(float64(worst.Microseconds()), "max-µs/touch")
+		})
+	}
+}
+
+func BenchmarkPresenceManager_SweepWorkers(b *testing.B) {
+	for _, leases := range []int{100_000, 1_000_000} {
+		for _, workers := range []int{1, 4, 8} {
+			b.Run(fmt.Sprintf("leases=%d/workers=%d", leases, workers), func(b *testing.B) {
+				pm := NewPresenceManager(nil, PresenceManagerConfig{
+					HeartbeatTTL: time.Hour,
+					SweepWorkers: workers,
+				})
+				now := time.Now()
+				for i := 0; i < leases; i++ {
+					pm.Touch(fmt.Sprintf("node-%d", i), now)
+				}
+
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					pm.checkExpirations()
+				}
+			})
+		}
+	}
+}
+
+func TestPresenceManagerConfig_Validate(t *testing.T) {
+	valid := []PresenceManagerConfig{
+		{},
+		{HeartbeatTTL: 5 * time.Minute, SweepInterval: 30 * time.Second, HardEvictTTL: 30 * time.Minute},
+		{HeartbeatTTL: time.Minute, HardEvictTTL: time.Minute},
+		{HeartbeatTTL: 100 * time.Millisecond},
+	}
+	for _, cfg := range valid {
+		assert.NoError(t, cfg.Validate(), "%+v", cfg)
+	}
+
+	invalid := map[string]PresenceManagerConfig{
+		"negative HeartbeatTTL":            {HeartbeatTTL: -time.Second},
+		"negative SweepInterval":           {SweepInterval: -time.Second},
+		"negative HardEvictTTL":            {HardEvictTTL: -time.Second},
+		"negative MinTouchInterval":        {MinTouchInterval: -time.Second},
+		"negative ReadmissionCooldown":     {ReadmissionCooldown: -time.Second},
+		"negative QuorumThreshold":         {QuorumThreshold: -1},
+		"negative SweepBatchSize":          {SweepBatchSize: -1},
+		"negative SweepWorkers":            {SweepWorkers: -1},
+		"negative TouchHistorySize":        {TouchHistorySize: -1},
+		"HardEvictTTL below TTL":           {HeartbeatTTL: time.Minute, HardEvictTTL: 30 * time.Second},
+		"defaulted HardEvictTTL below TTL": {HeartbeatTTL: 10 * time.Minute},
+		"SweepInterval above TTL":          {HeartbeatTTL: time.Minute, SweepInterval: 2 * time.Minute},
+	}
+	for name, cfg := range invalid {
+		t.Run(name, func(t *testing.T) {
+			err := cfg.Validate()
+			require.Error(t, err)
+			assert.ErrorIs(t, err, ErrInvalidPresenceConfig)
+		})
+	}
+}
+
+func TestPresenceManager_HealthyRatio(t *testing.T) {
+	clock := presencetest.NewManualClock(time.Now())
+	pm := NewPresenceManagerWithClock(nil, PresenceManagerConfig{HeartbeatTTL: 5 * time.Second, HardEvictTTL: 10 * time.Second}, clock)
+
+	assert.Equal(t, 1.0, pm.HealthyRatio(), "an empty fleet is healthy")
+
+	nodes := []string{"node-a", "node-b", "node-c", "node-d"}
+	for _, nodeID := range nodes {
+		pm.Touch(nodeID, clock.Now())
+	}
+	assert.Equal(t, 1.0, pm.HealthyRatio())
+
+	// Half the fleet keeps heartbeating while the other half goes silent until
+	// it is hard-evicted.
+	for i := 0; i < 3; i++ {
+		clock.Advance(4 * time.Second)
+		pm.Touch("node-a", clock.Now())
+		pm.Touch("node-b", clock.Now())
+		pm.checkExpirations()
+	}
+	state, _ := pm.LeaseState("node-c")
+	require.Equal(t, LeaseEvicted, state)
+	assert.InDelta(t, 0.5, pm.HealthyRatio(), 0.001)
+
+	// A returning node raises the ratio again; a forgotten one leaves the fleet.
+	pm.Touch("node-c", clock.Now())
+	assert.InDelta(t, 0.75, pm.HealthyRatio(), 0.001)
+	pm.Forget("node-d")
+	assert.Equal(t, 1.0, pm.HealthyRatio())
+
+	t.Run("ExpectedNodes", func(t *testing.T) {
+		pm := NewPresenceManager(nil, PresenceManagerConfig{ExpectedNodes: 10})
+		for i := 0; i < 4; i++ {
+			pm.Touch(fmt.Sprintf("node-%d", i), time.Now())
+		}
+		assert.InDelta(t, 0.4, pm.HealthyRatio(), 0.001)
+	})
+}
+
+func TestPresenceManager_EvictionLogging(t *testing.T) {
+	var logs bytes.Buffer
+	evictionLogger := zerolog.New(&logs)
+	start := time.Now()
+	clock := presencetest.NewManualClock(start)
+	pm := NewPresenceManagerWithClock(nil, PresenceManagerConfig{
+		HeartbeatTTL:   5 * time.Second,
+		HardEvictTTL:   10 * time.Second,
+		EvictionLogger: &evictionLogger,
+	}, clock)
+
+	pm.Touch("node-silent", start)
+	pm.SetLabels("node-silent", map[string]string{"team": "payments"})
+
+	clock.Advance(6 * time.Second)
+	pm.checkExpirations()
+	assert.Empty(t, logs.String(), "expiry alone is not an eviction")
+
+	clock.Advance(6 * time.Second)
+	pm.checkExpirations()
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(logs.Bytes(), &entry), logs.String())
+	assert.Equal(t, "warn", entry["level"])
+	assert.Equal(t, "node-silent", entry["node_id"])
+	assert.Equal(t, string(EvictionReasonHardEvicted), entry["reason"])
+	assert.Equal(t, float64((12 * time.Second).Milliseconds()), entry["lease_age"])
+	assert.Equal(t, map[string]any{"team": "payments"}, entry["labels"])
+
+	t.Run("no logger", func(t *testing.T) {
+		pm := NewPresenceManager(nil, PresenceManagerConfig{HeartbeatTTL: time.Second, HardEvictTTL: time.Second})
+		pm.Touch("node-1", time.Now())
+		assert.Equal(t, 1, pm.ForgetMatching(func(string, LeaseInfo) bool { return true }))
+	})
+}
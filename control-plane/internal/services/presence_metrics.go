@@ -0,0 +1,152 @@
+package services
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// PresenceMetrics reports contention on the presence lock and the cost of
+// expiry sweeps. It is only populated when PresenceManagerConfig.Instrument
+// is set.
+type PresenceMetrics struct {
+	LockAcquisitions uint64        `json:"lock_acquisitions"`
+	LockWaitTotal    time.Duration `json:"lock_wait_total"`
+	LockWaitMax      time.Duration `json:"lock_wait_max"`
+	Sweeps           uint64        `json:"sweeps"`
+	SweepTotal       time.Duration `json:"sweep_total"`
+	SweepMax         time.Duration `json:"sweep_max"`
+	LastSweep        time.Duration `json:"last_sweep"`
+}
+
+// presenceInstruments accumulates PresenceMetrics. Readers record their wait
+// concurrently, so every field is atomic.
+type presenceInstruments struct {
+	lockAcquisitions atomic.Uint64
+	lockWaitTotal    atomic.Int64
+	lockWaitMax      atomic.Int64
+	sweeps           atomic.Uint64
+	sweepTotal       atomic.Int64
+	sweepMax         atomic.Int64
+	lastSweep        atomic.Int64
+}
+
+func storeMax(v *atomic.Int64, n int64) {
+	for {
+		current := v.Load()
+		if n <= current || v.CompareAndSwap(current, n) {
+			return
+		}
+	}
+}
+
+func (in *presenceInstruments) recordLockWait(wait time.Duration) {
+	in.lockAcquisitions.Add(1)
+	in.lockWaitTotal.Add(int64(wait))
+	storeMax(&in.lockWaitMax, int64(wait))
+}
+
+func (in *presenceInstruments) recordSweep(took time.Duration) {
+	in.sweeps.Add(1)
+	in.sweepTotal.Add(int64(took))
+	storeMax(&in.sweepMax, int64(took))
+	in.lastSweep.Store(int64(took))
+}
+
+// lock takes the write lock, timing the wait when instrumented.
+func (pm *PresenceManager) lock() {
+	if pm.instruments == nil {
+		pm.mu.Lock()
+		return
+	}
+	began := time.Now()
+	pm.mu.Lock()
+	pm.instruments.recordLockWait(time.Since(began))
+}
+
+// rlock takes the read lock, timing the wait when instrumented.
+func (pm *PresenceManager) rlock() {
+	if pm.instruments == nil {
+		pm.mu.RLock()
+		return
+	}
+	began := time.Now()
+	pm.mu.RLock()
+	pm.instruments.recordLockWait(time.Since(began))
+}
+
+// Metrics returns lock wait and sweep duration totals since the manager was
+// created. It returns the zero PresenceMetrics unless Instrument is set.
+// Durations are wall-clock time, independent of the manager's clock.
+func (pm *PresenceManager) Metrics() PresenceMetrics {
+	in := pm.instruments
+	if in == nil {
+		return PresenceMetrics{}
+	}
+	return PresenceMetrics{
+		LockAcquisitions: in.lockAcquisitions.Load(),
+		LockWaitTotal:    time.Duration(in.lockWaitTotal.Load()),
+		LockWaitMax:      time.Duration(in.lockWaitMax.Load()),
+		Sweeps:           in.sweeps.Load(),
+		SweepTotal:       time.Duration(in.sweepTotal.Load()),
+		SweepMax:         time.Duration(in.sweepMax.Load()),
+		LastSweep:        time.Duration(in.lastSweep.Load()),
+	}
+}
+
+// DefaultLeaseAgeBuckets are the lease age histogram bounds, in seconds, used
+// when PresenceManagerConfig.LeaseAgeBuckets is nil.
+var DefaultLeaseAgeBuckets = []float64{1, 5, 15, 30, 60, 120, 300, 600, 1800}
+
+var leaseAgeDesc = prometheus.NewDesc(
+	"agentfield_presence_lease_age_seconds",
+	"Time since each held lease was last touched, observed at scrape time.",
+	nil, nil,
+)
+
+// presenceLeaseAgeCollector builds the lease age histogram from the lease map
+// on every scrape, so it always reflects current ages rather than ages at the
+// last touch.
+type presenceLeaseAgeCollector struct {
+	pm *PresenceManager
+}
+
+// LeaseAgeCollector returns a Prometheus collector exporting a histogram of
+// how long ago each held lease was last touched, measured on the manager's
+// clock. Imported remote leases are not included. Register it once per
+// registry to expose it on /metrics.
+func (pm *PresenceManager) LeaseAgeCollector() prometheus.Collector {
+	return presenceLeaseAgeCollector{pm: pm}
+}
+
+func (c presenceLeaseAgeCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- leaseAgeDesc
+}
+
+func (c presenceLeaseAgeCollector) Collect(ch chan<- prometheus.Metric) {
+	bounds := c.pm.config.LeaseAgeBuckets
+	buckets := make(map[float64]uint64, len(bounds))
+	for _, bound := range bounds {
+		buckets[bound] = 0
+	}
+
+	var count uint64
+	var sum float64
+	c.pm.rlock()
+	now := c.pm.clock.Now()
+	for _, lease := range c.pm.leases {
+		age := max(now.Sub(lease.LastSeen).Seconds(), 0)
+		count++
+		sum += age
+		// Prometheus buckets are cumulative.
+		for _, bound := range bounds {
+			if age <= bound {
+				buckets[bound]++
+			}
+		}
+	}
+	c.pm.mu.RUnlock()
+
+	ch <- prometheus.MustNewConstHistogram(leaseAgeDesc, count, sum, buckets)
+}
@@ -0,0 +1,142 @@
+package services
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Agent-Field/agentfield/control-plane/internal/services/presencetest"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPresenceManager_Metrics(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		pm := NewPresenceManager(nil, PresenceManagerConfig{HeartbeatTTL: time.Minute})
+		pm.Touch("node-1", time.Now())
+		pm.checkExpirations()
+		assert.Equal(t, PresenceMetrics{}, pm.Metrics())
+	})
+
+	t.Run("populated after activity", func(t *testing.T) {
+		pm := NewPresenceManager(nil, PresenceManagerConfig{HeartbeatTTL: time.Minute, Instrument: true})
+
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					pm.Touch(fmt.Sprintf("node-%d", i), time.Now())
+					pm.HasLease(fmt.Sprintf("node-%d", j%8))
+				}
+			}(i)
+		}
+		wg.Wait()
+		pm.checkExpirations()
+		pm.checkExpirations()
+
+		metrics := pm.Metrics()
+		require.GreaterOrEqual(t, metrics.LockAcquisitions, uint64(8*100*2+2))
+		assert.GreaterOrEqual(t, metrics.LockWaitTotal, metrics.LockWaitMax)
+		assert.Equal(t, uint64(2), metrics.Sweeps)
+		assert.Positive(t, metrics.SweepTotal)
+		assert.GreaterOrEqual(t, metrics.SweepMax, metrics.LastSweep)
+		assert.GreaterOrEqual(t, metrics.SweepTotal, metrics.SweepMax)
+	})
+}
+
+var presenceBenchFleetSizes = []int{1_000, 10_000, 100_000}
+
+// BenchmarkPresenceManager_Touch measures parallel touches against fleets of
+// various sizes and reports the mean presence lock wait per acquisition.
+func BenchmarkPresenceManager_Touch(b *testing.B) {
+	for _, fleet := range presenceBenchFleetSizes {
+		b.Run(fmt.Sprintf("fleet=%d", fleet), func(b *testing.B) {
+			pm := NewPresenceManager(nil, PresenceManagerConfig{HeartbeatTTL: time.Hour, Instrument: true})
+			nodeIDs := make([]string, fleet)
+			now := time.Now()
+			for i := range nodeIDs {
+				nodeIDs[i] = fmt.Sprintf("node-%d", i)
+				pm.Touch(nodeIDs[i], now)
+			}
+			before := pm.Metrics()
+
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				i := 0
+				for pb.Next() {
+					pm.Touch(nodeIDs[i%fleet], now)
+					i++
+				}
+			})
+			b.StopTimer()
+
+			after := pm.Metrics()
+			if acquired := after.LockAcquisitions - before.LockAcquisitions; acquired > 0 {
+				wait := after.LockWaitTotal - before.LockWaitTotal
+				b.ReportMetric(float64(wait.Nanoseconds())/float64(acquired), "wait-ns/lock")
+			}
+		})
+	}
+}
+
+// BenchmarkPresenceManager_Sweep measures a full expiry sweep over fleets of
+// various sizes in which no lease has expired.
+func BenchmarkPresenceManager_Sweep(b *testing.B) {
+	for _, fleet := range presenceBenchFleetSizes {
+		b.Run(fmt.Sprintf("fleet=%d", fleet), func(b *testing.B) {
+			pm := NewPresenceManager(nil, PresenceManagerConfig{HeartbeatTTL: time.Hour, Instrument: true})
+			now := time.Now()
+			for i := 0; i < fleet; i++ {
+				pm.Touch(fmt.Sprintf("node-%d", i), now)
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				pm.checkExpirations()
+			}
+			b.StopTimer()
+
+			b.ReportMetric(float64(pm.Metrics().SweepMax.Microseconds()), "max-µs/sweep")
+		})
+	}
+}
+
+func TestPresenceManager_LeaseAgeCollector(t *testing.T) {
+	clock := presencetest.NewManualClock(time.Now())
+	pm := NewPresenceManagerWithClock(nil, PresenceManagerConfig{
+		HeartbeatTTL:    time.Minute,
+		LeaseAgeBuckets: []float64{5, 10, 30},
+	}, clock)
+
+	pm.Touch("node-old", clock.Now())
+	clock.Advance(3 * time.Second)
+	pm.Touch("node-new", clock.Now())
+	clock.Advance(4 * time.Second) // node-old is 7s old, node-new 4s
+
+	registry := prometheus.NewPedanticRegistry()
+	require.NoError(t, registry.Register(pm.LeaseAgeCollector()))
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	require.Len(t, families, 1)
+	require.Equal(t, "agentfield_presence_lease_age_seconds", families[0].GetName())
+
+	histogram := families[0].GetMetric()[0].GetHistogram()
+	assert.Equal(t, uint64(2), histogram.GetSampleCount())
+	assert.InDelta(t, 11.0, histogram.GetSampleSum(), 1e-9)
+
+	cumulative := map[float64]uint64{}
+	for _, bucket := range histogram.GetBucket() {
+		cumulative[bucket.GetUpperBound()] = bucket.GetCumulativeCount()
+	}
+	assert.Equal(t, map[float64]uint64{5: 1, 10: 2, 30: 2}, cumulative)
+}
+
+func TestPresenceManagerConfig_ValidateLeaseAgeBuckets(t *testing.T) {
+	err := PresenceManagerConfig{LeaseAgeBuckets: []float64{10, 5}}.Validate()
+	require.ErrorIs(t, err, ErrInvalidPresenceConfig)
+	require.NoError(t, PresenceManagerConfig{LeaseAgeBuckets: []float64{5, 10}}.Validate())
+}
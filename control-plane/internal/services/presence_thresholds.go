@@ -0,0 +1,78 @@
+package services
+
+import "time"
+
+// ThresholdKind names the fleet-size limit a ThresholdAlert is about.
+type ThresholdKind string
+
+const (
+	// ThresholdMinPresent fires when fewer than the floor of nodes are present.
+	ThresholdMinPresent ThresholdKind = "min_present"
+	// ThresholdMaxOffline fires when more than the ceiling of nodes are offline.
+	ThresholdMaxOffline ThresholdKind = "max_offline"
+)
+
+// ThresholdAlert reports that a SetThresholdAlert limit was crossed, or that
+// the fleet recovered from a previous crossing.
+type ThresholdAlert struct {
+	Kind      ThresholdKind `json:"kind"`
+	Breached  bool          `json:"breached"` // false for a recovery
+	Threshold int           `json:"threshold"`
+	Present   int           `json:"present"`
+	Offline   int           `json:"offline"`
+	At        time.Time     `json:"at"`
+}
+
+// thresholdAlert is the SetThresholdAlert registration and the breach state
+// it debounces against.
+type thresholdAlert struct {
+	minPresent      int
+	maxOffline      int
+	fn              func(ThresholdAlert)
+	presentBreached bool
+	offlineBreached bool
+}
+
+// SetThresholdAlert calls fn at the end of a sweep when the number of present
+// nodes (leases not marked offline) drops below minPresent, or the number of
+// offline leases rises above maxOffline. It fires once per crossing, not on
+// every sweep while a limit stays breached, and again with Breached false once
+// the fleet is back within the limit. A limit of zero or less is not checked.
+// fn runs on the sweep goroutine, so it should not block. Calling it again
+// replaces the previous alert and resets its state; a nil fn removes it.
+func (pm *PresenceManager) SetThresholdAlert(minPresent, maxOffline int, fn func(alert ThresholdAlert)) {
+	pm.lock()
+	defer pm.mu.Unlock()
+
+	if fn == nil {
+		pm.thresholds = nil
+		return
+	}
+	pm.thresholds = &thresholdAlert{minPresent: minPresent, maxOffline: maxOffline, fn: fn}
+}
+
+// checkThresholdsLocked returns the alerts for limits crossed since the last
+// sweep, along with the callback to deliver them to.
+func (pm *PresenceManager) checkThresholdsLocked(now time.Time) ([]ThresholdAlert, func(ThresholdAlert)) {
+	t := pm.thresholds
+	if t == nil {
+		return nil, nil
+	}
+	present := len(pm.leases) - pm.offline
+	offline := pm.offline
+
+	var alerts []ThresholdAlert
+	if t.minPresent > 0 {
+		if breached := present < t.minPresent; breached != t.presentBreached {
+			t.presentBreached = breached
+			alerts = append(alerts, ThresholdAlert{Kind: ThresholdMinPresent, Breached: breached, Threshold: t.minPresent, Present: present, Offline: offline, At: now})
+		}
+	}
+	if t.maxOffline > 0 {
+		if breached := offline > t.maxOffline; breached != t.offlineBreached {
+			t.offlineBreached = breached
+			alerts = append(alerts, ThresholdAlert{Kind: ThresholdMaxOffline, Breached: breached, Threshold: t.maxOffline, Present: present, Offline: offline, At: now})
+		}
+	}
+	return alerts, t.fn
+}
@@ -0,0 +1,80 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Agent-Field/agentfield/control-plane/internal/services/presencetest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPresenceManager_ThresholdAlert(t *testing.T) {
+	clock := presencetest.NewManualClock(time.Now())
+	pm := NewPresenceManagerWithClock(nil, PresenceManagerConfig{HeartbeatTTL: 5 * time.Second, HardEvictTTL: time.Hour}, clock)
+
+	var alerts []ThresholdAlert
+	pm.SetThresholdAlert(4, 1, func(alert ThresholdAlert) { alerts = append(alerts, alert) })
+
+	nodes := []string{"node-a", "node-b", "node-c", "node-d"}
+	touch := func(ids ...string) {
+		for _, id := range ids {
+			pm.Touch(id, clock.Now())
+		}
+	}
+	touch(nodes...)
+	pm.checkExpirations()
+	assert.Empty(t, alerts, "a healthy fleet raises nothing")
+
+	// Two nodes go silent, breaching both limits.
+	clock.Advance(4 * time.Second)
+	touch("node-a", "node-b")
+	clock.Advance(2 * time.Second)
+	pm.checkExpirations()
+	require.Len(t, alerts, 2)
+	assert.Equal(t, ThresholdAlert{Kind: ThresholdMinPresent, Breached: true, Threshold: 4, Present: 2, Offline: 2, At: clock.Now()}, alerts[0])
+	assert.Equal(t, ThresholdAlert{Kind: ThresholdMaxOffline, Breached: true, Threshold: 1, Present: 2, Offline: 2, At: clock.Now()}, alerts[1])
+
+	// Staying breached does not fire again.
+	for i := 0; i < 3; i++ {
+		clock.Advance(time.Second)
+		touch("node-a", "node-b")
+		pm.checkExpirations()
+	}
+	assert.Len(t, alerts, 2)
+
+	// One node returns: the ceiling recovers while the floor stays breached.
+	touch("node-a", "node-b", "node-c")
+	pm.checkExpirations()
+	require.Len(t, alerts, 3)
+	assert.Equal(t, ThresholdMaxOffline, alerts[2].Kind)
+	assert.False(t, alerts[2].Breached)
+	assert.Equal(t, 1, alerts[2].Offline)
+
+	touch("node-d")
+	pm.checkExpirations()
+	require.Len(t, alerts, 4)
+	assert.Equal(t, ThresholdMinPresent, alerts[3].Kind)
+	assert.False(t, alerts[3].Breached)
+	assert.Equal(t, 4, alerts[3].Present)
+}
+
+func TestPresenceManager_ThresholdAlertDisabledLimits(t *testing.T) {
+	clock := presencetest.NewManualClock(time.Now())
+	pm := NewPresenceManagerWithClock(nil, PresenceManagerConfig{HeartbeatTTL: 5 * time.Second, HardEvictTTL: time.Hour}, clock)
+
+	fired := 0
+	pm.SetThresholdAlert(0, 0, func(ThresholdAlert) { fired++ })
+	for i := 0; i < 3; i++ {
+		pm.Touch(fmt.Sprintf("node-%d", i), clock.Now())
+	}
+	clock.Advance(10 * time.Second)
+	pm.checkExpirations()
+	assert.Zero(t, fired)
+
+	pm.SetThresholdAlert(5, 0, nil)
+	pm.checkExpirations()
+	assert.Zero(t, fired, "a nil fn removes the alert")
+}
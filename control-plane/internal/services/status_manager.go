@@ -3,6 +3,7 @@ package services
 import (
 	"context"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
@@ -18,6 +19,51 @@ type StatusManagerConfig struct {
 	ReconcileInterval time.Duration // How often to reconcile status
 	StatusCacheTTL    time.Duration // How long to cache status
 	MaxTransitionTime time.Duration // Max time for state transitions
+
+	// Flap detection: a node with more than FlapThreshold state transitions
+	// within FlapWindow is reported as flapping. Zero threshold disables it.
+	FlapThreshold int
+	FlapWindow    time.Duration
+
+	// OfflineConfirmPasses is how many consecutive reconciliation passes must
+	// find a node's heartbeat stale before it is marked offline. A fresh
+	// heartbeat in between cancels the pending transition. Values below two
+	// mark a node offline on the first stale pass.
+	OfflineConfirmPasses int
+
+	// ReconcilePriority decides which source reconciliation believes when a
+	// node's stored heartbeat and its presence lease disagree about whether
+	// it is up. It only matters once SetReconcilePresence has been called.
+	// The zero value is ReconcileStorageWins.
+	ReconcilePriority ReconcilePriority
+}
+
+// ReconcilePriority is the precedence reconciliation applies between storage
+// and presence.
+type ReconcilePriority string
+
+const (
+	// ReconcileStorageWins judges nodes by their stored heartbeat alone.
+	ReconcileStorageWins ReconcilePriority = "storage"
+	// ReconcilePresenceWins follows the presence lease for every node
+	// presence knows about, falling back to the stored heartbeat otherwise.
+	ReconcilePresenceWins ReconcilePriority = "presence"
+	// ReconcileNewestWins follows whichever source saw the node last: the
+	// lease's last touch or the stored heartbeat.
+	ReconcileNewestWins ReconcilePriority = "newest"
+)
+
+// reconcileHeartbeatTimeout is how old a stored heartbeat may be before
+// reconciliation considers the node down.
+const reconcileHeartbeatTimeout = 30 * time.Second
+
+// ReconcilePresence is the presence view reconciliation weighs against
+// storage. PresenceManager implements it.
+type ReconcilePresence interface {
+	// PresenceObservation reports whether nodeID holds an active lease and
+	// when presence last saw it. ok is false when presence has no record of
+	// the node.
+	PresenceObservation(nodeID string) (active bool, lastSeen time.Time, ok bool)
 }
 
 // StatusManager provides a single source of truth for agent status
@@ -36,11 +82,50 @@ type StatusManager struct {
 	activeTransitions map[string]*types.StateTransition
 	transitionMutex   sync.RWMutex
 
+	// Flap tracking: recent state transition times per node
+	flapHistory map[string][]time.Time
+	flapMutex   sync.Mutex
+
+	// Status history: last maxStatusHistory state changes per node, oldest first
+	statusHistory map[string][]StatusHistoryEntry
+	historyMutex  sync.RWMutex
+
+	// Cordoned nodes: still present, but should not be given new work
+	cordoned    map[string]bool
+	cordonMutex sync.RWMutex
+
+	// Offline debounce: consecutive stale reconciliation passes per node
+	pendingOffline      map[string]int
+	pendingOfflineMutex sync.Mutex
+
+	// Reconciliation progress, for health reporting
+	startedAt      time.Time
+	lastReconcile  time.Time
+	reconcileMutex sync.RWMutex
+
 	// Control channels
 	stopCh chan struct{}
 
 	// Event handlers
 	eventHandlers []StatusEventHandler
+
+	// presence is consulted by reconciliation per ReconcilePriority; nil
+	// leaves storage authoritative.
+	presence ReconcilePresence
+
+	now func() time.Time
+}
+
+// maxStatusHistory bounds the number of transitions retained per node.
+const maxStatusHistory = 100
+
+// StatusHistoryEntry records a single state change of an agent.
+type StatusHistoryEntry struct {
+	From   types.AgentState   `json:"from"`
+	To     types.AgentState   `json:"to"`
+	At     time.Time          `json:"at"`
+	Source types.StatusSource `json:"source"`
+	Reason string             `json:"reason,omitempty"`
 }
 
 // cachedAgentStatus represents a cached status with timestamp
@@ -91,6 +176,9 @@ func NewStatusManager(storage storage.StorageProvider, config StatusManagerConfi
 	if config.MaxTransitionTime == 0 {
 		config.MaxTransitionTime = 2 * time.Minute
 	}
+	if config.FlapThreshold > 0 && config.FlapWindow == 0 {
+		config.FlapWindow = 5 * time.Minute
+	}
 
 	return &StatusManager{
 		storage:           storage,
@@ -99,8 +187,13 @@ func NewStatusManager(storage storage.StorageProvider, config StatusManagerConfi
 		agentClient:       agentClient,
 		statusCache:       make(map[string]*cachedAgentStatus),
 		activeTransitions: make(map[string]*types.StateTransition),
+		flapHistory:       make(map[string][]time.Time),
+		statusHistory:     make(map[string][]StatusHistoryEntry),
+		cordoned:          make(map[string]bool),
+		pendingOffline:    make(map[string]int),
 		stopCh:            make(chan struct{}),
 		eventHandlers:     make([]StatusEventHandler, 0),
+		now:               time.Now,
 	}
 }
 
@@ -108,6 +201,10 @@ func NewStatusManager(storage storage.StorageProvider, config StatusManagerConfi
 func (sm *StatusManager) Start() {
 	logger.Logger.Debug().Msg("🔄 Starting status manager")
 
+	sm.reconcileMutex.Lock()
+	sm.startedAt = sm.now()
+	sm.reconcileMutex.Unlock()
+
 	// Start reconciliation loop
 	go sm.reconcileLoop()
 
@@ -359,6 +456,16 @@ func (sm *StatusManager) UpdateAgentStatus(ctx context.Context, nodeID string, u
 	}
 	sm.cacheMutex.Unlock()
 
+	if oldStatus.State != newStatus.State {
+		sm.recordHistory(nodeID, StatusHistoryEntry{
+			From:   oldStatus.State,
+			To:     newStatus.State,
+			At:     newStatus.LastUpdated,
+			Source: update.Source,
+			Reason: update.Reason,
+		})
+	}
+
 	// Notify event handlers
 	sm.notifyStatusChanged(nodeID, &oldStatus, &newStatus)
 
@@ -378,6 +485,8 @@ func (sm *StatusManager) UpdateAgentStatus(ctx context.Context, nodeID string, u
 
 // UpdateFromHeartbeat updates status based on heartbeat data
 func (sm *StatusManager) UpdateFromHeartbeat(ctx context.Context, nodeID string, lifecycleStatus *types.AgentLifecycleStatus, mcpStatus *types.MCPStatusInfo) error {
+	sm.clearPendingOffline(nodeID)
+
 	currentStatus, err := sm.GetAgentStatus(ctx, nodeID)
 	if err != nil {
 		// If agent doesn't exist, create new status
@@ -436,6 +545,215 @@ func (sm *StatusManager) RefreshAgentStatus(ctx context.Context, nodeID string)
 	return nil
 }
 
+// ForgetAgentStatus drops cached status and transition tracking for an agent,
+// e.g. after its presence lease has been hard-evicted. Status history is kept
+// so the eviction stays visible, as is an operator cordon, so a node that is
+// evicted and comes back stays cordoned; clear it with SetCordoned when the
+// node is deregistered.
+func (sm *StatusManager) ForgetAgentStatus(nodeID string) {
+	sm.cacheMutex.Lock()
+	delete(sm.statusCache, nodeID)
+	sm.cacheMutex.Unlock()
+
+	sm.transitionMutex.Lock()
+	delete(sm.activeTransitions, nodeID)
+	sm.transitionMutex.Unlock()
+
+	sm.flapMutex.Lock()
+	delete(sm.flapHistory, nodeID)
+	sm.flapMutex.Unlock()
+
+	sm.clearPendingOffline(nodeID)
+}
+
+// AllStatuses returns a copy of the latest known status of every node the
+// manager has observed, keyed by node ID. It reads only the in-memory status
+// cache, so nodes with no status update, snapshot or live check since startup
+// are absent. Callers may modify the returned statuses freely.
+func (sm *StatusManager) AllStatuses() map[string]*types.AgentStatus {
+	sm.cacheMutex.RLock()
+	defer sm.cacheMutex.RUnlock()
+
+	statuses := make(map[string]*types.AgentStatus, len(sm.statusCache))
+	for nodeID, cached := range sm.statusCache {
+		if cached.Status == nil {
+			continue
+		}
+		statuses[nodeID] = cloneAgentStatus(cached.Status)
+	}
+	return statuses
+}
+
+// SetCordoned marks or clears nodeID as cordoned. Cordoning does not change the
+// node's state; it is reported alongside it, and the execution handlers refuse
+// new work for cordoned nodes. Unlike a presence drain it outlives the lease.
+func (sm *StatusManager) SetCordoned(nodeID string, cordoned bool) {
+	sm.cordonMutex.Lock()
+	defer sm.cordonMutex.Unlock()
+
+	if cordoned {
+		sm.cordoned[nodeID] = true
+	} else {
+		delete(sm.cordoned, nodeID)
+	}
+}
+
+// IsCordoned reports whether nodeID has been cordoned.
+func (sm *StatusManager) IsCordoned(nodeID string) bool {
+	sm.cordonMutex.RLock()
+	defer sm.cordonMutex.RUnlock()
+	return sm.cordoned[nodeID]
+}
+
+// StatusHistory returns up to limit of the most recent state changes for nodeID,
+// newest first. A non-positive limit returns the full retained history.
+func (sm *StatusManager) StatusHistory(nodeID string, limit int) []StatusHistoryEntry {
+	sm.historyMutex.RLock()
+	defer sm.historyMutex.RUnlock()
+
+	history := sm.statusHistory[nodeID]
+	if limit <= 0 || limit > len(history) {
+		limit = len(history)
+	}
+	result := make([]StatusHistoryEntry, 0, limit)
+	for i := len(history) - 1; i >= 0 && len(result) < limit; i-- {
+		result = append(result, history[i])
+	}
+	return result
+}
+
+// StatusTimelineEntry is a StatusHistoryEntry tagged with the node it belongs to.
+type StatusTimelineEntry struct {
+	NodeID string `json:"node_id"`
+	StatusHistoryEntry
+}
+
+// StatusTimeline merges the retained history of every node into a single list,
+// oldest first, keeping transitions at or after from and before to. A zero
+// from or to leaves that end of the window open. Transitions at the same
+// instant are ordered by node ID.
+func (sm *StatusManager) StatusTimeline(from, to time.Time) []StatusTimelineEntry {
+	sm.historyMutex.RLock()
+	timeline := make([]StatusTimelineEntry, 0)
+	for nodeID, history := range sm.statusHistory {
+		for _, entry := range history {
+			if (!from.IsZero() && entry.At.Before(from)) || (!to.IsZero() && !entry.At.Before(to)) {
+				continue
+			}
+			timeline = append(timeline, StatusTimelineEntry{NodeID: nodeID, StatusHistoryEntry: entry})
+		}
+	}
+	sm.historyMutex.RUnlock()
+
+	// Stable, so each node's own transitions keep their recorded order.
+	sort.SliceStable(timeline, func(i, j int) bool {
+		if !timeline[i].At.Equal(timeline[j].At) {
+			return timeline[i].At.Before(timeline[j].At)
+		}
+		return timeline[i].NodeID < timeline[j].NodeID
+	})
+	return timeline
+}
+
+func (sm *StatusManager) recordHistory(nodeID string, entry StatusHistoryEntry) {
+	sm.historyMutex.Lock()
+	defer sm.historyMutex.Unlock()
+
+	history := append(sm.statusHistory[nodeID], entry)
+	if len(history) > maxStatusHistory {
+		history = history[len(history)-maxStatusHistory:]
+	}
+	sm.statusHistory[nodeID] = history
+}
+
+// RecordPresenceEviction adds a history entry explaining that nodeID's
+// presence lease was dropped while it was already inactive, where no state
+// change would otherwise record the reason.
+func (sm *StatusManager) RecordPresenceEviction(nodeID string, reason types.StatusReason) {
+	sm.recordHistory(nodeID, StatusHistoryEntry{
+		From:   types.AgentStateInactive,
+		To:     types.AgentStateInactive,
+		At:     sm.now(),
+		Source: types.StatusSourcePresence,
+		Reason: string(reason),
+	})
+}
+
+// FlappingNodes returns the IDs of nodes for which IsFlapping is true.
+func (sm *StatusManager) FlappingNodes() []string {
+	sm.flapMutex.Lock()
+	candidates := make([]string, 0, len(sm.flapHistory))
+	for nodeID := range sm.flapHistory {
+		candidates = append(candidates, nodeID)
+	}
+	sm.flapMutex.Unlock()
+
+	var flapping []string
+	for _, nodeID := range candidates {
+		if sm.IsFlapping(nodeID) {
+			flapping = append(flapping, nodeID)
+		}
+	}
+	return flapping
+}
+
+// IsFlapping reports whether nodeID changed state more than FlapThreshold
+// times within the last FlapWindow. It is always false when flap detection
+// is disabled.
+func (sm *StatusManager) IsFlapping(nodeID string) bool {
+	if sm.config.FlapThreshold <= 0 {
+		return false
+	}
+
+	cutoff := sm.now().Add(-sm.config.FlapWindow)
+	sm.flapMutex.Lock()
+	defer sm.flapMutex.Unlock()
+
+	count := 0
+	for _, at := range sm.flapHistory[nodeID] {
+		if at.After(cutoff) {
+			count++
+		}
+	}
+	return count > sm.config.FlapThreshold
+}
+
+// recordTransition notes a state transition for flap detection.
+func (sm *StatusManager) recordTransition(nodeID string) {
+	if sm.config.FlapThreshold <= 0 {
+		return
+	}
+
+	sm.flapMutex.Lock()
+	sm.flapHistory[nodeID] = append(sm.flapHistory[nodeID], sm.now())
+	sm.flapMutex.Unlock()
+}
+
+// pruneFlapHistory drops transitions that have slid out of the flap window.
+func (sm *StatusManager) pruneFlapHistory() {
+	if sm.config.FlapThreshold <= 0 {
+		return
+	}
+
+	cutoff := sm.now().Add(-sm.config.FlapWindow)
+	sm.flapMutex.Lock()
+	defer sm.flapMutex.Unlock()
+
+	for nodeID, history := range sm.flapHistory {
+		kept := history[:0]
+		for _, at := range history {
+			if at.After(cutoff) {
+				kept = append(kept, at)
+			}
+		}
+		if len(kept) == 0 {
+			delete(sm.flapHistory, nodeID)
+		} else {
+			sm.flapHistory[nodeID] = kept
+		}
+	}
+}
+
 // AddEventHandler adds a status event handler
 func (sm *StatusManager) AddEventHandler(handler StatusEventHandler) {
 	sm.eventHandlers = append(sm.eventHandlers, handler)
@@ -450,6 +768,7 @@ func (sm *StatusManager) handleStateTransition(nodeID string, status *types.Agen
 
 	// Start transition
 	status.StartTransition(newState, reason)
+	sm.recordTransition(nodeID)
 
 	// Track active transition
 	sm.transitionMutex.Lock()
@@ -633,6 +952,9 @@ func (sm *StatusManager) reconcileLoop() {
 func (sm *StatusManager) performReconciliation() {
 	ctx := context.Background()
 
+	// Slide the flap detection window forward
+	sm.pruneFlapHistory()
+
 	// Get all agents
 	agents, err := sm.storage.ListAgents(ctx, types.AgentFilters{})
 	if err != nil {
@@ -643,9 +965,15 @@ func (sm *StatusManager) performReconciliation() {
 	logger.Logger.Debug().Int("agent_count", len(agents)).Msg("🔄 Starting status reconciliation")
 
 	for _, agent := range agents {
+		alive, source := sm.observeAgent(agent)
+		if alive {
+			// The node is back; drop any pending offline transition
+			sm.clearPendingOffline(agent.ID)
+		}
+
 		// Check if status needs reconciliation
-		if sm.needsReconciliation(agent) {
-			if err := sm.reconcileAgentStatus(ctx, agent); err != nil {
+		if sm.needsReconciliation(agent, alive, source) {
+			if err := sm.reconcileAgentStatus(ctx, agent, alive); err != nil {
 				logger.Logger.Error().
 					Err(err).
 					Str("node_id", agent.ID).
@@ -653,13 +981,70 @@ func (sm *StatusManager) performReconciliation() {
 			}
 		}
 	}
+
+	sm.reconcileMutex.Lock()
+	sm.lastReconcile = sm.now()
+	sm.reconcileMutex.Unlock()
+}
+
+// ReconcileInfo describes the progress of the periodic reconciliation loop.
+type ReconcileInfo struct {
+	StartedAt     time.Time     // zero until Start is called
+	LastReconcile time.Time     // zero until a pass has completed
+	Interval      time.Duration // configured ReconcileInterval
+}
+
+// ReconcileInfo reports when the manager started and last finished a
+// reconciliation pass.
+func (sm *StatusManager) ReconcileInfo() ReconcileInfo {
+	sm.reconcileMutex.RLock()
+	defer sm.reconcileMutex.RUnlock()
+	return ReconcileInfo{
+		StartedAt:     sm.startedAt,
+		LastReconcile: sm.lastReconcile,
+		Interval:      sm.config.ReconcileInterval,
+	}
+}
+
+// SetReconcilePresence makes reconciliation weigh presence against storage
+// according to ReconcilePriority. Call it before Start.
+func (sm *StatusManager) SetReconcilePresence(presence ReconcilePresence) {
+	sm.presence = presence
+}
+
+// observeAgent decides whether agent is up, resolving any disagreement
+// between its stored heartbeat and presence by ReconcilePriority. source
+// names the view that decided.
+func (sm *StatusManager) observeAgent(agent *types.AgentNode) (alive bool, source types.StatusSource) {
+	storageAlive := time.Since(agent.LastHeartbeat) <= reconcileHeartbeatTimeout
+	if sm.presence == nil {
+		return storageAlive, types.StatusSourceHeartbeat
+	}
+
+	presenceAlive, lastSeen, ok := sm.presence.PresenceObservation(agent.ID)
+	if !ok || presenceAlive == storageAlive {
+		return storageAlive, types.StatusSourceHeartbeat
+	}
+	switch sm.config.ReconcilePriority {
+	case ReconcilePresenceWins:
+		return presenceAlive, types.StatusSourcePresence
+	case ReconcileNewestWins:
+		if lastSeen.After(agent.LastHeartbeat) {
+			return presenceAlive, types.StatusSourcePresence
+		}
+	}
+	return storageAlive, types.StatusSourceHeartbeat
 }
 
 // needsReconciliation checks if an agent needs status reconciliation
-func (sm *StatusManager) needsReconciliation(agent *types.AgentNode) bool {
-	// Check if last heartbeat is too old
-	timeSinceHeartbeat := time.Since(agent.LastHeartbeat)
-	if timeSinceHeartbeat > 30*time.Second && agent.HealthStatus == types.HealthStatusActive {
+func (sm *StatusManager) needsReconciliation(agent *types.AgentNode, alive bool, source types.StatusSource) bool {
+	// Check if the node is down but still marked active
+	if !alive && agent.HealthStatus == types.HealthStatusActive {
+		return true
+	}
+
+	// Presence overruled storage and says a node marked inactive is up
+	if alive && source == types.StatusSourcePresence && agent.HealthStatus != types.HealthStatusActive {
 		return true
 	}
 
@@ -672,14 +1057,15 @@ func (sm *StatusManager) needsReconciliation(agent *types.AgentNode) bool {
 }
 
 // reconcileAgentStatus reconciles status for a specific agent
-func (sm *StatusManager) reconcileAgentStatus(ctx context.Context, agent *types.AgentNode) error {
-	// Determine correct status based on heartbeat age
-	timeSinceHeartbeat := time.Since(agent.LastHeartbeat)
-
+func (sm *StatusManager) reconcileAgentStatus(ctx context.Context, agent *types.AgentNode, alive bool) error {
 	var newHealthStatus types.HealthStatus
 	var newLifecycleStatus types.AgentLifecycleStatus
 
-	if timeSinceHeartbeat > 30*time.Second {
+	if !alive {
+		if agent.HealthStatus == types.HealthStatusActive && !sm.confirmOffline(agent.ID) {
+			logger.Logger.Debug().Str("node_id", agent.ID).Msg("⏳ Heartbeat stale; waiting for more passes before marking offline")
+			return nil
+		}
 		newHealthStatus = types.HealthStatusInactive
 		newLifecycleStatus = types.AgentStatusOffline
 	} else {
@@ -716,6 +1102,31 @@ func (sm *StatusManager) reconcileAgentStatus(ctx context.Context, agent *types.
 	return nil
 }
 
+// confirmOffline records a stale pass for nodeID and reports whether it has
+// now been stale for OfflineConfirmPasses consecutive passes.
+func (sm *StatusManager) confirmOffline(nodeID string) bool {
+	if sm.config.OfflineConfirmPasses < 2 {
+		return true
+	}
+
+	sm.pendingOfflineMutex.Lock()
+	defer sm.pendingOfflineMutex.Unlock()
+
+	sm.pendingOffline[nodeID]++
+	if sm.pendingOffline[nodeID] < sm.config.OfflineConfirmPasses {
+		return false
+	}
+	delete(sm.pendingOffline, nodeID)
+	return true
+}
+
+// clearPendingOffline cancels a debounced offline transition for nodeID.
+func (sm *StatusManager) clearPendingOffline(nodeID string) {
+	sm.pendingOfflineMutex.Lock()
+	delete(sm.pendingOffline, nodeID)
+	sm.pendingOfflineMutex.Unlock()
+}
+
 // transitionTimeoutLoop checks for stuck transitions
 func (sm *StatusManager) transitionTimeoutLoop() {
 	ticker := time.NewTicker(30 * time.Second)
@@ -487,3 +487,199 @@ func (h *testStatusEventHandler) OnStatusChanged(nodeID string, oldStatus, newSt
 		h.onStatusChanged(nodeID, oldStatus, newStatus)
 	}
 }
+
+func TestStatusManagerDetectsFlapping(t *testing.T) {
+	provider, ctx := setupStatusManagerStorage(t)
+	registerTestAgent(t, provider, ctx, "node-flappy")
+
+	sm := NewStatusManager(provider, StatusManagerConfig{
+		FlapThreshold: 3,
+		FlapWindow:    time.Minute,
+	}, nil, nil)
+	clock := time.Now()
+	sm.now = func() time.Time { return clock }
+
+	toggle := func(state types.AgentState) {
+		require.NoError(t, sm.UpdateAgentStatus(ctx, "node-flappy", &types.AgentStatusUpdate{
+			State:  ptrAgentState(state),
+			Source: types.StatusSourceHeartbeat,
+		}))
+		clock = clock.Add(5 * time.Second)
+	}
+
+	toggle(types.AgentStateActive)
+	toggle(types.AgentStateInactive)
+	toggle(types.AgentStateActive)
+	require.False(t, sm.IsFlapping("node-flappy"), "three transitions should not exceed a threshold of three")
+
+	toggle(types.AgentStateInactive)
+	require.True(t, sm.IsFlapping("node-flappy"), "alternating online/offline within the window should trip flapping")
+
+	// Once the transitions slide out of the window the node is stable again.
+	clock = clock.Add(time.Minute)
+	sm.pruneFlapHistory()
+	require.False(t, sm.IsFlapping("node-flappy"))
+	require.False(t, sm.IsFlapping("node-other"))
+}
+
+func TestStatusManagerAllStatuses(t *testing.T) {
+	provider, ctx := setupStatusManagerStorage(t)
+
+	for _, nodeID := range []string{"node-live", "node-stale"} {
+		require.NoError(t, provider.RegisterAgent(ctx, &types.AgentNode{
+			ID:              nodeID,
+			TeamID:          "team",
+			BaseURL:         "http://localhost",
+			Version:         "1.0.0",
+			HealthStatus:    types.HealthStatusActive,
+			LifecycleStatus: types.AgentStatusReady,
+			LastHeartbeat:   time.Now(),
+			Reasoners:       []types.ReasonerDefinition{},
+			Skills:          []types.SkillDefinition{},
+		}))
+	}
+
+	sm := NewStatusManager(provider, StatusManagerConfig{}, nil, nil)
+	require.Empty(t, sm.AllStatuses())
+
+	require.NoError(t, provider.UpdateAgentHeartbeat(ctx, "node-stale", time.Now().Add(-time.Minute)))
+	sm.performReconciliation()
+	require.NoError(t, sm.UpdateFromHeartbeat(ctx, "node-live", nil, nil))
+
+	statuses := sm.AllStatuses()
+	require.Len(t, statuses, 2)
+	require.Equal(t, types.AgentStateInactive, statuses["node-stale"].State)
+	require.Equal(t, types.AgentStateActive, statuses["node-live"].State)
+
+	// The snapshot is a copy.
+	statuses["node-live"].State = types.AgentStateStopping
+	require.Equal(t, types.AgentStateActive, sm.AllStatuses()["node-live"].State)
+}
+
+func TestStatusManagerOfflineConfirmPasses(t *testing.T) {
+	provider, ctx := setupStatusManagerStorage(t)
+
+	const nodeID = "node-debounce"
+	require.NoError(t, provider.RegisterAgent(ctx, &types.AgentNode{
+		ID:              nodeID,
+		TeamID:          "team",
+		BaseURL:         "http://localhost",
+		Version:         "1.0.0",
+		HealthStatus:    types.HealthStatusActive,
+		LifecycleStatus: types.AgentStatusReady,
+		LastHeartbeat:   time.Now(),
+		Reasoners:       []types.ReasonerDefinition{},
+		Skills:          []types.SkillDefinition{},
+	}))
+
+	sm := NewStatusManager(provider, StatusManagerConfig{OfflineConfirmPasses: 3}, nil, nil)
+
+	health := func() types.HealthStatus {
+		t.Helper()
+		agent, err := provider.GetAgent(ctx, nodeID)
+		require.NoError(t, err)
+		return agent.HealthStatus
+	}
+	stale := func() {
+		require.NoError(t, provider.UpdateAgentHeartbeat(ctx, nodeID, time.Now().Add(-time.Minute)))
+	}
+
+	// A brief absence: two stale passes, then the node heartbeats again.
+	stale()
+	sm.performReconciliation()
+	sm.performReconciliation()
+	require.Equal(t, types.HealthStatusActive, health(), "node must stay active within the debounce window")
+
+	require.NoError(t, provider.UpdateAgentHeartbeat(ctx, nodeID, time.Now()))
+	sm.performReconciliation()
+
+	// The returning heartbeat reset the count, so two more stale passes are
+	// still not enough.
+	stale()
+	sm.performReconciliation()
+	sm.performReconciliation()
+	require.Equal(t, types.HealthStatusActive, health())
+
+	// A sustained absence flips the node on the third consecutive pass.
+	sm.performReconciliation()
+	require.Equal(t, types.HealthStatusInactive, health())
+}
+
+type fakeReconcilePresence struct {
+	active   bool
+	lastSeen time.Time
+}
+
+func (f fakeReconcilePresence) PresenceObservation(string) (bool, time.Time, bool) {
+	return f.active, f.lastSeen, true
+}
+
+func TestStatusManagerReconcilePriority(t *testing.T) {
+	now := time.Now()
+	conflicts := []struct {
+		name      string
+		stored    types.HealthStatus
+		heartbeat time.Time
+		presence  fakeReconcilePresence
+		want      map[ReconcilePriority]types.HealthStatus
+	}{
+		{
+			// Storage last heard from the node a minute ago; presence saw it just now.
+			name:      "stale storage, live presence",
+			stored:    types.HealthStatusInactive,
+			heartbeat: now.Add(-time.Minute),
+			presence:  fakeReconcilePresence{active: true, lastSeen: now},
+			want: map[ReconcilePriority]types.HealthStatus{
+				ReconcileStorageWins:  types.HealthStatusInactive,
+				ReconcilePresenceWins: types.HealthStatusActive,
+				ReconcileNewestWins:   types.HealthStatusActive,
+			},
+		},
+		{
+			// Storage's heartbeat is still fresh, but presence has since
+			// expired the lease after a later touch.
+			name:      "fresh storage, expired presence",
+			stored:    types.HealthStatusActive,
+			heartbeat: now.Add(-20 * time.Second),
+			presence:  fakeReconcilePresence{active: false, lastSeen: now.Add(-5 * time.Second)},
+			want: map[ReconcilePriority]types.HealthStatus{
+				ReconcileStorageWins:  types.HealthStatusActive,
+				ReconcilePresenceWins: types.HealthStatusInactive,
+				ReconcileNewestWins:   types.HealthStatusInactive,
+			},
+		},
+	}
+
+	for _, conflict := range conflicts {
+		for _, priority := range []ReconcilePriority{ReconcileStorageWins, ReconcilePresenceWins, ReconcileNewestWins} {
+			t.Run(conflict.name+"/"+string(priority), func(t *testing.T) {
+				provider, ctx := setupStatusManagerStorage(t)
+
+				const nodeID = "node-conflict"
+				lifecycle := types.AgentStatusReady
+				if conflict.stored == types.HealthStatusInactive {
+					lifecycle = types.AgentStatusOffline
+				}
+				require.NoError(t, provider.RegisterAgent(ctx, &types.AgentNode{
+					ID:              nodeID,
+					TeamID:          "team",
+					BaseURL:         "http://localhost",
+					Version:         "1.0.0",
+					HealthStatus:    conflict.stored,
+					LifecycleStatus: lifecycle,
+					LastHeartbeat:   conflict.heartbeat,
+					Reasoners:       []types.ReasonerDefinition{},
+					Skills:          []types.SkillDefinition{},
+				}))
+
+				sm := NewStatusManager(provider, StatusManagerConfig{ReconcilePriority: priority}, nil, nil)
+				sm.SetReconcilePresence(conflict.presence)
+				sm.performReconciliation()
+
+				agent, err := provider.GetAgent(ctx, nodeID)
+				require.NoError(t, err)
+				require.Equal(t, conflict.want[priority], agent.HealthStatus)
+			})
+		}
+	}
+}
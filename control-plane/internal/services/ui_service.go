@@ -176,7 +176,8 @@ func (s *UIService) getReconciledNodeStatus(nodeID string, node *types.AgentNode
 // NodeDetailsWithPackageInfo represents node details enhanced with package information
 type NodeDetailsWithPackageInfo struct {
 	*types.AgentNode
-	PackageInfo *PackageInfo `json:"package_info,omitempty"`
+	PackageInfo   *PackageInfo         `json:"package_info,omitempty"`
+	StatusHistory []StatusHistoryEntry `json:"status_history"`
 }
 
 // PackageInfo represents package information for the node details response
@@ -186,12 +187,37 @@ type PackageInfo struct {
 	Status    string `json:"status"`
 }
 
+// GetAgentsChangedSince returns the agents whose registration, health or lifecycle
+// state changed after since, allowing the UI to refresh incrementally.
+func (s *UIService) GetAgentsChangedSince(ctx context.Context, since time.Time) ([]*types.AgentNode, error) {
+	return s.storage.ListAgents(ctx, types.AgentFilters{UpdatedSince: &since})
+}
+
 // GetNodeDetails retrieves full details for a specific node.
 // For now, it's the same as storage.GetAgent, but can be optimized later.
 func (s *UIService) GetNodeDetails(ctx context.Context, nodeID string) (*types.AgentNode, error) {
 	return s.storage.GetAgent(ctx, nodeID)
 }
 
+// GetNodeStatusHistory returns up to limit recent state changes for a node,
+// newest first. It returns an empty slice when no status manager is configured.
+func (s *UIService) GetNodeStatusHistory(nodeID string, limit int) []StatusHistoryEntry {
+	if s.statusManager == nil {
+		return []StatusHistoryEntry{}
+	}
+	return s.statusManager.StatusHistory(nodeID, limit)
+}
+
+// GetFleetStatusTimeline returns the state changes of every node between from
+// and to, oldest first. It returns an empty slice when no status manager is
+// configured.
+func (s *UIService) GetFleetStatusTimeline(from, to time.Time) []StatusTimelineEntry {
+	if s.statusManager == nil {
+		return []StatusTimelineEntry{}
+	}
+	return s.statusManager.StatusTimeline(from, to)
+}
+
 // GetNodeDetailsWithPackageInfo retrieves full details for a specific node including package information.
 func (s *UIService) GetNodeDetailsWithPackageInfo(ctx context.Context, nodeID string) (*NodeDetailsWithPackageInfo, error) {
 	// Get base node details
@@ -202,7 +228,8 @@ func (s *UIService) GetNodeDetailsWithPackageInfo(ctx context.Context, nodeID st
 
 	// Create response with node details
 	response := &NodeDetailsWithPackageInfo{
-		AgentNode: node,
+		AgentNode:     node,
+		StatusHistory: []StatusHistoryEntry{},
 	}
 
 	// Find the package that corresponds to this node by searching through package configurations
@@ -18,6 +18,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/Agent-Field/agentfield/control-plane/internal/core/interfaces"
 	"github.com/Agent-Field/agentfield/control-plane/internal/events"
 	"github.com/Agent-Field/agentfield/control-plane/pkg/types"
 
@@ -4093,7 +4094,7 @@ func (ls *LocalStorage) RegisterAgent(ctx context.Context, agent *types.AgentNod
 	defer rollbackTx(tx, "RegisterAgent:"+agent.ID)
 
 	// Execute the agent registration using the transaction
-	if err := ls.executeRegisterAgent(ctx, tx, agent); err != nil {
+	if err := ls.executeRegisterAgent(ctx, tx, agent, true); err != nil {
 		return err
 	}
 
@@ -4105,14 +4106,50 @@ func (ls *LocalStorage) RegisterAgent(ctx context.Context, agent *types.AgentNod
 	return nil
 }
 
-// executeRegisterAgent performs the actual agent registration using DBTX interface
-func (ls *LocalStorage) executeRegisterAgent(ctx context.Context, q DBTX, agent *types.AgentNode) error {
+// CreateAgent stores a new agent node record in SQLite. Unlike RegisterAgent it
+// never overwrites an existing node; a taken ID yields interfaces.ErrAgentNodeExists.
+func (ls *LocalStorage) CreateAgent(ctx context.Context, agent *types.AgentNode) error {
+	// Check context cancellation early
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("context cancelled during create agent: %w", err)
+	}
+
+	if strings.TrimSpace(agent.DeploymentType) == "" {
+		agent.DeploymentType = "long_running"
+	}
+
+	if err := ls.executeRegisterAgent(ctx, ls.db, agent, false); err != nil {
+		if isUniqueViolation(err) {
+			return fmt.Errorf("%w: %s", interfaces.ErrAgentNodeExists, agent.ID)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// isUniqueViolation reports whether err is a primary key or unique constraint
+// violation from either SQLite or PostgreSQL.
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == "23505" // unique_violation
+	}
+
+	return strings.Contains(err.Error(), "UNIQUE constraint failed")
+}
+
+// executeRegisterAgent performs the actual agent registration using DBTX interface.
+// When upsert is false an existing ID is a constraint violation rather than an update.
+func (ls *LocalStorage) executeRegisterAgent(ctx context.Context, q DBTX, agent *types.AgentNode, upsert bool) error {
 	query := `
 		INSERT INTO agent_nodes (
 			id, team_id, base_url, version, deployment_type, invocation_url, reasoners, skills,
 			communication_config, health_status, lifecycle_status, last_heartbeat,
-			registered_at, features, metadata
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			registered_at, updated_at, features, metadata
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	if upsert {
+		query += `
 		ON CONFLICT(id) DO UPDATE SET
 			team_id = excluded.team_id,
 			base_url = excluded.base_url,
@@ -4125,8 +4162,13 @@ func (ls *LocalStorage) executeRegisterAgent(ctx context.Context, q DBTX, agent
 			health_status = excluded.health_status,
 			lifecycle_status = excluded.lifecycle_status,
 			last_heartbeat = excluded.last_heartbeat,
+			updated_at = excluded.updated_at,
 			features = excluded.features,
-			metadata = excluded.metadata;`
+			metadata = excluded.metadata`
+	}
+
+	// Every (re-)registration counts as a state change for delta listings.
+	agent.UpdatedAt = time.Now().UTC()
 
 	reasonersJSON, err := json.Marshal(agent.Reasoners)
 	if err != nil {
@@ -4152,7 +4194,7 @@ func (ls *LocalStorage) executeRegisterAgent(ctx context.Context, q DBTX, agent
 	_, err = q.ExecContext(ctx, query,
 		agent.ID, agent.TeamID, agent.BaseURL, agent.Version, agent.DeploymentType, agent.InvocationURL,
 		reasonersJSON, skillsJSON, commConfigJSON, agent.HealthStatus, agent.LifecycleStatus,
-		agent.LastHeartbeat, agent.RegisteredAt, featuresJSON, metadataJSON,
+		agent.LastHeartbeat, agent.RegisteredAt, agent.UpdatedAt, featuresJSON, metadataJSON,
 	)
 
 	if err != nil {
@@ -4173,7 +4215,7 @@ func (ls *LocalStorage) GetAgent(ctx context.Context, id string) (*types.AgentNo
 		SELECT
 			id, team_id, base_url, version, deployment_type, invocation_url, reasoners, skills,
 			communication_config, health_status, lifecycle_status, last_heartbeat,
-			registered_at, features, metadata
+			registered_at, updated_at, features, metadata
 		FROM agent_nodes WHERE id = ?`
 
 	row := ls.db.QueryRowContext(ctx, query, id)
@@ -4182,11 +4224,12 @@ func (ls *LocalStorage) GetAgent(ctx context.Context, id string) (*types.AgentNo
 	var reasonersJSON, skillsJSON, commConfigJSON, featuresJSON, metadataJSON []byte
 	var healthStatusStr, lifecycleStatusStr string
 	var invocationURL sql.NullString
+	var updatedAt sql.NullTime
 
 	err := row.Scan(
 		&agent.ID, &agent.TeamID, &agent.BaseURL, &agent.Version, &agent.DeploymentType, &invocationURL,
 		&reasonersJSON, &skillsJSON, &commConfigJSON, &healthStatusStr, &lifecycleStatusStr,
-		&agent.LastHeartbeat, &agent.RegisteredAt, &featuresJSON, &metadataJSON,
+		&agent.LastHeartbeat, &agent.RegisteredAt, &updatedAt, &featuresJSON, &metadataJSON,
 	)
 
 	if err != nil {
@@ -4196,6 +4239,7 @@ func (ls *LocalStorage) GetAgent(ctx context.Context, id string) (*types.AgentNo
 		return nil, fmt.Errorf("failed to get agent node with ID '%s': %w", id, err)
 	}
 
+	agent.UpdatedAt = agentUpdatedAt(updatedAt, agent.RegisteredAt)
 	agent.HealthStatus = types.HealthStatus(healthStatusStr)
 	agent.LifecycleStatus = types.AgentLifecycleStatus(lifecycleStatusStr)
 	if invocationURL.Valid && strings.TrimSpace(invocationURL.String) != "" {
@@ -4261,7 +4305,7 @@ func (ls *LocalStorage) ListAgents(ctx context.Context, filters types.AgentFilte
 		SELECT
 			id, team_id, base_url, version, deployment_type, invocation_url, reasoners, skills,
 			communication_config, health_status, lifecycle_status, last_heartbeat,
-			registered_at, features, metadata
+			registered_at, updated_at, features, metadata
 		FROM agent_nodes`
 
 	var conditions []string
@@ -4279,6 +4323,12 @@ func (ls *LocalStorage) ListAgents(ctx context.Context, filters types.AgentFilte
 		args = append(args, *filters.TeamID)
 	}
 
+	// Add delta filter; rows written before updated_at existed fall back to registered_at
+	if filters.UpdatedSince != nil {
+		conditions = append(conditions, "COALESCE(updated_at, registered_at) > ?")
+		args = append(args, filters.UpdatedSince.UTC())
+	}
+
 	// Add WHERE clause if there are conditions
 	if len(conditions) > 0 {
 		query += " WHERE " + conditions[0]
@@ -4306,16 +4356,18 @@ func (ls *LocalStorage) ListAgents(ctx context.Context, filters types.AgentFilte
 		var reasonersJSON, skillsJSON, commConfigJSON, featuresJSON, metadataJSON []byte
 		var healthStatusStr, lifecycleStatusStr string
 		var invocationURL sql.NullString
+		var updatedAt sql.NullTime
 
 		err := rows.Scan(
 			&agent.ID, &agent.TeamID, &agent.BaseURL, &agent.Version, &agent.DeploymentType, &invocationURL,
 			&reasonersJSON, &skillsJSON, &commConfigJSON, &healthStatusStr, &lifecycleStatusStr,
-			&agent.LastHeartbeat, &agent.RegisteredAt, &featuresJSON, &metadataJSON,
+			&agent.LastHeartbeat, &agent.RegisteredAt, &updatedAt, &featuresJSON, &metadataJSON,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan agent node row: %w", err)
 		}
 
+		agent.UpdatedAt = agentUpdatedAt(updatedAt, agent.RegisteredAt)
 		agent.HealthStatus = types.HealthStatus(healthStatusStr)
 		agent.LifecycleStatus = types.AgentLifecycleStatus(lifecycleStatusStr)
 		if invocationURL.Valid && strings.TrimSpace(invocationURL.String) != "" {
@@ -4377,6 +4429,15 @@ func (ls *LocalStorage) ListAgents(ctx context.Context, filters types.AgentFilte
 	return agents, nil
 }
 
+// agentUpdatedAt resolves the stored updated_at, falling back to the registration
+// time for rows written before the column existed.
+func agentUpdatedAt(updatedAt sql.NullTime, registeredAt time.Time) time.Time {
+	if updatedAt.Valid {
+		return updatedAt.Time
+	}
+	return registeredAt
+}
+
 // UpdateAgentHealth updates the health status of an agent node in SQLite.
 // IMPORTANT: This method ONLY updates health_status, never last_heartbeat (only heartbeat endpoint should do that)
 func (ls *LocalStorage) UpdateAgentHealth(ctx context.Context, id string, status types.HealthStatus) error {
@@ -4409,10 +4470,11 @@ func (ls *LocalStorage) UpdateAgentHealth(ctx context.Context, id string, status
 func (ls *LocalStorage) executeUpdateAgentHealth(ctx context.Context, q DBTX, id string, status types.HealthStatus) error {
 	query := `
 		UPDATE agent_nodes
-		SET health_status = ?
+		SET health_status = ?,
+			updated_at = CASE WHEN health_status = ? THEN updated_at ELSE ? END
 		WHERE id = ?;`
 
-	_, err := q.ExecContext(ctx, query, status, id)
+	_, err := q.ExecContext(ctx, query, status, status, time.Now().UTC(), id)
 	if err != nil {
 		return fmt.Errorf("failed to update agent health status for ID '%s': %w", id, err)
 	}
@@ -4438,16 +4500,18 @@ func (ls *LocalStorage) UpdateAgentHealthAtomic(ctx context.Context, id string,
 		// DO NOT update last_heartbeat here - that creates phantom heartbeats!
 		query = `
 			UPDATE agent_nodes
-			SET health_status = ?
+			SET health_status = ?,
+				updated_at = CASE WHEN health_status = ? THEN updated_at ELSE ? END
 			WHERE id = ? AND last_heartbeat = ?;`
-		args = []interface{}{status, id, expectedLastHeartbeat.UTC().Format(time.RFC3339Nano)}
+		args = []interface{}{status, status, time.Now().UTC(), id, expectedLastHeartbeat.UTC().Format(time.RFC3339Nano)}
 	} else {
 		// Standard atomic update without timestamp check - only update health_status
 		query = `
 			UPDATE agent_nodes
-			SET health_status = ?
+			SET health_status = ?,
+				updated_at = CASE WHEN health_status = ? THEN updated_at ELSE ? END
 			WHERE id = ?;`
-		args = []interface{}{status, id}
+		args = []interface{}{status, status, time.Now().UTC(), id}
 	}
 
 	result, err := ls.db.ExecContext(ctx, query, args...)
@@ -4514,6 +4578,29 @@ func (ls *LocalStorage) executeUpdateAgentHeartbeat(ctx context.Context, q DBTX,
 	return nil
 }
 
+// DeleteAgent removes an agent node record from SQLite by ID.
+func (ls *LocalStorage) DeleteAgent(ctx context.Context, id string) error {
+	// Check context cancellation early
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("context cancelled during delete agent: %w", err)
+	}
+
+	result, err := ls.db.ExecContext(ctx, `DELETE FROM agent_nodes WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete agent node with ID '%s': %w", id, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected for agent delete ID '%s': %w", id, err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: %s", interfaces.ErrAgentNodeNotFound, id)
+	}
+
+	return nil
+}
+
 // UpdateAgentLifecycleStatus updates the lifecycle status of an agent node in SQLite.
 func (ls *LocalStorage) UpdateAgentLifecycleStatus(ctx context.Context, id string, status types.AgentLifecycleStatus) error {
 	// Check context cancellation early
@@ -4545,10 +4632,11 @@ func (ls *LocalStorage) UpdateAgentLifecycleStatus(ctx context.Context, id strin
 func (ls *LocalStorage) executeUpdateAgentLifecycleStatus(ctx context.Context, q DBTX, id string, status types.AgentLifecycleStatus) error {
 	query := `
 		UPDATE agent_nodes
-		SET lifecycle_status = ?
+		SET lifecycle_status = ?,
+			updated_at = CASE WHEN lifecycle_status = ? THEN updated_at ELSE ? END
 		WHERE id = ?;`
 
-	_, err := q.ExecContext(ctx, query, status, id)
+	_, err := q.ExecContext(ctx, query, status, status, time.Now().UTC(), id)
 	if err != nil {
 		fmt.Printf("❌ DEBUG: Database update failed for node %s: %v\n", id, err)
 		return fmt.Errorf("failed to update agent lifecycle status for ID '%s': %w", id, err)
@@ -0,0 +1,219 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Agent-Field/agentfield/control-plane/pkg/types"
+	"github.com/Agent-Field/agentfield/sdk/go/agent"
+)
+
+// StorageProviderBackend adapts a StorageProvider to the SDK's
+// agent.MemoryBackend, so agents embedded in the control plane share its
+// persistence layer instead of bringing their own. Values are stored as JSON,
+// which means Get returns the generic decoding (numbers as float64, objects as
+// map[string]any), matching the control plane's HTTP memory API.
+type StorageProviderBackend struct {
+	provider StorageProvider
+	timeout  time.Duration
+}
+
+var (
+	_ agent.MemoryBackend  = (*StorageProviderBackend)(nil)
+	_ agent.EntriesBackend = (*StorageProviderBackend)(nil)
+	_ agent.Pinger         = (*StorageProviderBackend)(nil)
+)
+
+// NewStorageProviderBackend wraps provider. Each call is bounded by a 15 second
+// timeout, matching the SDK's HTTP backends.
+func NewStorageProviderBackend(provider StorageProvider) *StorageProviderBackend {
+	return &StorageProviderBackend{
+		provider: provider,
+		timeout:  15 * time.Second,
+	}
+}
+
+func (b *StorageProviderBackend) context() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), b.timeout)
+}
+
+// providerScope maps SDK scopes onto the provider's memory scopes; the control
+// plane calls the user scope "actor".
+func providerScope(scope agent.MemoryScope) string {
+	switch scope {
+	case agent.ScopeWorkflow:
+		return "workflow"
+	case agent.ScopeSession:
+		return "session"
+	case agent.ScopeUser:
+		return "actor"
+	default:
+		return "global"
+	}
+}
+
+// isMemoryNotFound reports whether err is the provider's missing-key error.
+// Neither backend exposes a sentinel, so this matches the message they share.
+func isMemoryNotFound(err error) bool {
+	return strings.Contains(err.Error(), "not found")
+}
+
+func (b *StorageProviderBackend) Set(scope agent.MemoryScope, scopeID, key string, value any) error {
+	// SetTyped passes json.RawMessage, which marshals verbatim; a plain
+	// []byte is user data and is stored base64-encoded like encoding/json does.
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("encode memory value: %w", err)
+	}
+
+	ctx, cancel := b.context()
+	defer cancel()
+	now := time.Now()
+	return b.provider.SetMemory(ctx, &types.Memory{
+		Scope:     providerScope(scope),
+		ScopeID:   scopeID,
+		Key:       key,
+		Data:      data,
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+}
+
+func (b *StorageProviderBackend) Get(scope agent.MemoryScope, scopeID, key string) (any, bool, error) {
+	ctx, cancel := b.context()
+	defer cancel()
+	memory, err := b.provider.GetMemory(ctx, providerScope(scope), scopeID, key)
+	if err != nil {
+		if ctx.Err() == nil && isMemoryNotFound(err) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	if memory == nil || len(memory.Data) == 0 {
+		return nil, false, nil
+	}
+
+	var value any
+	if err := json.Unmarshal(memory.Data, &value); err != nil {
+		return nil, false, fmt.Errorf("decode memory value: %w", err)
+	}
+	return value, true, nil
+}
+
+func (b *StorageProviderBackend) Delete(scope agent.MemoryScope, scopeID, key string) error {
+	ctx, cancel := b.context()
+	defer cancel()
+	return b.provider.DeleteMemory(ctx, providerScope(scope), scopeID, key)
+}
+
+func (b *StorageProviderBackend) List(scope agent.MemoryScope, scopeID string) ([]string, error) {
+	ctx, cancel := b.context()
+	defer cancel()
+	memories, err := b.provider.ListMemory(ctx, providerScope(scope), scopeID)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(memories))
+	for _, memory := range memories {
+		keys = append(keys, memory.Key)
+	}
+	return keys, nil
+}
+
+// Entries implements agent.EntriesBackend with a single ListMemory call.
+func (b *StorageProviderBackend) Entries(scope agent.MemoryScope, scopeID string) (map[string]any, error) {
+	ctx, cancel := b.context()
+	defer cancel()
+	memories, err := b.provider.ListMemory(ctx, providerScope(scope), scopeID)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make(map[string]any, len(memories))
+	for _, memory := range memories {
+		var value any
+		if len(memory.Data) > 0 {
+			if err := json.Unmarshal(memory.Data, &value); err != nil {
+				return nil, fmt.Errorf("decode memory value %q: %w", memory.Key, err)
+			}
+		}
+		entries[memory.Key] = value
+	}
+	return entries, nil
+}
+
+func (b *StorageProviderBackend) SetVector(scope agent.MemoryScope, scopeID, key string, embedding []float64, metadata map[string]any) error {
+	vector := make([]float32, len(embedding))
+	for i, v := range embedding {
+		vector[i] = float32(v)
+	}
+
+	ctx, cancel := b.context()
+	defer cancel()
+	return b.provider.SetVector(ctx, &types.VectorRecord{
+		Scope:     providerScope(scope),
+		ScopeID:   scopeID,
+		Key:       key,
+		Embedding: vector,
+		Metadata:  metadata,
+	})
+}
+
+func (b *StorageProviderBackend) GetVector(scope agent.MemoryScope, scopeID, key string) ([]float64, map[string]any, bool, error) {
+	ctx, cancel := b.context()
+	defer cancel()
+	record, err := b.provider.GetVector(ctx, providerScope(scope), scopeID, key)
+	if err != nil || record == nil {
+		return nil, nil, false, err
+	}
+
+	embedding := make([]float64, len(record.Embedding))
+	for i, v := range record.Embedding {
+		embedding[i] = float64(v)
+	}
+	return embedding, record.Metadata, true, nil
+}
+
+func (b *StorageProviderBackend) SearchVector(scope agent.MemoryScope, scopeID string, embedding []float64, opts agent.SearchOptions) ([]agent.VectorSearchResult, error) {
+	query := make([]float32, len(embedding))
+	for i, v := range embedding {
+		query[i] = float32(v)
+	}
+
+	ctx, cancel := b.context()
+	defer cancel()
+	hits, err := b.provider.SimilaritySearch(ctx, providerScope(scope), scopeID, query, opts.Limit, opts.Filters)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]agent.VectorSearchResult, 0, len(hits))
+	for _, hit := range hits {
+		if opts.Threshold > 0 && hit.Score < opts.Threshold {
+			continue
+		}
+		results = append(results, agent.VectorSearchResult{
+			Key:      hit.Key,
+			Score:    hit.Score,
+			Metadata: hit.Metadata,
+			Scope:    scope,
+			ScopeID:  scopeID,
+		})
+	}
+	return results, nil
+}
+
+func (b *StorageProviderBackend) DeleteVector(scope agent.MemoryScope, scopeID, key string) error {
+	ctx, cancel := b.context()
+	defer cancel()
+	return b.provider.DeleteVector(ctx, providerScope(scope), scopeID, key)
+}
+
+// Ping reports whether the underlying provider is reachable.
+func (b *StorageProviderBackend) Ping(ctx context.Context) error {
+	return b.provider.HealthCheck(ctx)
+}
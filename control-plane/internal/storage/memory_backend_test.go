@@ -0,0 +1,96 @@
+package storage
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/Agent-Field/agentfield/sdk/go/agent"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStorageProviderBackend(t *testing.T) {
+	provider, ctx := setupTestStorage(t)
+	backend := NewStorageProviderBackend(provider)
+
+	t.Run("Set and Get", func(t *testing.T) {
+		require.NoError(t, backend.Set(agent.ScopeSession, "session-1", "key1", map[string]any{"name": "test", "count": 42}))
+
+		val, found, err := backend.Get(agent.ScopeSession, "session-1", "key1")
+		require.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, map[string]any{"name": "test", "count": float64(42)}, val)
+
+		_, found, err = backend.Get(agent.ScopeSession, "session-1", "missing")
+		require.NoError(t, err)
+		assert.False(t, found)
+	})
+
+	t.Run("Ping", func(t *testing.T) {
+		require.NoError(t, backend.Ping(ctx))
+	})
+
+	t.Run("user scope maps to actor", func(t *testing.T) {
+		require.NoError(t, backend.Set(agent.ScopeUser, "u-1", "pref", "dark"))
+
+		stored, err := provider.GetMemory(ctx, "actor", "u-1", "pref")
+		require.NoError(t, err)
+		assert.JSONEq(t, `"dark"`, string(stored.Data))
+	})
+
+	t.Run("List and Delete", func(t *testing.T) {
+		require.NoError(t, backend.Set(agent.ScopeWorkflow, "wf", "a", 1))
+		require.NoError(t, backend.Set(agent.ScopeWorkflow, "wf", "b", 2))
+		require.NoError(t, backend.Set(agent.ScopeWorkflow, "wf-other", "c", 3))
+
+		keys, err := backend.List(agent.ScopeWorkflow, "wf")
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"a", "b"}, keys)
+
+		require.NoError(t, backend.Delete(agent.ScopeWorkflow, "wf", "a"))
+		keys, err = backend.List(agent.ScopeWorkflow, "wf")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"b"}, keys)
+		_, found, err := backend.Get(agent.ScopeWorkflow, "wf", "a")
+		require.NoError(t, err)
+		assert.False(t, found)
+	})
+
+	t.Run("Entries", func(t *testing.T) {
+		require.NoError(t, backend.Set(agent.ScopeSession, "session-entries", "a", "x"))
+		require.NoError(t, backend.Set(agent.ScopeSession, "session-entries", "b", map[string]any{"n": 1}))
+
+		entries, err := backend.Entries(agent.ScopeSession, "session-entries")
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"a": "x", "b": map[string]any{"n": float64(1)}}, entries)
+	})
+
+	t.Run("Memory integration", func(t *testing.T) {
+		memory := agent.NewMemory(backend)
+		type profile struct {
+			Name string `json:"name"`
+		}
+		scope := memory.GlobalScope()
+		require.NoError(t, scope.SetTyped(ctx, "profile", profile{Name: "ada"}))
+
+		var got profile
+		require.NoError(t, scope.GetTyped(ctx, "profile", &got))
+		assert.Equal(t, "ada", got.Name)
+	})
+
+	t.Run("byte slices are user data", func(t *testing.T) {
+		// []byte("42") is valid JSON but must not be stored as the number 42.
+		require.NoError(t, backend.Set(agent.ScopeGlobal, "global", "raw", []byte("42")))
+
+		stored, err := provider.GetMemory(ctx, "global", "global", "raw")
+		require.NoError(t, err)
+		var raw []byte
+		require.NoError(t, json.Unmarshal(stored.Data, &raw))
+		assert.Equal(t, []byte("42"), raw)
+
+		val, _, err := backend.Get(agent.ScopeGlobal, "global", "raw")
+		require.NoError(t, err)
+		assert.NotEqual(t, float64(42), val)
+	})
+}
@@ -63,6 +63,7 @@ type AgentNodeModel struct {
 	LifecycleStatus     string     `gorm:"column:lifecycle_status;default:'starting';index"`
 	LastHeartbeat       *time.Time `gorm:"column:last_heartbeat"`
 	RegisteredAt        time.Time  `gorm:"column:registered_at;autoCreateTime"`
+	UpdatedAt           *time.Time `gorm:"column:updated_at;index"`
 	Features            []byte     `gorm:"column:features"`
 	Metadata            []byte     `gorm:"column:metadata"`
 }
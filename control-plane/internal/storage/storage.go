@@ -104,7 +104,9 @@ type StorageProvider interface {
 
 	// Agent registry
 	RegisterAgent(ctx context.Context, agent *types.AgentNode) error
+	CreateAgent(ctx context.Context, agent *types.AgentNode) error
 	GetAgent(ctx context.Context, id string) (*types.AgentNode, error)
+	DeleteAgent(ctx context.Context, id string) error
 	ListAgents(ctx context.Context, filters types.AgentFilters) ([]*types.AgentNode, error)
 	UpdateAgentHealth(ctx context.Context, id string, status types.HealthStatus) error
 	UpdateAgentHealthAtomic(ctx context.Context, id string, status types.HealthStatus, expectedLastHeartbeat *time.Time) error
@@ -175,6 +175,9 @@ type AgentNode struct {
 	LifecycleStatus AgentLifecycleStatus `json:"lifecycle_status" db:"lifecycle_status"`
 	LastHeartbeat   time.Time            `json:"last_heartbeat" db:"last_heartbeat"`
 	RegisteredAt    time.Time            `json:"registered_at" db:"registered_at"`
+	// UpdatedAt records the last registration, health or lifecycle change.
+	// Heartbeats alone do not advance it.
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
 
 	Features AgentFeatures `json:"features" db:"features"`
 	Metadata AgentMetadata `json:"metadata" db:"metadata"`
@@ -302,6 +305,21 @@ type StateTransition struct {
 	Reason    string     `json:"reason,omitempty"`
 }
 
+// StatusReason is a machine-readable explanation of why an agent is in its current state.
+type StatusReason string
+
+const (
+	StatusReasonHealthy          StatusReason = "healthy"           // Active and responding
+	StatusReasonStarting         StatusReason = "starting"          // Still initializing
+	StatusReasonDraining         StatusReason = "draining"          // Shutting down
+	StatusReasonCordoned         StatusReason = "cordoned"          // Up, but withheld from new work by an operator
+	StatusReasonFlapping         StatusReason = "flapping"          // Changing state too often to be trusted
+	StatusReasonHeartbeatExpired StatusReason = "heartbeat_expired" // Missed heartbeats past the presence TTL
+	StatusReasonHardEvicted      StatusReason = "hard_evicted"      // Presence lease dropped after prolonged silence
+	StatusReasonNeverSeen        StatusReason = "never_seen"        // No status or presence on record
+	StatusReasonUnreachable      StatusReason = "unreachable"       // Inactive without a presence explanation
+)
+
 // StatusSource indicates where a status update originated
 type StatusSource string
 
@@ -531,6 +549,16 @@ type AgentMetadata struct {
 	Deployment  *DeploymentMetadata       `json:"deployment,omitempty"`
 	Performance *AgentPerformanceMetadata `json:"performance,omitempty"`
 	Custom      map[string]interface{}    `json:"custom,omitempty"`
+	// Manifest is what the node advertises it can handle. Nil means the node
+	// has not published one.
+	Manifest *AgentManifest `json:"manifest,omitempty"`
+}
+
+// AgentManifest lists the capabilities and handlers a node advertises so a
+// router can pick a node for a request without calling each one.
+type AgentManifest struct {
+	Capabilities []string `json:"capabilities,omitempty"`
+	Handlers     []string `json:"handlers,omitempty"`
 }
 
 // DeploymentMetadata holds deployment-related metadata for an agent node.
@@ -567,6 +595,18 @@ type AgentFilters struct {
 	TeamID       *string       `json:"team_id,omitempty"`
 	HealthStatus *HealthStatus `json:"health_status,omitempty"`
 	Features     []string      `json:"features,omitempty"`
+	// UpdatedSince restricts results to agents whose UpdatedAt is after the given time.
+	UpdatedSince *time.Time `json:"updated_since,omitempty"`
+}
+
+// NodeStatusAggregate counts, by status, the nodes whose deployment tags
+// include LabelKey=LabelValue.
+type NodeStatusAggregate struct {
+	LabelKey   string                       `json:"label_key"`
+	LabelValue string                       `json:"label_value"`
+	Total      int                          `json:"total"`
+	Health     map[HealthStatus]int         `json:"health"`
+	Lifecycle  map[AgentLifecycleStatus]int `json:"lifecycle"`
 }
 
 // EventFilter holds filters for querying memory events.
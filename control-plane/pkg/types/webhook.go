@@ -12,6 +12,9 @@ const (
 	// Execution webhook event types
 	WebhookEventExecutionCompleted = "execution.completed"
 	WebhookEventExecutionFailed    = "execution.failed"
+
+	// Node status webhook event types
+	WebhookEventNodeStatusChanged = "node.status_changed"
 )
 
 // ExecutionWebhook captures the persisted webhook registration metadata for an execution.
@@ -52,6 +55,17 @@ type ExecutionWebhookPayload struct {
 	Timestamp    string      `json:"timestamp"`
 }
 
+// NodeStatusChange is the payload AgentField sends to node status webhooks
+// when a node moves between states.
+type NodeStatusChange struct {
+	Event     string       `json:"event"`
+	NodeID    string       `json:"node_id"`
+	From      AgentState   `json:"from"`
+	To        AgentState   `json:"to"`
+	Source    StatusSource `json:"source,omitempty"`
+	Timestamp string       `json:"timestamp"`
+}
+
 // CloneWithoutSecret returns a shallow copy of the webhook metadata without the secret.
 func (w *ExecutionWebhook) CloneWithoutSecret() *ExecutionWebhook {
 	if w == nil {
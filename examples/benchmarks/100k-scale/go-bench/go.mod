@@ -4,6 +4,9 @@ go 1.21
 
 require github.com/Agent-Field/agentfield/sdk/go v0.0.0
 
-require gopkg.in/yaml.v3 v3.0.1 // indirect
+require (
+	golang.org/x/sync v0.8.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
 
 replace github.com/Agent-Field/agentfield/sdk/go => ../../../../sdk/go
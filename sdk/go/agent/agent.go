@@ -13,6 +13,9 @@ import (
 	"net/url"
 	"os"
 	"os/signal"
+	"path"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
 	"syscall"
@@ -32,6 +35,7 @@ type ExecutionContext struct {
 	ParentExecutionID string
 	SessionID         string
 	ActorID           string
+	TenantID          string
 	WorkflowID        string
 	ParentWorkflowID  string
 	RootWorkflowID    string
@@ -136,6 +140,16 @@ type Config struct {
 	// MemoryBackend allows plugging in a custom memory storage backend.
 	// If nil, an in-memory backend is used (data lost on restart).
 	MemoryBackend MemoryBackend
+
+	// MemoryConfig tunes the memory system (e.g. per-operation timeouts).
+	// If nil, defaults are used.
+	MemoryConfig *MemoryConfig
+
+	// EnableMemoryAdminAPI mounts the /api/memory/* support endpoints on the
+	// agent's handler. They are unauthenticated, expose scope IDs, keys and
+	// values, and purge-expired deletes data, so only enable it when the
+	// agent port is not reachable by untrusted callers.
+	EnableMemoryAdminAPI bool
 }
 
 // CLIConfig controls CLI behaviour and presentation.
@@ -216,12 +230,17 @@ func New(cfg Config) (*Agent, error) {
 		}
 	}
 
+	memoryConfig := MemoryConfig{}
+	if cfg.MemoryConfig != nil {
+		memoryConfig = *cfg.MemoryConfig
+	}
+
 	a := &Agent{
 		cfg:        cfg,
 		httpClient: httpClient,
 		reasoners:  make(map[string]*Reasoner),
 		aiClient:   aiClient,
-		memory:     NewMemory(cfg.MemoryBackend),
+		memory:     NewMemoryWithConfig(cfg.MemoryBackend, memoryConfig),
 		stopLease:  make(chan struct{}),
 		logger:     cfg.Logger,
 	}
@@ -276,6 +295,7 @@ func (ec ExecutionContext) ChildContext(agentNodeID, reasonerName string) Execut
 		ParentExecutionID: ec.ExecutionID,
 		SessionID:         ec.SessionID,
 		ActorID:           ec.ActorID,
+		TenantID:          ec.TenantID,
 		WorkflowID:        workflowID,
 		ParentWorkflowID:  workflowID,
 		RootWorkflowID:    rootWorkflowID,
@@ -573,6 +593,11 @@ func (a *Agent) handler() http.Handler {
 		mux.HandleFunc("/execute", a.handleExecute)
 		mux.HandleFunc("/execute/", a.handleExecute)
 		mux.HandleFunc("/reasoners/", a.handleReasoner)
+		if a.cfg.EnableMemoryAdminAPI {
+			mux.HandleFunc("/api/memory/stats", a.handleMemoryStats)
+			mux.HandleFunc("/api/memory/search", a.handleMemorySearch)
+			mux.HandleFunc("/api/memory/purge-expired", a.handleMemoryPurgeExpired)
+		}
 		a.router = mux
 	})
 	return a.router
@@ -590,6 +615,97 @@ func (a *Agent) handleDiscover(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, a.discoveryPayload())
 }
 
+func (a *Agent) handleMemoryStats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	stats, err := a.memory.ScopeStats(r.Context())
+	if errors.Is(err, ErrScopeListingUnsupported) {
+		writeJSON(w, http.StatusNotImplemented, map[string]any{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]any{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"scopes": stats})
+}
+
+// handleMemorySearch serves Memory.Search for support tooling:
+// GET /api/memory/search?pattern=cart*&scope=session&scope=user&limit=50
+func (a *Agent) handleMemorySearch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	opts := KeySearchOptions{Pattern: query.Get("pattern")}
+	if opts.Pattern == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "pattern is required"})
+		return
+	}
+	for _, scope := range query["scope"] {
+		opts.Scopes = append(opts.Scopes, MemoryScope(scope))
+	}
+	if raw := query.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 0 {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": "limit must be a non-negative integer"})
+			return
+		}
+		opts.Limit = limit
+	}
+
+	results, err := a.memory.Search(r.Context(), opts)
+	switch {
+	case errors.Is(err, ErrSearchDisabled):
+		writeJSON(w, http.StatusForbidden, map[string]any{"error": err.Error()})
+		return
+	case errors.Is(err, ErrScopeListingUnsupported):
+		writeJSON(w, http.StatusNotImplemented, map[string]any{"error": err.Error()})
+		return
+	case errors.Is(err, path.ErrBadPattern):
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
+		return
+	case err != nil:
+		writeJSON(w, http.StatusInternalServerError, map[string]any{"error": err.Error()})
+		return
+	}
+	if results == nil {
+		results = []KeySearchResult{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"results": results})
+}
+
+// handleMemoryPurgeExpired serves Memory.PurgeExpired for ops reclaiming
+// capacity by hand: POST /api/memory/purge-expired?scope=session
+func (a *Agent) handleMemoryPurgeExpired(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	scope := MemoryScope(r.URL.Query().Get("scope"))
+	if !slices.Contains(allMemoryScopes, scope) {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "scope must be one of workflow, session, user or global"})
+		return
+	}
+
+	purged, err := a.memory.PurgeExpired(r.Context(), scope)
+	if errors.Is(err, ErrPurgeExpiredUnsupported) {
+		writeJSON(w, http.StatusNotImplemented, map[string]any{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]any{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"scope": scope, "purged": purged})
+}
+
 func (a *Agent) discoveryPayload() map[string]any {
 	reasoners := make([]map[string]any, 0, len(a.reasoners))
 	for _, reasoner := range a.reasoners {
@@ -697,6 +813,7 @@ func (a *Agent) buildExecutionContextFromServerless(r *http.Request, payload map
 		ParentExecutionID: strings.TrimSpace(r.Header.Get("X-Parent-Execution-ID")),
 		SessionID:         strings.TrimSpace(r.Header.Get("X-Session-ID")),
 		ActorID:           strings.TrimSpace(r.Header.Get("X-Actor-ID")),
+		TenantID:          strings.TrimSpace(r.Header.Get("X-Tenant-ID")),
 		WorkflowID:        strings.TrimSpace(r.Header.Get("X-Workflow-ID")),
 		AgentNodeID:       a.cfg.NodeID,
 		ReasonerName:      reasonerName,
@@ -722,6 +839,9 @@ func (a *Agent) buildExecutionContextFromServerless(r *http.Request, payload map
 		if execCtx.ActorID == "" {
 			execCtx.ActorID = stringFromMap(ctxMap, "actor_id", "actorId")
 		}
+		if execCtx.TenantID == "" {
+			execCtx.TenantID = stringFromMap(ctxMap, "tenant_id", "tenantId")
+		}
 	}
 
 	if execCtx.RunID == "" {
@@ -774,6 +894,7 @@ func (a *Agent) handleReasoner(w http.ResponseWriter, r *http.Request) {
 		ParentExecutionID: r.Header.Get("X-Parent-Execution-ID"),
 		SessionID:         r.Header.Get("X-Session-ID"),
 		ActorID:           r.Header.Get("X-Actor-ID"),
+		TenantID:          r.Header.Get("X-Tenant-ID"),
 		WorkflowID:        r.Header.Get("X-Workflow-ID"),
 		AgentNodeID:       a.cfg.NodeID,
 		ReasonerName:      name,
@@ -944,6 +1065,9 @@ func (a *Agent) Call(ctx context.Context, target string, input map[string]any) (
 	if execCtx.ActorID != "" {
 		req.Header.Set("X-Actor-ID", execCtx.ActorID)
 	}
+	if execCtx.TenantID != "" {
+		req.Header.Set("X-Tenant-ID", execCtx.TenantID)
+	}
 	if a.cfg.Token != "" {
 		req.Header.Set("Authorization", "Bearer "+a.cfg.Token)
 	}
@@ -1105,6 +1229,7 @@ func (a *Agent) buildChildContext(parent ExecutionContext, reasonerName string)
 			ExecutionID:    generateExecutionID(),
 			SessionID:      parent.SessionID,
 			ActorID:        parent.ActorID,
+			TenantID:       parent.TenantID,
 			WorkflowID:     runID,
 			RootWorkflowID: runID,
 			Depth:          0,
@@ -1214,11 +1339,21 @@ func (a *Agent) AIStream(ctx context.Context, prompt string, opts ...ai.Option)
 	return a.aiClient.StreamComplete(ctx, prompt, opts...)
 }
 
-// ExecutionContextFrom returns the execution context embedded in the provided context, if any.
+// ExecutionContextFrom returns the execution context embedded in the provided context.
+// It returns a zero-value ExecutionContext when none is set.
 func ExecutionContextFrom(ctx context.Context) ExecutionContext {
 	return executionContextFrom(ctx)
 }
 
+// WithExecutionContext returns a copy of ctx carrying ec, retrievable via
+// ExecutionContextFrom. Useful for tests and middleware that call handlers directly.
+func WithExecutionContext(ctx context.Context, ec ExecutionContext) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return contextWithExecution(ctx, ec)
+}
+
 // Memory returns the agent's memory system for state management.
 // Memory provides hierarchical scoped storage (workflow, session, user, global).
 //
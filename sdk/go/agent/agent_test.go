@@ -287,6 +287,159 @@ func TestHandler(t *testing.T) {
 	assert.Equal(t, "ok", response["status"])
 }
 
+func TestHandleMemoryStats(t *testing.T) {
+	backend := NewInMemoryBackend()
+	require.NoError(t, backend.Set(ScopeSession, "session-1", "a", 1))
+	require.NoError(t, backend.Set(ScopeSession, "session-1", "b", 2))
+	require.NoError(t, backend.Set(ScopeSession, "session-2", "c", 3))
+	require.NoError(t, backend.Set(ScopeGlobal, "global", "d", 4))
+
+	agent, err := New(Config{
+		NodeID:               "node-1",
+		Version:              "1.0.0",
+		Logger:               log.New(io.Discard, "", 0),
+		MemoryBackend:        backend,
+		EnableMemoryAdminAPI: true,
+	})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/memory/stats", nil)
+	w := httptest.NewRecorder()
+	agent.Handler().ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Scopes []ScopeStats `json:"scopes"`
+	}
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+
+	byScope := make(map[MemoryScope]ScopeStats)
+	for _, s := range response.Scopes {
+		byScope[s.Scope] = s
+	}
+	assert.Equal(t, ScopeStats{Scope: ScopeSession, ScopeIDs: 2, Keys: 3}, byScope[ScopeSession])
+	assert.Equal(t, ScopeStats{Scope: ScopeGlobal, ScopeIDs: 1, Keys: 1}, byScope[ScopeGlobal])
+	assert.Equal(t, ScopeStats{Scope: ScopeWorkflow}, byScope[ScopeWorkflow])
+	assert.Equal(t, ScopeStats{Scope: ScopeUser}, byScope[ScopeUser])
+}
+
+func TestHandleMemoryStats_UnsupportedBackend(t *testing.T) {
+	agent, err := New(Config{
+		NodeID:               "node-1",
+		Version:              "1.0.0",
+		Logger:               log.New(io.Discard, "", 0),
+		MemoryBackend:        NewControlPlaneMemoryBackend("http://localhost:8080", "", "node-1"),
+		EnableMemoryAdminAPI: true,
+	})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/memory/stats", nil)
+	w := httptest.NewRecorder()
+	agent.Handler().ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotImplemented, w.Code)
+}
+
+func TestMemoryAdminAPI_DisabledByDefault(t *testing.T) {
+	agent, err := New(Config{
+		NodeID:       "node-1",
+		Version:      "1.0.0",
+		Logger:       log.New(io.Discard, "", 0),
+		MemoryConfig: &MemoryConfig{EnableSearch: true},
+	})
+	require.NoError(t, err)
+
+	for _, path := range []string{"/api/memory/stats", "/api/memory/search?pattern=*"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		w := httptest.NewRecorder()
+		agent.Handler().ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNotFound, w.Code, path)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/memory/purge-expired?scope=session", nil)
+	w := httptest.NewRecorder()
+	agent.Handler().ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestHandleMemoryPurgeExpired(t *testing.T) {
+	backend, clock := newTestCacheBackend(0, time.Minute)
+	require.NoError(t, backend.Set(ScopeSession, "session-1", "cart", 1))
+	*clock = clock.Add(2 * time.Minute)
+	require.NoError(t, backend.Set(ScopeSession, "session-1", "profile", "ada"))
+
+	agent, err := New(Config{
+		NodeID:               "node-1",
+		Version:              "1.0.0",
+		Logger:               log.New(io.Discard, "", 0),
+		MemoryBackend:        backend,
+		EnableMemoryAdminAPI: true,
+	})
+	require.NoError(t, err)
+
+	purge := func(method, query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/api/memory/purge-expired?"+query, nil)
+		w := httptest.NewRecorder()
+		agent.Handler().ServeHTTP(w, req)
+		return w
+	}
+
+	w := purge(http.MethodPost, "scope=session")
+	require.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Scope  MemoryScope `json:"scope"`
+		Purged int         `json:"purged"`
+	}
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	assert.Equal(t, ScopeSession, response.Scope)
+	assert.Equal(t, 1, response.Purged)
+	assert.Equal(t, 1, backend.Len())
+
+	assert.Equal(t, http.StatusBadRequest, purge(http.MethodPost, "scope=bogus").Code)
+	assert.Equal(t, http.StatusMethodNotAllowed, purge(http.MethodGet, "scope=session").Code)
+}
+
+func TestHandleMemorySearch(t *testing.T) {
+	backend := NewInMemoryBackend()
+	require.NoError(t, backend.Set(ScopeSession, "session-1", "cart", 1))
+	require.NoError(t, backend.Set(ScopeSession, "session-2", "cart", 2))
+	require.NoError(t, backend.Set(ScopeSession, "session-2", "profile", "ada"))
+
+	newAgent := func(enabled bool) *Agent {
+		agent, err := New(Config{
+			NodeID:               "node-1",
+			Version:              "1.0.0",
+			Logger:               log.New(io.Discard, "", 0),
+			MemoryBackend:        backend,
+			MemoryConfig:         &MemoryConfig{EnableSearch: enabled},
+			EnableMemoryAdminAPI: true,
+		})
+		require.NoError(t, err)
+		return agent
+	}
+	search := func(agent *Agent, query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/memory/search?"+query, nil)
+		w := httptest.NewRecorder()
+		agent.Handler().ServeHTTP(w, req)
+		return w
+	}
+
+	w := search(newAgent(true), "pattern=car*&scope=session")
+	require.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Results []KeySearchResult `json:"results"`
+	}
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	require.Len(t, response.Results, 2)
+	assert.Equal(t, "session-1", response.Results[0].ScopeID)
+	assert.Equal(t, "session-2", response.Results[1].ScopeID)
+	assert.Equal(t, "cart", response.Results[1].Key)
+
+	assert.Equal(t, http.StatusBadRequest, search(newAgent(true), "").Code)
+	assert.Equal(t, http.StatusBadRequest, search(newAgent(true), "pattern=%5B").Code)
+	assert.Equal(t, http.StatusForbidden, search(newAgent(false), "pattern=*").Code)
+}
+
 func TestHandleReasoner_Sync(t *testing.T) {
 	cfg := Config{
 		NodeID:        "node-1",
@@ -674,6 +827,25 @@ func TestExecutionContext_Empty(t *testing.T) {
 	assert.Equal(t, ExecutionContext{}, execCtx)
 }
 
+func TestWithExecutionContext_RoundTrip(t *testing.T) {
+	execCtx := ExecutionContext{
+		RunID:       "run-1",
+		ExecutionID: "exec-1",
+		SessionID:   "session-1",
+		ActorID:     "actor-1",
+		WorkflowID:  "workflow-1",
+	}
+
+	ctx := WithExecutionContext(context.Background(), execCtx)
+	retrieved := ExecutionContextFrom(ctx)
+
+	assert.Equal(t, execCtx.RunID, retrieved.RunID)
+	assert.Equal(t, execCtx.ExecutionID, retrieved.ExecutionID)
+	assert.Equal(t, execCtx.SessionID, retrieved.SessionID)
+	assert.Equal(t, execCtx.ActorID, retrieved.ActorID)
+	assert.Equal(t, execCtx.WorkflowID, retrieved.WorkflowID)
+}
+
 func TestHandleReasonerAsyncPostsStatus(t *testing.T) {
 	callbackCh := make(chan map[string]any, 1)
 	callbackServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -1,6 +1,7 @@
 package agent
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -113,3 +114,63 @@ func TestControlPlaneMemoryBackend_ListReturnsKeys(t *testing.T) {
 		t.Fatalf("keys = %#v", keys)
 	}
 }
+
+func TestControlPlaneMemoryBackend_Ping(t *testing.T) {
+	healthy := true
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/health" {
+			t.Errorf("path = %q", r.URL.Path)
+		}
+		if !healthy {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	b := NewControlPlaneMemoryBackend(srv.URL, "", "agent-1")
+	if err := b.Ping(context.Background()); err != nil {
+		t.Fatalf("Ping: %v", err)
+	}
+
+	healthy = false
+	if err := b.Ping(context.Background()); err == nil {
+		t.Fatal("expected error for unhealthy control plane")
+	}
+}
+
+func TestControlPlaneMemoryBackend_SetEncodesBytesByType(t *testing.T) {
+	var gotData []json.RawMessage
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Data json.RawMessage `json:"data"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		gotData = append(gotData, body.Data)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	memory := NewMemory(NewControlPlaneMemoryBackend(srv.URL, "", "agent-1"))
+	scope := memory.Scoped(ScopeGlobal, "global")
+	ctx := context.Background()
+
+	if err := scope.SetTyped(ctx, "typed", map[string]int{"n": 123}); err != nil {
+		t.Fatalf("SetTyped: %v", err)
+	}
+	// Plain byte slices stay bytes even when they happen to be valid JSON.
+	if err := scope.Set(ctx, "raw", []byte("123")); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	if len(gotData) != 2 {
+		t.Fatalf("requests = %d", len(gotData))
+	}
+	if string(gotData[0]) != `{"n":123}` {
+		t.Fatalf("typed data = %s", gotData[0])
+	}
+	if string(gotData[1]) != `"MTIz"` {
+		t.Fatalf("raw data = %s", gotData[1])
+	}
+}
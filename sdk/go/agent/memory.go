@@ -1,9 +1,21 @@
 package agent
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"reflect"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sync/singleflight"
 )
 
 // MemoryScope represents different memory isolation levels.
@@ -42,6 +54,123 @@ type MemoryBackend interface {
 	DeleteVector(scope MemoryScope, scopeID, key string) error
 }
 
+// ScopeIDLister is implemented by backends that can enumerate the scope IDs
+// holding data for a scope. It enables admin tooling such as memory stats.
+type ScopeIDLister interface {
+	// ListScopeIDs returns the IDs within scope that currently hold keys.
+	ListScopeIDs(scope MemoryScope) ([]string, error)
+}
+
+// EntriesBackend is implemented by backends that can return every key/value
+// pair in a scope in a single call (e.g. Redis HGETALL).
+type EntriesBackend interface {
+	// Entries returns all key/value pairs stored at scope and scopeID.
+	Entries(scope MemoryScope, scopeID string) (map[string]any, error)
+}
+
+// ConditionalBackend is implemented by backends that can atomically write a
+// key depending on whether it already exists.
+type ConditionalBackend interface {
+	// SetIfAbsent stores value only if key does not exist and reports whether it wrote.
+	SetIfAbsent(scope MemoryScope, scopeID, key string, value any) (bool, error)
+	// SetIfPresent stores value only if key already exists and reports whether it wrote.
+	SetIfPresent(scope MemoryScope, scopeID, key string, value any) (bool, error)
+}
+
+// Versioner is implemented by backends that track a version per key, bumped
+// on every write, so callers can detect changes without comparing values.
+type Versioner interface {
+	// Version returns key's current version and whether the key exists.
+	// A key's version only grows while it exists and is never reused after
+	// a delete.
+	Version(scope MemoryScope, scopeID, key string) (uint64, bool, error)
+}
+
+// GetSetter is implemented by backends that can store a value and return the
+// one it replaced in a single atomic operation (e.g. Redis GETSET).
+type GetSetter interface {
+	// GetSet stores value at key and returns the previous value and whether it existed.
+	GetSet(scope MemoryScope, scopeID, key string, value any) (any, bool, error)
+}
+
+// GetDeleter is implemented by backends that can remove a key and return its
+// value in a single atomic operation (e.g. Redis GETDEL).
+type GetDeleter interface {
+	// GetAndDelete removes key and returns the value it held and whether it existed.
+	GetAndDelete(scope MemoryScope, scopeID, key string) (any, bool, error)
+}
+
+// CompareAndSwapper is implemented by backends that can replace a value only
+// if it has not changed since it was read, for lock-free read-modify-write.
+type CompareAndSwapper interface {
+	// CompareAndSwap stores value only if key exists holding a value deeply
+	// equal to old, and reports whether it wrote.
+	CompareAndSwap(scope MemoryScope, scopeID, key string, old, value any) (bool, error)
+}
+
+// PrefixDeleter is implemented by backends that can remove every key sharing
+// a prefix in one operation (e.g. Redis SCAN plus DEL).
+type PrefixDeleter interface {
+	// DeletePrefix removes keys starting with prefix and returns how many were removed.
+	DeletePrefix(scope MemoryScope, scopeID, prefix string) (int, error)
+}
+
+// NoExpiry is the TTL reported for keys that exist but never expire.
+const NoExpiry time.Duration = -1
+
+// TTLReader is implemented by backends that store per-key expirations and can
+// report how long a key has left to live.
+type TTLReader interface {
+	// TTL returns the remaining lifetime of key and whether it exists.
+	// Keys without an expiry report NoExpiry.
+	TTL(scope MemoryScope, scopeID, key string) (time.Duration, bool, error)
+}
+
+// Pinger is implemented by networked backends that can report whether their
+// underlying store is reachable.
+type Pinger interface {
+	// Ping returns an error if the backend cannot currently serve requests.
+	Ping(ctx context.Context) error
+}
+
+// ScopeSizer is implemented by backends that can estimate storage usage per
+// scope ID natively (e.g. Redis MEMORY USAGE) instead of reading every value.
+type ScopeSizer interface {
+	// ScopeSizes returns approximate bytes held by each scope ID within scope.
+	ScopeSizes(scope MemoryScope) (map[string]int64, error)
+}
+
+// ErrConditionalSetUnsupported is returned when the backend does not implement ConditionalBackend.
+var ErrConditionalSetUnsupported = errors.New("memory backend does not support conditional set")
+
+// ErrVersionUnsupported is returned when the backend does not implement Versioner.
+var ErrVersionUnsupported = errors.New("memory backend does not support key versions")
+
+// ErrGetSetUnsupported is returned when the backend does not implement GetSetter.
+var ErrGetSetUnsupported = errors.New("memory backend does not support get-and-set")
+
+// ErrGetAndDeleteUnsupported is returned when the backend does not implement GetDeleter.
+var ErrGetAndDeleteUnsupported = errors.New("memory backend does not support get-and-delete")
+
+// ErrScopeListingUnsupported is returned when the backend does not implement ScopeIDLister.
+var ErrScopeListingUnsupported = errors.New("memory backend does not support listing scope IDs")
+
+// ErrPatchNotObject is returned by Patch when the stored value is not a JSON object.
+var ErrPatchNotObject = errors.New("memory value is not an object")
+
+// ErrNilMemoryBackend is returned by NewMemoryStrict when no backend is given.
+var ErrNilMemoryBackend = errors.New("memory backend is nil")
+
+// allMemoryScopes lists every scope in a stable order.
+var allMemoryScopes = []MemoryScope{ScopeWorkflow, ScopeSession, ScopeUser, ScopeGlobal}
+
+// ScopeStats summarizes how much data a single scope holds.
+type ScopeStats struct {
+	Scope    MemoryScope `json:"scope"`
+	ScopeIDs int         `json:"scope_ids"`
+	Keys     int         `json:"keys"`
+}
+
 // SearchOptions defines parameters for similarity search.
 type SearchOptions struct {
 	Limit     int            `json:"limit"`
@@ -59,62 +188,971 @@ type VectorSearchResult struct {
 	ScopeID  string         `json:"scope_id"`
 }
 
+// MemoryConfig tunes how Memory dispatches operations to its backend.
+type MemoryConfig struct {
+	// OpTimeout bounds each backend call. A timed-out call returns an error
+	// wrapping context.DeadlineExceeded. Zero disables the timeout.
+	OpTimeout time.Duration
+
+	// EnableSearch allows Memory.Search, which reads every key in the
+	// searched scopes. Leave it off outside admin and support tooling.
+	EnableSearch bool
+}
+
+// Codec serializes values for typed memory operations (GetTyped/SetTyped).
+type Codec interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+// JSONCodec is the default Codec, backed by encoding/json.
+type JSONCodec struct {
+	// UseNumber decodes numbers held in untyped destinations (any,
+	// map[string]any, []any) as json.Number instead of float64, so integers
+	// beyond 2^53 survive a round trip.
+	UseNumber bool
+}
+
+// Marshal encodes v as JSON.
+func (JSONCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Unmarshal decodes JSON data into v.
+func (c JSONCodec) Unmarshal(data []byte, v any) error {
+	if !c.UseNumber {
+		return json.Unmarshal(data, v)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	// Match json.Unmarshal, which rejects anything after the value.
+	if _, err := dec.Token(); err != io.EOF {
+		return errors.New("invalid JSON: data after top-level value")
+	}
+	return nil
+}
+
+// encoded wraps codec output for storage. JSONCodec output is stored as
+// json.RawMessage, which backends that serialize values embed verbatim; any
+// other codec's output stays opaque []byte.
+func (m *Memory) encoded(data []byte) any {
+	switch m.codec.(type) {
+	case JSONCodec, *JSONCodec:
+		return json.RawMessage(data)
+	default:
+		return data
+	}
+}
+
+// encodedBytes returns the payload of a value written by SetTyped, which is
+// json.RawMessage or []byte depending on the codec.
+func encodedBytes(val any) ([]byte, bool) {
+	switch v := val.(type) {
+	case json.RawMessage:
+		return v, true
+	case []byte:
+		return v, true
+	default:
+		return nil, false
+	}
+}
+
+// DeserializeErrorPolicy decides what GetTyped does with a stored value that
+// cannot be decoded into the destination, typically after the stored schema
+// has drifted from the Go type reading it.
+type DeserializeErrorPolicy int
+
+const (
+	// DeserializeFail returns a *DeserializeError. It is the default.
+	DeserializeFail DeserializeErrorPolicy = iota
+	// DeserializeSkip treats the value as absent: dest keeps whatever default
+	// the caller put in it and GetTypedFound reports not found.
+	DeserializeSkip
+	// DeserializeLogAndZero logs the *DeserializeError, resets dest to its
+	// zero value and reports the key as found.
+	DeserializeLogAndZero
+)
+
+// DeserializeError reports a stored value that could not be decoded,
+// identifying where it lives.
+type DeserializeError struct {
+	Scope   MemoryScope
+	ScopeID string
+	Key     string
+	Err     error
+}
+
+func (e *DeserializeError) Error() string {
+	return fmt.Sprintf("memory: decode %s/%s key %q: %v", e.Scope, e.ScopeID, e.Key, e.Err)
+}
+
+func (e *DeserializeError) Unwrap() error {
+	return e.Err
+}
+
+// MemoryOption customizes a Memory instance.
+type MemoryOption func(*Memory)
+
+// WithCodec sets the codec used by typed operations.
+// A nil codec leaves the default JSON codec in place.
+func WithCodec(codec Codec) MemoryOption {
+	return func(m *Memory) {
+		if codec != nil {
+			m.codec = codec
+		}
+	}
+}
+
+// WithJSONNumbers makes typed reads decode numbers in untyped destinations as
+// json.Number, preserving large int64 IDs. It replaces the codec with
+// JSONCodec{UseNumber: true}.
+func WithJSONNumbers() MemoryOption {
+	return func(m *Memory) {
+		m.codec = JSONCodec{UseNumber: true}
+	}
+}
+
+// WithDeserializeErrorPolicy sets how typed reads handle values that cannot be
+// decoded. logger receives DeserializeLogAndZero reports; nil uses log.Default.
+func WithDeserializeErrorPolicy(policy DeserializeErrorPolicy, logger *log.Logger) MemoryOption {
+	return func(m *Memory) {
+		m.deserializePolicy = policy
+		if logger != nil {
+			m.deserializeLogger = logger
+		}
+	}
+}
+
+// ScopeResolver derives the scope ID for a memory scope from the execution context.
+type ScopeResolver func(ExecutionContext) string
+
+// defaultScopeResolvers holds the built-in scope ID fallback chains.
+var defaultScopeResolvers = map[MemoryScope]ScopeResolver{
+	ScopeWorkflow: func(execCtx ExecutionContext) string {
+		if execCtx.WorkflowID != "" {
+			return execCtx.WorkflowID
+		}
+		return execCtx.RunID
+	},
+	ScopeSession: func(execCtx ExecutionContext) string {
+		if execCtx.SessionID != "" {
+			return execCtx.SessionID
+		}
+		return execCtx.RunID
+	},
+	ScopeUser: func(execCtx ExecutionContext) string {
+		if execCtx.ActorID != "" {
+			return execCtx.ActorID
+		}
+		// Fall back to session if no actor
+		if execCtx.SessionID != "" {
+			return execCtx.SessionID
+		}
+		return execCtx.RunID
+	},
+	ScopeGlobal: func(ExecutionContext) string {
+		return "global"
+	},
+}
+
+// WithScopeResolver overrides how scope IDs are derived for scope, e.g. to
+// isolate user data by TenantID first. Scopes without an override keep the
+// default fallback chain. A nil resolver is ignored.
+func WithScopeResolver(scope MemoryScope, resolver ScopeResolver) MemoryOption {
+	return func(m *Memory) {
+		if resolver == nil {
+			return
+		}
+		if m.resolvers == nil {
+			m.resolvers = make(map[MemoryScope]ScopeResolver)
+		}
+		m.resolvers[scope] = resolver
+	}
+}
+
 // Memory provides hierarchical state management for agent handlers.
 // It supports multiple isolation scopes (workflow, session, user, global)
 // with automatic scope ID resolution from execution context.
 type Memory struct {
 	backend MemoryBackend
+	config  MemoryConfig
+	codec   Codec
+
+	deserializePolicy DeserializeErrorPolicy
+	deserializeLogger *log.Logger
+
+	auditSink   AuditSink
+	auditConfig AuditConfig
+
+	// strict is nil unless WithStrictScopes is set.
+	strict *scopeTracker
+
+	// resolvers overrides defaultScopeResolvers per scope.
+	resolvers map[MemoryScope]ScopeResolver
+
+	// flight coalesces concurrent GetOrCompute misses on the same key.
+	flight singleflight.Group
+
+	// patchMu serializes Patch read-modify-write cycles on backends without
+	// ConditionalBackend and CompareAndSwapper.
+	patchMu sync.Mutex
+
+	// schemas holds RegisterSchema types by stored key.
+	schemaMu sync.RWMutex
+	schemas  map[string]reflect.Type
+
+	// scopeHooks holds OnScopeFirstAccess hooks; firstAccessed tracks the
+	// scope IDs they have run for, keyed by firstAccessKey.
+	hookMu        sync.RWMutex
+	scopeHooks    map[MemoryScope]ScopeHook
+	firstAccessed sync.Map
+
+	// slidingTTLs holds SetSlidingTTL idle timeouts by scope.
+	slidingMu   sync.RWMutex
+	slidingTTLs map[MemoryScope]time.Duration
+
+	// tracer is nil unless WithTracer is set.
+	tracer Tracer
+
+	// counters is nil unless WithStats is set.
+	counters *memoryCounters
 }
 
 // NewMemory creates a Memory instance with the given backend.
 // If backend is nil, an in-memory backend is used.
-func NewMemory(backend MemoryBackend) *Memory {
+func NewMemory(backend MemoryBackend, opts ...MemoryOption) *Memory {
+	return NewMemoryWithConfig(backend, MemoryConfig{}, opts...)
+}
+
+// NewMemoryStrict is like NewMemory but returns ErrNilMemoryBackend instead
+// of falling back to an in-memory backend, so a missing backend cannot leave
+// an application silently running on an ephemeral store.
+func NewMemoryStrict(backend MemoryBackend, opts ...MemoryOption) (*Memory, error) {
+	if backend == nil {
+		return nil, ErrNilMemoryBackend
+	}
+	return NewMemory(backend, opts...), nil
+}
+
+// NewMemoryWithConfig creates a Memory instance with the given backend and config.
+// If backend is nil, an in-memory backend is used.
+func NewMemoryWithConfig(backend MemoryBackend, config MemoryConfig, opts ...MemoryOption) *Memory {
 	if backend == nil {
 		backend = NewInMemoryBackend()
 	}
-	return &Memory{backend: backend}
+	m := &Memory{backend: backend, config: config, codec: JSONCodec{}, deserializeLogger: log.Default()}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// Backend returns the MemoryBackend m was constructed with, so callers can
+// type-assert to a concrete backend for native features. Calls made on it
+// directly bypass Memory's scope resolution, key namespacing, timeouts,
+// auditing and strict scope checks.
+func (m *Memory) Backend() MemoryBackend {
+	return m.backend
+}
+
+// backendKey identifies a key across scopes for in-process bookkeeping.
+func (m *Memory) backendKey(scope MemoryScope, scopeID, key string) string {
+	return string(scope) + ":" + scopeID + "\x00" + key
+}
+
+// call runs a backend operation, bounding it by OpTimeout when configured.
+// The backend call itself is not interrupted; its result is discarded if it
+// finishes after the deadline.
+func (m *Memory) call(ctx context.Context, op func() error) error {
+	if m.config.OpTimeout <= 0 {
+		return op()
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, cancel := context.WithTimeout(ctx, m.config.OpTimeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- op()
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("memory operation: %w", ctx.Err())
+	}
 }
 
 // Set stores a value in the session scope (default scope).
 func (m *Memory) Set(ctx context.Context, key string, value any) error {
-	execCtx := ExecutionContextFrom(ctx)
-	scopeID := execCtx.SessionID
-	if scopeID == "" {
-		scopeID = execCtx.RunID
-	}
-	return m.backend.Set(ScopeSession, scopeID, key, value)
+	return m.SessionScope().Set(ctx, key, value)
+}
+
+// GetOrCompute returns the session-scoped value for key, computing and storing
+// it on a miss. See ScopedMemory.GetOrCompute.
+func (m *Memory) GetOrCompute(ctx context.Context, key string, compute func() (any, error)) (any, error) {
+	return m.SessionScope().GetOrCompute(ctx, key, compute)
+}
+
+// SetIfAbsent stores a value in the session scope only if the key does not exist.
+func (m *Memory) SetIfAbsent(ctx context.Context, key string, value any) (bool, error) {
+	return m.SessionScope().SetIfAbsent(ctx, key, value)
+}
+
+// SetIfPresent stores a value in the session scope only if the key already exists.
+func (m *Memory) SetIfPresent(ctx context.Context, key string, value any) (bool, error) {
+	return m.SessionScope().SetIfPresent(ctx, key, value)
+}
+
+// GetSet stores a value in the session scope and returns the one it replaced.
+func (m *Memory) GetSet(ctx context.Context, key string, value any) (any, bool, error) {
+	return m.SessionScope().GetSet(ctx, key, value)
+}
+
+// GetAndDelete removes a key from the session scope and returns the value it held.
+func (m *Memory) GetAndDelete(ctx context.Context, key string) (any, bool, error) {
+	return m.SessionScope().GetAndDelete(ctx, key)
+}
+
+// Version returns the version of a key in the session scope.
+func (m *Memory) Version(ctx context.Context, key string) (uint64, bool, error) {
+	return m.SessionScope().Version(ctx, key)
 }
 
 // Get retrieves a value from the session scope (default scope).
-// Returns nil if the key does not exist.
+// Returns nil if the key does not exist, which cannot be told apart from a
+// stored nil; use GetFound when nil is a meaningful value.
 func (m *Memory) Get(ctx context.Context, key string) (any, error) {
-	execCtx := ExecutionContextFrom(ctx)
-	scopeID := execCtx.SessionID
-	if scopeID == "" {
-		scopeID = execCtx.RunID
+	return m.SessionScope().Get(ctx, key)
+}
+
+// GetFound retrieves a value from the session scope and reports whether the
+// key exists, so a stored nil is distinguishable from a missing key.
+func (m *Memory) GetFound(ctx context.Context, key string) (any, bool, error) {
+	return m.SessionScope().GetFound(ctx, key)
+}
+
+// GetTyped retrieves a value from the session scope and decodes it into dest.
+func (m *Memory) GetTyped(ctx context.Context, key string, dest any) error {
+	return m.SessionScope().GetTyped(ctx, key, dest)
+}
+
+// GetTypedFound retrieves a typed value from the session scope and reports
+// whether it exists. See ScopedMemory.GetTypedFound.
+func (m *Memory) GetTypedFound(ctx context.Context, key string, dest any) (bool, error) {
+	return m.SessionScope().GetTypedFound(ctx, key, dest)
+}
+
+// SetTyped encodes value with the configured codec and stores it in the session scope.
+func (m *Memory) SetTyped(ctx context.Context, key string, value any) error {
+	return m.SessionScope().SetTyped(ctx, key, value)
+}
+
+// Ping checks that the backend is reachable. Backends that do not implement
+// Pinger are assumed to be available and return nil.
+func (m *Memory) Ping(ctx context.Context) error {
+	pinger, ok := m.backend.(Pinger)
+	if !ok {
+		return nil
 	}
-	val, _, err := m.backend.Get(ScopeSession, scopeID, key)
-	return val, err
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return m.call(ctx, func() error {
+		return pinger.Ping(ctx)
+	})
+}
+
+// ScopeStats reports, per scope, the number of scope IDs and total keys stored.
+// Returns ErrScopeListingUnsupported if the backend cannot enumerate scope IDs.
+// For hit and miss counts see Stats.
+func (m *Memory) ScopeStats(ctx context.Context) ([]ScopeStats, error) {
+	lister, ok := m.backend.(ScopeIDLister)
+	if !ok {
+		return nil, ErrScopeListingUnsupported
+	}
+
+	stats := make([]ScopeStats, 0, len(allMemoryScopes))
+	for _, scope := range allMemoryScopes {
+		var scopeIDs []string
+		err := m.call(ctx, func() error {
+			var err error
+			scopeIDs, err = lister.ListScopeIDs(scope)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("list %s scope IDs: %w", scope, err)
+		}
+
+		entry := ScopeStats{Scope: scope, ScopeIDs: len(scopeIDs)}
+		for _, scopeID := range scopeIDs {
+			keys, err := m.adminScoped(scope, scopeID).List(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("list %s/%s keys: %w", scope, scopeID, err)
+			}
+			entry.Keys += len(keys)
+		}
+		stats = append(stats, entry)
+	}
+	return stats, nil
+}
+
+// ScopeSizes reports approximate bytes stored per scope ID within scope, for
+// capacity planning. Sizes are estimates: backends that implement ScopeSizer
+// report their own figure, otherwise each key is read back and counted as its
+// key length plus its JSON encoding, which differs from what a backend
+// actually stores. Returns ErrScopeListingUnsupported if the backend can
+// neither size nor enumerate scope IDs.
+func (m *Memory) ScopeSizes(scope MemoryScope) (map[string]int64, error) {
+	ctx := context.Background()
+	if sizer, ok := m.backend.(ScopeSizer); ok {
+		var sizes map[string]int64
+		err := m.call(ctx, func() error {
+			var err error
+			sizes, err = sizer.ScopeSizes(scope)
+			return err
+		})
+		return sizes, err
+	}
+
+	lister, ok := m.backend.(ScopeIDLister)
+	if !ok {
+		return nil, ErrScopeListingUnsupported
+	}
+	var scopeIDs []string
+	err := m.call(ctx, func() error {
+		var err error
+		scopeIDs, err = lister.ListScopeIDs(scope)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list %s scope IDs: %w", scope, err)
+	}
+
+	sizes := make(map[string]int64, len(scopeIDs))
+	for _, scopeID := range scopeIDs {
+		var size int64
+		err := m.adminScoped(scope, scopeID).ForEach(ctx, func(key string, value any) error {
+			size += int64(len(key)) + estimateValueSize(value)
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("size %s/%s: %w", scope, scopeID, err)
+		}
+		sizes[scopeID] = size
+	}
+	return sizes, nil
+}
+
+// estimateValueSize approximates the stored size of value by its JSON
+// encoding. Raw bytes count as-is since backends store them unencoded.
+func estimateValueSize(value any) int64 {
+	if raw, ok := encodedBytes(value); ok {
+		return int64(len(raw))
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return int64(len(fmt.Sprint(value)))
+	}
+	return int64(len(data))
 }
 
 // Scoped returns a ScopedMemory for a specific scope and ID.
 func (m *Memory) Scoped(scope MemoryScope, scopeID string) *ScopedMemory {
 	return &ScopedMemory{
-		backend: m.backend,
-		scope:   scope,
-		getID:   func(ctx context.Context) string { return scopeID },
+		memory: m,
+		scope:  scope,
+		getID:  func(ctx context.Context) string { return scopeID },
 	}
 }
 
+// adminScoped is Scoped for internal enumeration (ScopeStats, Search,
+// ScopeSizes): it does not run first-access hooks, which would otherwise write
+// warm-up data into every scope ID walked, and its reads neither extend
+// sliding TTLs nor count towards Stats, so walking a scope does not keep
+// idle keys alive.
+func (m *Memory) adminScoped(scope MemoryScope, scopeID string) *ScopedMemory {
+	scoped := m.Scoped(scope, scopeID)
+	scoped.admin = true
+	return scoped
+}
+
 // GetWithDefault retrieves a value from the session scope,
 // returning the default if the key does not exist.
 func (m *Memory) GetWithDefault(ctx context.Context, key string, defaultVal any) (any, error) {
-	execCtx := ExecutionContextFrom(ctx)
-	scopeID := execCtx.SessionID
-	if scopeID == "" {
-		scopeID = execCtx.RunID
+	return m.SessionScope().GetWithDefault(ctx, key, defaultVal)
+}
+
+// Delete removes a key from the session scope.
+func (m *Memory) Delete(ctx context.Context, key string) error {
+	return m.SessionScope().Delete(ctx, key)
+}
+
+// TTL reports the remaining lifetime of a key in the session scope (default scope).
+func (m *Memory) TTL(ctx context.Context, key string) (time.Duration, bool, error) {
+	return m.SessionScope().TTL(ctx, key)
+}
+
+// DeletePrefix removes all keys starting with prefix from the session scope (default scope).
+func (m *Memory) DeletePrefix(ctx context.Context, prefix string) (int, error) {
+	return m.SessionScope().DeletePrefix(ctx, prefix)
+}
+
+// Patch shallow-merges patch into the session-scoped object at key.
+// See ScopedMemory.Patch.
+func (m *Memory) Patch(ctx context.Context, key string, patch map[string]any) error {
+	return m.SessionScope().Patch(ctx, key, patch)
+}
+
+// Seed writes values into scope, e.g. default flags at boot, and returns how
+// many keys were written. A nil scope means the global scope. Without
+// overwrite, keys that already exist are left untouched; this uses
+// SetIfAbsent when the backend supports it and a Get-then-Set otherwise. Keys
+// are written in sorted order, stopping at the first error.
+func (m *Memory) Seed(ctx context.Context, scope *ScopedMemory, values map[string]any, overwrite bool) (int, error) {
+	if scope == nil {
+		scope = m.GlobalScope()
+	}
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	written := 0
+	for _, key := range keys {
+		ok, err := seedKey(ctx, scope, key, values[key], overwrite)
+		if err != nil {
+			return written, fmt.Errorf("seed %q: %w", key, err)
+		}
+		if ok {
+			written++
+		}
+	}
+	return written, nil
+}
+
+func seedKey(ctx context.Context, scope *ScopedMemory, key string, value any, overwrite bool) (bool, error) {
+	if overwrite {
+		return true, scope.Set(ctx, key, value)
+	}
+	written, err := scope.SetIfAbsent(ctx, key, value)
+	if !errors.Is(err, ErrConditionalSetUnsupported) {
+		return written, err
+	}
+	if _, found, err := scope.get(ctx, key); err != nil || found {
+		return false, err
+	}
+	return true, scope.Set(ctx, key, value)
+}
+
+// List returns all keys in the session scope.
+func (m *Memory) List(ctx context.Context) ([]string, error) {
+	return m.SessionScope().List(ctx)
+}
+
+// ListSorted returns all keys in the session scope in ascending order.
+func (m *Memory) ListSorted(ctx context.Context) ([]string, error) {
+	return m.SessionScope().ListSorted(ctx)
+}
+
+// Entries returns all key/value pairs in the session scope.
+func (m *Memory) Entries(ctx context.Context) (map[string]any, error) {
+	return m.SessionScope().Entries(ctx)
+}
+
+// ForEach calls fn for each entry in the session scope. See ScopedMemory.ForEach.
+func (m *Memory) ForEach(ctx context.Context, fn func(key string, value any) error) error {
+	return m.SessionScope().ForEach(ctx, fn)
+}
+
+// SetVector stores a vector in the session scope (default scope).
+func (m *Memory) SetVector(ctx context.Context, key string, embedding []float64, metadata map[string]any) error {
+	return m.SessionScope().SetVector(ctx, key, embedding, metadata)
+}
+
+// GetVector retrieves a vector from the session scope (default scope).
+func (m *Memory) GetVector(ctx context.Context, key string) (embedding []float64, metadata map[string]any, err error) {
+	return m.SessionScope().GetVector(ctx, key)
+}
+
+// SearchVector performs a similarity search across session scope (default).
+func (m *Memory) SearchVector(ctx context.Context, embedding []float64, opts SearchOptions) ([]VectorSearchResult, error) {
+	return m.SessionScope().SearchVector(ctx, embedding, opts)
+}
+
+// DeleteVector removes a vector from the session scope (default scope).
+func (m *Memory) DeleteVector(ctx context.Context, key string) error {
+	return m.SessionScope().DeleteVector(ctx, key)
+}
+
+// WorkflowScope returns a ScopedMemory for workflow-level storage.
+// Data is isolated to the current workflow execution.
+func (m *Memory) WorkflowScope() *ScopedMemory {
+	return m.scoped(ScopeWorkflow)
+}
+
+// SessionScope returns a ScopedMemory for session-level storage.
+// Data persists across workflow executions within the same session.
+func (m *Memory) SessionScope() *ScopedMemory {
+	return m.scoped(ScopeSession)
+}
+
+// UserScope returns a ScopedMemory for user/actor-level storage.
+// Data persists across sessions for the same user.
+func (m *Memory) UserScope() *ScopedMemory {
+	return m.scoped(ScopeUser)
+}
+
+// GlobalScope returns a ScopedMemory for global storage.
+// Data is shared across all sessions, users, and workflows.
+func (m *Memory) GlobalScope() *ScopedMemory {
+	return m.scoped(ScopeGlobal)
+}
+
+// scoped builds a ScopedMemory whose scope ID comes from the configured
+// resolver for scope, falling back to the default chain.
+func (m *Memory) scoped(scope MemoryScope) *ScopedMemory {
+	resolve, ok := m.resolvers[scope]
+	if !ok {
+		resolve = defaultScopeResolvers[scope]
+	}
+	return &ScopedMemory{
+		memory: m,
+		scope:  scope,
+		getID: func(ctx context.Context) string {
+			return resolve(ExecutionContextFrom(ctx))
+		},
 	}
-	val, found, err := m.backend.Get(ScopeSession, scopeID, key)
+}
+
+// ScopedMemory provides memory operations within a specific scope.
+type ScopedMemory struct {
+	memory *Memory
+	scope  MemoryScope
+	getID  func(context.Context) string
+	prefix string // prepended to every key; set by Sub
+	// admin marks views created by adminScoped: they skip first-access
+	// hooks, sliding TTL refreshes and Stats read counting.
+	admin bool
+}
+
+// Sub returns a view of this scope whose keys are transparently prefixed with
+// prefix + "/". Keys passed to and returned from the view are unprefixed, so
+// library code can keep its own namespace without knowing the scope it runs
+// in. Subs nest: s.Sub("a").Sub("b") stores key k as "a/b/k".
+func (s *ScopedMemory) Sub(prefix string) *ScopedMemory {
+	return &ScopedMemory{
+		memory: s.memory,
+		scope:  s.scope,
+		getID:  s.getID,
+		prefix: s.prefix + prefix + "/",
+		admin:  s.admin,
+	}
+}
+
+// fullKey maps a key in this view to the key stored in the backend.
+func (s *ScopedMemory) fullKey(key string) string {
+	return s.prefix + key
+}
+
+// localKey maps a backend key back into this view, reporting false for keys
+// outside the view's prefix.
+func (s *ScopedMemory) localKey(key string) (string, bool) {
+	if !strings.HasPrefix(key, s.prefix) {
+		return "", false
+	}
+	return key[len(s.prefix):], true
+}
+
+// Set stores a value in this scope.
+func (s *ScopedMemory) Set(ctx context.Context, key string, value any) error {
+	if err := s.memory.checkSchema(s.fullKey(key), value); err != nil {
+		return err
+	}
+	return s.set(ctx, key, value)
+}
+
+// set stores value without schema validation, for callers that validated it
+// before encoding.
+func (s *ScopedMemory) set(ctx context.Context, key string, value any) error {
+	scopeID, err := s.resolveID(ctx)
+	if err != nil {
+		return err
+	}
+	if err := s.memory.strict.checkWrite(s.scope, scopeID, s.fullKey(key)); err != nil {
+		return err
+	}
+	err = s.call(ctx, "set", func() error {
+		if ttl := s.memory.slidingTTL(s.scope); ttl > 0 {
+			return s.memory.backend.(TTLRefresher).SetWithTTL(s.scope, scopeID, s.fullKey(key), value, ttl)
+		}
+		return s.memory.backend.Set(s.scope, scopeID, s.fullKey(key), value)
+	})
+	if err != nil {
+		return err
+	}
+	s.memory.counters.recordSet()
+	s.memory.audit(ctx, AuditOpSet, s.scope, scopeID, s.fullKey(key), value)
+	return nil
+}
+
+// GetOrCompute returns the value for key if present. Otherwise it calls compute,
+// stores the result and returns it. Concurrent misses on the same key within
+// this Memory share a single compute call; errors from compute are returned to
+// every waiting caller and nothing is stored.
+func (s *ScopedMemory) GetOrCompute(ctx context.Context, key string, compute func() (any, error)) (any, error) {
+	val, found, err := s.get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		return val, nil
+	}
+
+	flightKey := s.memory.backendKey(s.scope, s.getID(ctx), s.fullKey(key))
+	val, err, _ = s.memory.flight.Do(flightKey, func() (any, error) {
+		// A previous flight may have stored the value since our miss.
+		if val, found, err := s.get(ctx, key); err != nil || found {
+			return val, err
+		}
+		computed, err := compute()
+		if err != nil {
+			return nil, err
+		}
+		if err := s.Set(ctx, key, computed); err != nil {
+			return nil, err
+		}
+		return computed, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return val, nil
+}
+
+// SetIfAbsent stores a value in this scope only if the key does not already
+// exist, reporting whether the write happened. The backend must implement
+// ConditionalBackend; otherwise ErrConditionalSetUnsupported is returned.
+func (s *ScopedMemory) SetIfAbsent(ctx context.Context, key string, value any) (bool, error) {
+	return s.setIf(ctx, key, value, true)
+}
+
+// SetIfPresent stores a value in this scope only if the key already exists,
+// reporting whether the write happened. The backend must implement
+// ConditionalBackend; otherwise ErrConditionalSetUnsupported is returned.
+func (s *ScopedMemory) SetIfPresent(ctx context.Context, key string, value any) (bool, error) {
+	return s.setIf(ctx, key, value, false)
+}
+
+func (s *ScopedMemory) setIf(ctx context.Context, key string, value any, absent bool) (bool, error) {
+	cb, ok := s.memory.backend.(ConditionalBackend)
+	if !ok {
+		return false, ErrConditionalSetUnsupported
+	}
+	if err := s.memory.checkSchema(s.fullKey(key), value); err != nil {
+		return false, err
+	}
+	scopeID, err := s.resolveID(ctx)
+	if err != nil {
+		return false, err
+	}
+	if err := s.memory.strict.checkWrite(s.scope, scopeID, s.fullKey(key)); err != nil {
+		return false, err
+	}
+	op := "set_if_present"
+	if absent {
+		op = "set_if_absent"
+	}
+	var written bool
+	err = s.call(ctx, op, func() error {
+		var err error
+		if absent {
+			written, err = cb.SetIfAbsent(s.scope, scopeID, s.fullKey(key), value)
+		} else {
+			written, err = cb.SetIfPresent(s.scope, scopeID, s.fullKey(key), value)
+		}
+		if err != nil || !written {
+			return err
+		}
+		s.refreshSlidingTTL(scopeID, s.fullKey(key))
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	if written {
+		s.memory.counters.recordSet()
+		s.memory.audit(ctx, AuditOpSet, s.scope, scopeID, s.fullKey(key), value)
+	}
+	return written, nil
+}
+
+// GetSet stores a value in this scope and returns the previous value and
+// whether the key existed, as one atomic operation. Use it instead of Get
+// followed by Set when the prior value matters. The backend must implement
+// GetSetter; otherwise ErrGetSetUnsupported is returned.
+func (s *ScopedMemory) GetSet(ctx context.Context, key string, value any) (any, bool, error) {
+	gs, ok := s.memory.backend.(GetSetter)
+	if !ok {
+		return nil, false, ErrGetSetUnsupported
+	}
+	if err := s.memory.checkSchema(s.fullKey(key), value); err != nil {
+		return nil, false, err
+	}
+	scopeID, err := s.resolveID(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	if err := s.memory.strict.checkWrite(s.scope, scopeID, s.fullKey(key)); err != nil {
+		return nil, false, err
+	}
+	var (
+		previous any
+		existed  bool
+	)
+	err = s.call(ctx, "get_set", func() error {
+		var err error
+		previous, existed, err = gs.GetSet(s.scope, scopeID, s.fullKey(key), value)
+		if err != nil {
+			return err
+		}
+		s.refreshSlidingTTL(scopeID, s.fullKey(key))
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	s.memory.counters.recordSet()
+	s.memory.audit(ctx, AuditOpSet, s.scope, scopeID, s.fullKey(key), value)
+	return previous, existed, nil
+}
+
+// GetAndDelete removes key from this scope and returns the value it held and
+// whether it existed, as one atomic operation. Concurrent callers popping the
+// same key see it exist at most once, which makes it a simple claim or queue
+// pop. The backend must implement GetDeleter; otherwise
+// ErrGetAndDeleteUnsupported is returned.
+func (s *ScopedMemory) GetAndDelete(ctx context.Context, key string) (any, bool, error) {
+	gd, ok := s.memory.backend.(GetDeleter)
+	if !ok {
+		return nil, false, ErrGetAndDeleteUnsupported
+	}
+	scopeID, err := s.resolveID(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	var (
+		value   any
+		existed bool
+	)
+	err = s.call(ctx, "get_and_delete", func() error {
+		var err error
+		value, existed, err = gd.GetAndDelete(s.scope, scopeID, s.fullKey(key))
+		return err
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	if existed {
+		s.memory.strict.release(s.scope, scopeID, s.fullKey(key))
+		s.memory.counters.recordDelete()
+		s.memory.audit(ctx, AuditOpDelete, s.scope, scopeID, s.fullKey(key), nil)
+	}
+	return value, existed, nil
+}
+
+// Version returns the version of key's current value and whether the key
+// exists. Every write bumps the version, so comparing versions detects
+// changes (e.g. for ETags) without comparing values. The backend must
+// implement Versioner; otherwise ErrVersionUnsupported is returned.
+func (s *ScopedMemory) Version(ctx context.Context, key string) (uint64, bool, error) {
+	v, ok := s.memory.backend.(Versioner)
+	if !ok {
+		return 0, false, ErrVersionUnsupported
+	}
+	scopeID, err := s.resolveID(ctx)
+	if err != nil {
+		return 0, false, err
+	}
+	var (
+		version uint64
+		found   bool
+	)
+	err = s.call(ctx, "version", func() error {
+		var err error
+		version, found, err = v.Version(s.scope, scopeID, s.fullKey(key))
+		return err
+	})
+	if err != nil {
+		return 0, false, err
+	}
+	return version, found, nil
+}
+
+// get fetches a raw value and whether it was found.
+func (s *ScopedMemory) get(ctx context.Context, key string) (any, bool, error) {
+	scopeID, err := s.resolveID(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	var (
+		val   any
+		found bool
+	)
+	err = s.call(ctx, "get", func() error {
+		var err error
+		val, found, err = s.memory.backend.Get(s.scope, scopeID, s.fullKey(key))
+		if err != nil || !found {
+			return err
+		}
+		// A key that expired between the two calls was still read.
+		s.refreshSlidingTTL(scopeID, s.fullKey(key))
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	s.readCounters().recordRead(found)
+	return val, found, nil
+}
+
+// Get retrieves a value from this scope.
+// Returns nil if the key does not exist, which cannot be told apart from a
+// stored nil; use GetFound when nil is a meaningful value.
+func (s *ScopedMemory) Get(ctx context.Context, key string) (any, error) {
+	val, _, err := s.get(ctx, key)
+	return val, err
+}
+
+// GetFound retrieves a value from this scope and reports whether the key
+// exists, so a stored nil is distinguishable from a missing key.
+func (s *ScopedMemory) GetFound(ctx context.Context, key string) (any, bool, error) {
+	return s.get(ctx, key)
+}
+
+// GetDecoded retrieves a value from this scope decoded with the Memory's
+// codec into an untyped value, so encoded values written by SetTyped come back
+// as maps, slices and numbers rather than bytes. With WithJSONNumbers numbers
+// are json.Number. Returns nil if the key does not exist.
+func (s *ScopedMemory) GetDecoded(ctx context.Context, key string) (any, error) {
+	var val any
+	if _, err := s.GetTypedFound(ctx, key, &val); err != nil {
+		return nil, err
+	}
+	return val, nil
+}
+
+// GetWithDefault retrieves a value from this scope,
+// returning the default if the key does not exist.
+func (s *ScopedMemory) GetWithDefault(ctx context.Context, key string, defaultVal any) (any, error) {
+	val, found, err := s.get(ctx, key)
 	if err != nil {
 		return nil, err
 	}
@@ -124,242 +1162,605 @@ func (m *Memory) GetWithDefault(ctx context.Context, key string, defaultVal any)
 	return val, nil
 }
 
-// Delete removes a key from the session scope.
-func (m *Memory) Delete(ctx context.Context, key string) error {
-	execCtx := ExecutionContextFrom(ctx)
-	scopeID := execCtx.SessionID
-	if scopeID == "" {
-		scopeID = execCtx.RunID
+// Delete removes a key from this scope.
+func (s *ScopedMemory) Delete(ctx context.Context, key string) error {
+	scopeID, err := s.resolveID(ctx)
+	if err != nil {
+		return err
 	}
-	return m.backend.Delete(ScopeSession, scopeID, key)
+	err = s.call(ctx, "delete", func() error {
+		return s.memory.backend.Delete(s.scope, scopeID, s.fullKey(key))
+	})
+	if err != nil {
+		return err
+	}
+	s.memory.strict.release(s.scope, scopeID, s.fullKey(key))
+	s.memory.counters.recordDelete()
+	s.memory.audit(ctx, AuditOpDelete, s.scope, scopeID, s.fullKey(key), nil)
+	return nil
 }
 
-// List returns all keys in the session scope.
-func (m *Memory) List(ctx context.Context) ([]string, error) {
-	execCtx := ExecutionContextFrom(ctx)
-	scopeID := execCtx.SessionID
-	if scopeID == "" {
-		scopeID = execCtx.RunID
+// TTL returns how long key has left to live in this scope and whether it
+// exists. Keys that never expire, including every key on backends that do not
+// implement TTLReader, report NoExpiry.
+func (s *ScopedMemory) TTL(ctx context.Context, key string) (time.Duration, bool, error) {
+	scopeID, err := s.resolveID(ctx)
+	if err != nil {
+		return 0, false, err
+	}
+	var (
+		ttl   time.Duration
+		found bool
+	)
+	err = s.call(ctx, "ttl", func() error {
+		var err error
+		if reader, ok := s.memory.backend.(TTLReader); ok {
+			ttl, found, err = reader.TTL(s.scope, scopeID, s.fullKey(key))
+			return err
+		}
+		ttl = NoExpiry
+		_, found, err = s.memory.backend.Get(s.scope, scopeID, s.fullKey(key))
+		return err
+	})
+	if err != nil {
+		return 0, false, err
+	}
+	if !found {
+		return 0, false, nil
 	}
-	return m.backend.List(ScopeSession, scopeID)
+	return ttl, true, nil
 }
 
-// SetVector stores a vector in the session scope (default scope).
-func (m *Memory) SetVector(ctx context.Context, key string, embedding []float64, metadata map[string]any) error {
-	execCtx := ExecutionContextFrom(ctx)
-	scopeID := execCtx.SessionID
-	if scopeID == "" {
-		scopeID = execCtx.RunID
+// DeletePrefix removes every key in this scope starting with prefix and
+// returns the number removed. Backends implementing PrefixDeleter serve this
+// in one call; others fall back to List plus Delete per matching key. When the
+// backend handles it, a single audit entry is recorded with key prefix+"*".
+func (s *ScopedMemory) DeletePrefix(ctx context.Context, prefix string) (int, error) {
+	scopeID, err := s.resolveID(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if pd, ok := s.memory.backend.(PrefixDeleter); ok {
+		var deleted int
+		err := s.call(ctx, "delete_prefix", func() error {
+			var err error
+			deleted, err = pd.DeletePrefix(s.scope, scopeID, s.fullKey(prefix))
+			return err
+		})
+		if err != nil {
+			return 0, err
+		}
+		s.memory.strict.releasePrefix(s.scope, scopeID, s.fullKey(prefix))
+		s.memory.counters.recordDeletes(deleted)
+		if deleted > 0 {
+			s.memory.audit(ctx, AuditOpDelete, s.scope, scopeID, s.fullKey(prefix)+"*", nil)
+		}
+		return deleted, nil
 	}
-	return m.backend.SetVector(ScopeSession, scopeID, key, embedding, metadata)
+
+	keys, err := s.List(ctx)
+	if err != nil {
+		return 0, err
+	}
+	deleted := 0
+	for _, key := range keys {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		if err := s.Delete(ctx, key); err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+	return deleted, nil
 }
 
-// GetVector retrieves a vector from the session scope (default scope).
-func (m *Memory) GetVector(ctx context.Context, key string) (embedding []float64, metadata map[string]any, err error) {
-	execCtx := ExecutionContextFrom(ctx)
-	scopeID := execCtx.SessionID
-	if scopeID == "" {
-		scopeID = execCtx.RunID
+// Patch shallow-merges patch into the object stored at key: each top-level key
+// in patch replaces the stored one. If key is absent it is created holding a
+// copy of patch. Patch only works on object values (maps, or encoded objects
+// as written by SetTyped) and returns ErrPatchNotObject for anything else.
+//
+// When the backend implements ConditionalBackend and CompareAndSwapper the
+// merge is written only if key is unchanged since it was read, retrying
+// otherwise, so concurrent writers never lose updates. Other backends fall
+// back to serializing Patch calls through the same Memory; writes from other
+// processes or through Set are then not atomic with respect to the merge.
+func (s *ScopedMemory) Patch(ctx context.Context, key string, patch map[string]any) error {
+	_, conditional := s.memory.backend.(ConditionalBackend)
+	_, swapper := s.memory.backend.(CompareAndSwapper)
+	if !conditional || !swapper {
+		s.memory.patchMu.Lock()
+		defer s.memory.patchMu.Unlock()
+
+		val, found, err := s.get(ctx, key)
+		if err != nil {
+			return err
+		}
+		merged, err := s.mergePatch(key, val, found, patch)
+		if err != nil {
+			return err
+		}
+		return s.Set(ctx, key, merged)
+	}
+
+	for {
+		val, found, err := s.get(ctx, key)
+		if err != nil {
+			return err
+		}
+		merged, err := s.mergePatch(key, val, found, patch)
+		if err != nil {
+			return err
+		}
+		var written bool
+		if found {
+			written, err = s.compareAndSwap(ctx, key, val, merged)
+		} else {
+			written, err = s.SetIfAbsent(ctx, key, merged)
+		}
+		if err != nil || written {
+			return err
+		}
+		// Another writer got there first; merge into what it stored.
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+}
+
+// mergePatch returns the value Patch stores for key given its current value,
+// keeping encoded objects encoded.
+func (s *ScopedMemory) mergePatch(key string, val any, found bool, patch map[string]any) (any, error) {
+	if !found {
+		merged := make(map[string]any, len(patch))
+		for k, v := range patch {
+			merged[k] = v
+		}
+		return merged, nil
+	}
+
+	codec := s.memory.codec
+	var (
+		object  map[string]any
+		encoded bool
+	)
+	if v, ok := val.(map[string]any); ok {
+		object = make(map[string]any, len(v)+len(patch))
+		for k, field := range v {
+			object[k] = field
+		}
+	} else if raw, ok := encodedBytes(val); ok {
+		if err := codec.Unmarshal(raw, &object); err != nil || object == nil {
+			return nil, fmt.Errorf("patch %q: %w", key, ErrPatchNotObject)
+		}
+		encoded = true
+	} else {
+		return nil, fmt.Errorf("patch %q: %w", key, ErrPatchNotObject)
+	}
+
+	for k, v := range patch {
+		object[k] = v
+	}
+	if !encoded {
+		return object, nil
+	}
+	data, err := codec.Marshal(object)
+	if err != nil {
+		return nil, err
+	}
+	return s.memory.encoded(data), nil
+}
+
+// compareAndSwap stores value at key only if it still holds old, with the
+// same checks and bookkeeping as Set. The backend must implement
+// CompareAndSwapper.
+func (s *ScopedMemory) compareAndSwap(ctx context.Context, key string, old, value any) (bool, error) {
+	if err := s.memory.checkSchema(s.fullKey(key), value); err != nil {
+		return false, err
+	}
+	scopeID, err := s.resolveID(ctx)
+	if err != nil {
+		return false, err
+	}
+	if err := s.memory.strict.checkWrite(s.scope, scopeID, s.fullKey(key)); err != nil {
+		return false, err
 	}
-	embedding, metadata, found, err := m.backend.GetVector(ScopeSession, scopeID, key)
+	var written bool
+	err = s.call(ctx, "compare_and_swap", func() error {
+		var err error
+		written, err = s.memory.backend.(CompareAndSwapper).CompareAndSwap(s.scope, scopeID, s.fullKey(key), old, value)
+		if err != nil || !written {
+			return err
+		}
+		s.refreshSlidingTTL(scopeID, s.fullKey(key))
+		return nil
+	})
 	if err != nil {
-		return nil, nil, err
+		return false, err
 	}
-	if !found {
-		return nil, nil, nil
+	if written {
+		s.memory.counters.recordSet()
+		s.memory.audit(ctx, AuditOpSet, s.scope, scopeID, s.fullKey(key), value)
 	}
-	return embedding, metadata, nil
+	return written, nil
 }
 
-// SearchVector performs a similarity search across session scope (default).
-func (m *Memory) SearchVector(ctx context.Context, embedding []float64, opts SearchOptions) ([]VectorSearchResult, error) {
-	execCtx := ExecutionContextFrom(ctx)
-	scopeID := execCtx.SessionID
-	if scopeID == "" {
-		scopeID = execCtx.RunID
+// List returns all keys in this scope in whatever order the backend yields
+// them; InMemoryBackend, for one, returns map iteration order. Use ListSorted
+// when callers need a stable order.
+func (s *ScopedMemory) List(ctx context.Context) ([]string, error) {
+	scopeID, err := s.resolveID(ctx)
+	if err != nil {
+		return nil, err
 	}
-	return m.backend.SearchVector(ScopeSession, scopeID, embedding, opts)
-}
-
-// DeleteVector removes a vector from the session scope (default scope).
-func (m *Memory) DeleteVector(ctx context.Context, key string) error {
-	execCtx := ExecutionContextFrom(ctx)
-	scopeID := execCtx.SessionID
-	if scopeID == "" {
-		scopeID = execCtx.RunID
+	var keys []string
+	err = s.call(ctx, "list", func() error {
+		var err error
+		keys, err = s.memory.backend.List(s.scope, scopeID)
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
-	return m.backend.DeleteVector(ScopeSession, scopeID, key)
-}
-
-// WorkflowScope returns a ScopedMemory for workflow-level storage.
-// Data is isolated to the current workflow execution.
-func (m *Memory) WorkflowScope() *ScopedMemory {
-	return &ScopedMemory{
-		backend: m.backend,
-		scope:   ScopeWorkflow,
-		getID: func(ctx context.Context) string {
-			execCtx := ExecutionContextFrom(ctx)
-			if execCtx.WorkflowID != "" {
-				return execCtx.WorkflowID
-			}
-			return execCtx.RunID
-		},
+	if s.prefix == "" {
+		return keys, nil
+	}
+	local := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if key, ok := s.localKey(key); ok {
+			local = append(local, key)
+		}
 	}
+	return local, nil
 }
 
-// SessionScope returns a ScopedMemory for session-level storage.
-// Data persists across workflow executions within the same session.
-func (m *Memory) SessionScope() *ScopedMemory {
-	return &ScopedMemory{
-		backend: m.backend,
-		scope:   ScopeSession,
-		getID: func(ctx context.Context) string {
-			execCtx := ExecutionContextFrom(ctx)
-			if execCtx.SessionID != "" {
-				return execCtx.SessionID
-			}
-			return execCtx.RunID
-		},
+// ListSorted is List with the keys sorted in ascending order, for stable
+// pagination and output.
+func (s *ScopedMemory) ListSorted(ctx context.Context) ([]string, error) {
+	keys, err := s.List(ctx)
+	if err != nil {
+		return nil, err
 	}
+	sort.Strings(keys)
+	return keys, nil
 }
 
-// UserScope returns a ScopedMemory for user/actor-level storage.
-// Data persists across sessions for the same user.
-func (m *Memory) UserScope() *ScopedMemory {
-	return &ScopedMemory{
-		backend: m.backend,
-		scope:   ScopeUser,
-		getID: func(ctx context.Context) string {
-			execCtx := ExecutionContextFrom(ctx)
-			if execCtx.ActorID != "" {
-				return execCtx.ActorID
+// Entries returns all key/value pairs in this scope. Backends implementing
+// EntriesBackend serve this in one call; others fall back to List plus Get per key.
+func (s *ScopedMemory) Entries(ctx context.Context) (map[string]any, error) {
+	scopeID, err := s.resolveID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if eb, ok := s.memory.backend.(EntriesBackend); ok {
+		var local map[string]any
+		err := s.call(ctx, "entries", func() error {
+			entries, err := eb.Entries(s.scope, scopeID)
+			if err != nil {
+				return err
 			}
-			// Fall back to session if no actor
-			if execCtx.SessionID != "" {
-				return execCtx.SessionID
+			local = make(map[string]any, len(entries))
+			for fullKey, val := range entries {
+				key, ok := s.localKey(fullKey)
+				if !ok {
+					continue
+				}
+				local[key] = val
+				s.refreshSlidingTTL(scopeID, fullKey)
 			}
-			return execCtx.RunID
-		},
-	}
-}
-
-// GlobalScope returns a ScopedMemory for global storage.
-// Data is shared across all sessions, users, and workflows.
-func (m *Memory) GlobalScope() *ScopedMemory {
-	return &ScopedMemory{
-		backend: m.backend,
-		scope:   ScopeGlobal,
-		getID: func(ctx context.Context) string {
-			return "global"
-		},
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		s.readCounters().recordHits(len(local))
+		return local, nil
 	}
-}
-
-// ScopedMemory provides memory operations within a specific scope.
-type ScopedMemory struct {
-	backend MemoryBackend
-	scope   MemoryScope
-	getID   func(context.Context) string
-}
-
-// Set stores a value in this scope.
-func (s *ScopedMemory) Set(ctx context.Context, key string, value any) error {
-	return s.backend.Set(s.scope, s.getID(ctx), key, value)
-}
-
-// Get retrieves a value from this scope.
-// Returns nil if the key does not exist.
-func (s *ScopedMemory) Get(ctx context.Context, key string) (any, error) {
-	val, _, err := s.backend.Get(s.scope, s.getID(ctx), key)
-	return val, err
-}
 
-// GetWithDefault retrieves a value from this scope,
-// returning the default if the key does not exist.
-func (s *ScopedMemory) GetWithDefault(ctx context.Context, key string, defaultVal any) (any, error) {
-	val, found, err := s.backend.Get(s.scope, s.getID(ctx), key)
+	// Fetch in sorted order so a failing key is reported deterministically.
+	keys, err := s.ListSorted(ctx)
 	if err != nil {
 		return nil, err
 	}
-	if !found {
-		return defaultVal, nil
+	entries := make(map[string]any, len(keys))
+	for _, key := range keys {
+		val, found, err := s.get(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		// Keys deleted between List and Get are skipped.
+		if found {
+			entries[key] = val
+		}
 	}
-	return val, nil
-}
-
-// Delete removes a key from this scope.
-func (s *ScopedMemory) Delete(ctx context.Context, key string) error {
-	return s.backend.Delete(s.scope, s.getID(ctx), key)
+	return entries, nil
 }
 
-// List returns all keys in this scope.
-func (s *ScopedMemory) List(ctx context.Context) ([]string, error) {
-	return s.backend.List(s.scope, s.getID(ctx))
+// ForEach calls fn with each key and value in this scope, stopping at and
+// returning the first error from fn or from the context. Only the key list is
+// held in memory; values are fetched one at a time, so keys deleted mid-walk
+// are skipped. No backend lock is held while fn runs, so fn may read or write
+// this Memory. Keys are visited in ascending order.
+func (s *ScopedMemory) ForEach(ctx context.Context, fn func(key string, value any) error) error {
+	keys, err := s.ListSorted(ctx)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if ctx != nil {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+		val, found, err := s.get(ctx, key)
+		if err != nil {
+			return err
+		}
+		if !found {
+			continue
+		}
+		if err := fn(key, val); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // SetVector stores a vector in this scope.
 func (s *ScopedMemory) SetVector(ctx context.Context, key string, embedding []float64, metadata map[string]any) error {
-	return s.backend.SetVector(s.scope, s.getID(ctx), key, embedding, metadata)
+	scopeID, err := s.resolveID(ctx)
+	if err != nil {
+		return err
+	}
+	return s.call(ctx, "set_vector", func() error {
+		return s.memory.backend.SetVector(s.scope, scopeID, s.fullKey(key), embedding, metadata)
+	})
 }
 
 // GetVector retrieves a vector from this scope.
 func (s *ScopedMemory) GetVector(ctx context.Context, key string) (embedding []float64, metadata map[string]any, err error) {
-	embedding, metadata, found, err := s.backend.GetVector(s.scope, s.getID(ctx), key)
+	scopeID, err := s.resolveID(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	// The closure writes locals rather than the named results: after a
+	// timeout the backend call may still be running when we return.
+	var (
+		vec   []float64
+		meta  map[string]any
+		found bool
+	)
+	err = s.call(ctx, "get_vector", func() error {
+		var err error
+		vec, meta, found, err = s.memory.backend.GetVector(s.scope, scopeID, s.fullKey(key))
+		return err
+	})
 	if err != nil {
 		return nil, nil, err
 	}
 	if !found {
 		return nil, nil, nil
 	}
-	return embedding, metadata, nil
+	return vec, meta, nil
 }
 
-// SearchVector performs a similarity search in this scope.
+// SearchVector performs a similarity search in this scope. On a Sub view
+// only vectors under the view's prefix are returned, with opts.Limit applied
+// to those.
 func (s *ScopedMemory) SearchVector(ctx context.Context, embedding []float64, opts SearchOptions) ([]VectorSearchResult, error) {
-	return s.backend.SearchVector(s.scope, s.getID(ctx), embedding, opts)
+	scopeID, err := s.resolveID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	search := func(opts SearchOptions) ([]VectorSearchResult, error) {
+		var results []VectorSearchResult
+		err := s.call(ctx, "search_vector", func() error {
+			var err error
+			results, err = s.memory.backend.SearchVector(s.scope, scopeID, embedding, opts)
+			return err
+		})
+		return results, err
+	}
+	if s.prefix == "" {
+		return search(opts)
+	}
+
+	// The backend applies Limit before matches outside the view are
+	// dropped, so widen the backend limit until the view has enough results
+	// or the backend runs out of matches.
+	fetch := opts
+	for {
+		results, err := search(fetch)
+		if err != nil {
+			return nil, err
+		}
+		local := make([]VectorSearchResult, 0, len(results))
+		for _, result := range results {
+			if key, ok := s.localKey(result.Key); ok {
+				result.Key = key
+				local = append(local, result)
+			}
+		}
+		if opts.Limit <= 0 {
+			return local, nil
+		}
+		if len(local) >= opts.Limit {
+			return local[:opts.Limit], nil
+		}
+		if len(results) < fetch.Limit {
+			return local, nil
+		}
+		fetch.Limit *= 2
+	}
 }
 
 // DeleteVector removes a vector from this scope.
 func (s *ScopedMemory) DeleteVector(ctx context.Context, key string) error {
-	return s.backend.DeleteVector(s.scope, s.getID(ctx), key)
+	scopeID, err := s.resolveID(ctx)
+	if err != nil {
+		return err
+	}
+	return s.call(ctx, "delete_vector", func() error {
+		return s.memory.backend.DeleteVector(s.scope, scopeID, s.fullKey(key))
+	})
 }
 
 // GetTyped retrieves a value and unmarshals it into the provided type.
 // This is useful when storing complex objects as JSON.
+// dest is left untouched if the key does not exist; use GetTypedFound to tell
+// absence apart from a stored null.
 func (s *ScopedMemory) GetTyped(ctx context.Context, key string, dest any) error {
-	val, found, err := s.backend.Get(s.scope, s.getID(ctx), key)
-	if err != nil {
-		return err
+	_, err := s.GetTypedFound(ctx, key, dest)
+	return err
+}
+
+// GetTypedFound is GetTyped that also reports whether key exists. A stored
+// null (a nil value, or encoded/string "null") is found and resets dest to its
+// zero value; an absent key is not found and leaves dest untouched.
+//
+// Values that cannot be decoded into dest are handled by the Memory's
+// DeserializeErrorPolicy; by default a *DeserializeError naming the key is
+// returned.
+func (s *ScopedMemory) GetTypedFound(ctx context.Context, key string, dest any) (bool, error) {
+	val, found, err := s.get(ctx, key)
+	if err != nil || !found {
+		return false, err
 	}
-	if !found {
-		return nil
+
+	target := reflect.ValueOf(dest)
+	if target.Kind() != reflect.Pointer || target.IsNil() {
+		return true, fmt.Errorf("memory: GetTyped destination must be a non-nil pointer, got %T", dest)
+	}
+	if isNullValue(val) {
+		target.Elem().SetZero()
+		return true, nil
+	}
+
+	if s.memory.deserializePolicy == DeserializeSkip {
+		// Decode into a scratch value so a failure leaves the caller's
+		// default in dest rather than a partial decode.
+		scratch := reflect.New(target.Elem().Type())
+		if err := s.memory.decode(val, scratch.Interface()); err != nil {
+			return false, nil
+		}
+		target.Elem().Set(scratch.Elem())
+		return true, nil
+	}
+
+	if err := s.memory.decode(val, dest); err != nil {
+		derr := &DeserializeError{Scope: s.scope, ScopeID: s.getID(ctx), Key: s.fullKey(key), Err: err}
+		if s.memory.deserializePolicy == DeserializeLogAndZero {
+			s.memory.deserializeLogger.Printf("%v; using zero value", derr)
+			target.Elem().SetZero()
+			return true, nil
+		}
+		return true, derr
 	}
+	return true, nil
+}
 
-	// If it's already the right type, try direct assignment
-	// Otherwise, marshal/unmarshal through JSON for complex types
+// decode converts a stored value into dest with the configured codec.
+func (m *Memory) decode(val, dest any) error {
+	// Encoded payloads are decoded directly; anything else is
+	// round-tripped through the codec for type conversion.
+	if raw, ok := encodedBytes(val); ok {
+		return m.codec.Unmarshal(raw, dest)
+	}
 	switch v := val.(type) {
-	case []byte:
-		return json.Unmarshal(v, dest)
 	case string:
-		return json.Unmarshal([]byte(v), dest)
+		return m.codec.Unmarshal([]byte(v), dest)
 	default:
-		// Round-trip through JSON for type conversion
-		data, err := json.Marshal(val)
+		data, err := m.codec.Marshal(val)
 		if err != nil {
 			return err
 		}
-		return json.Unmarshal(data, dest)
+		return m.codec.Unmarshal(data, dest)
+	}
+}
+
+// isNullValue reports whether a stored value represents null, whether it was
+// stored as nil or as an encoded null. Codecs leave most destinations
+// unchanged when decoding null, so GetTypedFound zeroes them itself.
+func isNullValue(val any) bool {
+	switch v := val.(type) {
+	case nil:
+		return true
+	case json.RawMessage:
+		return bytes.Equal(bytes.TrimSpace(v), []byte("null"))
+	case []byte:
+		return bytes.Equal(bytes.TrimSpace(v), []byte("null"))
+	case string:
+		return strings.TrimSpace(v) == "null"
+	default:
+		return false
+	}
+}
+
+// SetTyped encodes value with the configured codec and stores the encoded
+// bytes, as json.RawMessage when the codec is JSONCodec.
+func (s *ScopedMemory) SetTyped(ctx context.Context, key string, value any) error {
+	if err := s.memory.checkSchema(s.fullKey(key), value); err != nil {
+		return err
+	}
+	data, err := s.memory.codec.Marshal(value)
+	if err != nil {
+		return err
 	}
+	return s.set(ctx, key, s.memory.encoded(data))
 }
 
 // InMemoryBackend provides a thread-safe in-memory implementation of MemoryBackend.
 // Data is lost when the process exits.
+//
+// Key/value data is striped across shards by a hash of the scope, scope ID and
+// key, each guarded by its own lock, so writes to unrelated keys (even within
+// the single global scope) do not contend. Whole-scope reads such as List and
+// Entries visit every shard and are not atomic with respect to concurrent writes.
+//
+// Values are stored and returned as is, so a caller mutating a map or slice
+// it stored or read changes the stored value; NewInMemoryBackendWithCopier
+// opts into copying them instead.
 type InMemoryBackend struct {
-	mu   sync.RWMutex
-	data map[string]map[string]any // "scope:scopeID" -> key -> value
+	shards  []memoryShard
+	version atomic.Uint64 // last version handed out; shared by every key
+	copier  ValueCopier   // nil shares values with callers
+
+	vectorMu   sync.RWMutex
 	vectorData map[string]map[string]vectorRecord // "scope:scopeID" -> key -> vectorRecord
 }
 
+// defaultMemoryShards is the number of lock stripes used by NewInMemoryBackend.
+const defaultMemoryShards = 32
+
+type memoryShard struct {
+	mu       sync.RWMutex
+	data     map[string]map[string]any    // "scope:scopeID" -> key -> value
+	versions map[string]map[string]uint64 // "scope:scopeID" -> key -> version of value
+}
+
+// putLocked stores value at key with version.
+func (s *memoryShard) putLocked(ck, key string, value any, version uint64) {
+	if s.data[ck] == nil {
+		s.data[ck] = make(map[string]any)
+		s.versions[ck] = make(map[string]uint64)
+	}
+	s.data[ck][key] = value
+	s.versions[ck][key] = version
+}
+
+// removeLocked deletes key and its version, dropping the scope once empty.
+func (s *memoryShard) removeLocked(ck, key string) {
+	if s.data[ck] == nil {
+		return
+	}
+	delete(s.data[ck], key)
+	delete(s.versions[ck], key)
+	if len(s.data[ck]) == 0 {
+		delete(s.data, ck)
+		delete(s.versions, ck)
+	}
+}
+
 type vectorRecord struct {
 	embedding []float64
 	metadata  map[string]any
@@ -367,74 +1768,295 @@ type vectorRecord struct {
 
 // NewInMemoryBackend creates a new in-memory storage backend.
 func NewInMemoryBackend() *InMemoryBackend {
-	return &InMemoryBackend{
-		data:       make(map[string]map[string]any),
+	return newInMemoryBackend(defaultMemoryShards)
+}
+
+func newInMemoryBackend(shards int) *InMemoryBackend {
+	if shards < 1 {
+		shards = 1
+	}
+	b := &InMemoryBackend{
+		shards:     make([]memoryShard, shards),
 		vectorData: make(map[string]map[string]vectorRecord),
 	}
+	for i := range b.shards {
+		b.shards[i].data = make(map[string]map[string]any)
+		b.shards[i].versions = make(map[string]map[string]uint64)
+	}
+	return b
 }
 
 func (b *InMemoryBackend) compositeKey(scope MemoryScope, scopeID string) string {
 	return string(scope) + ":" + scopeID
 }
 
+// shard returns the stripe responsible for key within the composite scope ck.
+func (b *InMemoryBackend) shard(ck, key string) *memoryShard {
+	// Inline FNV-1a to avoid allocating a hash.Hash per call.
+	const prime32 = 16777619
+	h := uint32(2166136261)
+	for i := 0; i < len(ck); i++ {
+		h = (h ^ uint32(ck[i])) * prime32
+	}
+	h *= prime32 // separator between ck and key
+	for i := 0; i < len(key); i++ {
+		h = (h ^ uint32(key[i])) * prime32
+	}
+	return &b.shards[h%uint32(len(b.shards))]
+}
+
 // Set stores a value.
 func (b *InMemoryBackend) Set(scope MemoryScope, scopeID, key string, value any) error {
-	b.mu.Lock()
-	defer b.mu.Unlock()
+	value, err := b.copyValue(value)
+	if err != nil {
+		return err
+	}
+	ck := b.compositeKey(scope, scopeID)
+	s := b.shard(ck, key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
+	s.putLocked(ck, key, value, b.version.Add(1))
+	return nil
+}
+
+// SetIfAbsent stores a value only if the key does not exist.
+func (b *InMemoryBackend) SetIfAbsent(scope MemoryScope, scopeID, key string, value any) (bool, error) {
+	value, err := b.copyValue(value)
+	if err != nil {
+		return false, err
+	}
 	ck := b.compositeKey(scope, scopeID)
-	if b.data[ck] == nil {
-		b.data[ck] = make(map[string]any)
+	s := b.shard(ck, key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.data[ck][key]; exists {
+		return false, nil
 	}
-	b.data[ck][key] = value
-	return nil
+	s.putLocked(ck, key, value, b.version.Add(1))
+	return true, nil
+}
+
+// SetIfPresent stores a value only if the key already exists.
+func (b *InMemoryBackend) SetIfPresent(scope MemoryScope, scopeID, key string, value any) (bool, error) {
+	value, err := b.copyValue(value)
+	if err != nil {
+		return false, err
+	}
+	ck := b.compositeKey(scope, scopeID)
+	s := b.shard(ck, key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.data[ck][key]; !exists {
+		return false, nil
+	}
+	s.putLocked(ck, key, value, b.version.Add(1))
+	return true, nil
+}
+
+// CompareAndSwap stores a value only if the key still holds one deeply equal
+// to old.
+func (b *InMemoryBackend) CompareAndSwap(scope MemoryScope, scopeID, key string, old, value any) (bool, error) {
+	value, err := b.copyValue(value)
+	if err != nil {
+		return false, err
+	}
+	ck := b.compositeKey(scope, scopeID)
+	s := b.shard(ck, key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, exists := s.data[ck][key]
+	if !exists || !reflect.DeepEqual(current, old) {
+		return false, nil
+	}
+	s.putLocked(ck, key, value, b.version.Add(1))
+	return true, nil
+}
+
+// GetSet stores a value and returns the one it replaced.
+func (b *InMemoryBackend) GetSet(scope MemoryScope, scopeID, key string, value any) (any, bool, error) {
+	value, err := b.copyValue(value)
+	if err != nil {
+		return nil, false, err
+	}
+	ck := b.compositeKey(scope, scopeID)
+	s := b.shard(ck, key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, existed := s.data[ck][key]
+	s.putLocked(ck, key, value, b.version.Add(1))
+	return previous, existed, nil
+}
+
+// GetAndDelete removes key and returns the value it held under the shard lock.
+func (b *InMemoryBackend) GetAndDelete(scope MemoryScope, scopeID, key string) (any, bool, error) {
+	ck := b.compositeKey(scope, scopeID)
+	s := b.shard(ck, key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	value, existed := s.data[ck][key]
+	if existed {
+		s.removeLocked(ck, key)
+	}
+	return value, existed, nil
+}
+
+// Version returns the version of key's current value. Versions come from a
+// counter shared by the whole backend, so a deleted and recreated key never
+// repeats an earlier version.
+func (b *InMemoryBackend) Version(scope MemoryScope, scopeID, key string) (uint64, bool, error) {
+	ck := b.compositeKey(scope, scopeID)
+	s := b.shard(ck, key)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	version, found := s.versions[ck][key]
+	return version, found, nil
 }
 
 // Get retrieves a value.
 func (b *InMemoryBackend) Get(scope MemoryScope, scopeID, key string) (any, bool, error) {
-	b.mu.RLock()
-	defer b.mu.RUnlock()
-
 	ck := b.compositeKey(scope, scopeID)
-	if b.data[ck] == nil {
+	s := b.shard(ck, key)
+	s.mu.RLock()
+	val, found := s.data[ck][key]
+	s.mu.RUnlock()
+
+	if !found {
 		return nil, false, nil
 	}
-	val, found := b.data[ck][key]
-	return val, found, nil
+	// Stored values are never mutated in place, so copying outside the lock
+	// is safe.
+	val, err := b.copyValue(val)
+	if err != nil {
+		return nil, false, err
+	}
+	return val, true, nil
 }
 
 // Delete removes a key.
 func (b *InMemoryBackend) Delete(scope MemoryScope, scopeID, key string) error {
-	b.mu.Lock()
-	defer b.mu.Unlock()
+	ck := b.compositeKey(scope, scopeID)
+	s := b.shard(ck, key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.removeLocked(ck, key)
+	return nil
+}
 
+// DeletePrefix removes all keys in a scope that start with prefix.
+func (b *InMemoryBackend) DeletePrefix(scope MemoryScope, scopeID, prefix string) (int, error) {
 	ck := b.compositeKey(scope, scopeID)
-	if b.data[ck] != nil {
-		delete(b.data[ck], key)
+	deleted := 0
+	for i := range b.shards {
+		s := &b.shards[i]
+		s.mu.Lock()
+		for key := range s.data[ck] {
+			if strings.HasPrefix(key, prefix) {
+				s.removeLocked(ck, key)
+				deleted++
+			}
+		}
+		s.mu.Unlock()
 	}
-	return nil
+	return deleted, nil
 }
 
-// List returns all keys in a scope.
+// List returns all keys in a scope, unordered. Sorting is left to
+// ScopedMemory.ListSorted so the common path does not pay for it.
 func (b *InMemoryBackend) List(scope MemoryScope, scopeID string) ([]string, error) {
-	b.mu.RLock()
-	defer b.mu.RUnlock()
+	ck := b.compositeKey(scope, scopeID)
+	var keys []string
+	for i := range b.shards {
+		s := &b.shards[i]
+		s.mu.RLock()
+		for key := range s.data[ck] {
+			keys = append(keys, key)
+		}
+		s.mu.RUnlock()
+	}
+	return keys, nil
+}
 
+// Entries returns a copy of all key/value pairs in a scope.
+func (b *InMemoryBackend) Entries(scope MemoryScope, scopeID string) (map[string]any, error) {
 	ck := b.compositeKey(scope, scopeID)
-	if b.data[ck] == nil {
-		return nil, nil
+	entries := make(map[string]any)
+	for i := range b.shards {
+		s := &b.shards[i]
+		s.mu.RLock()
+		for key, val := range s.data[ck] {
+			entries[key] = val
+		}
+		s.mu.RUnlock()
 	}
-	keys := make([]string, 0, len(b.data[ck]))
-	for key := range b.data[ck] {
-		keys = append(keys, key)
+	for key, val := range entries {
+		copied, err := b.copyValue(val)
+		if err != nil {
+			return nil, err
+		}
+		entries[key] = copied
 	}
-	return keys, nil
+	return entries, nil
+}
+
+// ListScopeIDs returns the IDs within scope that currently hold keys, sorted.
+func (b *InMemoryBackend) ListScopeIDs(scope MemoryScope) ([]string, error) {
+	prefix := string(scope) + ":"
+	seen := make(map[string]struct{})
+	for i := range b.shards {
+		s := &b.shards[i]
+		s.mu.RLock()
+		for ck, entries := range s.data {
+			if len(entries) == 0 || !strings.HasPrefix(ck, prefix) {
+				continue
+			}
+			seen[strings.TrimPrefix(ck, prefix)] = struct{}{}
+		}
+		s.mu.RUnlock()
+	}
+
+	var scopeIDs []string
+	for id := range seen {
+		scopeIDs = append(scopeIDs, id)
+	}
+	sort.Strings(scopeIDs)
+	return scopeIDs, nil
+}
+
+// ScopeSizes estimates bytes per scope ID by JSON-encoding each stored value;
+// see Memory.ScopeSizes.
+func (b *InMemoryBackend) ScopeSizes(scope MemoryScope) (map[string]int64, error) {
+	prefix := string(scope) + ":"
+	sizes := make(map[string]int64)
+	for i := range b.shards {
+		s := &b.shards[i]
+		s.mu.RLock()
+		for ck, entries := range s.data {
+			if len(entries) == 0 || !strings.HasPrefix(ck, prefix) {
+				continue
+			}
+			// A scope ID's keys are spread across shards, so sizes accumulate.
+			scopeID := strings.TrimPrefix(ck, prefix)
+			for key, val := range entries {
+				sizes[scopeID] += int64(len(key)) + estimateValueSize(val)
+			}
+		}
+		s.mu.RUnlock()
+	}
+	return sizes, nil
 }
 
 // SetVector stores a vector.
 func (b *InMemoryBackend) SetVector(scope MemoryScope, scopeID, key string, embedding []float64, metadata map[string]any) error {
-	b.mu.Lock()
-	defer b.mu.Unlock()
+	b.vectorMu.Lock()
+	defer b.vectorMu.Unlock()
 
 	ck := b.compositeKey(scope, scopeID)
 	if b.vectorData[ck] == nil {
@@ -449,8 +2071,8 @@ func (b *InMemoryBackend) SetVector(scope MemoryScope, scopeID, key string, embe
 
 // GetVector retrieves a vector.
 func (b *InMemoryBackend) GetVector(scope MemoryScope, scopeID, key string) ([]float64, map[string]any, bool, error) {
-	b.mu.RLock()
-	defer b.mu.RUnlock()
+	b.vectorMu.RLock()
+	defer b.vectorMu.RUnlock()
 
 	ck := b.compositeKey(scope, scopeID)
 	if b.vectorData[ck] == nil {
@@ -471,8 +2093,8 @@ func (b *InMemoryBackend) SearchVector(scope MemoryScope, scopeID string, embedd
 
 // DeleteVector removes a vector.
 func (b *InMemoryBackend) DeleteVector(scope MemoryScope, scopeID, key string) error {
-	b.mu.Lock()
-	defer b.mu.Unlock()
+	b.vectorMu.Lock()
+	defer b.vectorMu.Unlock()
 
 	ck := b.compositeKey(scope, scopeID)
 	if b.vectorData[ck] != nil {
@@ -484,17 +2106,31 @@ func (b *InMemoryBackend) DeleteVector(scope MemoryScope, scopeID, key string) e
 // Clear removes all data from the backend.
 // Useful for testing.
 func (b *InMemoryBackend) Clear() {
-	b.mu.Lock()
-	defer b.mu.Unlock()
-	b.data = make(map[string]map[string]any)
+	for i := range b.shards {
+		s := &b.shards[i]
+		s.mu.Lock()
+		s.data = make(map[string]map[string]any)
+		s.versions = make(map[string]map[string]uint64)
+		s.mu.Unlock()
+	}
+
+	b.vectorMu.Lock()
+	defer b.vectorMu.Unlock()
 	b.vectorData = make(map[string]map[string]vectorRecord)
 }
 
 // ClearScope removes all data for a specific scope and scopeID.
 func (b *InMemoryBackend) ClearScope(scope MemoryScope, scopeID string) {
-	b.mu.Lock()
-	defer b.mu.Unlock()
 	ck := b.compositeKey(scope, scopeID)
-	delete(b.data, ck)
+	for i := range b.shards {
+		s := &b.shards[i]
+		s.mu.Lock()
+		delete(s.data, ck)
+		delete(s.versions, ck)
+		s.mu.Unlock()
+	}
+
+	b.vectorMu.Lock()
+	defer b.vectorMu.Unlock()
 	delete(b.vectorData, ck)
 }
@@ -0,0 +1,85 @@
+package agent
+
+import (
+	"context"
+	"time"
+)
+
+// AuditOp identifies the kind of memory mutation being audited.
+type AuditOp string
+
+const (
+	// AuditOpSet records a write, including conditional writes that took effect.
+	AuditOpSet AuditOp = "set"
+	// AuditOpDelete records a key removal.
+	AuditOpDelete AuditOp = "delete"
+)
+
+// AuditEntry describes a single memory mutation.
+type AuditEntry struct {
+	Op        AuditOp     `json:"op"`
+	Scope     MemoryScope `json:"scope"`
+	ScopeID   string      `json:"scope_id"`
+	Key       string      `json:"key"`
+	ActorID   string      `json:"actor_id,omitempty"`
+	Timestamp time.Time   `json:"timestamp"`
+	// Value is only populated when AuditConfig.IncludeValues is set.
+	Value any `json:"value,omitempty"`
+}
+
+// AuditSink receives audit entries for memory mutations. Record is called
+// synchronously after the backend write succeeds, so it should not block.
+type AuditSink interface {
+	Record(entry AuditEntry)
+}
+
+// AuditConfig selects which mutations are audited.
+type AuditConfig struct {
+	// Scopes limits auditing to the listed scopes. Empty audits every scope.
+	Scopes []MemoryScope
+	// IncludeValues records the written value on set entries.
+	IncludeValues bool
+}
+
+// WithAuditSink records Set and Delete operations to sink for the scopes in
+// config. A nil sink disables auditing.
+func WithAuditSink(sink AuditSink, config AuditConfig) MemoryOption {
+	return func(m *Memory) {
+		m.auditSink = sink
+		m.auditConfig = config
+	}
+}
+
+func (m *Memory) audits(scope MemoryScope) bool {
+	if m.auditSink == nil {
+		return false
+	}
+	if len(m.auditConfig.Scopes) == 0 {
+		return true
+	}
+	for _, s := range m.auditConfig.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// audit records a successful mutation if auditing is enabled for scope.
+func (m *Memory) audit(ctx context.Context, op AuditOp, scope MemoryScope, scopeID, key string, value any) {
+	if !m.audits(scope) {
+		return
+	}
+	entry := AuditEntry{
+		Op:        op,
+		Scope:     scope,
+		ScopeID:   scopeID,
+		Key:       key,
+		ActorID:   executionContextFrom(ctx).ActorID,
+		Timestamp: time.Now().UTC(),
+	}
+	if m.auditConfig.IncludeValues && op == AuditOpSet {
+		entry.Value = value
+	}
+	m.auditSink.Record(entry)
+}
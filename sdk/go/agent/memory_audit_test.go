@@ -0,0 +1,65 @@
+package agent
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type capturingAuditSink struct {
+	mu      sync.Mutex
+	entries []AuditEntry
+}
+
+func (s *capturingAuditSink) Record(entry AuditEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append(s.entries, entry)
+}
+
+func TestMemory_AuditSink(t *testing.T) {
+	ctx := contextWithExecution(context.Background(), ExecutionContext{
+		SessionID: "session-1",
+		ActorID:   "user-42",
+	})
+
+	t.Run("user scope delete is audited with actor", func(t *testing.T) {
+		sink := &capturingAuditSink{}
+		memory := NewMemory(NewInMemoryBackend(), WithAuditSink(sink, AuditConfig{
+			Scopes: []MemoryScope{ScopeUser},
+		}))
+
+		require.NoError(t, memory.UserScope().Set(ctx, "email", "a@example.com"))
+		require.NoError(t, memory.UserScope().Delete(ctx, "email"))
+		// Session scope is not configured and must not be audited.
+		require.NoError(t, memory.Set(ctx, "scratch", 1))
+
+		require.Len(t, sink.entries, 2)
+		del := sink.entries[1]
+		assert.Equal(t, AuditOpDelete, del.Op)
+		assert.Equal(t, ScopeUser, del.Scope)
+		assert.Equal(t, "user-42", del.ScopeID)
+		assert.Equal(t, "email", del.Key)
+		assert.Equal(t, "user-42", del.ActorID)
+		assert.False(t, del.Timestamp.IsZero())
+
+		assert.Nil(t, sink.entries[0].Value, "values are omitted unless enabled")
+	})
+
+	t.Run("values included when enabled", func(t *testing.T) {
+		sink := &capturingAuditSink{}
+		memory := NewMemory(NewInMemoryBackend(), WithAuditSink(sink, AuditConfig{IncludeValues: true}))
+
+		require.NoError(t, memory.Set(ctx, "k", "v"))
+		written, err := memory.SetIfAbsent(ctx, "k", "ignored")
+		require.NoError(t, err)
+		require.False(t, written)
+
+		require.Len(t, sink.entries, 1, "skipped conditional writes are not audited")
+		assert.Equal(t, AuditOpSet, sink.entries[0].Op)
+		assert.Equal(t, "v", sink.entries[0].Value)
+	})
+}
@@ -0,0 +1,367 @@
+package agent
+
+import (
+	"container/list"
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CacheBackend is an in-process MemoryBackend bounded by both entry count and
+// age. Every Set gives the key defaultTTL to live; once maxEntries keys are
+// held, a new key first reclaims an expired entry if there is one and
+// otherwise evicts the least recently used key. Expired keys are never
+// returned and are removed on access, on Sweep, or by StartSweeper; Watch
+// reports each removal as a MemoryEventExpired event. CacheBackend implements
+// TTLRefresher, so keys can also be given their own expiry with SetWithTTL.
+//
+// Vectors are kept alongside the cache without a bound or expiry.
+type CacheBackend struct {
+	maxEntries int
+	defaultTTL time.Duration
+	now        func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]map[string]*cacheEntry // "scope:scopeID" -> key -> entry
+	lru     *list.List                        // most recently used at the front
+	expiry  *list.List                        // soonest expiry at the front, no expiry last
+	vectors *InMemoryBackend
+
+	watchers memoryWatchers
+}
+
+type cacheEntry struct {
+	scope      MemoryScope
+	scopeID    string
+	ck, key    string
+	value      any
+	expiresAt  time.Time // zero when the key never expires
+	lruElem    *list.Element
+	expiryElem *list.Element
+}
+
+// NewCacheBackend creates a cache holding at most maxEntries keys, each
+// expiring defaultTTL after it was last written. A maxEntries of zero or less
+// disables the size bound and a defaultTTL of zero or less disables expiry.
+func NewCacheBackend(maxEntries int, defaultTTL time.Duration) *CacheBackend {
+	return &CacheBackend{
+		maxEntries: maxEntries,
+		defaultTTL: defaultTTL,
+		now:        time.Now,
+		entries:    make(map[string]map[string]*cacheEntry),
+		lru:        list.New(),
+		expiry:     list.New(),
+		vectors:    NewInMemoryBackend(),
+	}
+}
+
+func (b *CacheBackend) compositeKey(scope MemoryScope, scopeID string) string {
+	return string(scope) + ":" + scopeID
+}
+
+func (e *cacheEntry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
+
+func (e *cacheEntry) event(op MemoryEventOp, now time.Time) MemoryEvent {
+	return MemoryEvent{Op: op, Scope: e.scope, ScopeID: e.scopeID, Key: e.key, Value: e.value, At: now}
+}
+
+// Watch returns a channel of the cache's set, delete and expired events,
+// closed once ctx is done. Each expiry is reported once, to the watchers
+// registered when the key is reclaimed. Keys evicted to make room for others
+// are not reported. Events are delivered synchronously after the operation
+// that caused them, so a watcher that stops reading stalls writers until its
+// ctx is done.
+func (b *CacheBackend) Watch(ctx context.Context) <-chan MemoryEvent {
+	return b.watchers.watch(ctx)
+}
+
+// Set stores value and resets the key's expiry to defaultTTL from now.
+func (b *CacheBackend) Set(scope MemoryScope, scopeID, key string, value any) error {
+	return b.set(scope, scopeID, key, value, b.defaultTTL)
+}
+
+// SetWithTTL implements TTLRefresher, storing value with an expiry of ttl
+// from now in place of defaultTTL. A ttl of zero or less never expires.
+func (b *CacheBackend) SetWithTTL(scope MemoryScope, scopeID, key string, value any, ttl time.Duration) error {
+	return b.set(scope, scopeID, key, value, ttl)
+}
+
+func (b *CacheBackend) set(scope MemoryScope, scopeID, key string, value any, ttl time.Duration) error {
+	ck := b.compositeKey(scope, scopeID)
+	now := b.now()
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = now.Add(ttl)
+	}
+
+	var events []MemoryEvent
+	defer func() { b.watchers.deliver(events) }()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if e, ok := b.entries[ck][key]; ok {
+		if e.expired(now) {
+			events = append(events, e.event(MemoryEventExpired, now))
+		}
+		e.value = value
+		e.expiresAt = expiresAt
+		b.lru.MoveToFront(e.lruElem)
+		b.placeByExpiryLocked(e)
+		events = append(events, e.event(MemoryEventSet, now))
+		return nil
+	}
+
+	if b.maxEntries > 0 && b.lru.Len() >= b.maxEntries {
+		if oldest := b.expiry.Front().Value.(*cacheEntry); oldest.expired(now) {
+			b.removeLocked(oldest)
+			events = append(events, oldest.event(MemoryEventExpired, now))
+		} else {
+			b.removeLocked(b.lru.Back().Value.(*cacheEntry))
+		}
+	}
+
+	e := &cacheEntry{scope: scope, scopeID: scopeID, ck: ck, key: key, value: value, expiresAt: expiresAt}
+	e.lruElem = b.lru.PushFront(e)
+	e.expiryElem = b.expiry.PushBack(e)
+	b.placeByExpiryLocked(e)
+	if b.entries[ck] == nil {
+		b.entries[ck] = make(map[string]*cacheEntry)
+	}
+	b.entries[ck][key] = e
+	events = append(events, e.event(MemoryEventSet, now))
+	return nil
+}
+
+// Get returns the value for key and marks it recently used.
+func (b *CacheBackend) Get(scope MemoryScope, scopeID, key string) (any, bool, error) {
+	ck := b.compositeKey(scope, scopeID)
+	now := b.now()
+
+	b.mu.Lock()
+	e, ok := b.entries[ck][key]
+	if !ok {
+		b.mu.Unlock()
+		return nil, false, nil
+	}
+	if e.expired(now) {
+		b.removeLocked(e)
+		b.mu.Unlock()
+		b.watchers.deliver([]MemoryEvent{e.event(MemoryEventExpired, now)})
+		return nil, false, nil
+	}
+	b.lru.MoveToFront(e.lruElem)
+	value := e.value
+	b.mu.Unlock()
+	return value, true, nil
+}
+
+// RefreshTTL implements TTLRefresher, making key expire ttl from now and
+// marking it recently used. A ttl of zero or less never expires.
+func (b *CacheBackend) RefreshTTL(scope MemoryScope, scopeID, key string, ttl time.Duration) (bool, error) {
+	ck := b.compositeKey(scope, scopeID)
+	now := b.now()
+
+	b.mu.Lock()
+	e, ok := b.entries[ck][key]
+	if !ok {
+		b.mu.Unlock()
+		return false, nil
+	}
+	if e.expired(now) {
+		b.removeLocked(e)
+		b.mu.Unlock()
+		b.watchers.deliver([]MemoryEvent{e.event(MemoryEventExpired, now)})
+		return false, nil
+	}
+	e.expiresAt = time.Time{}
+	if ttl > 0 {
+		e.expiresAt = now.Add(ttl)
+	}
+	b.lru.MoveToFront(e.lruElem)
+	b.placeByExpiryLocked(e)
+	b.mu.Unlock()
+	return true, nil
+}
+
+// Delete removes key.
+func (b *CacheBackend) Delete(scope MemoryScope, scopeID, key string) error {
+	ck := b.compositeKey(scope, scopeID)
+	now := b.now()
+
+	b.mu.Lock()
+	e, ok := b.entries[ck][key]
+	if !ok {
+		b.mu.Unlock()
+		return nil
+	}
+	b.removeLocked(e)
+	b.mu.Unlock()
+
+	op := MemoryEventDelete
+	if e.expired(now) {
+		op = MemoryEventExpired
+	}
+	b.watchers.deliver([]MemoryEvent{e.event(op, now)})
+	return nil
+}
+
+// List returns the unexpired keys in a scope, unordered.
+func (b *CacheBackend) List(scope MemoryScope, scopeID string) ([]string, error) {
+	ck := b.compositeKey(scope, scopeID)
+	now := b.now()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var keys []string
+	for key, e := range b.entries[ck] {
+		if !e.expired(now) {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+// TTL implements TTLReader. Keys stored without an expiry report NoExpiry.
+func (b *CacheBackend) TTL(scope MemoryScope, scopeID, key string) (time.Duration, bool, error) {
+	ck := b.compositeKey(scope, scopeID)
+	now := b.now()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	e, ok := b.entries[ck][key]
+	if !ok || e.expired(now) {
+		return 0, false, nil
+	}
+	if e.expiresAt.IsZero() {
+		return NoExpiry, true, nil
+	}
+	return e.expiresAt.Sub(now), true, nil
+}
+
+// Len returns the number of keys held, including expired keys not yet
+// reclaimed.
+func (b *CacheBackend) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.lru.Len()
+}
+
+// Sweep removes every expired key and returns how many it removed.
+func (b *CacheBackend) Sweep() int {
+	now := b.now()
+
+	b.mu.Lock()
+	var events []MemoryEvent
+	for front := b.expiry.Front(); front != nil; front = b.expiry.Front() {
+		e := front.Value.(*cacheEntry)
+		if !e.expired(now) {
+			break
+		}
+		b.removeLocked(e)
+		events = append(events, e.event(MemoryEventExpired, now))
+	}
+	b.mu.Unlock()
+
+	b.watchers.deliver(events)
+	return len(events)
+}
+
+// PurgeExpired implements ExpiredPurger, removing the expired keys of scope
+// only. Like Sweep it reports each removal to watchers as expired.
+func (b *CacheBackend) PurgeExpired(scope MemoryScope) (int, error) {
+	prefix := string(scope) + ":"
+	now := b.now()
+
+	b.mu.Lock()
+	var events []MemoryEvent
+	for ck, keys := range b.entries {
+		if !strings.HasPrefix(ck, prefix) {
+			continue
+		}
+		for _, e := range keys {
+			if e.expired(now) {
+				b.removeLocked(e)
+				events = append(events, e.event(MemoryEventExpired, now))
+			}
+		}
+	}
+	b.mu.Unlock()
+
+	b.watchers.deliver(events)
+	return len(events), nil
+}
+
+// StartSweeper calls Sweep every interval until ctx is done, so expired keys
+// free their memory even when they are never read again.
+func (b *CacheBackend) StartSweeper(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				b.Sweep()
+			}
+		}
+	}()
+}
+
+// placeByExpiryLocked moves e to its place in the expiry list. It searches
+// from the back, so keys sharing defaultTTL are placed in constant time.
+func (b *CacheBackend) placeByExpiryLocked(e *cacheEntry) {
+	for prev := b.expiry.Back(); prev != nil; prev = prev.Prev() {
+		if prev == e.expiryElem {
+			continue
+		}
+		if p := prev.Value.(*cacheEntry); !expiresBefore(e.expiresAt, p.expiresAt) {
+			b.expiry.MoveAfter(e.expiryElem, prev)
+			return
+		}
+	}
+	b.expiry.MoveToFront(e.expiryElem)
+}
+
+// expiresBefore orders expiries, treating the zero time as never.
+func expiresBefore(a, b time.Time) bool {
+	if a.IsZero() {
+		return false
+	}
+	return b.IsZero() || a.Before(b)
+}
+
+func (b *CacheBackend) removeLocked(e *cacheEntry) {
+	b.lru.Remove(e.lruElem)
+	b.expiry.Remove(e.expiryElem)
+	delete(b.entries[e.ck], e.key)
+	if len(b.entries[e.ck]) == 0 {
+		delete(b.entries, e.ck)
+	}
+}
+
+// SetVector stores a vector.
+func (b *CacheBackend) SetVector(scope MemoryScope, scopeID, key string, embedding []float64, metadata map[string]any) error {
+	return b.vectors.SetVector(scope, scopeID, key, embedding, metadata)
+}
+
+// GetVector retrieves a vector.
+func (b *CacheBackend) GetVector(scope MemoryScope, scopeID, key string) ([]float64, map[string]any, bool, error) {
+	return b.vectors.GetVector(scope, scopeID, key)
+}
+
+// SearchVector performs similarity search.
+func (b *CacheBackend) SearchVector(scope MemoryScope, scopeID string, embedding []float64, opts SearchOptions) ([]VectorSearchResult, error) {
+	return b.vectors.SearchVector(scope, scopeID, embedding, opts)
+}
+
+// DeleteVector removes a vector.
+func (b *CacheBackend) DeleteVector(scope MemoryScope, scopeID, key string) error {
+	return b.vectors.DeleteVector(scope, scopeID, key)
+}
@@ -0,0 +1,155 @@
+package agent
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestCacheBackend(maxEntries int, ttl time.Duration) (*CacheBackend, *time.Time) {
+	clock := time.Now()
+	b := NewCacheBackend(maxEntries, ttl)
+	b.now = func() time.Time { return clock }
+	return b, &clock
+}
+
+func cacheKeys(t *testing.T, b *CacheBackend) []string {
+	t.Helper()
+	keys, err := b.List(ScopeGlobal, "global")
+	require.NoError(t, err)
+	sort.Strings(keys)
+	return keys
+}
+
+func TestCacheBackend(t *testing.T) {
+	var _ MemoryBackend = (*CacheBackend)(nil)
+	var _ TTLReader = (*CacheBackend)(nil)
+	var _ TTLRefresher = (*CacheBackend)(nil)
+
+	t.Run("evicts the least recently used key under size pressure", func(t *testing.T) {
+		b, _ := newTestCacheBackend(3, time.Hour)
+		for _, key := range []string{"a", "b", "c"} {
+			require.NoError(t, b.Set(ScopeGlobal, "global", key, key))
+		}
+		// Reading "a" makes "b" the least recently used.
+		_, found, err := b.Get(ScopeGlobal, "global", "a")
+		require.NoError(t, err)
+		require.True(t, found)
+
+		require.NoError(t, b.Set(ScopeGlobal, "global", "d", "d"))
+		assert.Equal(t, []string{"a", "c", "d"}, cacheKeys(t, b))
+		assert.Equal(t, 3, b.Len())
+
+		// Overwriting an existing key does not evict anything.
+		require.NoError(t, b.Set(ScopeGlobal, "global", "c", "c2"))
+		assert.Equal(t, 3, b.Len())
+	})
+
+	t.Run("expires keys after the default TTL", func(t *testing.T) {
+		b, clock := newTestCacheBackend(0, 10*time.Second)
+		require.NoError(t, b.Set(ScopeGlobal, "global", "token", "abc"))
+
+		*clock = clock.Add(9 * time.Second)
+		ttl, found, err := b.TTL(ScopeGlobal, "global", "token")
+		require.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, time.Second, ttl)
+
+		*clock = clock.Add(time.Second)
+		value, found, err := b.Get(ScopeGlobal, "global", "token")
+		require.NoError(t, err)
+		assert.False(t, found)
+		assert.Nil(t, value)
+		assert.Zero(t, b.Len(), "reading an expired key reclaims it")
+	})
+
+	t.Run("rewriting a key restarts its TTL", func(t *testing.T) {
+		b, clock := newTestCacheBackend(0, 10*time.Second)
+		require.NoError(t, b.Set(ScopeGlobal, "global", "a", 1))
+		require.NoError(t, b.Set(ScopeGlobal, "global", "b", 1))
+		*clock = clock.Add(6 * time.Second)
+		require.NoError(t, b.Set(ScopeGlobal, "global", "a", 2))
+		*clock = clock.Add(6 * time.Second)
+
+		assert.Equal(t, 1, b.Sweep())
+		assert.Equal(t, []string{"a"}, cacheKeys(t, b))
+	})
+
+	t.Run("sweeper reclaims keys that are never read", func(t *testing.T) {
+		b, clock := newTestCacheBackend(0, time.Second)
+		for _, key := range []string{"a", "b", "c"} {
+			require.NoError(t, b.Set(ScopeGlobal, "global", key, key))
+		}
+		*clock = clock.Add(time.Second)
+		assert.Equal(t, 3, b.Len(), "expired keys are held until reclaimed")
+		assert.Empty(t, cacheKeys(t, b), "expired keys are not listed")
+		assert.Equal(t, 3, b.Sweep())
+		assert.Zero(t, b.Len())
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		require.NoError(t, b.Set(ScopeGlobal, "global", "d", "d"))
+		*clock = clock.Add(time.Second)
+		b.StartSweeper(ctx, time.Millisecond)
+		assert.Eventually(t, func() bool { return b.Len() == 0 }, time.Second, time.Millisecond)
+	})
+
+	t.Run("an expired key frees space before a live key is evicted", func(t *testing.T) {
+		b, clock := newTestCacheBackend(2, 10*time.Second)
+		require.NoError(t, b.Set(ScopeGlobal, "global", "stale", 1))
+		*clock = clock.Add(5 * time.Second)
+		require.NoError(t, b.Set(ScopeGlobal, "global", "live", 1))
+
+		// "live" is the least recently used once "stale" is read, but "stale"
+		// has expired by the time the third key arrives.
+		*clock = clock.Add(4 * time.Second)
+		_, found, err := b.Get(ScopeGlobal, "global", "stale")
+		require.NoError(t, err)
+		require.True(t, found)
+		*clock = clock.Add(time.Second)
+
+		require.NoError(t, b.Set(ScopeGlobal, "global", "new", 1))
+		assert.Equal(t, []string{"live", "new"}, cacheKeys(t, b))
+	})
+
+	t.Run("per-key TTLs keep sweeps in expiry order", func(t *testing.T) {
+		b, clock := newTestCacheBackend(0, 10*time.Second)
+		require.NoError(t, b.SetWithTTL(ScopeGlobal, "global", "long", 1, time.Minute))
+		require.NoError(t, b.Set(ScopeGlobal, "global", "default", 2))
+		require.NoError(t, b.SetWithTTL(ScopeGlobal, "global", "forever", 3, 0))
+		require.NoError(t, b.SetWithTTL(ScopeGlobal, "global", "short", 4, time.Second))
+
+		*clock = clock.Add(10 * time.Second)
+		assert.Equal(t, 2, b.Sweep())
+		assert.Equal(t, []string{"forever", "long"}, cacheKeys(t, b))
+
+		found, err := b.RefreshTTL(ScopeGlobal, "global", "long", 5*time.Second)
+		require.NoError(t, err)
+		assert.True(t, found)
+		*clock = clock.Add(5 * time.Second)
+		assert.Equal(t, 1, b.Sweep())
+		assert.Equal(t, []string{"forever"}, cacheKeys(t, b))
+
+		found, err = b.RefreshTTL(ScopeGlobal, "global", "missing", time.Second)
+		require.NoError(t, err)
+		assert.False(t, found)
+	})
+
+	t.Run("through Memory", func(t *testing.T) {
+		memory := NewMemory(NewCacheBackend(10, time.Minute))
+		ctx := context.Background()
+		require.NoError(t, memory.GlobalScope().Set(ctx, "k", "v"))
+		value, err := memory.GlobalScope().Get(ctx, "k")
+		require.NoError(t, err)
+		assert.Equal(t, "v", value)
+
+		ttl, found, err := memory.GlobalScope().TTL(ctx, "k")
+		require.NoError(t, err)
+		assert.True(t, found)
+		assert.LessOrEqual(t, ttl, time.Minute)
+	})
+}
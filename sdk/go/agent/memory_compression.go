@@ -0,0 +1,111 @@
+package agent
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+)
+
+// Marker bytes prefixed to []byte values written through CompressedBackend.
+const (
+	compressionMarkerRaw  byte = 0x00
+	compressionMarkerGzip byte = 0x01
+)
+
+// defaultCompressionThreshold is used when NewCompressedBackend is given a
+// non-positive threshold.
+const defaultCompressionThreshold = 1024
+
+// CompressedBackend wraps a MemoryBackend and gzip-compresses []byte values
+// larger than a threshold before storing them. Values produced by SetTyped
+// (json.RawMessage or []byte) are compressed too, so typed reads and writes
+// are compressed transparently.
+//
+// Every such value is stored as []byte with a one-byte marker so reads can
+// tell raw and compressed payloads apart, and comes back as []byte; other
+// value types pass through unchanged.
+// The wrapped backend must round-trip []byte values as []byte, and data
+// written without the wrapper cannot be read through it. Vector operations
+// are delegated as-is.
+type CompressedBackend struct {
+	MemoryBackend
+	threshold int
+}
+
+// NewCompressedBackend wraps backend, compressing []byte values longer than
+// threshold bytes. A non-positive threshold uses a 1 KiB default.
+func NewCompressedBackend(backend MemoryBackend, threshold int) *CompressedBackend {
+	if threshold <= 0 {
+		threshold = defaultCompressionThreshold
+	}
+	return &CompressedBackend{MemoryBackend: backend, threshold: threshold}
+}
+
+// Set stores value, compressing it first if it is a []byte above the threshold.
+func (b *CompressedBackend) Set(scope MemoryScope, scopeID, key string, value any) error {
+	raw, ok := encodedBytes(value)
+	if !ok {
+		return b.MemoryBackend.Set(scope, scopeID, key, value)
+	}
+	encoded, err := b.encode(raw)
+	if err != nil {
+		return fmt.Errorf("compress %q: %w", key, err)
+	}
+	return b.MemoryBackend.Set(scope, scopeID, key, encoded)
+}
+
+// Get retrieves a value, decompressing it if it was stored compressed.
+func (b *CompressedBackend) Get(scope MemoryScope, scopeID, key string) (any, bool, error) {
+	val, found, err := b.MemoryBackend.Get(scope, scopeID, key)
+	if err != nil || !found {
+		return val, found, err
+	}
+	stored, ok := val.([]byte)
+	if !ok {
+		return val, true, nil
+	}
+	decoded, err := decodeCompressed(stored)
+	if err != nil {
+		return nil, false, fmt.Errorf("decompress %q: %w", key, err)
+	}
+	return decoded, true, nil
+}
+
+func (b *CompressedBackend) encode(raw []byte) ([]byte, error) {
+	if len(raw) <= b.threshold {
+		out := make([]byte, 0, len(raw)+1)
+		out = append(out, compressionMarkerRaw)
+		return append(out, raw...), nil
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte(compressionMarkerGzip)
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(raw); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decodeCompressed(stored []byte) ([]byte, error) {
+	if len(stored) == 0 {
+		return nil, fmt.Errorf("missing compression marker")
+	}
+	switch stored[0] {
+	case compressionMarkerRaw:
+		return stored[1:], nil
+	case compressionMarkerGzip:
+		zr, err := gzip.NewReader(bytes.NewReader(stored[1:]))
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		return io.ReadAll(zr)
+	default:
+		return nil, fmt.Errorf("unknown compression marker 0x%02x", stored[0])
+	}
+}
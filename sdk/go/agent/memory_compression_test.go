@@ -0,0 +1,75 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompressedBackend(t *testing.T) {
+	ctx := contextWithExecution(context.Background(), ExecutionContext{
+		SessionID: "test-session",
+	})
+
+	type document struct {
+		Body string `json:"body"`
+	}
+
+	t.Run("large value is compressed", func(t *testing.T) {
+		inner := NewInMemoryBackend()
+		memory := NewMemory(NewCompressedBackend(inner, 64))
+
+		doc := document{Body: strings.Repeat("agentfield ", 200)}
+		require.NoError(t, memory.SessionScope().SetTyped(ctx, "doc", doc))
+
+		stored, found, err := inner.Get(ScopeSession, "test-session", "doc")
+		require.NoError(t, err)
+		require.True(t, found)
+		raw := stored.([]byte)
+		assert.Equal(t, compressionMarkerGzip, raw[0])
+		assert.Less(t, len(raw), len(doc.Body))
+
+		var got document
+		require.NoError(t, memory.SessionScope().GetTyped(ctx, "doc", &got))
+		assert.Equal(t, doc, got)
+	})
+
+	t.Run("small value stays raw", func(t *testing.T) {
+		inner := NewInMemoryBackend()
+		memory := NewMemory(NewCompressedBackend(inner, 64))
+
+		require.NoError(t, memory.SessionScope().SetTyped(ctx, "doc", document{Body: "hi"}))
+
+		stored, _, err := inner.Get(ScopeSession, "test-session", "doc")
+		require.NoError(t, err)
+		raw := stored.([]byte)
+		assert.Equal(t, compressionMarkerRaw, raw[0])
+		assert.JSONEq(t, `{"body":"hi"}`, string(raw[1:]))
+
+		var got document
+		require.NoError(t, memory.SessionScope().GetTyped(ctx, "doc", &got))
+		assert.Equal(t, "hi", got.Body)
+	})
+
+	t.Run("non-byte values pass through", func(t *testing.T) {
+		inner := NewInMemoryBackend()
+		memory := NewMemory(NewCompressedBackend(inner, 64))
+
+		require.NoError(t, memory.Set(ctx, "count", 3))
+		val, err := memory.Get(ctx, "count")
+		require.NoError(t, err)
+		assert.Equal(t, 3, val)
+	})
+
+	t.Run("corrupted marker", func(t *testing.T) {
+		inner := NewInMemoryBackend()
+		memory := NewMemory(NewCompressedBackend(inner, 64))
+
+		require.NoError(t, inner.Set(ScopeSession, "test-session", "doc", []byte{0x7f, '{', '}'}))
+		_, err := memory.Get(ctx, "doc")
+		assert.ErrorContains(t, err, "unknown compression marker")
+	})
+}
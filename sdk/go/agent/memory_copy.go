@@ -0,0 +1,113 @@
+package agent
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// ValueCopier returns a copy of value that shares no mutable state with it.
+type ValueCopier func(value any) (any, error)
+
+// DeepCopy is the default ValueCopier. It recursively copies maps, slices,
+// arrays, pointers and the exported fields of structs, keeping every value's
+// dynamic type; unexported struct fields, channels and funcs are copied
+// shallowly. value must not contain pointer cycles.
+func DeepCopy(value any) (any, error) {
+	if value == nil {
+		return nil, nil
+	}
+	return deepCopyValue(reflect.ValueOf(value)).Interface(), nil
+}
+
+func deepCopyValue(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			out.SetMapIndex(iter.Key(), deepCopyValue(iter.Value()))
+		}
+		return out
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			out.Index(i).Set(deepCopyValue(v.Index(i)))
+		}
+		return out
+	case reflect.Array:
+		out := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			out.Index(i).Set(deepCopyValue(v.Index(i)))
+		}
+		return out
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.New(v.Type().Elem())
+		out.Elem().Set(deepCopyValue(v.Elem()))
+		return out
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.New(v.Type()).Elem()
+		out.Set(deepCopyValue(v.Elem()))
+		return out
+	case reflect.Struct:
+		out := reflect.New(v.Type()).Elem()
+		out.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if field := out.Field(i); field.CanSet() {
+				field.Set(deepCopyValue(v.Field(i)))
+			}
+		}
+		return out
+	}
+	return v
+}
+
+// JSONCopy is a ValueCopier that round-trips value through encoding/json.
+// Copies come back as JSON's generic types (map[string]any, []any, float64,
+// string, bool), so structs lose their type and []byte becomes a base64
+// string; use it when stored values are already plain JSON.
+func JSONCopy(value any) (any, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var out any
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// NewInMemoryBackendWithCopier creates an in-memory backend that copies values
+// with copier as they are stored and again as they are read, so a caller
+// mutating a map or slice it passed to Set or got back from Get never changes
+// what the backend holds. A nil copier uses DeepCopy. NewInMemoryBackend skips
+// the copies and shares values with callers.
+func NewInMemoryBackendWithCopier(copier ValueCopier) *InMemoryBackend {
+	if copier == nil {
+		copier = DeepCopy
+	}
+	b := newInMemoryBackend(defaultMemoryShards)
+	b.copier = copier
+	return b
+}
+
+// copyValue returns a copy of value when the backend was created with a
+// copier, and value itself otherwise.
+func (b *InMemoryBackend) copyValue(value any) (any, error) {
+	if b.copier == nil {
+		return value, nil
+	}
+	return b.copier(value)
+}
@@ -0,0 +1,87 @@
+package agent
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInMemoryBackend_CopyOnAccess(t *testing.T) {
+	t.Run("mutating a returned map does not change the stored value", func(t *testing.T) {
+		backend := NewInMemoryBackendWithCopier(nil)
+		require.NoError(t, backend.Set(ScopeSession, "s1", "profile", map[string]any{"tags": []any{"a"}}))
+
+		got, found, err := backend.Get(ScopeSession, "s1", "profile")
+		require.NoError(t, err)
+		require.True(t, found)
+		profile := got.(map[string]any)
+		profile["name"] = "mallory"
+		profile["tags"].([]any)[0] = "z"
+
+		again, _, err := backend.Get(ScopeSession, "s1", "profile")
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"tags": []any{"a"}}, again)
+	})
+
+	t.Run("mutating a stored map does not change the stored value", func(t *testing.T) {
+		backend := NewInMemoryBackendWithCopier(nil)
+		value := map[string]any{"count": 1}
+		require.NoError(t, backend.Set(ScopeSession, "s1", "k", value))
+		value["count"] = 2
+
+		entries, err := backend.Entries(ScopeSession, "s1")
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"count": 1}, entries["k"])
+	})
+
+	t.Run("default backend shares values", func(t *testing.T) {
+		backend := NewInMemoryBackend()
+		require.NoError(t, backend.Set(ScopeSession, "s1", "k", map[string]any{"count": 1}))
+
+		got, _, _ := backend.Get(ScopeSession, "s1", "k")
+		got.(map[string]any)["count"] = 2
+
+		again, _, _ := backend.Get(ScopeSession, "s1", "k")
+		assert.Equal(t, map[string]any{"count": 2}, again)
+	})
+
+	t.Run("json copier", func(t *testing.T) {
+		backend := NewInMemoryBackendWithCopier(JSONCopy)
+		require.NoError(t, backend.Set(ScopeSession, "s1", "k", map[string]int{"count": 1}))
+
+		got, _, err := backend.Get(ScopeSession, "s1", "k")
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"count": float64(1)}, got)
+
+		err = backend.Set(ScopeSession, "s1", "bad", func() {})
+		assert.Error(t, err)
+	})
+}
+
+func TestDeepCopy(t *testing.T) {
+	type inner struct{ Values []int }
+	type outer struct {
+		Name  string
+		Inner *inner
+		At    time.Time
+		Bytes []byte
+	}
+	at := time.Now()
+	original := outer{Name: "n", Inner: &inner{Values: []int{1, 2}}, At: at, Bytes: []byte("hi")}
+
+	copied, err := DeepCopy(original)
+	require.NoError(t, err)
+	original.Inner.Values[0] = 9
+	original.Bytes[0] = 'X'
+
+	out := copied.(outer)
+	assert.Equal(t, []int{1, 2}, out.Inner.Values)
+	assert.Equal(t, []byte("hi"), out.Bytes)
+	assert.True(t, at.Equal(out.At))
+
+	copied, err = DeepCopy(nil)
+	require.NoError(t, err)
+	assert.Nil(t, copied)
+}
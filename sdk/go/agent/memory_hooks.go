@@ -0,0 +1,81 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// ScopeHook warms a scope ID on first access. scoped addresses the scope ID
+// being accessed.
+type ScopeHook func(ctx context.Context, scoped *ScopedMemory) error
+
+// firstAccessKey identifies a scope ID for first-access tracking. It doubles
+// as the context key marking that its hook is running.
+type firstAccessKey struct {
+	scope   MemoryScope
+	scopeID string
+}
+
+// firstAccess runs a scope ID's hook once.
+type firstAccess struct {
+	once sync.Once
+	err  error
+}
+
+// OnScopeFirstAccess registers fn to run the first time each scope ID of
+// scope is read or written through this Memory after registration, e.g. to
+// copy user defaults into a new session. fn runs at most once per scope ID;
+// concurrent first accesses wait for it, and the access that ran it fails
+// with its error (later accesses proceed without retrying). Memory calls fn
+// makes on the same scope ID do not re-enter the hook. Registering again
+// replaces fn. The set of warmed scope IDs is kept for the life of the Memory.
+func (m *Memory) OnScopeFirstAccess(scope MemoryScope, fn ScopeHook) {
+	m.hookMu.Lock()
+	defer m.hookMu.Unlock()
+	if m.scopeHooks == nil {
+		m.scopeHooks = make(map[MemoryScope]ScopeHook)
+	}
+	m.scopeHooks[scope] = fn
+}
+
+// resolveID returns the scope ID for ctx, first running the scope's
+// first-access hook if this is the scope ID's first access.
+func (s *ScopedMemory) resolveID(ctx context.Context) (string, error) {
+	scopeID := s.getID(ctx)
+	if s.admin {
+		return scopeID, nil
+	}
+	return scopeID, s.memory.runFirstAccess(ctx, s.scope, scopeID)
+}
+
+func (m *Memory) runFirstAccess(ctx context.Context, scope MemoryScope, scopeID string) error {
+	m.hookMu.RLock()
+	hook := m.scopeHooks[scope]
+	m.hookMu.RUnlock()
+	if hook == nil {
+		return nil
+	}
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	key := firstAccessKey{scope: scope, scopeID: scopeID}
+	if ctx.Value(key) != nil {
+		return nil
+	}
+	entry, _ := m.firstAccessed.LoadOrStore(key, &firstAccess{})
+	state := entry.(*firstAccess)
+
+	ran := false
+	state.once.Do(func() {
+		ran = true
+		if err := hook(context.WithValue(ctx, key, true), m.Scoped(scope, scopeID)); err != nil {
+			state.err = fmt.Errorf("first-access hook for %s/%s: %w", scope, scopeID, err)
+		}
+	})
+	if ran {
+		return state.err
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemory_OnScopeFirstAccess(t *testing.T) {
+	t.Run("runs once under concurrent first access", func(t *testing.T) {
+		memory := NewMemory(NewInMemoryBackend())
+		require.NoError(t, memory.Scoped(ScopeUser, "user-1").Set(context.Background(), "theme", "dark"))
+
+		var calls atomic.Int32
+		memory.OnScopeFirstAccess(ScopeSession, func(ctx context.Context, session *ScopedMemory) error {
+			calls.Add(1)
+			theme, err := memory.Scoped(ScopeUser, "user-1").Get(ctx, "theme")
+			if err != nil {
+				return err
+			}
+			// Writes to the scope being warmed do not re-enter the hook.
+			return session.Set(ctx, "theme", theme)
+		})
+
+		ctx := contextWithExecution(context.Background(), ExecutionContext{SessionID: "session-1"})
+		var wg sync.WaitGroup
+		for i := 0; i < 16; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				val, err := memory.SessionScope().Get(ctx, "theme")
+				assert.NoError(t, err)
+				assert.Equal(t, "dark", val, "accesses must see the warmed scope")
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, int32(1), calls.Load())
+
+		other := contextWithExecution(context.Background(), ExecutionContext{SessionID: "session-2"})
+		require.NoError(t, memory.SessionScope().Set(other, "k", 1))
+		assert.Equal(t, int32(2), calls.Load(), "each scope ID is warmed separately")
+
+		require.NoError(t, memory.GlobalScope().Set(ctx, "k", 1))
+		assert.Equal(t, int32(2), calls.Load(), "other scopes have no hook")
+	})
+
+	t.Run("hook error fails the triggering access only", func(t *testing.T) {
+		memory := NewMemory(NewInMemoryBackend())
+		boom := errors.New("boom")
+		memory.OnScopeFirstAccess(ScopeSession, func(context.Context, *ScopedMemory) error {
+			return boom
+		})
+
+		ctx := contextWithExecution(context.Background(), ExecutionContext{SessionID: "session-1"})
+		assert.ErrorIs(t, memory.SessionScope().Set(ctx, "k", 1), boom)
+		assert.NoError(t, memory.SessionScope().Set(ctx, "k", 1))
+	})
+
+	t.Run("nil ctx is treated as background", func(t *testing.T) {
+		memory := NewMemory(NewInMemoryBackend())
+		var calls atomic.Int32
+		memory.OnScopeFirstAccess(ScopeUser, func(context.Context, *ScopedMemory) error {
+			calls.Add(1)
+			return nil
+		})
+
+		require.NoError(t, memory.Scoped(ScopeUser, "user-1").Set(nil, "k", 1))
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("admin enumeration does not run hooks", func(t *testing.T) {
+		backend := NewInMemoryBackend()
+		require.NoError(t, backend.Set(ScopeSession, "session-1", "cart", 1))
+		require.NoError(t, backend.Set(ScopeSession, "session-2", "cart", 2))
+
+		memory := NewMemoryWithConfig(backend, MemoryConfig{EnableSearch: true})
+		var calls atomic.Int32
+		memory.OnScopeFirstAccess(ScopeSession, func(ctx context.Context, session *ScopedMemory) error {
+			calls.Add(1)
+			return session.Set(ctx, "warmed", true)
+		})
+
+		ctx := context.Background()
+		_, err := memory.ScopeStats(ctx)
+		require.NoError(t, err)
+		_, err = memory.Search(ctx, KeySearchOptions{Pattern: "*"})
+		require.NoError(t, err)
+		_, err = memory.ScopeSizes(ScopeSession)
+		require.NoError(t, err)
+
+		assert.Equal(t, int32(0), calls.Load())
+		_, found, _ := backend.Get(ScopeSession, "session-1", "warmed")
+		assert.False(t, found, "enumeration must not write warm-up data")
+	})
+}
@@ -0,0 +1,37 @@
+package agent
+
+// BackendMiddleware wraps a MemoryBackend with extra behavior, returning the
+// wrapped backend. NewCompressedBackend and NewRetryingBackend are adapted by
+// CompressionMiddleware and RetryMiddleware.
+type BackendMiddleware func(MemoryBackend) MemoryBackend
+
+// Chain wraps base in middlewares. The first middleware is the outermost
+// layer: it sees each call first and each result last. For example
+//
+//	Chain(base, RetryMiddleware(cfg), CompressionMiddleware(0))
+//
+// retries whole compressed operations, with values compressed before they
+// reach base. Nil middlewares are skipped.
+func Chain(base MemoryBackend, middlewares ...BackendMiddleware) MemoryBackend {
+	backend := base
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		if middlewares[i] != nil {
+			backend = middlewares[i](backend)
+		}
+	}
+	return backend
+}
+
+// CompressionMiddleware is a BackendMiddleware applying NewCompressedBackend.
+func CompressionMiddleware(threshold int) BackendMiddleware {
+	return func(backend MemoryBackend) MemoryBackend {
+		return NewCompressedBackend(backend, threshold)
+	}
+}
+
+// RetryMiddleware is a BackendMiddleware applying NewRetryingBackend.
+func RetryMiddleware(config RetryConfig) BackendMiddleware {
+	return func(backend MemoryBackend) MemoryBackend {
+		return NewRetryingBackend(backend, config)
+	}
+}
@@ -0,0 +1,106 @@
+package agent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// metricsBackend counts operations reaching it.
+type metricsBackend struct {
+	MemoryBackend
+	sets, gets int
+}
+
+func (b *metricsBackend) Set(scope MemoryScope, scopeID, key string, value any) error {
+	b.sets++
+	return b.MemoryBackend.Set(scope, scopeID, key, value)
+}
+
+func (b *metricsBackend) Get(scope MemoryScope, scopeID, key string) (any, bool, error) {
+	b.gets++
+	return b.MemoryBackend.Get(scope, scopeID, key)
+}
+
+// xorBackend stands in for an encrypting wrapper: it obscures []byte values
+// on the way in and restores them on the way out.
+type xorBackend struct {
+	MemoryBackend
+}
+
+func xorBytes(in []byte) []byte {
+	out := make([]byte, len(in))
+	for i, c := range in {
+		out[i] = c ^ 0x5a
+	}
+	return out
+}
+
+func (b *xorBackend) Set(scope MemoryScope, scopeID, key string, value any) error {
+	if raw, ok := encodedBytes(value); ok {
+		value = xorBytes(raw)
+	}
+	return b.MemoryBackend.Set(scope, scopeID, key, value)
+}
+
+func (b *xorBackend) Get(scope MemoryScope, scopeID, key string) (any, bool, error) {
+	val, found, err := b.MemoryBackend.Get(scope, scopeID, key)
+	if raw, ok := val.([]byte); ok {
+		val = xorBytes(raw)
+	}
+	return val, found, err
+}
+
+func TestChain(t *testing.T) {
+	ctx := contextWithExecution(context.Background(), ExecutionContext{
+		SessionID: "test-session",
+	})
+
+	t.Run("metrics over encryption", func(t *testing.T) {
+		inner := NewInMemoryBackend()
+		metrics := &metricsBackend{}
+		backend := Chain(inner,
+			func(next MemoryBackend) MemoryBackend {
+				metrics.MemoryBackend = next
+				return metrics
+			},
+			func(next MemoryBackend) MemoryBackend { return &xorBackend{next} },
+		)
+		require.Same(t, metrics, backend, "first middleware is outermost")
+
+		memory := NewMemory(backend)
+		require.NoError(t, memory.SessionScope().SetTyped(ctx, "secret", map[string]string{"pin": "1234"}))
+
+		stored, found, err := inner.Get(ScopeSession, "test-session", "secret")
+		require.NoError(t, err)
+		require.True(t, found)
+		assert.NotContains(t, string(stored.([]byte)), "1234", "value should be encrypted at rest")
+
+		var got map[string]string
+		require.NoError(t, memory.SessionScope().GetTyped(ctx, "secret", &got))
+		assert.Equal(t, "1234", got["pin"])
+		assert.Equal(t, 1, metrics.sets)
+		assert.Equal(t, 1, metrics.gets)
+	})
+
+	t.Run("built-in middlewares", func(t *testing.T) {
+		inner := NewInMemoryBackend()
+		backend := Chain(inner, RetryMiddleware(RetryConfig{}), nil, CompressionMiddleware(0))
+		retrying, ok := backend.(*RetryingBackend)
+		require.True(t, ok)
+		assert.IsType(t, &CompressedBackend{}, retrying.MemoryBackend)
+
+		memory := NewMemory(backend)
+		require.NoError(t, memory.SessionScope().SetTyped(ctx, "doc", "hello"))
+		stored, _, err := inner.Get(ScopeSession, "test-session", "doc")
+		require.NoError(t, err)
+		assert.Equal(t, compressionMarkerRaw, stored.([]byte)[0])
+	})
+
+	t.Run("no middlewares", func(t *testing.T) {
+		inner := NewInMemoryBackend()
+		assert.Same(t, inner, Chain(inner))
+	})
+}
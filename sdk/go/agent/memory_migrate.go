@@ -0,0 +1,101 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ScopeMigration reports what Migrate did for a single scope.
+type ScopeMigration struct {
+	Scope    MemoryScope `json:"scope"`
+	ScopeIDs int         `json:"scope_ids"`
+	// Copied counts keys written to the destination.
+	Copied int `json:"copied"`
+	// Errors holds one entry per key or scope ID that could not be copied.
+	Errors []error `json:"-"`
+}
+
+// MigrationReport summarizes a Migrate run, one entry per requested scope.
+type MigrationReport struct {
+	Scopes []ScopeMigration `json:"scopes"`
+}
+
+// Copied returns the number of keys copied across all scopes.
+func (r MigrationReport) Copied() int {
+	total := 0
+	for _, scope := range r.Scopes {
+		total += scope.Copied
+	}
+	return total
+}
+
+// Err joins every per-key error in the report, or returns nil if all keys
+// were copied.
+func (r MigrationReport) Err() error {
+	var errs []error
+	for _, scope := range r.Scopes {
+		errs = append(errs, scope.Errors...)
+	}
+	return errors.Join(errs...)
+}
+
+// Migrate copies every key in scopes from src to dst, for moving data to a
+// new backend. A nil scopes migrates all of them. src must implement
+// ScopeIDLister; otherwise ErrScopeListingUnsupported is returned.
+//
+// Keys are written with Set, overwriting what dst holds, so a failed or
+// interrupted migration can simply be re-run. Keys that fail are recorded in
+// the report and skipped; the returned error is reserved for failures that stop
+// the run, such as ctx ending. Vectors and per-key TTLs are not migrated.
+func Migrate(ctx context.Context, src, dst MemoryBackend, scopes []MemoryScope) (MigrationReport, error) {
+	lister, ok := src.(ScopeIDLister)
+	if !ok {
+		return MigrationReport{}, ErrScopeListingUnsupported
+	}
+	if scopes == nil {
+		scopes = allMemoryScopes
+	}
+
+	var report MigrationReport
+	for _, scope := range scopes {
+		entry := ScopeMigration{Scope: scope}
+		scopeIDs, err := lister.ListScopeIDs(scope)
+		if err != nil {
+			entry.Errors = append(entry.Errors, fmt.Errorf("list %s scope IDs: %w", scope, err))
+			report.Scopes = append(report.Scopes, entry)
+			continue
+		}
+		entry.ScopeIDs = len(scopeIDs)
+
+		for _, scopeID := range scopeIDs {
+			keys, err := src.List(scope, scopeID)
+			if err != nil {
+				entry.Errors = append(entry.Errors, fmt.Errorf("list %s/%s: %w", scope, scopeID, err))
+				continue
+			}
+			for _, key := range keys {
+				if err := ctx.Err(); err != nil {
+					report.Scopes = append(report.Scopes, entry)
+					return report, err
+				}
+				val, found, err := src.Get(scope, scopeID, key)
+				if err != nil {
+					entry.Errors = append(entry.Errors, fmt.Errorf("get %s/%s/%s: %w", scope, scopeID, key, err))
+					continue
+				}
+				// Keys deleted since List are skipped.
+				if !found {
+					continue
+				}
+				if err := dst.Set(scope, scopeID, key, val); err != nil {
+					entry.Errors = append(entry.Errors, fmt.Errorf("set %s/%s/%s: %w", scope, scopeID, key, err))
+					continue
+				}
+				entry.Copied++
+			}
+		}
+		report.Scopes = append(report.Scopes, entry)
+	}
+	return report, nil
+}
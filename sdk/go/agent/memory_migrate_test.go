@@ -0,0 +1,98 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingSetBackend rejects writes to a single key.
+type failingSetBackend struct {
+	MemoryBackend
+	key string
+}
+
+func (b *failingSetBackend) Set(scope MemoryScope, scopeID, key string, value any) error {
+	if key == b.key {
+		return errors.New("disk full")
+	}
+	return b.MemoryBackend.Set(scope, scopeID, key, value)
+}
+
+func TestMigrate(t *testing.T) {
+	ctx := context.Background()
+	src := NewInMemoryBackend()
+	for i := 0; i < 3; i++ {
+		sessionID := fmt.Sprintf("session-%d", i)
+		for j := 0; j < 4; j++ {
+			require.NoError(t, src.Set(ScopeSession, sessionID, fmt.Sprintf("key-%d", j), map[string]any{"n": j}))
+		}
+	}
+	require.NoError(t, src.Set(ScopeGlobal, "global", "config", "v1"))
+	require.NoError(t, src.Set(ScopeUser, "u-1", "pref", []byte(`{"theme":"dark"}`)))
+
+	t.Run("copies every scope", func(t *testing.T) {
+		dst := NewInMemoryBackend()
+		report, err := Migrate(ctx, src, dst, nil)
+		require.NoError(t, err)
+		require.NoError(t, report.Err())
+		assert.Equal(t, 14, report.Copied())
+
+		before, err := src.Snapshot()
+		require.NoError(t, err)
+		after, err := dst.Snapshot()
+		require.NoError(t, err)
+		diffs, err := DiffSnapshots(before, after)
+		require.NoError(t, err)
+		assert.Empty(t, diffs)
+
+		// Re-running over a populated destination is harmless.
+		report, err = Migrate(ctx, src, dst, nil)
+		require.NoError(t, err)
+		assert.Equal(t, 14, report.Copied())
+		after, err = dst.Snapshot()
+		require.NoError(t, err)
+		diffs, err = DiffSnapshots(before, after)
+		require.NoError(t, err)
+		assert.Empty(t, diffs)
+	})
+
+	t.Run("selected scopes only", func(t *testing.T) {
+		dst := NewInMemoryBackend()
+		report, err := Migrate(ctx, src, dst, []MemoryScope{ScopeGlobal})
+		require.NoError(t, err)
+		require.Len(t, report.Scopes, 1)
+		assert.Equal(t, ScopeMigration{Scope: ScopeGlobal, ScopeIDs: 1, Copied: 1}, report.Scopes[0])
+
+		keys, err := dst.List(ScopeSession, "session-0")
+		require.NoError(t, err)
+		assert.Empty(t, keys)
+	})
+
+	t.Run("per-key errors are reported", func(t *testing.T) {
+		dst := &failingSetBackend{MemoryBackend: NewInMemoryBackend(), key: "config"}
+		report, err := Migrate(ctx, src, dst, []MemoryScope{ScopeGlobal, ScopeUser})
+		require.NoError(t, err)
+		require.Len(t, report.Scopes, 2)
+		assert.Len(t, report.Scopes[0].Errors, 1)
+		assert.Equal(t, 0, report.Scopes[0].Copied)
+		assert.Equal(t, 1, report.Scopes[1].Copied)
+		assert.ErrorContains(t, report.Err(), "global/global/config")
+	})
+
+	t.Run("source must list scope IDs", func(t *testing.T) {
+		_, err := Migrate(ctx, struct{ MemoryBackend }{src}, NewInMemoryBackend(), nil)
+		assert.ErrorIs(t, err, ErrScopeListingUnsupported)
+	})
+
+	t.Run("cancelled context stops the run", func(t *testing.T) {
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		_, err := Migrate(cancelled, src, NewInMemoryBackend(), nil)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"runtime"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -203,6 +204,41 @@ func BenchmarkInMemoryBackendList(b *testing.B) {
 	}
 }
 
+// BenchmarkInMemoryBackendConcurrentSet benchmarks parallel global-scope writes
+// to distinct keys, comparing a single lock stripe with the default striping.
+func BenchmarkInMemoryBackendConcurrentSet(b *testing.B) {
+	cases := []struct {
+		name   string
+		shards int
+	}{
+		{"single_lock", 1},
+		{"striped", defaultMemoryShards},
+	}
+
+	for _, tc := range cases {
+		b.Run(tc.name, func(b *testing.B) {
+			backend := newInMemoryBackend(tc.shards)
+			var worker atomic.Int64
+
+			b.ResetTimer()
+			b.ReportAllocs()
+
+			b.RunParallel(func(pb *testing.PB) {
+				id := worker.Add(1)
+				keys := make([]string, 1024)
+				for i := range keys {
+					keys[i] = fmt.Sprintf("worker_%d_key_%d", id, i)
+				}
+				i := 0
+				for pb.Next() {
+					_ = backend.Set(ScopeGlobal, "global", keys[i%len(keys)], i)
+					i++
+				}
+			})
+		})
+	}
+}
+
 // TestMemoryPerformanceReport generates a comprehensive memory report
 func TestMemoryPerformanceReport(t *testing.T) {
 	var metrics []*MemoryMetrics
@@ -0,0 +1,37 @@
+package agent
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrPurgeExpiredUnsupported is returned by PurgeExpired when the backend does
+// not implement ExpiredPurger.
+var ErrPurgeExpiredUnsupported = errors.New("memory backend does not support purging expired keys")
+
+// ExpiredPurger is implemented by backends that hold on to expired keys until
+// a sweep reclaims them, such as CacheBackend.
+type ExpiredPurger interface {
+	// PurgeExpired removes every expired key in scope and returns how many
+	// it removed.
+	PurgeExpired(scope MemoryScope) (int, error)
+}
+
+// PurgeExpired reclaims the expired keys in scope now instead of waiting for
+// the backend's own sweep, and returns how many were removed. Keys that have
+// not expired are left alone, so it is safe to run alongside a background
+// sweeper: each expired key is removed by exactly one of them.
+func (m *Memory) PurgeExpired(ctx context.Context, scope MemoryScope) (int, error) {
+	purger, ok := m.backend.(ExpiredPurger)
+	if !ok {
+		return 0, ErrPurgeExpiredUnsupported
+	}
+
+	var purged int
+	err := m.call(ctx, func() error {
+		var err error
+		purged, err = purger.PurgeExpired(scope)
+		return err
+	})
+	return purged, err
+}
@@ -0,0 +1,46 @@
+package agent
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemory_PurgeExpired(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("purges expired keys in the scope only", func(t *testing.T) {
+		backend, clock := newTestCacheBackend(0, time.Minute)
+		memory := NewMemory(backend)
+
+		require.NoError(t, backend.Set(ScopeSession, "s1", "old-1", 1))
+		require.NoError(t, backend.Set(ScopeSession, "s2", "old-2", 2))
+		require.NoError(t, backend.Set(ScopeGlobal, "global", "old-global", 3))
+		*clock = clock.Add(30 * time.Second)
+		require.NoError(t, backend.Set(ScopeSession, "s1", "fresh", 4))
+		*clock = clock.Add(45 * time.Second) // the first three keys are now expired
+
+		purged, err := memory.PurgeExpired(ctx, ScopeSession)
+		require.NoError(t, err)
+		assert.Equal(t, 2, purged)
+		assert.Equal(t, 2, backend.Len(), "fresh key and the other scope's expired key remain")
+
+		keys, err := backend.List(ScopeSession, "s1")
+		require.NoError(t, err)
+		sort.Strings(keys)
+		assert.Equal(t, []string{"fresh"}, keys)
+
+		purged, err = memory.PurgeExpired(ctx, ScopeSession)
+		require.NoError(t, err)
+		assert.Zero(t, purged)
+	})
+
+	t.Run("unsupported backend", func(t *testing.T) {
+		_, err := NewMemory(NewInMemoryBackend()).PurgeExpired(ctx, ScopeSession)
+		assert.ErrorIs(t, err, ErrPurgeExpiredUnsupported)
+	})
+}
@@ -0,0 +1,122 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Defaults applied by NewRetryingBackend to zero RetryConfig fields.
+const (
+	defaultRetryAttempts  = 3
+	defaultRetryBaseDelay = 50 * time.Millisecond
+)
+
+// RetryConfig controls how RetryingBackend retries failed operations.
+type RetryConfig struct {
+	// Attempts is the total number of tries per operation, including the
+	// first. Defaults to 3.
+	Attempts int
+	// BaseDelay is the wait before the first retry; it doubles after each
+	// further failure. Defaults to 50ms.
+	BaseDelay time.Duration
+	// Context stops retrying once done, returning the last error. Backend
+	// calls carry no context of their own, so this is typically the agent's
+	// lifetime context. Defaults to context.Background().
+	Context context.Context
+}
+
+// RetryingBackend wraps a MemoryBackend and retries Set, Get, Delete and List
+// with exponential backoff. All four are idempotent, so a retry after an
+// ambiguous failure cannot corrupt data. Vector operations are delegated
+// without retries.
+//
+// Retries happen inside the backend call, so they count against
+// MemoryConfig.OpTimeout.
+type RetryingBackend struct {
+	MemoryBackend
+	config RetryConfig
+}
+
+// NewRetryingBackend wraps backend with retries configured by config.
+func NewRetryingBackend(backend MemoryBackend, config RetryConfig) *RetryingBackend {
+	if config.Attempts <= 0 {
+		config.Attempts = defaultRetryAttempts
+	}
+	if config.BaseDelay <= 0 {
+		config.BaseDelay = defaultRetryBaseDelay
+	}
+	if config.Context == nil {
+		config.Context = context.Background()
+	}
+	return &RetryingBackend{MemoryBackend: backend, config: config}
+}
+
+// retry runs op until it succeeds, the attempts are used up or the context
+// is done, returning the last error.
+func (b *RetryingBackend) retry(op func() error) error {
+	delay := b.config.BaseDelay
+	for attempt := 1; ; attempt++ {
+		err := op()
+		if err == nil {
+			return nil
+		}
+		if attempt >= b.config.Attempts {
+			return fmt.Errorf("memory backend failed after %d attempts: %w", attempt, err)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-b.config.Context.Done():
+			timer.Stop()
+			return fmt.Errorf("memory backend retry aborted after %d attempts: %w", attempt, err)
+		case <-timer.C:
+		}
+		delay *= 2
+	}
+}
+
+// Set stores value, retrying on failure.
+func (b *RetryingBackend) Set(scope MemoryScope, scopeID, key string, value any) error {
+	return b.retry(func() error {
+		return b.MemoryBackend.Set(scope, scopeID, key, value)
+	})
+}
+
+// Get retrieves a value, retrying on failure.
+func (b *RetryingBackend) Get(scope MemoryScope, scopeID, key string) (any, bool, error) {
+	var (
+		val   any
+		found bool
+	)
+	err := b.retry(func() error {
+		var err error
+		val, found, err = b.MemoryBackend.Get(scope, scopeID, key)
+		return err
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return val, found, nil
+}
+
+// Delete removes a key, retrying on failure.
+func (b *RetryingBackend) Delete(scope MemoryScope, scopeID, key string) error {
+	return b.retry(func() error {
+		return b.MemoryBackend.Delete(scope, scopeID, key)
+	})
+}
+
+// List returns the keys in a scope, retrying on failure.
+func (b *RetryingBackend) List(scope MemoryScope, scopeID string) ([]string, error) {
+	var keys []string
+	err := b.retry(func() error {
+		var err error
+		keys, err = b.MemoryBackend.List(scope, scopeID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
@@ -0,0 +1,79 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyBackend fails the first failures calls to Set and Get.
+type flakyBackend struct {
+	MemoryBackend
+	failures int
+	attempts int
+}
+
+var errFlaky = errors.New("connection reset")
+
+func (b *flakyBackend) fail() bool {
+	b.attempts++
+	return b.attempts <= b.failures
+}
+
+func (b *flakyBackend) Set(scope MemoryScope, scopeID, key string, value any) error {
+	if b.fail() {
+		return errFlaky
+	}
+	return b.MemoryBackend.Set(scope, scopeID, key, value)
+}
+
+func (b *flakyBackend) Get(scope MemoryScope, scopeID, key string) (any, bool, error) {
+	if b.fail() {
+		return nil, false, errFlaky
+	}
+	return b.MemoryBackend.Get(scope, scopeID, key)
+}
+
+func TestRetryingBackend(t *testing.T) {
+	ctx := contextWithExecution(context.Background(), ExecutionContext{
+		SessionID: "test-session",
+	})
+
+	t.Run("succeeds after transient failures", func(t *testing.T) {
+		flaky := &flakyBackend{MemoryBackend: NewInMemoryBackend(), failures: 2}
+		memory := NewMemory(NewRetryingBackend(flaky, RetryConfig{Attempts: 3, BaseDelay: time.Millisecond}))
+
+		require.NoError(t, memory.Set(ctx, "key", "value"))
+		assert.Equal(t, 3, flaky.attempts)
+
+		flaky.attempts, flaky.failures = 0, 2
+		val, err := memory.Get(ctx, "key")
+		require.NoError(t, err)
+		assert.Equal(t, "value", val)
+		assert.Equal(t, 3, flaky.attempts)
+	})
+
+	t.Run("gives up after the configured attempts", func(t *testing.T) {
+		flaky := &flakyBackend{MemoryBackend: NewInMemoryBackend(), failures: 5}
+		memory := NewMemory(NewRetryingBackend(flaky, RetryConfig{Attempts: 2, BaseDelay: time.Millisecond}))
+
+		err := memory.Set(ctx, "key", "value")
+		assert.ErrorIs(t, err, errFlaky)
+		assert.Equal(t, 2, flaky.attempts)
+	})
+
+	t.Run("stops when the context is cancelled", func(t *testing.T) {
+		retryCtx, cancel := context.WithCancel(context.Background())
+		cancel()
+		flaky := &flakyBackend{MemoryBackend: NewInMemoryBackend(), failures: 5}
+		backend := NewRetryingBackend(flaky, RetryConfig{Attempts: 5, BaseDelay: time.Hour, Context: retryCtx})
+
+		err := backend.Set(ScopeSession, "s", "key", "value")
+		assert.ErrorIs(t, err, errFlaky)
+		assert.Equal(t, 1, flaky.attempts)
+	})
+}
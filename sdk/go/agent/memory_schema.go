@@ -0,0 +1,53 @@
+package agent
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// ErrSchemaMismatch is returned when a write to a key registered with
+// RegisterSchema does not match the registered sample's shape.
+var ErrSchemaMismatch = errors.New("memory: value does not match registered schema")
+
+// RegisterSchema makes writes to key validate against sample's type: the
+// value is JSON-encoded and must decode into that type without unknown fields
+// or type errors. Absent fields are allowed. key is the key as stored, i.e.
+// including any ScopedMemory.Sub prefix, and applies in every scope. A nil sample
+// removes the registration. Keys without a registration are not checked.
+func (m *Memory) RegisterSchema(key string, sample any) {
+	m.schemaMu.Lock()
+	defer m.schemaMu.Unlock()
+
+	if sample == nil {
+		delete(m.schemas, key)
+		return
+	}
+	if m.schemas == nil {
+		m.schemas = make(map[string]reflect.Type)
+	}
+	m.schemas[key] = reflect.TypeOf(sample)
+}
+
+// checkSchema validates value against the schema registered for key, if any.
+func (m *Memory) checkSchema(key string, value any) error {
+	m.schemaMu.RLock()
+	typ, ok := m.schemas[key]
+	m.schemaMu.RUnlock()
+	if !ok {
+		return nil
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("%w: key %q: %v", ErrSchemaMismatch, key, err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(reflect.New(typ).Interface()); err != nil {
+		return fmt.Errorf("%w: key %q expects %s: %v", ErrSchemaMismatch, key, typ, err)
+	}
+	return nil
+}
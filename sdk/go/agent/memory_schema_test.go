@@ -0,0 +1,57 @@
+package agent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemory_RegisterSchema(t *testing.T) {
+	type profile struct {
+		Name string `json:"name"`
+		Age  int    `json:"age"`
+	}
+	ctx := contextWithExecution(context.Background(), ExecutionContext{SessionID: "session-1"})
+
+	t.Run("conforming writes succeed", func(t *testing.T) {
+		memory := NewMemory(NewInMemoryBackend())
+		memory.RegisterSchema("profile", profile{})
+
+		require.NoError(t, memory.Set(ctx, "profile", profile{Name: "ada", Age: 36}))
+		require.NoError(t, memory.Set(ctx, "profile", map[string]any{"name": "ada"}), "absent fields are allowed")
+		require.NoError(t, memory.SetTyped(ctx, "profile", profile{Name: "ada"}))
+
+		var got profile
+		require.NoError(t, memory.GetTyped(ctx, "profile", &got))
+		assert.Equal(t, profile{Name: "ada"}, got)
+	})
+
+	t.Run("mismatched writes fail", func(t *testing.T) {
+		memory := NewMemory(NewInMemoryBackend())
+		memory.RegisterSchema("profile", profile{})
+
+		err := memory.Set(ctx, "profile", map[string]any{"name": "ada", "age": "old"})
+		require.ErrorIs(t, err, ErrSchemaMismatch)
+		assert.Contains(t, err.Error(), `"profile"`)
+		assert.ErrorIs(t, memory.Set(ctx, "profile", map[string]any{"nickname": "ada"}), ErrSchemaMismatch)
+		assert.ErrorIs(t, memory.SetTyped(ctx, "profile", "ada"), ErrSchemaMismatch)
+		_, err = memory.SessionScope().SetIfAbsent(ctx, "profile", 42)
+		assert.ErrorIs(t, err, ErrSchemaMismatch)
+
+		val, err := memory.Get(ctx, "profile")
+		require.NoError(t, err)
+		assert.Nil(t, val, "rejected writes must not reach the backend")
+	})
+
+	t.Run("unregistered keys and removal", func(t *testing.T) {
+		memory := NewMemory(NewInMemoryBackend())
+		memory.RegisterSchema("profile", profile{})
+
+		assert.NoError(t, memory.Set(ctx, "other", "anything"))
+
+		memory.RegisterSchema("profile", nil)
+		assert.NoError(t, memory.Set(ctx, "profile", "ada"))
+	})
+}
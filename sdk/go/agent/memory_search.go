@@ -0,0 +1,93 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+)
+
+// ErrSearchDisabled is returned by Memory.Search unless MemoryConfig.EnableSearch is set.
+var ErrSearchDisabled = errors.New("memory search is disabled")
+
+// KeySearchOptions selects the keys Memory.Search returns.
+type KeySearchOptions struct {
+	// Scopes to search; empty searches every scope.
+	Scopes []MemoryScope `json:"scopes,omitempty"`
+	// Pattern is a path.Match glob matched against whole keys, e.g. "cart*".
+	Pattern string `json:"pattern"`
+	// Limit caps the number of results; zero returns every match.
+	Limit int `json:"limit,omitempty"`
+}
+
+// KeySearchResult is a single key matched by Memory.Search.
+type KeySearchResult struct {
+	Scope   MemoryScope `json:"scope"`
+	ScopeID string      `json:"scope_id"`
+	Key     string      `json:"key"`
+	Value   any         `json:"value"`
+}
+
+// Search finds keys matching opts.Pattern across every scope ID of the
+// requested scopes, for support tooling that knows a key but not where it
+// lives. Results are ordered by scope, scope ID and key. It lists every key
+// in each scope ID, so it is gated behind MemoryConfig.EnableSearch and
+// returns ErrSearchDisabled otherwise, and ErrScopeListingUnsupported when
+// the backend cannot enumerate scope IDs.
+func (m *Memory) Search(ctx context.Context, opts KeySearchOptions) ([]KeySearchResult, error) {
+	if !m.config.EnableSearch {
+		return nil, ErrSearchDisabled
+	}
+	if _, err := path.Match(opts.Pattern, ""); err != nil {
+		return nil, fmt.Errorf("search pattern %q: %w", opts.Pattern, err)
+	}
+	lister, ok := m.backend.(ScopeIDLister)
+	if !ok {
+		return nil, ErrScopeListingUnsupported
+	}
+
+	scopes := opts.Scopes
+	if len(scopes) == 0 {
+		scopes = allMemoryScopes
+	}
+
+	var results []KeySearchResult
+	for _, scope := range scopes {
+		var scopeIDs []string
+		err := m.call(ctx, func() error {
+			var err error
+			scopeIDs, err = lister.ListScopeIDs(scope)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("list %s scope IDs: %w", scope, err)
+		}
+		sort.Strings(scopeIDs)
+
+		for _, scopeID := range scopeIDs {
+			scoped := m.adminScoped(scope, scopeID)
+			keys, err := scoped.ListSorted(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("list %s/%s keys: %w", scope, scopeID, err)
+			}
+			for _, key := range keys {
+				if matched, _ := path.Match(opts.Pattern, key); !matched {
+					continue
+				}
+				value, found, err := scoped.get(ctx, key)
+				if err != nil {
+					return nil, fmt.Errorf("get %s/%s %q: %w", scope, scopeID, key, err)
+				}
+				if !found {
+					continue
+				}
+				results = append(results, KeySearchResult{Scope: scope, ScopeID: scopeID, Key: key, Value: value})
+				if opts.Limit > 0 && len(results) == opts.Limit {
+					return results, nil
+				}
+			}
+		}
+	}
+	return results, nil
+}
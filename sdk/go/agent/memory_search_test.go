@@ -0,0 +1,55 @@
+package agent
+
+import (
+	"context"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemory_Search(t *testing.T) {
+	ctx := context.Background()
+	backend := NewInMemoryBackend()
+	require.NoError(t, backend.Set(ScopeSession, "session-2", "cart:items", 2))
+	require.NoError(t, backend.Set(ScopeSession, "session-1", "cart:items", 1))
+	require.NoError(t, backend.Set(ScopeSession, "session-1", "profile", "ada"))
+	require.NoError(t, backend.Set(ScopeUser, "user-1", "cart:saved", 3))
+
+	t.Run("disabled by default", func(t *testing.T) {
+		_, err := NewMemory(backend).Search(ctx, KeySearchOptions{Pattern: "*"})
+		assert.ErrorIs(t, err, ErrSearchDisabled)
+	})
+
+	memory := NewMemoryWithConfig(backend, MemoryConfig{EnableSearch: true})
+
+	t.Run("glob matches across scope IDs", func(t *testing.T) {
+		results, err := memory.Search(ctx, KeySearchOptions{Pattern: "cart:*"})
+		require.NoError(t, err)
+		assert.Equal(t, []KeySearchResult{
+			{Scope: ScopeSession, ScopeID: "session-1", Key: "cart:items", Value: 1},
+			{Scope: ScopeSession, ScopeID: "session-2", Key: "cart:items", Value: 2},
+			{Scope: ScopeUser, ScopeID: "user-1", Key: "cart:saved", Value: 3},
+		}, results)
+	})
+
+	t.Run("scopes and limit narrow the search", func(t *testing.T) {
+		results, err := memory.Search(ctx, KeySearchOptions{Scopes: []MemoryScope{ScopeSession}, Pattern: "cart:*", Limit: 1})
+		require.NoError(t, err)
+		assert.Equal(t, []KeySearchResult{
+			{Scope: ScopeSession, ScopeID: "session-1", Key: "cart:items", Value: 1},
+		}, results)
+	})
+
+	t.Run("invalid pattern", func(t *testing.T) {
+		_, err := memory.Search(ctx, KeySearchOptions{Pattern: "["})
+		assert.ErrorIs(t, err, path.ErrBadPattern)
+	})
+
+	t.Run("backend without scope listing", func(t *testing.T) {
+		memory := NewMemoryWithConfig(listOnlyBackend{backend}, MemoryConfig{EnableSearch: true})
+		_, err := memory.Search(ctx, KeySearchOptions{Pattern: "*"})
+		assert.ErrorIs(t, err, ErrScopeListingUnsupported)
+	})
+}
@@ -0,0 +1,77 @@
+package agent
+
+import (
+	"errors"
+	"log"
+	"time"
+)
+
+// ErrSlidingTTLUnsupported is returned by SetSlidingTTL when the backend does
+// not implement TTLRefresher.
+var ErrSlidingTTLUnsupported = errors.New("memory backend does not support sliding TTLs")
+
+// TTLRefresher is implemented by backends that store per-key expirations and
+// can push an existing key's expiry out without changing its value.
+type TTLRefresher interface {
+	// SetWithTTL stores value so that it expires after ttl.
+	SetWithTTL(scope MemoryScope, scopeID, key string, value any, ttl time.Duration) error
+	// RefreshTTL makes key expire ttl from now and reports whether it exists.
+	RefreshTTL(scope MemoryScope, scopeID, key string, ttl time.Duration) (bool, error)
+}
+
+// SetSlidingTTL gives keys in scope an idle timeout: Set and SetTyped store
+// them with ttl, SetIfAbsent, SetIfPresent and GetSet extend the expiry of the
+// key they wrote, and every read that finds a key (Get, GetTyped and the
+// other ScopedMemory getters, and each key returned by Entries) extends its
+// expiry to ttl from then; the reads made by Search and ScopeSizes do not.
+// Keys that are no longer read expire ttl after their last access. Keys
+// written before the call, or through other write paths, gain an expiry on
+// their next read. A ttl of zero or less turns sliding expiry off for scope;
+// existing expirations are left as they are. A failed refresh is logged and
+// does not fail the read or write that triggered it.
+//
+// The backend must implement TTLRefresher; CacheBackend and the etcdmemory
+// backend do, while InMemoryBackend, the bolt and control-plane backends
+// return ErrSlidingTTLUnsupported.
+func (m *Memory) SetSlidingTTL(scope MemoryScope, ttl time.Duration) error {
+	if _, ok := m.backend.(TTLRefresher); !ok && ttl > 0 {
+		return ErrSlidingTTLUnsupported
+	}
+
+	m.slidingMu.Lock()
+	defer m.slidingMu.Unlock()
+
+	if ttl <= 0 {
+		delete(m.slidingTTLs, scope)
+		return nil
+	}
+	if m.slidingTTLs == nil {
+		m.slidingTTLs = make(map[MemoryScope]time.Duration)
+	}
+	m.slidingTTLs[scope] = ttl
+	return nil
+}
+
+// refreshSlidingTTL extends fullKey's expiry when the scope has a sliding
+// TTL. Callers run it inside their call closure, after the backend access.
+// Admin enumeration does not count as access and never refreshes. The access
+// itself already succeeded, so a failed refresh is only logged.
+func (s *ScopedMemory) refreshSlidingTTL(scopeID, fullKey string) {
+	if s.admin {
+		return
+	}
+	ttl := s.memory.slidingTTL(s.scope)
+	if ttl <= 0 {
+		return
+	}
+	if _, err := s.memory.backend.(TTLRefresher).RefreshTTL(s.scope, scopeID, fullKey, ttl); err != nil {
+		log.Printf("memory: sliding TTL refresh of %s key %q failed: %v", s.scope, fullKey, err)
+	}
+}
+
+// slidingTTL returns the idle timeout configured for scope, or zero.
+func (m *Memory) slidingTTL(scope MemoryScope) time.Duration {
+	m.slidingMu.RLock()
+	defer m.slidingMu.RUnlock()
+	return m.slidingTTLs[scope]
+}
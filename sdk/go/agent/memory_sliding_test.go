@@ -0,0 +1,203 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// expiringBackend honours per-key expirations against an injected clock.
+type expiringBackend struct {
+	*InMemoryBackend
+
+	mu       sync.Mutex
+	now      time.Time
+	expiries map[string]time.Time
+}
+
+func newExpiringBackend(now time.Time) *expiringBackend {
+	return &expiringBackend{InMemoryBackend: NewInMemoryBackend(), now: now, expiries: map[string]time.Time{}}
+}
+
+func (b *expiringBackend) advance(d time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.now = b.now.Add(d)
+}
+
+func (b *expiringBackend) SetWithTTL(scope MemoryScope, scopeID, key string, value any, ttl time.Duration) error {
+	b.mu.Lock()
+	b.expiries[string(scope)+scopeID+"/"+key] = b.now.Add(ttl)
+	b.mu.Unlock()
+	return b.InMemoryBackend.Set(scope, scopeID, key, value)
+}
+
+func (b *expiringBackend) RefreshTTL(scope MemoryScope, scopeID, key string, ttl time.Duration) (bool, error) {
+	if _, found, err := b.Get(scope, scopeID, key); err != nil || !found {
+		return false, err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.expiries[string(scope)+scopeID+"/"+key] = b.now.Add(ttl)
+	return true, nil
+}
+
+func (b *expiringBackend) Get(scope MemoryScope, scopeID, key string) (any, bool, error) {
+	b.mu.Lock()
+	expiry, ok := b.expiries[string(scope)+scopeID+"/"+key]
+	expired := ok && !b.now.Before(expiry)
+	b.mu.Unlock()
+	if expired {
+		return nil, false, nil
+	}
+	return b.InMemoryBackend.Get(scope, scopeID, key)
+}
+
+// failingRefreshBackend is an expiringBackend whose refreshes always fail.
+type failingRefreshBackend struct {
+	*expiringBackend
+}
+
+func (b *failingRefreshBackend) RefreshTTL(MemoryScope, string, string, time.Duration) (bool, error) {
+	return false, errors.New("refresh unavailable")
+}
+
+func TestMemory_SlidingTTL(t *testing.T) {
+	ctx := contextWithExecution(context.Background(), ExecutionContext{SessionID: "session-1"})
+
+	t.Run("reads keep a key alive", func(t *testing.T) {
+		backend := newExpiringBackend(time.Now())
+		memory := NewMemory(backend)
+		require.NoError(t, memory.SetSlidingTTL(ScopeSession, 10*time.Second))
+
+		require.NoError(t, memory.Set(ctx, "cart", "3 items"))
+		for i := 0; i < 5; i++ {
+			backend.advance(8 * time.Second)
+			val, err := memory.Get(ctx, "cart")
+			require.NoError(t, err)
+			assert.Equal(t, "3 items", val, "read %d, %s after the write", i, time.Duration(i+1)*8*time.Second)
+		}
+
+		backend.advance(10 * time.Second)
+		val, err := memory.Get(ctx, "cart")
+		require.NoError(t, err)
+		assert.Nil(t, val, "the key expires once reads stop")
+	})
+
+	t.Run("conditional writes and entries reads slide", func(t *testing.T) {
+		backend := newExpiringBackend(time.Now())
+		memory := NewMemory(backend)
+		require.NoError(t, memory.SetSlidingTTL(ScopeSession, 10*time.Second))
+
+		written, err := memory.SetIfAbsent(ctx, "cart", "3 items")
+		require.NoError(t, err)
+		require.True(t, written)
+		for i := 0; i < 3; i++ {
+			backend.advance(8 * time.Second)
+			entries, err := memory.Entries(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, map[string]any{"cart": "3 items"}, entries)
+		}
+
+		backend.advance(10 * time.Second)
+		val, err := memory.Get(ctx, "cart")
+		require.NoError(t, err)
+		assert.Nil(t, val, "SetIfAbsent stored the key with an expiry")
+	})
+
+	t.Run("admin enumeration does not slide or count", func(t *testing.T) {
+		backend := newExpiringBackend(time.Now())
+		memory := NewMemoryWithConfig(backend, MemoryConfig{EnableSearch: true}, WithStats())
+		require.NoError(t, memory.SetSlidingTTL(ScopeSession, 10*time.Second))
+
+		require.NoError(t, memory.Set(ctx, "cart", "3 items"))
+		backend.advance(8 * time.Second)
+		results, err := memory.Search(ctx, KeySearchOptions{Scopes: []MemoryScope{ScopeSession}, Pattern: "cart"})
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.Zero(t, memory.Stats().Hits, "search reads are not hits")
+
+		backend.advance(8 * time.Second)
+		val, err := memory.Get(ctx, "cart")
+		require.NoError(t, err)
+		assert.Nil(t, val, "search must not extend an idle key")
+	})
+
+	t.Run("CacheBackend supports sliding TTLs", func(t *testing.T) {
+		backend, clock := newTestCacheBackend(0, time.Hour)
+		memory := NewMemory(backend)
+		require.NoError(t, memory.SetSlidingTTL(ScopeSession, 10*time.Second))
+
+		require.NoError(t, memory.Set(ctx, "cart", "3 items"))
+		ttl, _, err := memory.TTL(ctx, "cart")
+		require.NoError(t, err)
+		assert.Equal(t, 10*time.Second, ttl, "the sliding TTL replaces defaultTTL")
+		*clock = clock.Add(8 * time.Second)
+		_, err = memory.Get(ctx, "cart")
+		require.NoError(t, err)
+		*clock = clock.Add(8 * time.Second)
+		val, err := memory.Get(ctx, "cart")
+		require.NoError(t, err)
+		assert.Equal(t, "3 items", val)
+
+		*clock = clock.Add(10 * time.Second)
+		val, err = memory.Get(ctx, "cart")
+		require.NoError(t, err)
+		assert.Nil(t, val)
+	})
+
+	t.Run("only the configured scope slides", func(t *testing.T) {
+		backend := newExpiringBackend(time.Now())
+		memory := NewMemory(backend)
+		require.NoError(t, memory.SetSlidingTTL(ScopeSession, 10*time.Second))
+
+		require.NoError(t, memory.GlobalScope().Set(ctx, "config", "v"))
+		backend.advance(time.Hour)
+		val, err := memory.GlobalScope().Get(ctx, "config")
+		require.NoError(t, err)
+		assert.Equal(t, "v", val)
+	})
+
+	t.Run("turning it off stops new expirations", func(t *testing.T) {
+		backend := newExpiringBackend(time.Now())
+		memory := NewMemory(backend)
+		require.NoError(t, memory.SetSlidingTTL(ScopeSession, 10*time.Second))
+		require.NoError(t, memory.SetSlidingTTL(ScopeSession, 0))
+
+		require.NoError(t, memory.Set(ctx, "cart", "3 items"))
+		backend.advance(time.Hour)
+		val, err := memory.Get(ctx, "cart")
+		require.NoError(t, err)
+		assert.Equal(t, "3 items", val)
+	})
+
+	t.Run("a failed refresh does not fail the read", func(t *testing.T) {
+		backend := &failingRefreshBackend{expiringBackend: newExpiringBackend(time.Now())}
+		memory := NewMemory(backend)
+		require.NoError(t, memory.SetSlidingTTL(ScopeSession, 10*time.Second))
+
+		require.NoError(t, memory.Set(ctx, "cart", "3 items"))
+		val, err := memory.Get(ctx, "cart")
+		require.NoError(t, err)
+		assert.Equal(t, "3 items", val)
+
+		written, err := memory.SessionScope().SetIfPresent(ctx, "cart", "4 items")
+		require.NoError(t, err)
+		assert.True(t, written)
+
+		entries, err := memory.SessionScope().Entries(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"cart": "4 items"}, entries)
+	})
+
+	t.Run("backend without TTLRefresher", func(t *testing.T) {
+		memory := NewMemory(NewInMemoryBackend())
+		assert.ErrorIs(t, memory.SetSlidingTTL(ScopeSession, time.Second), ErrSlidingTTLUnsupported)
+		assert.NoError(t, memory.SetSlidingTTL(ScopeSession, 0))
+	})
+}
@@ -0,0 +1,255 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ScopeSnapshotter is implemented by backends that can copy several scopes at
+// a single point in time.
+type ScopeSnapshotter interface {
+	// SnapshotScopes returns a copy of the key/value pairs held by each scope
+	// at the given scope ID, taken atomically across all of them.
+	SnapshotScopes(scopeIDs map[MemoryScope]string) (map[MemoryScope]map[string]any, error)
+}
+
+// ErrSnapshotUnsupported is returned by Memory.Snapshot when the backend
+// implements neither ScopeSnapshotter nor EntriesBackend.
+var ErrSnapshotUnsupported = errors.New("memory backend does not support snapshots")
+
+// SnapshotMemory is a read-only, point-in-time view of the scopes resolved
+// for one execution context. Writes made after it was taken are invisible,
+// giving a handler repeatable reads. Values are shared with the backend, not
+// deep-copied, so they must not be mutated.
+type SnapshotMemory struct {
+	codec    Codec
+	scopeIDs map[MemoryScope]string
+	entries  map[MemoryScope]map[string]any
+}
+
+// Snapshot copies every scope resolved from ctx into a SnapshotMemory.
+// Backends implementing ScopeSnapshotter, such as InMemoryBackend, copy all
+// scopes atomically; EntriesBackend implementations are copied one scope at a
+// time, so the view is only consistent within each scope.
+func (m *Memory) Snapshot(ctx context.Context) (*SnapshotMemory, error) {
+	scopeIDs := make(map[MemoryScope]string, len(allMemoryScopes))
+	for _, scope := range allMemoryScopes {
+		scopeIDs[scope] = m.scoped(scope).getID(ctx)
+	}
+
+	var entries map[MemoryScope]map[string]any
+	err := m.call(ctx, func() error {
+		switch backend := m.backend.(type) {
+		case ScopeSnapshotter:
+			var err error
+			entries, err = backend.SnapshotScopes(scopeIDs)
+			return err
+		case EntriesBackend:
+			entries = make(map[MemoryScope]map[string]any, len(scopeIDs))
+			for scope, scopeID := range scopeIDs {
+				scopeEntries, err := backend.Entries(scope, scopeID)
+				if err != nil {
+					return err
+				}
+				entries[scope] = scopeEntries
+			}
+			return nil
+		default:
+			return ErrSnapshotUnsupported
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &SnapshotMemory{codec: m.codec, scopeIDs: scopeIDs, entries: entries}, nil
+}
+
+// ScopeID returns the scope ID the snapshot resolved for scope.
+func (s *SnapshotMemory) ScopeID(scope MemoryScope) string {
+	return s.scopeIDs[scope]
+}
+
+// Get returns the value key held in scope when the snapshot was taken.
+func (s *SnapshotMemory) Get(scope MemoryScope, key string) (any, bool) {
+	val, found := s.entries[scope][key]
+	return val, found
+}
+
+// GetTyped decodes the value key held in scope into dest, reporting whether
+// the key existed. Absent keys leave dest untouched.
+func (s *SnapshotMemory) GetTyped(scope MemoryScope, key string, dest any) (bool, error) {
+	val, found := s.Get(scope, key)
+	if !found {
+		return false, nil
+	}
+	var data []byte
+	switch v := val.(type) {
+	case []byte:
+		data = v
+	case json.RawMessage:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		encoded, err := s.codec.Marshal(val)
+		if err != nil {
+			return true, err
+		}
+		data = encoded
+	}
+	return true, s.codec.Unmarshal(data, dest)
+}
+
+// List returns the keys scope held when the snapshot was taken, sorted.
+func (s *SnapshotMemory) List(scope MemoryScope) []string {
+	keys := make([]string, 0, len(s.entries[scope]))
+	for key := range s.entries[scope] {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// SnapshotScopes implements ScopeSnapshotter by read-locking every shard, so
+// the copy reflects a single instant across all requested scopes.
+func (b *InMemoryBackend) SnapshotScopes(scopeIDs map[MemoryScope]string) (map[MemoryScope]map[string]any, error) {
+	for i := range b.shards {
+		b.shards[i].mu.RLock()
+	}
+	defer func() {
+		for i := range b.shards {
+			b.shards[i].mu.RUnlock()
+		}
+	}()
+
+	snapshot := make(map[MemoryScope]map[string]any, len(scopeIDs))
+	for scope, scopeID := range scopeIDs {
+		ck := b.compositeKey(scope, scopeID)
+		entries := make(map[string]any)
+		for i := range b.shards {
+			for key, val := range b.shards[i].data[ck] {
+				entries[key] = val
+			}
+		}
+		snapshot[scope] = entries
+	}
+	return snapshot, nil
+}
+
+// MemoryDiffKind describes how a key differs between two snapshots.
+type MemoryDiffKind string
+
+const (
+	// MemoryDiffAdded marks a key present only in the second snapshot.
+	MemoryDiffAdded MemoryDiffKind = "added"
+	// MemoryDiffRemoved marks a key present only in the first snapshot.
+	MemoryDiffRemoved MemoryDiffKind = "removed"
+	// MemoryDiffChanged marks a key whose value differs between snapshots.
+	MemoryDiffChanged MemoryDiffKind = "changed"
+)
+
+// MemoryDiff is a single key-level difference reported by DiffSnapshots.
+type MemoryDiff struct {
+	Kind    MemoryDiffKind `json:"kind"`
+	Scope   MemoryScope    `json:"scope"`
+	ScopeID string         `json:"scope_id"`
+	Key     string         `json:"key"`
+	Old     any            `json:"old,omitempty"`
+	New     any            `json:"new,omitempty"`
+}
+
+// Snapshot serializes the key/value contents of the backend as JSON, keyed by
+// "scope:scopeID". Vector data is not included. Snapshots are intended for
+// debugging with DiffSnapshots rather than for persistence.
+func (b *InMemoryBackend) Snapshot() ([]byte, error) {
+	data := make(map[string]map[string]any)
+	for i := range b.shards {
+		s := &b.shards[i]
+		s.mu.RLock()
+		for ck, entries := range s.data {
+			if len(entries) == 0 {
+				continue
+			}
+			if data[ck] == nil {
+				data[ck] = make(map[string]any, len(entries))
+			}
+			for key, val := range entries {
+				data[ck][key] = val
+			}
+		}
+		s.mu.RUnlock()
+	}
+	return json.Marshal(data)
+}
+
+// DiffSnapshots compares two InMemoryBackend snapshots and reports the keys
+// that were added, removed or changed going from a to b. Results are sorted by
+// scope, scope ID and key.
+func DiffSnapshots(a, b []byte) ([]MemoryDiff, error) {
+	before, err := parseSnapshot(a)
+	if err != nil {
+		return nil, fmt.Errorf("parse first snapshot: %w", err)
+	}
+	after, err := parseSnapshot(b)
+	if err != nil {
+		return nil, fmt.Errorf("parse second snapshot: %w", err)
+	}
+
+	var diffs []MemoryDiff
+	for ck, oldEntries := range before {
+		scope, scopeID := splitCompositeKey(ck)
+		newEntries := after[ck]
+		for key, oldVal := range oldEntries {
+			newVal, ok := newEntries[key]
+			switch {
+			case !ok:
+				diffs = append(diffs, MemoryDiff{Kind: MemoryDiffRemoved, Scope: scope, ScopeID: scopeID, Key: key, Old: oldVal})
+			case !reflect.DeepEqual(oldVal, newVal):
+				diffs = append(diffs, MemoryDiff{Kind: MemoryDiffChanged, Scope: scope, ScopeID: scopeID, Key: key, Old: oldVal, New: newVal})
+			}
+		}
+	}
+	for ck, newEntries := range after {
+		scope, scopeID := splitCompositeKey(ck)
+		oldEntries := before[ck]
+		for key, newVal := range newEntries {
+			if _, ok := oldEntries[key]; !ok {
+				diffs = append(diffs, MemoryDiff{Kind: MemoryDiffAdded, Scope: scope, ScopeID: scopeID, Key: key, New: newVal})
+			}
+		}
+	}
+
+	sort.Slice(diffs, func(i, j int) bool {
+		if diffs[i].Scope != diffs[j].Scope {
+			return diffs[i].Scope < diffs[j].Scope
+		}
+		if diffs[i].ScopeID != diffs[j].ScopeID {
+			return diffs[i].ScopeID < diffs[j].ScopeID
+		}
+		return diffs[i].Key < diffs[j].Key
+	})
+	return diffs, nil
+}
+
+func parseSnapshot(raw []byte) (map[string]map[string]any, error) {
+	var data map[string]map[string]any
+	if len(raw) == 0 {
+		return data, nil
+	}
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// splitCompositeKey reverses InMemoryBackend.compositeKey. Scope names never
+// contain a colon, so everything after the first one is the scope ID.
+func splitCompositeKey(ck string) (MemoryScope, string) {
+	scope, scopeID, _ := strings.Cut(ck, ":")
+	return MemoryScope(scope), scopeID
+}
@@ -0,0 +1,143 @@
+package agent
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffSnapshots(t *testing.T) {
+	a := NewInMemoryBackend()
+	require.NoError(t, a.Set(ScopeSession, "s1", "kept", "same"))
+	require.NoError(t, a.Set(ScopeSession, "s1", "changed", float64(1)))
+	require.NoError(t, a.Set(ScopeGlobal, "global", "removed", "gone"))
+
+	b := NewInMemoryBackend()
+	require.NoError(t, b.Set(ScopeSession, "s1", "kept", "same"))
+	require.NoError(t, b.Set(ScopeSession, "s1", "changed", float64(2)))
+	require.NoError(t, b.Set(ScopeUser, "u:1", "added", map[string]any{"x": true}))
+
+	snapA, err := a.Snapshot()
+	require.NoError(t, err)
+	snapB, err := b.Snapshot()
+	require.NoError(t, err)
+
+	diffs, err := DiffSnapshots(snapA, snapB)
+	require.NoError(t, err)
+	assert.Equal(t, []MemoryDiff{
+		{Kind: MemoryDiffRemoved, Scope: ScopeGlobal, ScopeID: "global", Key: "removed", Old: "gone"},
+		{Kind: MemoryDiffChanged, Scope: ScopeSession, ScopeID: "s1", Key: "changed", Old: float64(1), New: float64(2)},
+		{Kind: MemoryDiffAdded, Scope: ScopeUser, ScopeID: "u:1", Key: "added", New: map[string]any{"x": true}},
+	}, diffs)
+
+	t.Run("identical snapshots", func(t *testing.T) {
+		diffs, err := DiffSnapshots(snapA, snapA)
+		require.NoError(t, err)
+		assert.Empty(t, diffs)
+	})
+
+	t.Run("empty first snapshot", func(t *testing.T) {
+		diffs, err := DiffSnapshots(nil, snapB)
+		require.NoError(t, err)
+		require.Len(t, diffs, 3)
+		for _, d := range diffs {
+			assert.Equal(t, MemoryDiffAdded, d.Kind)
+		}
+	})
+
+	t.Run("invalid snapshot", func(t *testing.T) {
+		_, err := DiffSnapshots([]byte("not json"), snapB)
+		assert.Error(t, err)
+	})
+}
+
+func TestMemory_Snapshot(t *testing.T) {
+	ctx := contextWithExecution(context.Background(), ExecutionContext{
+		SessionID:  "session-1",
+		WorkflowID: "workflow-1",
+	})
+
+	t.Run("concurrent writes are invisible", func(t *testing.T) {
+		memory := NewMemory(NewInMemoryBackend())
+		require.NoError(t, memory.Set(ctx, "step", 1))
+		require.NoError(t, memory.WorkflowScope().Set(ctx, "plan", map[string]any{"steps": 3}))
+		require.NoError(t, memory.GlobalScope().Set(ctx, "config", "v1"))
+
+		snap, err := memory.Snapshot(ctx)
+		require.NoError(t, err)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				assert.NoError(t, memory.Set(ctx, "step", 100+i))
+				assert.NoError(t, memory.Set(ctx, "added", i))
+				assert.NoError(t, memory.GlobalScope().Delete(ctx, "config"))
+			}(i)
+		}
+		wg.Wait()
+
+		val, found := snap.Get(ScopeSession, "step")
+		assert.True(t, found)
+		assert.Equal(t, 1, val)
+		_, found = snap.Get(ScopeSession, "added")
+		assert.False(t, found)
+		val, found = snap.Get(ScopeGlobal, "config")
+		assert.True(t, found)
+		assert.Equal(t, "v1", val)
+		assert.Equal(t, []string{"step"}, snap.List(ScopeSession))
+
+		var plan struct {
+			Steps int `json:"steps"`
+		}
+		found, err = snap.GetTyped(ScopeWorkflow, "plan", &plan)
+		require.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, 3, plan.Steps)
+
+		live, err := memory.Get(ctx, "step")
+		require.NoError(t, err)
+		assert.NotEqual(t, 1, live, "live reads still see new writes")
+	})
+
+	t.Run("scope IDs come from the context", func(t *testing.T) {
+		memory := NewMemory(NewInMemoryBackend())
+		other := contextWithExecution(context.Background(), ExecutionContext{SessionID: "session-2"})
+		require.NoError(t, memory.Set(other, "step", 2))
+
+		snap, err := memory.Snapshot(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "session-1", snap.ScopeID(ScopeSession))
+		assert.Empty(t, snap.List(ScopeSession))
+	})
+
+	t.Run("entries fallback", func(t *testing.T) {
+		memory := NewMemory(entriesOnlyBackend{NewInMemoryBackend()})
+		require.NoError(t, memory.Set(ctx, "step", 1))
+
+		snap, err := memory.Snapshot(ctx)
+		require.NoError(t, err)
+		require.NoError(t, memory.Set(ctx, "step", 2))
+		val, _ := snap.Get(ScopeSession, "step")
+		assert.Equal(t, 1, val)
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		memory := NewMemory(listOnlyBackend{NewInMemoryBackend()})
+		_, err := memory.Snapshot(ctx)
+		assert.ErrorIs(t, err, ErrSnapshotUnsupported)
+	})
+}
+
+// entriesOnlyBackend exposes only the EntriesBackend capability of its backend.
+type entriesOnlyBackend struct {
+	MemoryBackend
+}
+
+func (b entriesOnlyBackend) Entries(scope MemoryScope, scopeID string) (map[string]any, error) {
+	return b.MemoryBackend.(EntriesBackend).Entries(scope, scopeID)
+}
@@ -0,0 +1,101 @@
+package agent
+
+import "sync/atomic"
+
+// MemoryStats counts memory operations since the Memory was created, for a
+// quick read on cache effectiveness. Every ScopedMemory read (Get, GetFound,
+// GetTyped and the other getters) counts as a hit or a miss, and Entries
+// counts a hit per entry returned. Sets counts Set, SetTyped, GetSet and the
+// conditional sets that wrote. Deletes counts each Delete call, each key
+// removed by DeletePrefix, and GetAndDelete calls that found their key.
+// Failed operations and the reads made by Search and ScopeSizes are not
+// counted.
+type MemoryStats struct {
+	Hits    uint64 `json:"hits"`
+	Misses  uint64 `json:"misses"`
+	Sets    uint64 `json:"sets"`
+	Deletes uint64 `json:"deletes"`
+}
+
+// HitRatio returns Hits as a fraction of all reads, or zero before any read.
+func (s MemoryStats) HitRatio() float64 {
+	reads := s.Hits + s.Misses
+	if reads == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(reads)
+}
+
+// memoryCounters accumulates MemoryStats. Operations run concurrently, so
+// every field is atomic.
+type memoryCounters struct {
+	hits    atomic.Uint64
+	misses  atomic.Uint64
+	sets    atomic.Uint64
+	deletes atomic.Uint64
+}
+
+// WithStats enables the counters reported by Stats. Without it the
+// Get, Set and Delete paths pay only a nil check.
+func WithStats() MemoryOption {
+	return func(m *Memory) {
+		m.counters = &memoryCounters{}
+	}
+}
+
+// Stats returns the operation counts recorded since m was created. It
+// returns the zero MemoryStats unless m was built with WithStats.
+func (m *Memory) Stats() MemoryStats {
+	c := m.counters
+	if c == nil {
+		return MemoryStats{}
+	}
+	return MemoryStats{
+		Hits:    c.hits.Load(),
+		Misses:  c.misses.Load(),
+		Sets:    c.sets.Load(),
+		Deletes: c.deletes.Load(),
+	}
+}
+
+// readCounters returns the counters reads through s are recorded in; admin
+// views return nil so enumeration is not counted.
+func (s *ScopedMemory) readCounters() *memoryCounters {
+	if s.admin {
+		return nil
+	}
+	return s.memory.counters
+}
+
+func (c *memoryCounters) recordRead(found bool) {
+	if c == nil {
+		return
+	}
+	if found {
+		c.hits.Add(1)
+	} else {
+		c.misses.Add(1)
+	}
+}
+
+func (c *memoryCounters) recordSet() {
+	if c != nil {
+		c.sets.Add(1)
+	}
+}
+
+func (c *memoryCounters) recordDelete() {
+	c.recordDeletes(1)
+}
+
+func (c *memoryCounters) recordDeletes(n int) {
+	if c != nil && n > 0 {
+		c.deletes.Add(uint64(n))
+	}
+}
+
+func (c *memoryCounters) recordHits(n int) {
+	if c != nil && n > 0 {
+		c.hits.Add(uint64(n))
+	}
+}
@@ -0,0 +1,71 @@
+package agent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemory_Stats(t *testing.T) {
+	ctx := contextWithExecution(context.Background(), ExecutionContext{SessionID: "s1"})
+
+	t.Run("counts hits, misses, sets and deletes", func(t *testing.T) {
+		memory := NewMemory(NewInMemoryBackend(), WithStats())
+
+		require.NoError(t, memory.Set(ctx, "k", 1))
+		_, err := memory.Get(ctx, "k")
+		require.NoError(t, err)
+		assert.Equal(t, MemoryStats{Hits: 1, Sets: 1}, memory.Stats())
+
+		_, err = memory.Get(ctx, "missing")
+		require.NoError(t, err)
+		assert.Equal(t, MemoryStats{Hits: 1, Misses: 1, Sets: 1}, memory.Stats())
+
+		require.NoError(t, memory.Delete(ctx, "k"))
+		_, found, err := memory.GetFound(ctx, "k")
+		require.NoError(t, err)
+		require.False(t, found)
+
+		stats := memory.Stats()
+		assert.Equal(t, MemoryStats{Hits: 1, Misses: 2, Sets: 1, Deletes: 1}, stats)
+		assert.InDelta(t, 1.0/3, stats.HitRatio(), 1e-9)
+	})
+
+	t.Run("counts conditional writes and bulk operations", func(t *testing.T) {
+		memory := NewMemory(NewInMemoryBackend(), WithStats())
+
+		written, err := memory.SetIfAbsent(ctx, "a", 1)
+		require.NoError(t, err)
+		require.True(t, written)
+		written, err = memory.SetIfAbsent(ctx, "a", 2)
+		require.NoError(t, err)
+		require.False(t, written)
+		_, _, err = memory.SessionScope().GetSet(ctx, "b", 1)
+		require.NoError(t, err)
+		assert.Equal(t, MemoryStats{Sets: 2}, memory.Stats(), "only writes that happened count")
+
+		entries, err := memory.Entries(ctx)
+		require.NoError(t, err)
+		require.Len(t, entries, 2)
+		assert.Equal(t, MemoryStats{Hits: 2, Sets: 2}, memory.Stats())
+
+		deleted, err := memory.DeletePrefix(ctx, "")
+		require.NoError(t, err)
+		require.Equal(t, 2, deleted)
+		_, existed, err := memory.SessionScope().GetAndDelete(ctx, "a")
+		require.NoError(t, err)
+		require.False(t, existed)
+		assert.Equal(t, MemoryStats{Hits: 2, Sets: 2, Deletes: 2}, memory.Stats())
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		memory := NewMemory(NewInMemoryBackend())
+		require.NoError(t, memory.Set(ctx, "k", 1))
+		_, err := memory.Get(ctx, "k")
+		require.NoError(t, err)
+		assert.Equal(t, MemoryStats{}, memory.Stats())
+		assert.Zero(t, memory.Stats().HitRatio())
+	})
+}
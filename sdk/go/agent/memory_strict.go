@@ -0,0 +1,109 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+)
+
+// ErrScopeConfusion is returned in strict scope mode when a key is written
+// under one scope after the same scope ID already wrote it under another,
+// e.g. a session write landing in the workflow scope because both fell back
+// to the run ID.
+var ErrScopeConfusion = errors.New("memory: key written under a different scope for the same scope ID")
+
+// StrictScopeConfig controls strict scope mode.
+type StrictScopeConfig struct {
+	// LogOnly reports confusion to Logger and lets the write proceed instead
+	// of failing it with ErrScopeConfusion.
+	LogOnly bool
+	// Logger receives reports; nil uses log.Default.
+	Logger *log.Logger
+}
+
+// WithStrictScopes remembers the scope each (scope ID, key) pair is first
+// written under and flags writes of the same pair under a different scope.
+// Delete and DeletePrefix release the pairs they remove. It is a development aid: the record grows with
+// every key written and is never pruned.
+func WithStrictScopes(config StrictScopeConfig) MemoryOption {
+	return func(m *Memory) {
+		if config.Logger == nil {
+			config.Logger = log.Default()
+		}
+		m.strict = &scopeTracker{
+			config: config,
+			owners: make(map[scopeTrackerKey]MemoryScope),
+		}
+	}
+}
+
+type scopeTrackerKey struct {
+	scopeID string
+	key     string
+}
+
+// scopeTracker records which scope owns each (scope ID, key) pair.
+type scopeTracker struct {
+	config StrictScopeConfig
+
+	mu     sync.Mutex
+	owners map[scopeTrackerKey]MemoryScope
+}
+
+// checkWrite claims the pair for scope, or reports a write under a scope
+// other than its owner.
+func (t *scopeTracker) checkWrite(scope MemoryScope, scopeID, key string) error {
+	if t == nil {
+		return nil
+	}
+	tk := scopeTrackerKey{scopeID: scopeID, key: key}
+
+	t.mu.Lock()
+	owner, claimed := t.owners[tk]
+	if !claimed {
+		t.owners[tk] = scope
+	}
+	t.mu.Unlock()
+
+	if !claimed || owner == scope {
+		return nil
+	}
+	err := fmt.Errorf("%w: key %q for scope ID %q is owned by the %s scope, not %s", ErrScopeConfusion, key, scopeID, owner, scope)
+	if t.config.LogOnly {
+		t.config.Logger.Print(err)
+		return nil
+	}
+	return err
+}
+
+// release drops scope's claim on the pair after a delete.
+func (t *scopeTracker) release(scope MemoryScope, scopeID, key string) {
+	if t == nil {
+		return
+	}
+	tk := scopeTrackerKey{scopeID: scopeID, key: key}
+
+	t.mu.Lock()
+	if t.owners[tk] == scope {
+		delete(t.owners, tk)
+	}
+	t.mu.Unlock()
+}
+
+// releasePrefix drops scope's claims on every key of scopeID starting with
+// prefix, after a DeletePrefix.
+func (t *scopeTracker) releasePrefix(scope MemoryScope, scopeID, prefix string) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	for tk, owner := range t.owners {
+		if owner == scope && tk.scopeID == scopeID && strings.HasPrefix(tk.key, prefix) {
+			delete(t.owners, tk)
+		}
+	}
+	t.mu.Unlock()
+}
@@ -0,0 +1,89 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemory_StrictScopes(t *testing.T) {
+	// With only a run ID, session and workflow scopes resolve to the same ID.
+	ctx := contextWithExecution(context.Background(), ExecutionContext{RunID: "run-1"})
+
+	t.Run("rejects a write to the wrong scope", func(t *testing.T) {
+		memory := NewMemory(NewInMemoryBackend(), WithStrictScopes(StrictScopeConfig{}))
+
+		require.NoError(t, memory.SessionScope().Set(ctx, "cart", []string{"apple"}))
+		require.NoError(t, memory.SessionScope().Set(ctx, "cart", []string{"pear"}), "same scope may rewrite")
+
+		err := memory.WorkflowScope().Set(ctx, "cart", []string{"plum"})
+		require.ErrorIs(t, err, ErrScopeConfusion)
+		assert.Contains(t, err.Error(), `"cart"`)
+
+		_, err = memory.WorkflowScope().SetIfAbsent(ctx, "cart", 1)
+		assert.ErrorIs(t, err, ErrScopeConfusion)
+
+		val, err := memory.WorkflowScope().Get(ctx, "cart")
+		require.NoError(t, err)
+		assert.Nil(t, val, "rejected write must not reach the backend")
+	})
+
+	t.Run("delete releases the key", func(t *testing.T) {
+		memory := NewMemory(NewInMemoryBackend(), WithStrictScopes(StrictScopeConfig{}))
+
+		require.NoError(t, memory.SessionScope().Set(ctx, "cart", 1))
+		require.NoError(t, memory.SessionScope().Delete(ctx, "cart"))
+		assert.NoError(t, memory.WorkflowScope().Set(ctx, "cart", 2))
+	})
+
+	t.Run("delete prefix releases the keys it removes", func(t *testing.T) {
+		memory := NewMemory(NewInMemoryBackend(), WithStrictScopes(StrictScopeConfig{}))
+		session := memory.SessionScope()
+
+		require.NoError(t, session.Set(ctx, "cart/a", 1))
+		require.NoError(t, session.Set(ctx, "cart/b", 2))
+		require.NoError(t, session.Set(ctx, "profile", 3))
+		deleted, err := session.DeletePrefix(ctx, "cart/")
+		require.NoError(t, err)
+		require.Equal(t, 2, deleted)
+
+		assert.NoError(t, memory.WorkflowScope().Set(ctx, "cart/a", 4))
+		assert.NoError(t, memory.WorkflowScope().Set(ctx, "cart/b", 5))
+		assert.ErrorIs(t, memory.WorkflowScope().Set(ctx, "profile", 6), ErrScopeConfusion, "keys outside the prefix stay claimed")
+	})
+
+	t.Run("different scope IDs do not collide", func(t *testing.T) {
+		memory := NewMemory(NewInMemoryBackend(), WithStrictScopes(StrictScopeConfig{}))
+		ctx := contextWithExecution(context.Background(), ExecutionContext{RunID: "run-1", SessionID: "session-1"})
+
+		require.NoError(t, memory.SessionScope().Set(ctx, "cart", 1))
+		assert.NoError(t, memory.WorkflowScope().Set(ctx, "cart", 2))
+	})
+
+	t.Run("log only", func(t *testing.T) {
+		var logs bytes.Buffer
+		memory := NewMemory(NewInMemoryBackend(), WithStrictScopes(StrictScopeConfig{
+			LogOnly: true,
+			Logger:  log.New(&logs, "", 0),
+		}))
+
+		require.NoError(t, memory.SessionScope().Set(ctx, "cart", 1))
+		require.NoError(t, memory.WorkflowScope().Set(ctx, "cart", 2))
+		assert.Contains(t, logs.String(), "owned by the session scope")
+
+		val, err := memory.WorkflowScope().Get(ctx, "cart")
+		require.NoError(t, err)
+		assert.Equal(t, 2, val)
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		memory := NewMemory(NewInMemoryBackend())
+
+		require.NoError(t, memory.SessionScope().Set(ctx, "cart", 1))
+		assert.NoError(t, memory.WorkflowScope().Set(ctx, "cart", 2))
+	})
+}
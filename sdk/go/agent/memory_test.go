@@ -1,10 +1,18 @@
 package agent
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"log"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -167,6 +175,25 @@ func TestMemory_DefaultScope(t *testing.T) {
 		assert.Equal(t, "default-value", val)
 	})
 
+	t.Run("GetFound - stored nil versus missing key", func(t *testing.T) {
+		require.NoError(t, memory.Set(ctx, "nil-value", nil))
+
+		val, found, err := memory.GetFound(ctx, "nil-value")
+		require.NoError(t, err)
+		assert.True(t, found)
+		assert.Nil(t, val)
+
+		val, found, err = memory.GetFound(ctx, "never-set")
+		require.NoError(t, err)
+		assert.False(t, found)
+		assert.Nil(t, val)
+
+		val, found, err = memory.GlobalScope().GetFound(ctx, "nil-value")
+		require.NoError(t, err)
+		assert.False(t, found, "the session key must not leak into the global scope")
+		assert.Nil(t, val)
+	})
+
 	t.Run("Delete", func(t *testing.T) {
 		err := memory.Set(ctx, "to-delete", "value")
 		require.NoError(t, err)
@@ -330,6 +357,95 @@ func TestMemory_ScopedGetTyped(t *testing.T) {
 		require.NoError(t, err)
 		assert.Equal(t, "", retrieved) // zero value
 	})
+
+	t.Run("GetTypedFound distinguishes null from absent", func(t *testing.T) {
+		type Nullable struct {
+			Name *string `json:"name"`
+		}
+		name := "kept"
+
+		require.NoError(t, memory.SessionScope().Set(ctx, "null-value", nil))
+		require.NoError(t, memory.SessionScope().Set(ctx, "null-encoded", []byte("null")))
+		require.NoError(t, memory.SessionScope().SetTyped(ctx, "stored", Nullable{Name: &name}))
+
+		for _, key := range []string{"null-value", "null-encoded"} {
+			dest := Nullable{Name: &name}
+			found, err := memory.SessionScope().GetTypedFound(ctx, key, &dest)
+			require.NoError(t, err, key)
+			assert.True(t, found, key)
+			assert.Nil(t, dest.Name, "stored null resets dest for %s", key)
+		}
+
+		dest := Nullable{Name: &name}
+		found, err := memory.SessionScope().GetTypedFound(ctx, "absent", &dest)
+		require.NoError(t, err)
+		assert.False(t, found)
+		assert.Equal(t, &name, dest.Name, "absent key leaves dest untouched")
+
+		var stored Nullable
+		found, err = memory.SessionScope().GetTypedFound(ctx, "stored", &stored)
+		require.NoError(t, err)
+		assert.True(t, found)
+		require.NotNil(t, stored.Name)
+		assert.Equal(t, "kept", *stored.Name)
+	})
+}
+
+func TestMemory_DeserializeErrorPolicy(t *testing.T) {
+	type Profile struct {
+		Name string `json:"name"`
+		Age  int    `json:"age"`
+	}
+	ctx := contextWithExecution(context.Background(), ExecutionContext{
+		SessionID: "test-session",
+	})
+	// "age" was a string under an older schema.
+	drifted := map[string]any{"name": "ada", "age": "thirty"}
+
+	t.Run("Fail", func(t *testing.T) {
+		memory := NewMemory(NewInMemoryBackend())
+		require.NoError(t, memory.Set(ctx, "profile", drifted))
+
+		var dest Profile
+		found, err := memory.SessionScope().GetTypedFound(ctx, "profile", &dest)
+		assert.True(t, found)
+		var derr *DeserializeError
+		require.ErrorAs(t, err, &derr)
+		assert.Equal(t, ScopeSession, derr.Scope)
+		assert.Equal(t, "test-session", derr.ScopeID)
+		assert.Equal(t, "profile", derr.Key)
+		assert.Contains(t, err.Error(), `"profile"`)
+	})
+
+	t.Run("Skip", func(t *testing.T) {
+		memory := NewMemory(NewInMemoryBackend(), WithDeserializeErrorPolicy(DeserializeSkip, nil))
+		require.NoError(t, memory.Set(ctx, "profile", drifted))
+
+		dest := Profile{Name: "default", Age: 1}
+		found, err := memory.SessionScope().GetTypedFound(ctx, "profile", &dest)
+		require.NoError(t, err)
+		assert.False(t, found)
+		assert.Equal(t, Profile{Name: "default", Age: 1}, dest, "default must survive without a partial decode")
+
+		require.NoError(t, memory.Set(ctx, "profile", map[string]any{"name": "ada", "age": 30}))
+		found, err = memory.SessionScope().GetTypedFound(ctx, "profile", &dest)
+		require.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, Profile{Name: "ada", Age: 30}, dest)
+	})
+
+	t.Run("LogAndZero", func(t *testing.T) {
+		var logs bytes.Buffer
+		memory := NewMemory(NewInMemoryBackend(), WithDeserializeErrorPolicy(DeserializeLogAndZero, log.New(&logs, "", 0)))
+		require.NoError(t, memory.Set(ctx, "profile", drifted))
+
+		dest := Profile{Name: "default", Age: 1}
+		found, err := memory.SessionScope().GetTypedFound(ctx, "profile", &dest)
+		require.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, Profile{}, dest)
+		assert.Contains(t, logs.String(), `"profile"`)
+	})
 }
 
 func TestMemory_FallbackToRunID(t *testing.T) {
@@ -432,3 +548,1033 @@ func TestAgentWithCustomMemoryBackend(t *testing.T) {
 	assert.True(t, found)
 	assert.Equal(t, "custom-value", val)
 }
+
+// slowMemoryBackend delays every Get to simulate a hung external backend.
+type slowMemoryBackend struct {
+	*InMemoryBackend
+	delay time.Duration
+}
+
+func (b *slowMemoryBackend) Get(scope MemoryScope, scopeID, key string) (any, bool, error) {
+	time.Sleep(b.delay)
+	return b.InMemoryBackend.Get(scope, scopeID, key)
+}
+
+func (b *slowMemoryBackend) GetVector(scope MemoryScope, scopeID, key string) ([]float64, map[string]any, bool, error) {
+	time.Sleep(b.delay)
+	return b.InMemoryBackend.GetVector(scope, scopeID, key)
+}
+
+func TestMemory_OpTimeout(t *testing.T) {
+	backend := &slowMemoryBackend{InMemoryBackend: NewInMemoryBackend(), delay: 200 * time.Millisecond}
+	ctx := contextWithExecution(context.Background(), ExecutionContext{
+		SessionID: "test-session",
+	})
+
+	t.Run("Timeout fires on slow backend", func(t *testing.T) {
+		memory := NewMemoryWithConfig(backend, MemoryConfig{OpTimeout: 20 * time.Millisecond})
+
+		start := time.Now()
+		_, err := memory.Get(ctx, "key")
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+		assert.Less(t, time.Since(start), backend.delay)
+	})
+
+	t.Run("GetVector timeout does not race the backend", func(t *testing.T) {
+		memory := NewMemoryWithConfig(backend, MemoryConfig{OpTimeout: 20 * time.Millisecond})
+		require.NoError(t, backend.SetVector(ScopeSession, "test-session", "vec", []float64{1, 2}, map[string]any{"k": "v"}))
+
+		embedding, metadata, err := memory.SessionScope().GetVector(ctx, "vec")
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Nil(t, embedding)
+		assert.Nil(t, metadata)
+
+		// Let the abandoned backend call finish so -race sees its writes.
+		time.Sleep(backend.delay + 50*time.Millisecond)
+	})
+
+	t.Run("Fast operations unaffected", func(t *testing.T) {
+		memory := NewMemoryWithConfig(backend, MemoryConfig{OpTimeout: time.Second})
+
+		err := memory.Set(ctx, "key", "value")
+		require.NoError(t, err)
+
+		val, err := memory.Get(ctx, "key")
+		require.NoError(t, err)
+		assert.Equal(t, "value", val)
+	})
+
+	t.Run("Zero timeout disables wrapping", func(t *testing.T) {
+		memory := NewMemory(backend)
+
+		val, err := memory.Get(ctx, "key")
+		require.NoError(t, err)
+		assert.Equal(t, "value", val)
+	})
+}
+
+// countingCodec wraps JSONCodec and records how often it is used.
+type countingCodec struct {
+	JSONCodec
+	marshals   int
+	unmarshals int
+}
+
+func (c *countingCodec) Marshal(v any) ([]byte, error) {
+	c.marshals++
+	return c.JSONCodec.Marshal(v)
+}
+
+func (c *countingCodec) Unmarshal(data []byte, v any) error {
+	c.unmarshals++
+	return c.JSONCodec.Unmarshal(data, v)
+}
+
+func TestMemory_CustomCodec(t *testing.T) {
+	codec := &countingCodec{}
+	memory := NewMemory(NewInMemoryBackend(), WithCodec(codec))
+
+	ctx := contextWithExecution(context.Background(), ExecutionContext{
+		SessionID: "test-session",
+	})
+
+	type TestData struct {
+		Name  string `json:"name"`
+		Count int    `json:"count"`
+	}
+
+	original := TestData{Name: "codec", Count: 7}
+	err := memory.SessionScope().SetTyped(ctx, "typed", original)
+	require.NoError(t, err)
+	assert.Equal(t, 1, codec.marshals)
+
+	var retrieved TestData
+	err = memory.SessionScope().GetTyped(ctx, "typed", &retrieved)
+	require.NoError(t, err)
+	assert.Equal(t, 1, codec.unmarshals)
+	assert.Equal(t, original, retrieved)
+}
+
+func TestMemory_JSONNumbers(t *testing.T) {
+	ctx := contextWithExecution(context.Background(), ExecutionContext{SessionID: "test-session"})
+	const id int64 = 1<<62 + 1 // not representable as a float64
+
+	t.Run("default decodes untyped numbers as float64", func(t *testing.T) {
+		memory := NewMemory(NewInMemoryBackend())
+		require.NoError(t, memory.SetTyped(ctx, "id", id))
+
+		var got any
+		require.NoError(t, memory.GetTyped(ctx, "id", &got))
+		assert.IsType(t, float64(0), got)
+	})
+
+	t.Run("WithJSONNumbers preserves precision", func(t *testing.T) {
+		memory := NewMemory(NewInMemoryBackend(), WithJSONNumbers())
+		require.NoError(t, memory.SetTyped(ctx, "id", id))
+		require.NoError(t, memory.SetTyped(ctx, "record", map[string]any{"id": id}))
+		require.NoError(t, memory.Set(ctx, "raw", id))
+
+		var got any
+		require.NoError(t, memory.GetTyped(ctx, "id", &got))
+		require.IsType(t, json.Number(""), got)
+		n, err := got.(json.Number).Int64()
+		require.NoError(t, err)
+		assert.Equal(t, id, n)
+
+		record, err := memory.SessionScope().GetDecoded(ctx, "record")
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"id": json.Number("4611686018427387905")}, record)
+
+		raw, err := memory.SessionScope().GetDecoded(ctx, "raw")
+		require.NoError(t, err)
+		assert.Equal(t, json.Number("4611686018427387905"), raw)
+
+		missing, err := memory.SessionScope().GetDecoded(ctx, "missing")
+		require.NoError(t, err)
+		assert.Nil(t, missing)
+
+		// Typed destinations are unaffected.
+		var typed int64
+		require.NoError(t, memory.GetTyped(ctx, "id", &typed))
+		assert.Equal(t, id, typed)
+	})
+
+	t.Run("codec rejects trailing data", func(t *testing.T) {
+		var v any
+		assert.Error(t, JSONCodec{UseNumber: true}.Unmarshal([]byte(`1 2`), &v))
+		assert.Error(t, JSONCodec{UseNumber: true}.Unmarshal([]byte(`{} }`), &v))
+		assert.NoError(t, JSONCodec{UseNumber: true}.Unmarshal([]byte(" 1 \n"), &v))
+	})
+}
+
+// listOnlyBackend hides optional capabilities of the wrapped backend so
+// callers exercise their fallback paths.
+type listOnlyBackend struct {
+	MemoryBackend
+}
+
+func TestMemory_Entries(t *testing.T) {
+	ctx := contextWithExecution(context.Background(), ExecutionContext{
+		SessionID: "test-session",
+	})
+
+	backends := map[string]func(*InMemoryBackend) MemoryBackend{
+		"EntriesBackend": func(b *InMemoryBackend) MemoryBackend { return b },
+		"Fallback":       func(b *InMemoryBackend) MemoryBackend { return listOnlyBackend{b} },
+	}
+
+	for name, wrap := range backends {
+		t.Run(name, func(t *testing.T) {
+			inner := NewInMemoryBackend()
+			memory := NewMemory(wrap(inner))
+
+			require.NoError(t, memory.Set(ctx, "a", "1"))
+			require.NoError(t, memory.Set(ctx, "b", 2))
+			require.NoError(t, memory.GlobalScope().Set(ctx, "other", "x"))
+
+			entries, err := memory.SessionScope().Entries(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, map[string]any{"a": "1", "b": 2}, entries)
+
+			empty, err := memory.WorkflowScope().Entries(ctx)
+			require.NoError(t, err)
+			assert.Empty(t, empty)
+		})
+	}
+}
+
+func TestMemory_ConditionalSet(t *testing.T) {
+	ctx := contextWithExecution(context.Background(), ExecutionContext{
+		SessionID: "test-session",
+	})
+
+	t.Run("SetIfAbsent", func(t *testing.T) {
+		memory := NewMemory(NewInMemoryBackend())
+
+		written, err := memory.SetIfAbsent(ctx, "counter", 0)
+		require.NoError(t, err)
+		assert.True(t, written, "absent key should be written")
+
+		written, err = memory.SetIfAbsent(ctx, "counter", 42)
+		require.NoError(t, err)
+		assert.False(t, written, "present key should be skipped")
+
+		val, err := memory.Get(ctx, "counter")
+		require.NoError(t, err)
+		assert.Equal(t, 0, val)
+	})
+
+	t.Run("SetIfPresent", func(t *testing.T) {
+		memory := NewMemory(NewInMemoryBackend())
+
+		written, err := memory.SetIfPresent(ctx, "flag", true)
+		require.NoError(t, err)
+		assert.False(t, written, "absent key should be skipped")
+
+		require.NoError(t, memory.Set(ctx, "flag", false))
+		written, err = memory.SetIfPresent(ctx, "flag", true)
+		require.NoError(t, err)
+		assert.True(t, written, "present key should be written")
+
+		val, err := memory.Get(ctx, "flag")
+		require.NoError(t, err)
+		assert.Equal(t, true, val)
+	})
+
+	t.Run("ConcurrentSetIfAbsent", func(t *testing.T) {
+		memory := NewMemory(NewInMemoryBackend())
+
+		const writers = 50
+		var wins int32
+		var wg sync.WaitGroup
+		for i := 0; i < writers; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				written, err := memory.GlobalScope().SetIfAbsent(ctx, "owner", i)
+				assert.NoError(t, err)
+				if written {
+					atomic.AddInt32(&wins, 1)
+				}
+			}(i)
+		}
+		wg.Wait()
+		assert.Equal(t, int32(1), wins, "exactly one writer should win")
+	})
+
+	t.Run("Unsupported", func(t *testing.T) {
+		memory := NewMemory(listOnlyBackend{NewInMemoryBackend()})
+
+		_, err := memory.SetIfAbsent(ctx, "k", "v")
+		assert.ErrorIs(t, err, ErrConditionalSetUnsupported)
+	})
+}
+
+func TestMemory_GetSet(t *testing.T) {
+	ctx := contextWithExecution(context.Background(), ExecutionContext{
+		SessionID: "test-session",
+	})
+
+	t.Run("NewKey", func(t *testing.T) {
+		memory := NewMemory(NewInMemoryBackend())
+
+		previous, existed, err := memory.GetSet(ctx, "status", "running")
+		require.NoError(t, err)
+		assert.False(t, existed)
+		assert.Nil(t, previous)
+
+		val, err := memory.Get(ctx, "status")
+		require.NoError(t, err)
+		assert.Equal(t, "running", val)
+	})
+
+	t.Run("ExistingKey", func(t *testing.T) {
+		memory := NewMemory(NewInMemoryBackend())
+		require.NoError(t, memory.Set(ctx, "status", "running"))
+
+		previous, existed, err := memory.GetSet(ctx, "status", "done")
+		require.NoError(t, err)
+		assert.True(t, existed)
+		assert.Equal(t, "running", previous)
+
+		val, err := memory.Get(ctx, "status")
+		require.NoError(t, err)
+		assert.Equal(t, "done", val)
+	})
+
+	t.Run("Unsupported", func(t *testing.T) {
+		memory := NewMemory(listOnlyBackend{NewInMemoryBackend()})
+
+		_, _, err := memory.GetSet(ctx, "k", "v")
+		assert.ErrorIs(t, err, ErrGetSetUnsupported)
+	})
+}
+
+func TestMemory_GetAndDelete(t *testing.T) {
+	ctx := contextWithExecution(context.Background(), ExecutionContext{
+		SessionID: "test-session",
+	})
+
+	t.Run("ExistingKey", func(t *testing.T) {
+		memory := NewMemory(NewInMemoryBackend())
+		require.NoError(t, memory.Set(ctx, "job", "build"))
+
+		value, existed, err := memory.GetAndDelete(ctx, "job")
+		require.NoError(t, err)
+		assert.True(t, existed)
+		assert.Equal(t, "build", value)
+
+		val, err := memory.Get(ctx, "job")
+		require.NoError(t, err)
+		assert.Nil(t, val)
+	})
+
+	t.Run("AbsentKey", func(t *testing.T) {
+		memory := NewMemory(NewInMemoryBackend())
+
+		value, existed, err := memory.GetAndDelete(ctx, "job")
+		require.NoError(t, err)
+		assert.False(t, existed)
+		assert.Nil(t, value)
+	})
+
+	t.Run("ConcurrentPoppers", func(t *testing.T) {
+		memory := NewMemory(NewInMemoryBackend())
+		require.NoError(t, memory.Set(ctx, "job", "build"))
+
+		var (
+			wg      sync.WaitGroup
+			winners atomic.Int32
+		)
+		for i := 0; i < 32; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				value, existed, err := memory.GetAndDelete(ctx, "job")
+				assert.NoError(t, err)
+				if existed {
+					assert.Equal(t, "build", value)
+					winners.Add(1)
+				}
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, int32(1), winners.Load())
+	})
+
+	t.Run("Unsupported", func(t *testing.T) {
+		memory := NewMemory(listOnlyBackend{NewInMemoryBackend()})
+
+		_, _, err := memory.GetAndDelete(ctx, "k")
+		assert.ErrorIs(t, err, ErrGetAndDeleteUnsupported)
+	})
+}
+
+func TestMemory_Version(t *testing.T) {
+	ctx := contextWithExecution(context.Background(), ExecutionContext{
+		SessionID: "test-session",
+	})
+
+	t.Run("SetsAndDeletes", func(t *testing.T) {
+		memory := NewMemory(NewInMemoryBackend())
+
+		_, found, err := memory.Version(ctx, "doc")
+		require.NoError(t, err)
+		assert.False(t, found)
+
+		require.NoError(t, memory.Set(ctx, "doc", "v1"))
+		first, found, err := memory.Version(ctx, "doc")
+		require.NoError(t, err)
+		require.True(t, found)
+
+		unchanged, _, err := memory.Version(ctx, "doc")
+		require.NoError(t, err)
+		assert.Equal(t, first, unchanged, "reads must not bump the version")
+
+		require.NoError(t, memory.Set(ctx, "doc", "v1"))
+		second, _, err := memory.Version(ctx, "doc")
+		require.NoError(t, err)
+		assert.Greater(t, second, first, "every set bumps the version, even with an equal value")
+
+		require.NoError(t, memory.Delete(ctx, "doc"))
+		_, found, err = memory.Version(ctx, "doc")
+		require.NoError(t, err)
+		assert.False(t, found, "deleted key has no version")
+
+		require.NoError(t, memory.Set(ctx, "doc", "v1"))
+		recreated, found, err := memory.Version(ctx, "doc")
+		require.NoError(t, err)
+		require.True(t, found)
+		assert.Greater(t, recreated, second, "a recreated key must not reuse an old version")
+	})
+
+	t.Run("ScopesAreIndependent", func(t *testing.T) {
+		memory := NewMemory(NewInMemoryBackend())
+		require.NoError(t, memory.GlobalScope().Set(ctx, "doc", 1))
+
+		_, found, err := memory.SessionScope().Version(ctx, "doc")
+		require.NoError(t, err)
+		assert.False(t, found)
+	})
+
+	t.Run("Unsupported", func(t *testing.T) {
+		memory := NewMemory(listOnlyBackend{NewInMemoryBackend()})
+
+		_, _, err := memory.Version(ctx, "k")
+		assert.ErrorIs(t, err, ErrVersionUnsupported)
+	})
+}
+
+func TestMemory_GetOrCompute(t *testing.T) {
+	ctx := contextWithExecution(context.Background(), ExecutionContext{
+		SessionID: "test-session",
+	})
+
+	t.Run("ConcurrentMissesComputeOnce", func(t *testing.T) {
+		memory := NewMemory(NewInMemoryBackend())
+
+		var calls int32
+		release := make(chan struct{})
+		compute := func() (any, error) {
+			atomic.AddInt32(&calls, 1)
+			<-release
+			return "expensive", nil
+		}
+
+		const callers = 20
+		results := make(chan any, callers)
+		var wg sync.WaitGroup
+		for i := 0; i < callers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				val, err := memory.GetOrCompute(ctx, "report", compute)
+				assert.NoError(t, err)
+				results <- val
+			}()
+		}
+		// Give the callers time to pile up on the in-flight compute.
+		time.Sleep(20 * time.Millisecond)
+		close(release)
+		wg.Wait()
+		close(results)
+
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+		for val := range results {
+			assert.Equal(t, "expensive", val)
+		}
+
+		stored, err := memory.Get(ctx, "report")
+		require.NoError(t, err)
+		assert.Equal(t, "expensive", stored)
+	})
+
+	t.Run("CachedValueSkipsCompute", func(t *testing.T) {
+		memory := NewMemory(NewInMemoryBackend())
+		require.NoError(t, memory.Set(ctx, "k", "cached"))
+
+		val, err := memory.GetOrCompute(ctx, "k", func() (any, error) {
+			t.Fatal("compute should not run on a hit")
+			return nil, nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "cached", val)
+	})
+
+	t.Run("ComputeErrorNotStored", func(t *testing.T) {
+		memory := NewMemory(NewInMemoryBackend())
+		boom := errors.New("boom")
+
+		_, err := memory.GetOrCompute(ctx, "k", func() (any, error) { return nil, boom })
+		assert.ErrorIs(t, err, boom)
+
+		keys, err := memory.List(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, keys)
+	})
+}
+
+// pingingBackend reports a fixed Ping result.
+type pingingBackend struct {
+	*InMemoryBackend
+	err error
+}
+
+func (b *pingingBackend) Ping(ctx context.Context) error {
+	return b.err
+}
+
+func TestMemory_Seed(t *testing.T) {
+	ctx := context.Background()
+	defaults := map[string]any{"flag.beta": true, "limit": 10}
+
+	for name, backend := range map[string]func() MemoryBackend{
+		"conditional backend": func() MemoryBackend { return NewInMemoryBackend() },
+		"plain backend":       func() MemoryBackend { return listOnlyBackend{NewInMemoryBackend()} },
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Run("overwrite replaces existing values", func(t *testing.T) {
+				memory := NewMemory(backend())
+				require.NoError(t, memory.GlobalScope().Set(ctx, "limit", 5))
+
+				n, err := memory.Seed(ctx, nil, defaults, true)
+				require.NoError(t, err)
+				assert.Equal(t, 2, n)
+
+				val, err := memory.GlobalScope().Get(ctx, "limit")
+				require.NoError(t, err)
+				assert.Equal(t, 10, val)
+			})
+
+			t.Run("without overwrite existing values are kept", func(t *testing.T) {
+				memory := NewMemory(backend())
+				require.NoError(t, memory.GlobalScope().Set(ctx, "limit", 5))
+
+				n, err := memory.Seed(ctx, memory.GlobalScope(), defaults, false)
+				require.NoError(t, err)
+				assert.Equal(t, 1, n)
+
+				entries, err := memory.GlobalScope().Entries(ctx)
+				require.NoError(t, err)
+				assert.Equal(t, map[string]any{"flag.beta": true, "limit": 5}, entries)
+			})
+		})
+	}
+}
+
+func TestMemory_Backend(t *testing.T) {
+	backend := NewInMemoryBackend()
+	memory := NewMemory(backend)
+	assert.Same(t, backend, memory.Backend())
+
+	_, ok := NewMemory(nil).Backend().(*InMemoryBackend)
+	assert.True(t, ok)
+}
+
+func TestNewMemoryStrict(t *testing.T) {
+	memory, err := NewMemoryStrict(nil)
+	assert.ErrorIs(t, err, ErrNilMemoryBackend)
+	assert.Nil(t, memory)
+
+	backend := NewInMemoryBackend()
+	memory, err = NewMemoryStrict(backend)
+	require.NoError(t, err)
+	assert.Same(t, backend, memory.Backend())
+}
+
+func TestMemory_Ping(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("backend without Pinger", func(t *testing.T) {
+		memory := NewMemory(NewInMemoryBackend())
+		assert.NoError(t, memory.Ping(ctx))
+	})
+
+	t.Run("healthy backend", func(t *testing.T) {
+		memory := NewMemory(&pingingBackend{InMemoryBackend: NewInMemoryBackend()})
+		assert.NoError(t, memory.Ping(ctx))
+	})
+
+	t.Run("unreachable backend", func(t *testing.T) {
+		pingErr := errors.New("connection refused")
+		memory := NewMemory(&pingingBackend{InMemoryBackend: NewInMemoryBackend(), err: pingErr})
+		assert.ErrorIs(t, memory.Ping(ctx), pingErr)
+	})
+}
+
+func TestMemory_ScopeResolver(t *testing.T) {
+	tenantFirst := func(execCtx ExecutionContext) string {
+		if execCtx.TenantID != "" {
+			return execCtx.TenantID + ":" + execCtx.ActorID
+		}
+		return execCtx.ActorID
+	}
+
+	backend := NewInMemoryBackend()
+	memory := NewMemory(backend, WithScopeResolver(ScopeUser, tenantFirst))
+
+	acme := contextWithExecution(context.Background(), ExecutionContext{
+		SessionID: "session-1",
+		ActorID:   "user-1",
+		TenantID:  "acme",
+	})
+	globex := contextWithExecution(context.Background(), ExecutionContext{
+		SessionID: "session-1",
+		ActorID:   "user-1",
+		TenantID:  "globex",
+	})
+
+	require.NoError(t, memory.UserScope().Set(acme, "plan", "pro"))
+
+	val, found, err := backend.Get(ScopeUser, "acme:user-1", "plan")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "pro", val)
+
+	// Same actor in a different tenant is isolated.
+	got, err := memory.UserScope().Get(globex, "plan")
+	require.NoError(t, err)
+	assert.Nil(t, got)
+
+	// Scopes without an override keep the default chain.
+	require.NoError(t, memory.Set(acme, "k", "v"))
+	_, found, err = backend.Get(ScopeSession, "session-1", "k")
+	require.NoError(t, err)
+	assert.True(t, found)
+
+	t.Run("nil resolver keeps default", func(t *testing.T) {
+		backend := NewInMemoryBackend()
+		memory := NewMemory(backend, WithScopeResolver(ScopeUser, nil))
+		require.NoError(t, memory.UserScope().Set(acme, "plan", "free"))
+		_, found, err := backend.Get(ScopeUser, "user-1", "plan")
+		require.NoError(t, err)
+		assert.True(t, found)
+	})
+}
+
+func TestMemory_DeletePrefix(t *testing.T) {
+	ctx := contextWithExecution(context.Background(), ExecutionContext{
+		SessionID: "test-session",
+	})
+
+	backends := map[string]func(*InMemoryBackend) MemoryBackend{
+		"PrefixDeleter": func(b *InMemoryBackend) MemoryBackend { return b },
+		"Fallback":      func(b *InMemoryBackend) MemoryBackend { return listOnlyBackend{b} },
+	}
+
+	for name, wrap := range backends {
+		t.Run(name, func(t *testing.T) {
+			inner := NewInMemoryBackend()
+			memory := NewMemory(wrap(inner))
+
+			require.NoError(t, memory.Set(ctx, "cache:a", 1))
+			require.NoError(t, memory.Set(ctx, "cache:b", 2))
+			require.NoError(t, memory.Set(ctx, "config", "keep"))
+			require.NoError(t, memory.GlobalScope().Set(ctx, "cache:global", "keep"))
+
+			deleted, err := memory.DeletePrefix(ctx, "cache:")
+			require.NoError(t, err)
+			assert.Equal(t, 2, deleted)
+
+			keys, err := memory.List(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"config"}, keys)
+
+			val, err := memory.GlobalScope().Get(ctx, "cache:global")
+			require.NoError(t, err)
+			assert.Equal(t, "keep", val, "other scopes are untouched")
+
+			deleted, err = memory.DeletePrefix(ctx, "cache:")
+			require.NoError(t, err)
+			assert.Zero(t, deleted)
+		})
+	}
+}
+
+// ttlBackend records expirations for keys set through SetWithTTL.
+type ttlBackend struct {
+	*InMemoryBackend
+	expiries map[string]time.Time
+	now      time.Time
+}
+
+func (b *ttlBackend) SetWithTTL(scope MemoryScope, scopeID, key string, value any, ttl time.Duration) error {
+	b.expiries[scopeID+"/"+key] = b.now.Add(ttl)
+	return b.Set(scope, scopeID, key, value)
+}
+
+func (b *ttlBackend) TTL(scope MemoryScope, scopeID, key string) (time.Duration, bool, error) {
+	_, found, err := b.Get(scope, scopeID, key)
+	if err != nil || !found {
+		return 0, false, err
+	}
+	expiry, ok := b.expiries[scopeID+"/"+key]
+	if !ok {
+		return NoExpiry, true, nil
+	}
+	return expiry.Sub(b.now), true, nil
+}
+
+func TestMemory_TTL(t *testing.T) {
+	ctx := contextWithExecution(context.Background(), ExecutionContext{
+		SessionID: "test-session",
+	})
+
+	t.Run("TTLReader", func(t *testing.T) {
+		backend := &ttlBackend{InMemoryBackend: NewInMemoryBackend(), expiries: map[string]time.Time{}, now: time.Now()}
+		memory := NewMemory(backend)
+
+		require.NoError(t, backend.SetWithTTL(ScopeSession, "test-session", "token", "abc", 30*time.Second))
+		require.NoError(t, memory.Set(ctx, "config", "v"))
+
+		ttl, found, err := memory.TTL(ctx, "token")
+		require.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, 30*time.Second, ttl)
+
+		ttl, found, err = memory.TTL(ctx, "config")
+		require.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, NoExpiry, ttl)
+
+		_, found, err = memory.TTL(ctx, "missing")
+		require.NoError(t, err)
+		assert.False(t, found)
+	})
+
+	t.Run("backend without TTL support", func(t *testing.T) {
+		memory := NewMemory(NewInMemoryBackend())
+		require.NoError(t, memory.Set(ctx, "config", "v"))
+
+		ttl, found, err := memory.TTL(ctx, "config")
+		require.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, NoExpiry, ttl)
+
+		_, found, err = memory.TTL(ctx, "missing")
+		require.NoError(t, err)
+		assert.False(t, found)
+	})
+}
+
+func TestMemory_Patch(t *testing.T) {
+	ctx := contextWithExecution(context.Background(), ExecutionContext{
+		SessionID: "test-session",
+	})
+
+	t.Run("merges into existing object", func(t *testing.T) {
+		memory := NewMemory(NewInMemoryBackend())
+		require.NoError(t, memory.Set(ctx, "profile", map[string]any{"name": "ada", "role": "admin"}))
+
+		require.NoError(t, memory.Patch(ctx, "profile", map[string]any{"role": "owner", "team": "core"}))
+
+		val, err := memory.Get(ctx, "profile")
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"name": "ada", "role": "owner", "team": "core"}, val)
+	})
+
+	t.Run("merges into encoded object", func(t *testing.T) {
+		memory := NewMemory(NewInMemoryBackend())
+		type profile struct {
+			Name string `json:"name"`
+			Role string `json:"role"`
+		}
+		require.NoError(t, memory.SessionScope().SetTyped(ctx, "profile", profile{Name: "ada", Role: "admin"}))
+
+		require.NoError(t, memory.Patch(ctx, "profile", map[string]any{"role": "owner"}))
+
+		var got profile
+		require.NoError(t, memory.SessionScope().GetTyped(ctx, "profile", &got))
+		assert.Equal(t, profile{Name: "ada", Role: "owner"}, got)
+	})
+
+	t.Run("creates absent key", func(t *testing.T) {
+		memory := NewMemory(NewInMemoryBackend())
+		patch := map[string]any{"count": 1}
+
+		require.NoError(t, memory.Patch(ctx, "fresh", patch))
+		patch["count"] = 2
+
+		val, err := memory.Get(ctx, "fresh")
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"count": 1}, val, "stored object must not alias the patch")
+	})
+
+	t.Run("retries over a concurrent write", func(t *testing.T) {
+		backend := &writeAfterGetBackend{InMemoryBackend: NewInMemoryBackend(), key: "profile"}
+		memory := NewMemory(backend)
+		require.NoError(t, memory.Set(ctx, "profile", map[string]any{"name": "ada"}))
+		backend.value = map[string]any{"name": "ada", "team": "core"}
+
+		require.NoError(t, memory.Patch(ctx, "profile", map[string]any{"role": "owner"}))
+
+		val, err := memory.Get(ctx, "profile")
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"name": "ada", "team": "core", "role": "owner"}, val, "the concurrent write must survive")
+	})
+
+	t.Run("separate Memory instances do not lose updates", func(t *testing.T) {
+		backend := NewInMemoryBackend()
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				memory := NewMemory(backend)
+				assert.NoError(t, memory.Patch(ctx, "counters", map[string]any{fmt.Sprintf("field-%d", i): i}))
+			}(i)
+		}
+		wg.Wait()
+
+		val, err := NewMemory(backend).Get(ctx, "counters")
+		require.NoError(t, err)
+		assert.Len(t, val, 20)
+	})
+
+	t.Run("rejects scalar values", func(t *testing.T) {
+		memory := NewMemory(NewInMemoryBackend())
+		require.NoError(t, memory.Set(ctx, "count", 3))
+		require.NoError(t, memory.SessionScope().SetTyped(ctx, "encoded", 3))
+
+		assert.ErrorIs(t, memory.Patch(ctx, "count", map[string]any{"a": 1}), ErrPatchNotObject)
+		assert.ErrorIs(t, memory.Patch(ctx, "encoded", map[string]any{"a": 1}), ErrPatchNotObject)
+	})
+}
+
+// writeAfterGetBackend stores value at key right after the first Get of it,
+// simulating a writer landing between Patch's read and its write.
+type writeAfterGetBackend struct {
+	*InMemoryBackend
+	key   string
+	value any
+	done  bool
+}
+
+func (b *writeAfterGetBackend) Get(scope MemoryScope, scopeID, key string) (any, bool, error) {
+	val, found, err := b.InMemoryBackend.Get(scope, scopeID, key)
+	if err == nil && key == b.key && b.value != nil && !b.done {
+		b.done = true
+		err = b.InMemoryBackend.Set(scope, scopeID, key, b.value)
+	}
+	return val, found, err
+}
+
+func TestMemory_ForEach(t *testing.T) {
+	ctx := contextWithExecution(context.Background(), ExecutionContext{
+		SessionID: "test-session",
+	})
+	memory := NewMemory(NewInMemoryBackend())
+	for i := 0; i < 5; i++ {
+		require.NoError(t, memory.Set(ctx, fmt.Sprintf("key-%d", i), i))
+	}
+
+	t.Run("visits every entry", func(t *testing.T) {
+		seen := make(map[string]any)
+		err := memory.ForEach(ctx, func(key string, value any) error {
+			seen[key] = value
+			// The callback runs without backend locks held.
+			return memory.GlobalScope().Set(ctx, "last-visited", key)
+		})
+		require.NoError(t, err)
+		assert.Len(t, seen, 5)
+		assert.Equal(t, 3, seen["key-3"])
+	})
+
+	t.Run("callback error halts iteration", func(t *testing.T) {
+		stop := errors.New("stop")
+		visits := 0
+		err := memory.ForEach(ctx, func(key string, value any) error {
+			visits++
+			if visits == 2 {
+				return stop
+			}
+			return nil
+		})
+		assert.ErrorIs(t, err, stop)
+		assert.Equal(t, 2, visits)
+	})
+}
+
+func TestMemory_ListSorted(t *testing.T) {
+	ctx := contextWithExecution(context.Background(), ExecutionContext{
+		SessionID: "test-session",
+	})
+	memory := NewMemory(NewInMemoryBackend())
+	want := []string{"alpha", "bravo", "charlie", "delta", "echo", "foxtrot", "golf", "hotel"}
+	for i := len(want) - 1; i >= 0; i-- {
+		require.NoError(t, memory.Set(ctx, want[i], i))
+	}
+
+	keys, err := memory.ListSorted(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, want, keys)
+
+	var visited []string
+	require.NoError(t, memory.ForEach(ctx, func(key string, value any) error {
+		visited = append(visited, key)
+		return nil
+	}))
+	assert.Equal(t, want, visited)
+
+	sub := memory.SessionScope().Sub("team")
+	require.NoError(t, sub.Set(ctx, "b", 1))
+	require.NoError(t, sub.Set(ctx, "a", 2))
+	keys, err = sub.ListSorted(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, keys)
+}
+
+func TestMemory_ScopeSizes(t *testing.T) {
+	backend := NewInMemoryBackend()
+	memory := NewMemory(backend)
+	ctx := context.Background()
+
+	small := memory.Scoped(ScopeSession, "small")
+	large := memory.Scoped(ScopeSession, "large")
+	require.NoError(t, small.Set(ctx, "note", "hi"))
+	for i := 0; i < 40; i++ {
+		require.NoError(t, large.Set(ctx, fmt.Sprintf("doc-%d", i), strings.Repeat("x", 100)))
+	}
+
+	sizes, err := memory.ScopeSizes(ScopeSession)
+	require.NoError(t, err)
+	require.Len(t, sizes, 2)
+	assert.Equal(t, int64(len("note")+len(`"hi"`)), sizes["small"])
+	assert.Greater(t, sizes["large"], sizes["small"])
+
+	t.Run("listing fallback matches native estimate", func(t *testing.T) {
+		listingOnly := struct {
+			MemoryBackend
+			ScopeIDLister
+		}{backend, backend}
+		fallback, err := NewMemory(listingOnly).ScopeSizes(ScopeSession)
+		require.NoError(t, err)
+		assert.Equal(t, sizes, fallback)
+	})
+
+	t.Run("unsupported backend", func(t *testing.T) {
+		_, err := NewMemory(struct{ MemoryBackend }{backend}).ScopeSizes(ScopeSession)
+		assert.ErrorIs(t, err, ErrScopeListingUnsupported)
+	})
+}
+
+func TestScopedMemory_Sub(t *testing.T) {
+	ctx := contextWithExecution(context.Background(), ExecutionContext{
+		SessionID: "test-session",
+	})
+	memory := NewMemory(NewInMemoryBackend())
+	parent := memory.SessionScope()
+	lib := parent.Sub("lib")
+
+	require.NoError(t, parent.Set(ctx, "config", "app"))
+	require.NoError(t, lib.Set(ctx, "config", "lib"))
+	require.NoError(t, lib.Sub("cache").Set(ctx, "hits", 3))
+
+	t.Run("writes land under the prefix in the parent", func(t *testing.T) {
+		val, err := parent.Get(ctx, "lib/config")
+		require.NoError(t, err)
+		assert.Equal(t, "lib", val)
+
+		val, err = parent.Get(ctx, "config")
+		require.NoError(t, err)
+		assert.Equal(t, "app", val, "sub writes must not collide with the parent")
+
+		val, err = parent.Get(ctx, "lib/cache/hits")
+		require.NoError(t, err)
+		assert.Equal(t, 3, val)
+	})
+
+	t.Run("List strips the prefix", func(t *testing.T) {
+		keys, err := lib.List(ctx)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"config", "cache/hits"}, keys)
+
+		entries, err := lib.Entries(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"config": "lib", "cache/hits": 3}, entries)
+
+		parentKeys, err := parent.List(ctx)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"config", "lib/config", "lib/cache/hits"}, parentKeys)
+	})
+
+	t.Run("DeletePrefix stays inside the view", func(t *testing.T) {
+		deleted, err := lib.DeletePrefix(ctx, "c")
+		require.NoError(t, err)
+		assert.Equal(t, 2, deleted)
+
+		keys, err := parent.List(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"config"}, keys)
+	})
+}
+
+// rankedVectorBackend answers SearchVector with every stored vector in key
+// order, truncated to opts.Limit, and counts the searches it served.
+type rankedVectorBackend struct {
+	*InMemoryBackend
+	keys     []string
+	searches int
+}
+
+func (b *rankedVectorBackend) SearchVector(scope MemoryScope, scopeID string, embedding []float64, opts SearchOptions) ([]VectorSearchResult, error) {
+	b.searches++
+	var results []VectorSearchResult
+	for _, key := range b.keys {
+		if opts.Limit > 0 && len(results) == opts.Limit {
+			break
+		}
+		results = append(results, VectorSearchResult{Key: key, Scope: scope, ScopeID: scopeID})
+	}
+	return results, nil
+}
+
+func TestScopedMemory_SubSearchVector(t *testing.T) {
+	ctx := contextWithExecution(context.Background(), ExecutionContext{
+		SessionID: "test-session",
+	})
+	// The best matches all sit outside the view, so a single search limited
+	// to 2 would return nothing under "lib/".
+	backend := &rankedVectorBackend{
+		InMemoryBackend: NewInMemoryBackend(),
+		keys:            []string{"a", "b", "c", "d", "lib/x", "e", "lib/y", "lib/z"},
+	}
+	lib := NewMemory(backend).SessionScope().Sub("lib")
+
+	t.Run("fills the limit from matches under the prefix", func(t *testing.T) {
+		results, err := lib.SearchVector(ctx, []float64{1}, SearchOptions{Limit: 2})
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		assert.Equal(t, "x", results[0].Key)
+		assert.Equal(t, "y", results[1].Key)
+	})
+
+	t.Run("stops once the backend runs out", func(t *testing.T) {
+		backend.searches = 0
+		results, err := lib.SearchVector(ctx, []float64{1}, SearchOptions{Limit: 5})
+		require.NoError(t, err)
+		assert.Len(t, results, 3)
+		assert.Equal(t, 2, backend.searches)
+	})
+}
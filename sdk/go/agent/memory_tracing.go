@@ -0,0 +1,48 @@
+package agent
+
+import "context"
+
+// Tracer starts a span around each backend call a ScopedMemory makes, so
+// memory latency shows up in distributed traces. It is deliberately minimal
+// so an OpenTelemetry (or any other) tracer can be adapted without the SDK
+// depending on one. The returned func ends the span.
+type Tracer interface {
+	StartSpan(ctx context.Context, name string) (context.Context, func())
+}
+
+// MemorySpan describes the memory operation a span covers.
+type MemorySpan struct {
+	Op    string      // e.g. "get", "set", "delete_prefix"
+	Scope MemoryScope // scope the operation ran in
+}
+
+type memorySpanKey struct{}
+
+// MemorySpanFromContext returns the operation being traced when called from
+// Tracer.StartSpan, so adapters can tag the span with its scope and op.
+func MemorySpanFromContext(ctx context.Context) (MemorySpan, bool) {
+	span, ok := ctx.Value(memorySpanKey{}).(MemorySpan)
+	return span, ok
+}
+
+// WithTracer wraps every memory operation in a span named "memory.<op>"
+// started by tracer. A nil tracer disables tracing, which is the default.
+func WithTracer(tracer Tracer) MemoryOption {
+	return func(m *Memory) {
+		m.tracer = tracer
+	}
+}
+
+// call runs a backend operation through Memory.call inside a span for op.
+func (s *ScopedMemory) call(ctx context.Context, op string, fn func() error) error {
+	if s.memory.tracer == nil {
+		return s.memory.call(ctx, fn)
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx = context.WithValue(ctx, memorySpanKey{}, MemorySpan{Op: op, Scope: s.scope})
+	ctx, end := s.memory.tracer.StartSpan(ctx, "memory."+op)
+	defer end()
+	return s.memory.call(ctx, fn)
+}
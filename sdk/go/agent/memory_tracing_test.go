@@ -0,0 +1,71 @@
+package agent
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordedSpan struct {
+	name  string
+	span  MemorySpan
+	ended bool
+}
+
+type fakeTracer struct {
+	mu    sync.Mutex
+	spans []*recordedSpan
+}
+
+func (f *fakeTracer) StartSpan(ctx context.Context, name string) (context.Context, func()) {
+	info, _ := MemorySpanFromContext(ctx)
+	rec := &recordedSpan{name: name, span: info}
+	f.mu.Lock()
+	f.spans = append(f.spans, rec)
+	f.mu.Unlock()
+	return ctx, func() {
+		f.mu.Lock()
+		rec.ended = true
+		f.mu.Unlock()
+	}
+}
+
+func TestMemory_Tracer(t *testing.T) {
+	ctx := contextWithExecution(context.Background(), ExecutionContext{SessionID: "s1"})
+
+	t.Run("one finished span per get", func(t *testing.T) {
+		tracer := &fakeTracer{}
+		memory := NewMemory(NewInMemoryBackend(), WithTracer(tracer))
+
+		_, err := memory.SessionScope().Get(ctx, "missing")
+		require.NoError(t, err)
+		_, err = memory.GlobalScope().Get(ctx, "missing")
+		require.NoError(t, err)
+
+		require.Len(t, tracer.spans, 2)
+		assert.Equal(t, &recordedSpan{name: "memory.get", span: MemorySpan{Op: "get", Scope: ScopeSession}, ended: true}, tracer.spans[0])
+		assert.Equal(t, &recordedSpan{name: "memory.get", span: MemorySpan{Op: "get", Scope: ScopeGlobal}, ended: true}, tracer.spans[1])
+	})
+
+	t.Run("writes are traced by op", func(t *testing.T) {
+		tracer := &fakeTracer{}
+		memory := NewMemory(NewInMemoryBackend(), WithTracer(tracer))
+
+		require.NoError(t, memory.SessionScope().Set(ctx, "k", 1))
+		require.NoError(t, memory.SessionScope().Delete(ctx, "k"))
+
+		require.Len(t, tracer.spans, 2)
+		assert.Equal(t, "memory.set", tracer.spans[0].name)
+		assert.Equal(t, "memory.delete", tracer.spans[1].name)
+	})
+
+	t.Run("no tracer", func(t *testing.T) {
+		memory := NewMemory(NewInMemoryBackend())
+		require.NoError(t, memory.SessionScope().Set(ctx, "k", 1))
+		_, ok := MemorySpanFromContext(ctx)
+		assert.False(t, ok)
+	})
+}
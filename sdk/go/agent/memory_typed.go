@@ -0,0 +1,64 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+)
+
+// Get reads key from sm and decodes it into a T, reporting whether the key
+// exists. It wraps ScopedMemory.GetTypedFound:
+//
+//	profile, found, err := agent.Get[Profile](ctx, mem.SessionScope(), "profile")
+func Get[T any](ctx context.Context, sm *ScopedMemory, key string) (T, bool, error) {
+	var value T
+	found, err := sm.GetTypedFound(ctx, key, &value)
+	if err != nil {
+		var zero T
+		return zero, found, err
+	}
+	return value, found, nil
+}
+
+// MustGet is Get for tests and setup code: it panics on error and returns the
+// zero T when key is absent.
+func MustGet[T any](ctx context.Context, sm *ScopedMemory, key string) T {
+	value, _, err := Get[T](ctx, sm, key)
+	if err != nil {
+		panic(fmt.Sprintf("agent.MustGet %q: %v", key, err))
+	}
+	return value
+}
+
+// TypedScope is a view of a ScopedMemory holding values of a single type T,
+// encoded with the Memory's codec:
+//
+//	carts := agent.NewTypedScope[Cart](mem.SessionScope())
+//	cart, found, err := carts.Get(ctx, "cart")
+type TypedScope[T any] struct {
+	sm *ScopedMemory
+}
+
+// NewTypedScope wraps sm so its values are read and written as T.
+func NewTypedScope[T any](sm *ScopedMemory) *TypedScope[T] {
+	return &TypedScope[T]{sm: sm}
+}
+
+// Get reads key and decodes it into a T, reporting whether the key exists.
+func (ts *TypedScope[T]) Get(ctx context.Context, key string) (T, bool, error) {
+	return Get[T](ctx, ts.sm, key)
+}
+
+// Set encodes value and stores it at key.
+func (ts *TypedScope[T]) Set(ctx context.Context, key string, value T) error {
+	return ts.sm.SetTyped(ctx, key, value)
+}
+
+// Delete removes key.
+func (ts *TypedScope[T]) Delete(ctx context.Context, key string) error {
+	return ts.sm.Delete(ctx, key)
+}
+
+// List returns the keys in the underlying scope, sorted.
+func (ts *TypedScope[T]) List(ctx context.Context) ([]string, error) {
+	return ts.sm.ListSorted(ctx)
+}
@@ -0,0 +1,101 @@
+package agent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenericGet(t *testing.T) {
+	ctx := contextWithExecution(context.Background(), ExecutionContext{
+		SessionID: "test-session",
+	})
+	memory := NewMemory(NewInMemoryBackend())
+	scope := memory.SessionScope()
+
+	type profile struct {
+		Name string `json:"name"`
+		Age  int    `json:"age"`
+	}
+
+	t.Run("struct", func(t *testing.T) {
+		require.NoError(t, scope.SetTyped(ctx, "profile", profile{Name: "ada", Age: 36}))
+
+		got, found, err := Get[profile](ctx, scope, "profile")
+		require.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, profile{Name: "ada", Age: 36}, got)
+
+		got, found, err = Get[profile](ctx, scope, "missing")
+		require.NoError(t, err)
+		assert.False(t, found)
+		assert.Equal(t, profile{}, got)
+	})
+
+	t.Run("primitive", func(t *testing.T) {
+		require.NoError(t, scope.Set(ctx, "count", 42))
+
+		count, found, err := Get[int](ctx, scope, "count")
+		require.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, 42, count)
+
+		assert.Equal(t, 42, MustGet[int](ctx, scope, "count"))
+		assert.Equal(t, "", MustGet[string](ctx, scope, "missing"))
+	})
+
+	t.Run("MustGet panics on decode error", func(t *testing.T) {
+		require.NoError(t, scope.Set(ctx, "name", "ada"))
+
+		_, _, err := Get[int](ctx, scope, "name")
+		assert.Error(t, err)
+		assert.Panics(t, func() { MustGet[int](ctx, scope, "name") })
+	})
+}
+
+func TestTypedScope(t *testing.T) {
+	ctx := contextWithExecution(context.Background(), ExecutionContext{
+		SessionID: "test-session",
+	})
+	memory := NewMemory(NewInMemoryBackend())
+
+	type item struct {
+		SKU string `json:"sku"`
+		Qty int    `json:"qty"`
+	}
+	type cart struct {
+		Items []item  `json:"items"`
+		Total float64 `json:"total"`
+	}
+
+	carts := NewTypedScope[cart](memory.SessionScope())
+	want := cart{Items: []item{{SKU: "apple", Qty: 2}}, Total: 3.5}
+	require.NoError(t, carts.Set(ctx, "b", want))
+	require.NoError(t, carts.Set(ctx, "a", cart{}))
+
+	got, found, err := carts.Get(ctx, "b")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, want, got)
+
+	got, found, err = carts.Get(ctx, "missing")
+	require.NoError(t, err)
+	assert.False(t, found)
+	assert.Equal(t, cart{}, got)
+
+	keys, err := carts.List(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, keys)
+
+	require.NoError(t, carts.Delete(ctx, "a"))
+	_, found, err = carts.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	// Values are stored like SetTyped, so untyped readers see the same data.
+	var raw cart
+	require.NoError(t, memory.SessionScope().GetTyped(ctx, "b", &raw))
+	assert.Equal(t, want, raw)
+}
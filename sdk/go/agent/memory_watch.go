@@ -0,0 +1,83 @@
+package agent
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryEventOp is the kind of change a MemoryEvent reports.
+type MemoryEventOp string
+
+const (
+	// MemoryEventSet reports a write.
+	MemoryEventSet MemoryEventOp = "set"
+	// MemoryEventDelete reports an explicit delete.
+	MemoryEventDelete MemoryEventOp = "delete"
+	// MemoryEventExpired reports a key reclaimed after its TTL lapsed. Value
+	// holds the value it had.
+	MemoryEventExpired MemoryEventOp = "expired"
+)
+
+// MemoryEvent is a change delivered to Watch subscribers.
+type MemoryEvent struct {
+	Op      MemoryEventOp
+	Scope   MemoryScope
+	ScopeID string
+	Key     string
+	Value   any
+	At      time.Time
+}
+
+// memoryWatchers fans MemoryEvents out to Watch subscribers. mu is held for
+// reading while events are delivered so a subscriber's channel is never
+// closed mid-send.
+type memoryWatchers struct {
+	mu   sync.RWMutex
+	subs map[*memoryWatcher]struct{}
+}
+
+type memoryWatcher struct {
+	ctx context.Context
+	ch  chan MemoryEvent
+}
+
+// watch registers a subscriber that receives events until ctx is done, when
+// its channel is closed.
+func (w *memoryWatchers) watch(ctx context.Context) <-chan MemoryEvent {
+	sub := &memoryWatcher{ctx: ctx, ch: make(chan MemoryEvent, 64)}
+
+	w.mu.Lock()
+	if w.subs == nil {
+		w.subs = make(map[*memoryWatcher]struct{})
+	}
+	w.subs[sub] = struct{}{}
+	w.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		w.mu.Lock()
+		delete(w.subs, sub)
+		close(sub.ch)
+		w.mu.Unlock()
+	}()
+	return sub.ch
+}
+
+// deliver sends events, in order, to every current subscriber. It blocks on a
+// subscriber whose buffer is full until it reads or its ctx is done.
+func (w *memoryWatchers) deliver(events []MemoryEvent) {
+	if len(events) == 0 {
+		return
+	}
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	for _, event := range events {
+		for sub := range w.subs {
+			select {
+			case sub.ch <- event:
+			case <-sub.ctx.Done():
+			}
+		}
+	}
+}
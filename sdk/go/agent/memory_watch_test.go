@@ -0,0 +1,97 @@
+package agent
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func receiveEvent(t *testing.T, events <-chan MemoryEvent) MemoryEvent {
+	t.Helper()
+	select {
+	case event := <-events:
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("no memory event received")
+		return MemoryEvent{}
+	}
+}
+
+func assertNoEvent(t *testing.T, events <-chan MemoryEvent) {
+	t.Helper()
+	select {
+	case event, ok := <-events:
+		if ok {
+			t.Fatalf("unexpected memory event %+v", event)
+		}
+	default:
+	}
+}
+
+func TestCacheBackend_WatchExpiry(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	t.Run("sweeper reclaim emits one expired event", func(t *testing.T) {
+		b, clock := newTestCacheBackend(0, 10*time.Second)
+		events := b.Watch(ctx)
+
+		require.NoError(t, b.Set(ScopeSession, "s1", "upload", "/tmp/upload-1"))
+		set := receiveEvent(t, events)
+		assert.Equal(t, MemoryEventSet, set.Op)
+
+		*clock = clock.Add(10 * time.Second)
+		require.Equal(t, 1, b.Sweep())
+		expired := receiveEvent(t, events)
+		assert.Equal(t, MemoryEvent{
+			Op:      MemoryEventExpired,
+			Scope:   ScopeSession,
+			ScopeID: "s1",
+			Key:     "upload",
+			Value:   "/tmp/upload-1",
+			At:      *clock,
+		}, expired)
+
+		assert.Zero(t, b.Sweep())
+		_, found, err := b.Get(ScopeSession, "s1", "upload")
+		require.NoError(t, err)
+		assert.False(t, found)
+		assertNoEvent(t, events)
+	})
+
+	t.Run("reading a lapsed key reports its expiry", func(t *testing.T) {
+		b, clock := newTestCacheBackend(0, time.Second)
+		events := b.Watch(ctx)
+		require.NoError(t, b.Set(ScopeGlobal, "global", "k", 1))
+		receiveEvent(t, events)
+
+		*clock = clock.Add(time.Second)
+		_, found, err := b.Get(ScopeGlobal, "global", "k")
+		require.NoError(t, err)
+		assert.False(t, found)
+		assert.Equal(t, MemoryEventExpired, receiveEvent(t, events).Op)
+
+		assert.Zero(t, b.Sweep())
+		assertNoEvent(t, events)
+	})
+
+	t.Run("only active watchers are notified", func(t *testing.T) {
+		b, clock := newTestCacheBackend(0, time.Second)
+		watchCtx, stopWatching := context.WithCancel(ctx)
+		stopped := b.Watch(watchCtx)
+		stopWatching()
+		_, open := <-stopped
+		assert.False(t, open, "the channel is closed once ctx is done")
+
+		require.NoError(t, b.Set(ScopeGlobal, "global", "k", 1))
+		*clock = clock.Add(time.Second)
+		late := b.Watch(ctx)
+		require.Equal(t, 1, b.Sweep())
+
+		event := receiveEvent(t, late)
+		assert.Equal(t, MemoryEventExpired, event.Op, "a watcher sees expiries after it subscribed but not earlier writes")
+	})
+}
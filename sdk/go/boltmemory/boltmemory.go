@@ -0,0 +1,155 @@
+// Package boltmemory stores agent memory in an embedded bbolt database, for
+// single-binary deployments that want durable memory without an external
+// service or cgo.
+//
+// Each composite "scope:scopeID" gets its own bucket holding the scope's keys
+// with JSON values, so Get returns the generic JSON decoding (numbers as
+// float64, objects as map[string]any).
+//
+// The package lives in its own module so that agents which do not use bbolt
+// do not pull it in.
+package boltmemory
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Agent-Field/agentfield/sdk/go/agent"
+	bolt "go.etcd.io/bbolt"
+)
+
+// ErrVectorUnsupported is returned by vector operations, which are not
+// implemented on top of bbolt.
+var ErrVectorUnsupported = errors.New("bolt memory backend does not support vector operations")
+
+// BoltBackend implements agent.MemoryBackend on a bbolt database file.
+type BoltBackend struct {
+	db *bolt.DB
+}
+
+// NewBoltBackend opens (creating if needed) the database at path. bbolt holds
+// an exclusive file lock, so opening a database another process has open
+// fails after one second instead of blocking. Call Close when done.
+func NewBoltBackend(path string) (*BoltBackend, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("open bolt database: %w", err)
+	}
+	return &BoltBackend{db: db}, nil
+}
+
+// Close releases the database file.
+func (b *BoltBackend) Close() error {
+	return b.db.Close()
+}
+
+func bucketName(scope agent.MemoryScope, scopeID string) []byte {
+	return []byte(string(scope) + ":" + scopeID)
+}
+
+func encodeValue(value any) ([]byte, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("encode memory value: %w", err)
+	}
+	return data, nil
+}
+
+// Set stores a value as JSON in the scope's bucket, creating it if needed.
+func (b *BoltBackend) Set(scope agent.MemoryScope, scopeID, key string, value any) error {
+	data, err := encodeValue(value)
+	if err != nil {
+		return err
+	}
+	return b.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists(bucketName(scope, scopeID))
+		if err != nil {
+			return err
+		}
+		return bucket.Put([]byte(key), data)
+	})
+}
+
+// Get retrieves a value, decoded from its stored JSON.
+func (b *BoltBackend) Get(scope agent.MemoryScope, scopeID, key string) (any, bool, error) {
+	var data []byte
+	err := b.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(bucketName(scope, scopeID))
+		if bucket == nil {
+			return nil
+		}
+		if v := bucket.Get([]byte(key)); v != nil {
+			// Values are only valid for the life of the transaction.
+			data = append([]byte(nil), v...)
+		}
+		return nil
+	})
+	if err != nil || data == nil {
+		return nil, false, err
+	}
+
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, false, fmt.Errorf("decode memory value: %w", err)
+	}
+	return value, true, nil
+}
+
+// Delete removes key, dropping the scope's bucket once it is empty.
+func (b *BoltBackend) Delete(scope agent.MemoryScope, scopeID, key string) error {
+	name := bucketName(scope, scopeID)
+	return b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(name)
+		if bucket == nil {
+			return nil
+		}
+		if err := bucket.Delete([]byte(key)); err != nil {
+			return err
+		}
+		if k, _ := bucket.Cursor().First(); k == nil {
+			return tx.DeleteBucket(name)
+		}
+		return nil
+	})
+}
+
+// List returns the keys in a scope in byte order.
+func (b *BoltBackend) List(scope agent.MemoryScope, scopeID string) ([]string, error) {
+	var keys []string
+	err := b.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(bucketName(scope, scopeID))
+		if bucket == nil {
+			return nil
+		}
+		return bucket.ForEach(func(k, _ []byte) error {
+			keys = append(keys, string(k))
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// SetVector returns ErrVectorUnsupported.
+func (b *BoltBackend) SetVector(scope agent.MemoryScope, scopeID, key string, embedding []float64, metadata map[string]any) error {
+	return ErrVectorUnsupported
+}
+
+// GetVector returns ErrVectorUnsupported.
+func (b *BoltBackend) GetVector(scope agent.MemoryScope, scopeID, key string) ([]float64, map[string]any, bool, error) {
+	return nil, nil, false, ErrVectorUnsupported
+}
+
+// SearchVector returns ErrVectorUnsupported.
+func (b *BoltBackend) SearchVector(scope agent.MemoryScope, scopeID string, embedding []float64, opts agent.SearchOptions) ([]agent.VectorSearchResult, error) {
+	return nil, ErrVectorUnsupported
+}
+
+// DeleteVector returns ErrVectorUnsupported.
+func (b *BoltBackend) DeleteVector(scope agent.MemoryScope, scopeID, key string) error {
+	return ErrVectorUnsupported
+}
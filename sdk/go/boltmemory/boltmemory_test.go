@@ -0,0 +1,92 @@
+package boltmemory
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/Agent-Field/agentfield/sdk/go/agent"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBoltBackend(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "memory.db")
+	backend, err := NewBoltBackend(path)
+	require.NoError(t, err)
+	defer func() { _ = backend.Close() }()
+
+	t.Run("Set and Get", func(t *testing.T) {
+		require.NoError(t, backend.Set(agent.ScopeSession, "s1", "name", "ada"))
+		require.NoError(t, backend.Set(agent.ScopeSession, "s1", "profile", map[string]any{"age": 36}))
+
+		val, found, err := backend.Get(agent.ScopeSession, "s1", "name")
+		require.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, "ada", val)
+
+		val, found, err = backend.Get(agent.ScopeSession, "s1", "profile")
+		require.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, map[string]any{"age": float64(36)}, val)
+
+		_, found, err = backend.Get(agent.ScopeSession, "s2", "name")
+		require.NoError(t, err)
+		assert.False(t, found, "scope IDs are isolated")
+	})
+
+	t.Run("List", func(t *testing.T) {
+		keys, err := backend.List(agent.ScopeSession, "s1")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"name", "profile"}, keys)
+
+		keys, err = backend.List(agent.ScopeGlobal, "global")
+		require.NoError(t, err)
+		assert.Empty(t, keys)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		require.NoError(t, backend.Delete(agent.ScopeSession, "s1", "name"))
+		_, found, err := backend.Get(agent.ScopeSession, "s1", "name")
+		require.NoError(t, err)
+		assert.False(t, found)
+
+		require.NoError(t, backend.Delete(agent.ScopeSession, "s1", "profile"))
+		keys, err := backend.List(agent.ScopeSession, "s1")
+		require.NoError(t, err)
+		assert.Empty(t, keys)
+
+		assert.NoError(t, backend.Delete(agent.ScopeSession, "missing", "name"))
+	})
+
+	t.Run("works through Memory", func(t *testing.T) {
+		memory := agent.NewMemory(backend)
+		ctx := context.Background()
+		type settings struct {
+			Theme string `json:"theme"`
+		}
+		require.NoError(t, memory.GlobalScope().SetTyped(ctx, "settings", settings{Theme: "dark"}))
+
+		var got settings
+		require.NoError(t, memory.GlobalScope().GetTyped(ctx, "settings", &got))
+		assert.Equal(t, "dark", got.Theme)
+	})
+}
+
+func TestBoltBackend_PersistsAcrossReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "memory.db")
+
+	backend, err := NewBoltBackend(path)
+	require.NoError(t, err)
+	require.NoError(t, backend.Set(agent.ScopeUser, "u1", "lang", "go"))
+	require.NoError(t, backend.Close())
+
+	reopened, err := NewBoltBackend(path)
+	require.NoError(t, err)
+	defer func() { _ = reopened.Close() }()
+
+	val, found, err := reopened.Get(agent.ScopeUser, "u1", "lang")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "go", val)
+}
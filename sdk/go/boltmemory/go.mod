@@ -0,0 +1,19 @@
+module github.com/Agent-Field/agentfield/sdk/go/boltmemory
+
+go 1.22
+
+require (
+	github.com/Agent-Field/agentfield/sdk/go v0.0.0
+	github.com/stretchr/testify v1.9.0
+	go.etcd.io/bbolt v1.3.11
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/sync v0.8.0 // indirect
+	golang.org/x/sys v0.28.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/Agent-Field/agentfield/sdk/go => ../
@@ -0,0 +1,248 @@
+// Package etcdmemory stores agent memory in etcd.
+//
+// Keys are laid out as /agentfield/memory/<scope>/<scopeID>/<key> with JSON
+// values, so Get returns the generic JSON decoding (numbers as float64, objects
+// as map[string]any). Scope IDs are path-escaped so a prefix query for one scope
+// ID never matches another. Per-key expiry is backed by etcd leases.
+//
+// The package lives in its own module so that agents which do not use etcd do
+// not pull in the etcd client.
+package etcdmemory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Agent-Field/agentfield/sdk/go/agent"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// DefaultPrefix is the key prefix used by NewEtcdBackend.
+const DefaultPrefix = "/agentfield/memory"
+
+// ErrVectorUnsupported is returned by vector operations, which are not
+// implemented on top of etcd.
+var ErrVectorUnsupported = errors.New("etcd memory backend does not support vector operations")
+
+// EtcdBackend implements agent.MemoryBackend on an etcd cluster.
+type EtcdBackend struct {
+	client  *clientv3.Client
+	prefix  string
+	timeout time.Duration
+}
+
+// NewEtcdBackend creates a backend that stores keys under DefaultPrefix using
+// client. Each call is bounded by a 15 second timeout, matching the HTTP backends.
+func NewEtcdBackend(client *clientv3.Client) *EtcdBackend {
+	return &EtcdBackend{
+		client:  client,
+		prefix:  DefaultPrefix,
+		timeout: 15 * time.Second,
+	}
+}
+
+func (b *EtcdBackend) context() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), b.timeout)
+}
+
+func (b *EtcdBackend) scopePrefix(scope agent.MemoryScope, scopeID string) string {
+	return b.prefix + "/" + string(scope) + "/" + url.PathEscape(scopeID) + "/"
+}
+
+func (b *EtcdBackend) key(scope agent.MemoryScope, scopeID, key string) string {
+	return b.scopePrefix(scope, scopeID) + key
+}
+
+func encodeValue(value any) (string, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("encode memory value: %w", err)
+	}
+	return string(data), nil
+}
+
+// Set stores a value as JSON, without an expiry.
+func (b *EtcdBackend) Set(scope agent.MemoryScope, scopeID, key string, value any) error {
+	data, err := encodeValue(value)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := b.context()
+	defer cancel()
+	_, err = b.client.Put(ctx, b.key(scope, scopeID, key), data)
+	return err
+}
+
+// SetWithTTL stores value and attaches it to a new lease so etcd deletes the
+// key once ttl elapses. etcd leases have one-second granularity; ttl is rounded
+// up to the next whole second.
+func (b *EtcdBackend) SetWithTTL(scope agent.MemoryScope, scopeID, key string, value any, ttl time.Duration) error {
+	if ttl <= 0 {
+		return b.Set(scope, scopeID, key, value)
+	}
+	data, err := encodeValue(value)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := b.context()
+	defer cancel()
+	seconds := int64((ttl + time.Second - 1) / time.Second)
+	lease, err := b.client.Grant(ctx, seconds)
+	if err != nil {
+		return fmt.Errorf("grant lease: %w", err)
+	}
+	_, err = b.client.Put(ctx, b.key(scope, scopeID, key), data, clientv3.WithLease(lease.ID))
+	return err
+}
+
+// RefreshTTL implements agent.TTLRefresher by moving the key onto a new lease
+// of ttl, rounded up to whole seconds like SetWithTTL, and revoking the lease
+// it was on. The value is rewritten only if the key has not changed since it
+// was read; a concurrent write wins, keeps whatever expiry it set and
+// RefreshTTL reports false.
+func (b *EtcdBackend) RefreshTTL(scope agent.MemoryScope, scopeID, key string, ttl time.Duration) (bool, error) {
+	ctx, cancel := b.context()
+	defer cancel()
+	fullKey := b.key(scope, scopeID, key)
+	resp, err := b.client.Get(ctx, fullKey)
+	if err != nil {
+		return false, err
+	}
+	if len(resp.Kvs) == 0 {
+		return false, nil
+	}
+	kv := resp.Kvs[0]
+
+	seconds := int64((ttl + time.Second - 1) / time.Second)
+	lease, err := b.client.Grant(ctx, seconds)
+	if err != nil {
+		return false, fmt.Errorf("grant lease: %w", err)
+	}
+	txn, err := b.client.Txn(ctx).
+		If(clientv3.Compare(clientv3.ModRevision(fullKey), "=", kv.ModRevision)).
+		Then(clientv3.OpPut(fullKey, string(kv.Value), clientv3.WithLease(lease.ID))).
+		Commit()
+	if err != nil || !txn.Succeeded {
+		// Nothing was attached to the new lease; don't leave it behind.
+		_, _ = b.client.Revoke(ctx, lease.ID)
+		return false, err
+	}
+	if kv.Lease != int64(clientv3.NoLease) {
+		// Each SetWithTTL or refresh grants a lease for one key, and that key
+		// has just moved off it, so revoking deletes nothing. A lease we fail
+		// to revoke still lapses at its own TTL.
+		_, _ = b.client.Revoke(ctx, clientv3.LeaseID(kv.Lease))
+	}
+	return true, nil
+}
+
+// Get retrieves a value, decoded from its stored JSON.
+func (b *EtcdBackend) Get(scope agent.MemoryScope, scopeID, key string) (any, bool, error) {
+	ctx, cancel := b.context()
+	defer cancel()
+	resp, err := b.client.Get(ctx, b.key(scope, scopeID, key))
+	if err != nil {
+		return nil, false, err
+	}
+	if len(resp.Kvs) == 0 {
+		return nil, false, nil
+	}
+
+	var value any
+	if err := json.Unmarshal(resp.Kvs[0].Value, &value); err != nil {
+		return nil, false, fmt.Errorf("decode memory value: %w", err)
+	}
+	return value, true, nil
+}
+
+// Delete removes a key.
+func (b *EtcdBackend) Delete(scope agent.MemoryScope, scopeID, key string) error {
+	ctx, cancel := b.context()
+	defer cancel()
+	_, err := b.client.Delete(ctx, b.key(scope, scopeID, key))
+	return err
+}
+
+// List returns all keys in a scope.
+func (b *EtcdBackend) List(scope agent.MemoryScope, scopeID string) ([]string, error) {
+	prefix := b.scopePrefix(scope, scopeID)
+
+	ctx, cancel := b.context()
+	defer cancel()
+	resp, err := b.client.Get(ctx, prefix, clientv3.WithPrefix(), clientv3.WithKeysOnly())
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		keys = append(keys, strings.TrimPrefix(string(kv.Key), prefix))
+	}
+	return keys, nil
+}
+
+// TTL implements agent.TTLReader using the remaining lifetime of the key's lease.
+func (b *EtcdBackend) TTL(scope agent.MemoryScope, scopeID, key string) (time.Duration, bool, error) {
+	ctx, cancel := b.context()
+	defer cancel()
+	resp, err := b.client.Get(ctx, b.key(scope, scopeID, key))
+	if err != nil {
+		return 0, false, err
+	}
+	if len(resp.Kvs) == 0 {
+		return 0, false, nil
+	}
+	leaseID := clientv3.LeaseID(resp.Kvs[0].Lease)
+	if leaseID == clientv3.NoLease {
+		return agent.NoExpiry, true, nil
+	}
+
+	ttl, err := b.client.TimeToLive(ctx, leaseID)
+	if err != nil {
+		return 0, false, fmt.Errorf("lease time to live: %w", err)
+	}
+	if ttl.TTL <= 0 {
+		// The lease expired between the two reads.
+		return 0, false, nil
+	}
+	return time.Duration(ttl.TTL) * time.Second, true, nil
+}
+
+// Ping implements agent.Pinger by querying the status of the first endpoint.
+func (b *EtcdBackend) Ping(ctx context.Context) error {
+	endpoints := b.client.Endpoints()
+	if len(endpoints) == 0 {
+		return errors.New("etcd client has no endpoints")
+	}
+	ctx, cancel := context.WithTimeout(ctx, b.timeout)
+	defer cancel()
+	_, err := b.client.Status(ctx, endpoints[0])
+	return err
+}
+
+// SetVector returns ErrVectorUnsupported.
+func (b *EtcdBackend) SetVector(scope agent.MemoryScope, scopeID, key string, embedding []float64, metadata map[string]any) error {
+	return ErrVectorUnsupported
+}
+
+// GetVector returns ErrVectorUnsupported.
+func (b *EtcdBackend) GetVector(scope agent.MemoryScope, scopeID, key string) ([]float64, map[string]any, bool, error) {
+	return nil, nil, false, ErrVectorUnsupported
+}
+
+// SearchVector returns ErrVectorUnsupported.
+func (b *EtcdBackend) SearchVector(scope agent.MemoryScope, scopeID string, embedding []float64, opts agent.SearchOptions) ([]agent.VectorSearchResult, error) {
+	return nil, ErrVectorUnsupported
+}
+
+// DeleteVector returns ErrVectorUnsupported.
+func (b *EtcdBackend) DeleteVector(scope agent.MemoryScope, scopeID, key string) error {
+	return ErrVectorUnsupported
+}
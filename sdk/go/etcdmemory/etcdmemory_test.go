@@ -0,0 +1,226 @@
+package etcdmemory
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/Agent-Field/agentfield/sdk/go/agent"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/server/v3/embed"
+)
+
+func freeURL(t *testing.T) url.URL {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := l.Addr().String()
+	require.NoError(t, l.Close())
+	return url.URL{Scheme: "http", Host: addr}
+}
+
+func setupEtcdBackend(t *testing.T) *EtcdBackend {
+	t.Helper()
+
+	cfg := embed.NewConfig()
+	cfg.Dir = t.TempDir()
+	cfg.LogLevel = "error"
+	clientURL, peerURL := freeURL(t), freeURL(t)
+	cfg.ListenClientUrls = []url.URL{clientURL}
+	cfg.AdvertiseClientUrls = []url.URL{clientURL}
+	cfg.ListenPeerUrls = []url.URL{peerURL}
+	cfg.AdvertisePeerUrls = []url.URL{peerURL}
+	cfg.InitialCluster = cfg.InitialClusterFromName(cfg.Name)
+
+	server, err := embed.StartEtcd(cfg)
+	require.NoError(t, err)
+	t.Cleanup(server.Close)
+
+	select {
+	case <-server.Server.ReadyNotify():
+	case <-time.After(10 * time.Second):
+		t.Fatal("embedded etcd did not become ready")
+	}
+
+	client, err := clientv3.New(clientv3.Config{
+		Endpoints:   []string{clientURL.String()},
+		DialTimeout: 5 * time.Second,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = client.Close() })
+
+	return NewEtcdBackend(client)
+}
+
+func TestEtcdBackend(t *testing.T) {
+	backend := setupEtcdBackend(t)
+
+	t.Run("Set and Get", func(t *testing.T) {
+		err := backend.Set(agent.ScopeSession, "session-1", "key1", map[string]any{"name": "test", "count": 42})
+		require.NoError(t, err)
+
+		val, found, err := backend.Get(agent.ScopeSession, "session-1", "key1")
+		require.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, map[string]any{"name": "test", "count": float64(42)}, val)
+
+		_, found, err = backend.Get(agent.ScopeSession, "session-1", "missing")
+		require.NoError(t, err)
+		assert.False(t, found)
+	})
+
+	t.Run("List and Delete", func(t *testing.T) {
+		require.NoError(t, backend.Set(agent.ScopeUser, "u", "a", 1))
+		require.NoError(t, backend.Set(agent.ScopeUser, "u", "b/c", 2))
+		// A scope ID sharing the prefix must not leak into the listing.
+		require.NoError(t, backend.Set(agent.ScopeUser, "u/2", "other", 3))
+
+		keys, err := backend.List(agent.ScopeUser, "u")
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"a", "b/c"}, keys)
+
+		require.NoError(t, backend.Delete(agent.ScopeUser, "u", "a"))
+		keys, err = backend.List(agent.ScopeUser, "u")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"b/c"}, keys)
+	})
+
+	t.Run("Memory integration", func(t *testing.T) {
+		memory := agent.NewMemory(backend)
+		ctx := agent.WithExecutionContext(context.Background(), agent.ExecutionContext{SessionID: "session-2"})
+
+		type profile struct {
+			Name string `json:"name"`
+		}
+		require.NoError(t, memory.SessionScope().SetTyped(ctx, "profile", profile{Name: "ada"}))
+		var got profile
+		require.NoError(t, memory.SessionScope().GetTyped(ctx, "profile", &got))
+		assert.Equal(t, "ada", got.Name)
+
+		assert.NoError(t, memory.Ping(ctx))
+	})
+
+	t.Run("TTL expiry via lease", func(t *testing.T) {
+		require.NoError(t, backend.SetWithTTL(agent.ScopeGlobal, "global", "token", "abc", time.Second))
+		require.NoError(t, backend.Set(agent.ScopeGlobal, "global", "config", "v"))
+
+		ttl, found, err := backend.TTL(agent.ScopeGlobal, "global", "token")
+		require.NoError(t, err)
+		assert.True(t, found)
+		assert.LessOrEqual(t, ttl, time.Second)
+
+		ttl, found, err = backend.TTL(agent.ScopeGlobal, "global", "config")
+		require.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, agent.NoExpiry, ttl)
+
+		assert.Eventually(t, func() bool {
+			_, found, err := backend.Get(agent.ScopeGlobal, "global", "token")
+			return err == nil && !found
+		}, 10*time.Second, 100*time.Millisecond, "leased key should expire")
+	})
+
+	t.Run("RefreshTTL moves the key onto a new lease", func(t *testing.T) {
+		require.NoError(t, backend.Set(agent.ScopeSession, "s1", "cart", "3 items"))
+
+		found, err := backend.RefreshTTL(agent.ScopeSession, "s1", "cart", 30*time.Second)
+		require.NoError(t, err)
+		assert.True(t, found)
+
+		ttl, found, err := backend.TTL(agent.ScopeSession, "s1", "cart")
+		require.NoError(t, err)
+		assert.True(t, found)
+		assert.Greater(t, ttl, 20*time.Second)
+		assert.LessOrEqual(t, ttl, 30*time.Second)
+
+		value, _, err := backend.Get(agent.ScopeSession, "s1", "cart")
+		require.NoError(t, err)
+		assert.Equal(t, "3 items", value)
+
+		found, err = backend.RefreshTTL(agent.ScopeSession, "s1", "missing", time.Second)
+		require.NoError(t, err)
+		assert.False(t, found)
+	})
+
+	t.Run("RefreshTTL revokes the lease it replaces", func(t *testing.T) {
+		require.NoError(t, backend.SetWithTTL(agent.ScopeSession, "s3", "cart", "3 items", 30*time.Second))
+
+		ctx := context.Background()
+		before, err := backend.client.Leases(ctx)
+		require.NoError(t, err)
+
+		for i := 0; i < 3; i++ {
+			found, err := backend.RefreshTTL(agent.ScopeSession, "s3", "cart", 30*time.Second)
+			require.NoError(t, err)
+			require.True(t, found)
+		}
+
+		after, err := backend.client.Leases(ctx)
+		require.NoError(t, err)
+		assert.Len(t, after.Leases, len(before.Leases), "refreshes must not accumulate leases")
+
+		value, found, err := backend.Get(agent.ScopeSession, "s3", "cart")
+		require.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, "3 items", value)
+	})
+
+	t.Run("RefreshTTL loses to a concurrent write", func(t *testing.T) {
+		require.NoError(t, backend.Set(agent.ScopeSession, "s2", "cart", "3 items"))
+
+		kv := backend.client.KV
+		backend.client.KV = &writeAfterGetKV{KV: kv, key: backend.key(agent.ScopeSession, "s2", "cart"), value: `"4 items"`}
+		defer func() { backend.client.KV = kv }()
+
+		ctx := context.Background()
+		before, err := backend.client.Leases(ctx)
+		require.NoError(t, err)
+
+		found, err := backend.RefreshTTL(agent.ScopeSession, "s2", "cart", 30*time.Second)
+		require.NoError(t, err)
+		assert.False(t, found, "refresh must report the lost compare")
+
+		value, _, err := backend.Get(agent.ScopeSession, "s2", "cart")
+		require.NoError(t, err)
+		assert.Equal(t, "4 items", value)
+		ttl, _, err := backend.TTL(agent.ScopeSession, "s2", "cart")
+		require.NoError(t, err)
+		assert.Equal(t, agent.NoExpiry, ttl, "the concurrent write keeps its expiry")
+
+		after, err := backend.client.Leases(ctx)
+		require.NoError(t, err)
+		known := make(map[clientv3.LeaseID]bool, len(before.Leases))
+		for _, lease := range before.Leases {
+			known[lease.ID] = true
+		}
+		for _, lease := range after.Leases {
+			assert.True(t, known[lease.ID], "lease %x granted by the failed refresh was not revoked", lease.ID)
+		}
+	})
+
+	t.Run("vector operations unsupported", func(t *testing.T) {
+		err := backend.SetVector(agent.ScopeSession, "s", "k", []float64{1}, nil)
+		assert.ErrorIs(t, err, ErrVectorUnsupported)
+	})
+}
+
+// writeAfterGetKV overwrites key right after the first Get, simulating a
+// writer that lands between a read and the transaction that follows it.
+type writeAfterGetKV struct {
+	clientv3.KV
+	key, value string
+	done       bool
+}
+
+func (kv *writeAfterGetKV) Get(ctx context.Context, key string, opts ...clientv3.OpOption) (*clientv3.GetResponse, error) {
+	resp, err := kv.KV.Get(ctx, key, opts...)
+	if err == nil && key == kv.key && !kv.done {
+		kv.done = true
+		_, err = kv.KV.Put(ctx, kv.key, kv.value)
+	}
+	return resp, err
+}
@@ -4,6 +4,7 @@ go 1.21
 
 require (
 	github.com/stretchr/testify v1.8.4
+	golang.org/x/sync v0.8.0
 	gopkg.in/yaml.v3 v3.0.1
 )
 
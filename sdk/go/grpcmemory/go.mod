@@ -0,0 +1,23 @@
+module github.com/Agent-Field/agentfield/sdk/go/grpcmemory
+
+go 1.22
+
+require (
+	github.com/Agent-Field/agentfield/sdk/go v0.0.0
+	github.com/stretchr/testify v1.8.4
+	google.golang.org/grpc v1.67.3
+	google.golang.org/protobuf v1.36.6
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/net v0.28.0 // indirect
+	golang.org/x/sync v0.8.0 // indirect
+	golang.org/x/sys v0.28.0 // indirect
+	golang.org/x/text v0.17.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20240814211410-ddb44dafa142 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/Agent-Field/agentfield/sdk/go => ../
@@ -0,0 +1,193 @@
+// Package grpcmemory shares an agent.MemoryBackend across processes over gRPC.
+//
+// A central process wraps its local backend with NewServer and registers it on
+// a grpc.Server; agent processes connect with NewGRPCBackend and pass the result
+// as Config.MemoryBackend. Values travel as JSON, so Get returns the generic
+// JSON decoding (numbers as float64, objects as map[string]any).
+package grpcmemory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Agent-Field/agentfield/sdk/go/agent"
+	"github.com/Agent-Field/agentfield/sdk/go/grpcmemory/memorypb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrVectorUnsupported is returned by vector operations, which the memory
+// service does not expose yet.
+var ErrVectorUnsupported = errors.New("grpc memory backend does not support vector operations")
+
+// GRPCBackend implements agent.MemoryBackend by calling a remote MemoryService.
+type GRPCBackend struct {
+	client  memorypb.MemoryServiceClient
+	timeout time.Duration
+}
+
+// NewGRPCBackend creates a backend that issues calls over conn.
+// Each call is bounded by a 15 second timeout, matching the HTTP backends.
+func NewGRPCBackend(conn grpc.ClientConnInterface) *GRPCBackend {
+	return &GRPCBackend{
+		client:  memorypb.NewMemoryServiceClient(conn),
+		timeout: 15 * time.Second,
+	}
+}
+
+func (b *GRPCBackend) context() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), b.timeout)
+}
+
+// Set stores a value, sent to the server as JSON.
+func (b *GRPCBackend) Set(scope agent.MemoryScope, scopeID, key string, value any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("encode memory value: %w", err)
+	}
+
+	ctx, cancel := b.context()
+	defer cancel()
+	_, err = b.client.Set(ctx, &memorypb.SetRequest{
+		Scope:   string(scope),
+		ScopeId: scopeID,
+		Key:     key,
+		Value:   data,
+	})
+	return err
+}
+
+// Get retrieves a value, decoded from the JSON the server returns.
+func (b *GRPCBackend) Get(scope agent.MemoryScope, scopeID, key string) (any, bool, error) {
+	ctx, cancel := b.context()
+	defer cancel()
+	resp, err := b.client.Get(ctx, &memorypb.GetRequest{
+		Scope:   string(scope),
+		ScopeId: scopeID,
+		Key:     key,
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	if !resp.GetFound() {
+		return nil, false, nil
+	}
+
+	var value any
+	if err := json.Unmarshal(resp.GetValue(), &value); err != nil {
+		return nil, false, fmt.Errorf("decode memory value: %w", err)
+	}
+	return value, true, nil
+}
+
+// Delete removes a key.
+func (b *GRPCBackend) Delete(scope agent.MemoryScope, scopeID, key string) error {
+	ctx, cancel := b.context()
+	defer cancel()
+	_, err := b.client.Delete(ctx, &memorypb.DeleteRequest{
+		Scope:   string(scope),
+		ScopeId: scopeID,
+		Key:     key,
+	})
+	return err
+}
+
+// List returns all keys in a scope.
+func (b *GRPCBackend) List(scope agent.MemoryScope, scopeID string) ([]string, error) {
+	ctx, cancel := b.context()
+	defer cancel()
+	resp, err := b.client.List(ctx, &memorypb.ListRequest{
+		Scope:   string(scope),
+		ScopeId: scopeID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.GetKeys(), nil
+}
+
+// SetVector returns ErrVectorUnsupported.
+func (b *GRPCBackend) SetVector(scope agent.MemoryScope, scopeID, key string, embedding []float64, metadata map[string]any) error {
+	return ErrVectorUnsupported
+}
+
+// GetVector returns ErrVectorUnsupported.
+func (b *GRPCBackend) GetVector(scope agent.MemoryScope, scopeID, key string) ([]float64, map[string]any, bool, error) {
+	return nil, nil, false, ErrVectorUnsupported
+}
+
+// SearchVector returns ErrVectorUnsupported.
+func (b *GRPCBackend) SearchVector(scope agent.MemoryScope, scopeID string, embedding []float64, opts agent.SearchOptions) ([]agent.VectorSearchResult, error) {
+	return nil, ErrVectorUnsupported
+}
+
+// DeleteVector returns ErrVectorUnsupported.
+func (b *GRPCBackend) DeleteVector(scope agent.MemoryScope, scopeID, key string) error {
+	return ErrVectorUnsupported
+}
+
+// Server implements memorypb.MemoryServiceServer on top of a local backend.
+type Server struct {
+	memorypb.UnimplementedMemoryServiceServer
+	backend agent.MemoryBackend
+}
+
+// NewServer wraps backend so it can be registered with
+// memorypb.RegisterMemoryServiceServer. If backend is nil, an in-memory
+// backend is used.
+func NewServer(backend agent.MemoryBackend) *Server {
+	if backend == nil {
+		backend = agent.NewInMemoryBackend()
+	}
+	return &Server{backend: backend}
+}
+
+// Set decodes the JSON value and stores it in the wrapped backend.
+func (s *Server) Set(ctx context.Context, req *memorypb.SetRequest) (*memorypb.SetResponse, error) {
+	var value any
+	if err := json.Unmarshal(req.GetValue(), &value); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "decode value: %v", err)
+	}
+	if err := s.backend.Set(agent.MemoryScope(req.GetScope()), req.GetScopeId(), req.GetKey(), value); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &memorypb.SetResponse{}, nil
+}
+
+// Get retrieves a value from the wrapped backend, encoded as JSON.
+func (s *Server) Get(ctx context.Context, req *memorypb.GetRequest) (*memorypb.GetResponse, error) {
+	value, found, err := s.backend.Get(agent.MemoryScope(req.GetScope()), req.GetScopeId(), req.GetKey())
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if !found {
+		return &memorypb.GetResponse{}, nil
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "encode value: %v", err)
+	}
+	return &memorypb.GetResponse{Found: true, Value: data}, nil
+}
+
+// Delete removes a key from the wrapped backend.
+func (s *Server) Delete(ctx context.Context, req *memorypb.DeleteRequest) (*memorypb.DeleteResponse, error) {
+	if err := s.backend.Delete(agent.MemoryScope(req.GetScope()), req.GetScopeId(), req.GetKey()); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &memorypb.DeleteResponse{}, nil
+}
+
+// List returns all keys in a scope of the wrapped backend.
+func (s *Server) List(ctx context.Context, req *memorypb.ListRequest) (*memorypb.ListResponse, error) {
+	keys, err := s.backend.List(agent.MemoryScope(req.GetScope()), req.GetScopeId())
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &memorypb.ListResponse{Keys: keys}, nil
+}
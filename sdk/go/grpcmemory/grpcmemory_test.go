@@ -0,0 +1,99 @@
+package grpcmemory
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/Agent-Field/agentfield/sdk/go/agent"
+	"github.com/Agent-Field/agentfield/sdk/go/grpcmemory/memorypb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func setupGRPCBackend(t *testing.T) (*GRPCBackend, *agent.InMemoryBackend) {
+	t.Helper()
+
+	listener := bufconn.Listen(1024 * 1024)
+	local := agent.NewInMemoryBackend()
+
+	server := grpc.NewServer()
+	memorypb.RegisterMemoryServiceServer(server, NewServer(local))
+	go func() {
+		_ = server.Serve(listener)
+	}()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_ = conn.Close()
+		server.Stop()
+	})
+
+	return NewGRPCBackend(conn), local
+}
+
+func TestGRPCBackend_RoundTrip(t *testing.T) {
+	backend, local := setupGRPCBackend(t)
+
+	t.Run("Set and Get", func(t *testing.T) {
+		err := backend.Set(agent.ScopeSession, "session-1", "key1", map[string]any{"name": "test", "count": 42})
+		require.NoError(t, err)
+
+		val, found, err := backend.Get(agent.ScopeSession, "session-1", "key1")
+		require.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, map[string]any{"name": "test", "count": float64(42)}, val)
+
+		// The value lives in the server-side backend.
+		_, found, err = local.Get(agent.ScopeSession, "session-1", "key1")
+		require.NoError(t, err)
+		assert.True(t, found)
+	})
+
+	t.Run("Get non-existent key", func(t *testing.T) {
+		val, found, err := backend.Get(agent.ScopeSession, "session-1", "missing")
+		require.NoError(t, err)
+		assert.False(t, found)
+		assert.Nil(t, val)
+	})
+
+	t.Run("List and Delete", func(t *testing.T) {
+		require.NoError(t, backend.Set(agent.ScopeWorkflow, "wf-1", "a", "1"))
+		require.NoError(t, backend.Set(agent.ScopeWorkflow, "wf-1", "b", "2"))
+
+		keys, err := backend.List(agent.ScopeWorkflow, "wf-1")
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"a", "b"}, keys)
+
+		require.NoError(t, backend.Delete(agent.ScopeWorkflow, "wf-1", "a"))
+
+		keys, err = backend.List(agent.ScopeWorkflow, "wf-1")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"b"}, keys)
+	})
+
+	t.Run("Works behind Memory", func(t *testing.T) {
+		memory := agent.NewMemory(backend)
+		ctx := agent.WithExecutionContext(context.Background(), agent.ExecutionContext{SessionID: "session-2"})
+
+		require.NoError(t, memory.Set(ctx, "greeting", "hello"))
+		val, err := memory.Get(ctx, "greeting")
+		require.NoError(t, err)
+		assert.Equal(t, "hello", val)
+	})
+
+	t.Run("Vector operations unsupported", func(t *testing.T) {
+		err := backend.SetVector(agent.ScopeSession, "session-1", "vec", []float64{1}, nil)
+		assert.ErrorIs(t, err, ErrVectorUnsupported)
+	})
+}
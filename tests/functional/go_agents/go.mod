@@ -6,4 +6,7 @@ replace github.com/Agent-Field/agentfield/sdk/go => ../../../sdk/go
 
 require github.com/Agent-Field/agentfield/sdk/go v0.0.0-00010101000000-000000000000
 
-require gopkg.in/yaml.v3 v3.0.1 // indirect
+require (
+	golang.org/x/sync v0.8.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)